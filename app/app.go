@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,7 +10,9 @@ import (
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/risa_restfull/clients/fcm"
 	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/migration"
 	"github.com/muchlist/risa_restfull/scheduller"
+	"github.com/muchlist/risa_restfull/utils/labelcode"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 )
 
@@ -19,10 +22,18 @@ func RunApp() {
 	defer client.Disconnect(ctx) //nolint:errcheck
 	defer cancel()
 
+	// menjalankan migrasi index dan data yang belum diterapkan
+	if err := migration.Run(context.Background(), db.DB); err != nil {
+		logger.Error("migrasi gagal, aplikasi tidak dijalankan", err)
+		return
+	}
+
 	// inisasi firebase app
 	_ = fcm.Init()
 	// inisiasi jwt
 	mjwt.Init()
+	// inisiasi secret kode label qr
+	labelcode.Init()
 
 	app := fiber.New()
 
@@ -39,8 +50,17 @@ func RunApp() {
 	setupDependency()
 	mapUrls(app)
 
+	// master data dimuat sebelum scheduler dan index pencarian yang membaca daftar branch,
+	// jika gagal validasi memakai nilai awal dan cache dimuat ulang berkala
+	if err := masterDataService.WarmCache(context.Background()); err != nil {
+		logger.Error("Gagal memuat master data saat startup", err)
+	}
+
+	// index pencarian disimpan di memory, dibangun di background agar startup tidak tertahan
+	go searchService.ReindexAll(context.Background())
+
 	// menjalankan job scheduller cctv
-	scheduller.RunScheduler(genUnitService, reportService)
+	scheduller.RunScheduler(jobService)
 
 	if err := app.Listen(":3500"); err != nil {
 		logger.Error("error fiber listen", err)
@@ -50,3 +70,17 @@ func RunApp() {
 	// cleanup app
 	fmt.Println("Running cleanup tasks...")
 }
+
+// RunMigration hanya menjalankan migrasi database tanpa menjalankan fiber
+func RunMigration() error {
+	client, ctx, cancel := db.Init()
+	defer client.Disconnect(ctx) //nolint:errcheck
+	defer cancel()
+
+	if err := migration.Run(context.Background(), db.DB); err != nil {
+		return err
+	}
+
+	logger.Info("migrasi selesai")
+	return nil
+}
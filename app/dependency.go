@@ -2,21 +2,34 @@ package app
 
 import (
 	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/dao/alertdao"
 	"github.com/muchlist/risa_restfull/dao/altaicheckdao"
 	"github.com/muchlist/risa_restfull/dao/altaiphycheckdao"
+	"github.com/muchlist/risa_restfull/dao/apikeydao"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/dao/cctvdao"
 	"github.com/muchlist/risa_restfull/dao/checkdao"
 	"github.com/muchlist/risa_restfull/dao/checkitemdao"
+	"github.com/muchlist/risa_restfull/dao/commentdao"
 	"github.com/muchlist/risa_restfull/dao/computerdao"
 	"github.com/muchlist/risa_restfull/dao/configcheckdao"
+	"github.com/muchlist/risa_restfull/dao/customfielddao"
+	"github.com/muchlist/risa_restfull/dao/dataqualitydao"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
 	"github.com/muchlist/risa_restfull/dao/improvedao"
+	"github.com/muchlist/risa_restfull/dao/jobdao"
+	"github.com/muchlist/risa_restfull/dao/masterdatadao"
 	"github.com/muchlist/risa_restfull/dao/otherdao"
 	"github.com/muchlist/risa_restfull/dao/pendingreportdao"
+	"github.com/muchlist/risa_restfull/dao/pinghistorydao"
 	"github.com/muchlist/risa_restfull/dao/reportdao"
+	"github.com/muchlist/risa_restfull/dao/sladao"
 	"github.com/muchlist/risa_restfull/dao/speedtestdao"
 	"github.com/muchlist/risa_restfull/dao/stockdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dao/transferdao"
+	"github.com/muchlist/risa_restfull/dao/trashdao"
 	"github.com/muchlist/risa_restfull/dao/userdao"
 	"github.com/muchlist/risa_restfull/dao/vendorcheckdao"
 	"github.com/muchlist/risa_restfull/dao/venphycheckdao"
@@ -44,6 +57,25 @@ var (
 	speedService         service.SpeedTestServiceAssumer
 	reportService        service.ReportServiceAssumer
 	prService            service.PRServiceAssumer
+	apiKeyService        service.ApiKeyServiceAssumer
+	uptimeService        service.UptimeServiceAssumer
+	alertService         service.AlertServiceAssumer
+	jobService           service.JobServiceAssumer
+	lifecycleService     service.LifecycleServiceAssumer
+	transferService      service.TransferServiceAssumer
+	mapService           service.MapServiceAssumer
+	mergeService         service.MergeServiceAssumer
+	auditService         service.AuditServiceAssumer
+	trashService         service.TrashServiceAssumer
+	importService        service.ImportServiceAssumer
+	exportService        service.ExportServiceAssumer
+	labelService         service.LabelServiceAssumer
+	customFieldService   service.CustomFieldServiceAssumer
+	dataQualityService   service.DataQualityServiceAssumer
+	searchService        service.SearchServiceAssumer
+	masterDataService    service.MasterDataServiceAssumer
+	slaService           service.SLAServiceAssumer
+	commentService       service.CommentServiceAssumer
 )
 
 func setupDependency() {
@@ -70,30 +102,53 @@ func setupDependency() {
 	speedDao := speedtestdao.NewSpeedTestDao()
 	pdfDao := reportdao.NewPdfDao()
 	prDao := pendingreportdao.NewPR()
+	apiKeyDao := apikeydao.NewApiKeyDao()
+	pingHistoryDao := pinghistorydao.NewPingHistoryDao()
+	alertDao := alertdao.NewAlertDao()
+	jobDao := jobdao.NewJobDao()
+	auditDao := auditdao.NewAuditDao()
+	trashDao := trashdao.NewTrashDao()
+	transferDao := transferdao.NewTransferDao()
+	customFieldDao := customfielddao.NewCustomFieldDao()
+	dataQualityDao := dataqualitydao.NewDataQualityDao()
+	masterDataDao := masterdatadao.NewMasterDataDao()
+	slaDao := sladao.NewSLADao()
+	commentDao := commentdao.NewCommentDao()
+	txDao := transactiondao.NewTransactionDao()
 
 	// api client
 	fcmClient := fcm.NewFcmClient()
 
 	// Service
+	masterDataService = service.NewMasterDataService(masterDataDao, jobDao, txDao)
 	userService = service.NewUserService(userDao, cryptoUtils, jwt)
-	genUnitService = service.NewGenUnitService(genUnitDao, userDao, fcmClient)
-	historyService = service.NewHistoryService(historyDao, genUnitDao, userDao, fcmClient)
-	cctvService = service.NewCctvService(cctvDao, historyDao, genUnitDao)
-	stockService = service.NewStockService(stockDao, historyDao, userDao, fcmClient)
+	searchService = service.NewSearchService(genUnitDao, historyDao, stockDao, prDao)
+	historyService = service.NewHistoryService(historyDao, genUnitDao, userDao, fcmClient, txDao, slaDao, commentDao, searchService)
+	slaService = service.NewSLAService(slaDao, historyDao, userDao, fcmClient)
+	commentService = service.NewCommentService(commentDao, historyDao, userDao, fcmClient)
+	alertService = service.NewAlertService(alertDao, genUnitDao, userDao, historyService, fcmClient)
+	genUnitService = service.NewGenUnitService(genUnitDao, pingHistoryDao, alertService)
+	customFieldService = service.NewCustomFieldService(customFieldDao)
+	dataQualityService = service.NewDataQualityService(dataQualityDao, genUnitDao, cctvDao, computerDao, otherDao)
+	cctvService = service.NewCctvService(cctvDao, historyDao, genUnitDao, txDao, customFieldService, dataQualityService, searchService)
+	stockService = service.NewStockService(stockDao, historyDao, userDao, fcmClient, txDao, searchService)
 	checkItemService = service.NewCheckItemService(checkItemDao)
 	checkService = service.NewCheckService(checkDao, checkItemDao, genUnitDao, historyService)
 	improveService = service.NewImproveService(improveDao)
-	computerService = service.NewComputerService(computerDao, historyDao, genUnitDao)
-	otherService = service.NewOtherService(otherDao, historyDao, genUnitDao)
+	computerService = service.NewComputerService(computerDao, historyDao, genUnitDao, txDao, customFieldService, dataQualityService, searchService)
+	otherService = service.NewOtherService(otherDao, historyDao, genUnitDao, txDao, customFieldService, dataQualityService, searchService)
 	vendorCheckService = service.NewVendorCheckService(vendorCheckDao, genUnitDao, cctvDao, historyService)
 	altaiCheckService = service.NewAltaiCheckService(altaiCheckDao, genUnitDao, otherDao, historyService)
 	venPhyCheckService = service.NewVenPhyCheckService(venPhyCheckDao, genUnitDao, cctvDao, historyService)
 	altaiPhyCheckService = service.NewAltaiPhyCheckService(altaiPhyCheckDao, genUnitDao, otherDao, historyService)
 	configCheckService = service.NewConfigCheckService(configCheckDao, genUnitDao, otherDao, historyService)
 	speedService = service.NewSpeedTestService(speedDao)
-	prService = service.NewPRService(prDao, genUnitDao, userDao, fcmClient)
+	prService = service.NewPRService(prDao, genUnitDao, userDao, fcmClient, searchService)
+	apiKeyService = service.NewApiKeyService(apiKeyDao)
+	uptimeService = service.NewUptimeService(pingHistoryDao, genUnitDao)
 	reportService = service.NewReportService(service.ReportParams{
 		History:       historyDao,
+		Comment:       commentDao,
 		CheckIT:       checkDao,
 		CheckCCTV:     vendorCheckDao,
 		CheckCCTVPhy:  venPhyCheckDao,
@@ -102,5 +157,29 @@ func setupDependency() {
 		CheckConfig:   configCheckDao,
 		Stock:         stockDao,
 		Pdf:           pdfDao,
+		PingHistory:   pingHistoryDao,
+	})
+	trashService = service.NewTrashService(trashDao, txDao, searchService)
+	lifecycleService = service.NewLifecycleService(cctvDao, computerDao, otherDao, userDao, fcmClient)
+	jobService = service.NewJobService(jobDao, alertService, reportService, trashService, lifecycleService, dataQualityService, searchService, slaService)
+	auditService = service.NewAuditService(auditDao)
+	importService = service.NewImportService(cctvService, computerService, otherService, customFieldService, cctvDao, computerDao, otherDao, dataQualityService, txDao)
+	exportService = service.NewExportService(cctvDao, computerDao, otherDao, stockDao, genUnitDao, historyDao, customFieldService)
+	labelService = service.NewLabelService(cctvDao, computerDao, otherDao, genUnitDao, historyDao)
+	transferService = service.NewTransferService(transferDao, cctvDao, computerDao, otherDao, genUnitDao, historyDao, userDao, fcmClient, txDao, searchService)
+	mapService = service.NewMapService(cctvDao, computerDao, otherDao, genUnitDao)
+	mergeService = service.NewMergeService(service.MergeParams{
+		GenUnit:       genUnitDao,
+		Cctv:          cctvDao,
+		Computer:      computerDao,
+		Other:         otherDao,
+		History:       historyDao,
+		CheckCCTV:     vendorCheckDao,
+		CheckCCTVPhy:  venPhyCheckDao,
+		CheckAltai:    altaiCheckDao,
+		CheckAltaiPhy: altaiPhyCheckDao,
+		PendingReport: prDao,
+		Tx:            txDao,
+		Search:        searchService,
 	})
 }
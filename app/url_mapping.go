@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/muchlist/risa_restfull/constants/apiscope"
 	"github.com/muchlist/risa_restfull/constants/roles"
 	"github.com/muchlist/risa_restfull/handler"
 	"github.com/muchlist/risa_restfull/middleware"
@@ -14,7 +15,7 @@ func mapUrls(app *fiber.App) {
 
 	// Controller or Handler
 	pingHandler := handler.NewPingHandler()
-	optionHandler := handler.NewOptionHandler()
+	optionHandler := handler.NewOptionHandler(customFieldService)
 	userHandler := handler.NewUserHandler(userService)
 	genUnitHandler := handler.NewGenUnitHandler(genUnitService)
 	historyHandler := handler.NewHistoryHandler(historyService)
@@ -33,11 +34,30 @@ func mapUrls(app *fiber.App) {
 	speedHandler := handler.NewSpeedHandler(speedService)
 	reportHandler := handler.NewReportHandler(reportService)
 	prHandler := handler.NewPRHandler(prService)
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyService)
+	uptimeHandler := handler.NewUptimeHandler(uptimeService)
+	alertHandler := handler.NewAlertHandler(alertService)
+	jobHandler := handler.NewJobHandler(jobService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	importHandler := handler.NewImportHandler(importService)
+	exportHandler := handler.NewExportHandler(exportService)
+	labelHandler := handler.NewLabelHandler(labelService)
+	lifecycleHandler := handler.NewLifecycleHandler(lifecycleService)
+	transferHandler := handler.NewTransferHandler(transferService)
+	mapHandler := handler.NewMapHandler(mapService)
+	mergeHandler := handler.NewMergeHandler(mergeService)
+	customFieldHandler := handler.NewCustomFieldHandler(customFieldService)
+	dataQualityHandler := handler.NewDataQualityHandler(dataQualityService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	masterDataHandler := handler.NewMasterDataHandler(masterDataService)
+	slaHandler := handler.NewSLAHandler(slaService)
+	commentHandler := handler.NewCommentHandler(commentService)
 
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowHeaders: "Content-Type, Accept, Authorization",
+		AllowHeaders: "Content-Type, Accept, Authorization, X-Api-Key",
 	}))
 	app.Use(middleware.LimitRequest())
 
@@ -51,6 +71,7 @@ func mapUrls(app *fiber.App) {
 	app.Static("/image/config", "./static/image/config")
 	app.Static("/image/document", "./static/image/document")
 	app.Static("/image/sign", "./static/image/sign")
+	app.Static("/image/comment", "./static/image/comment")
 	app.Static("/image/config", "./static/image/config")
 	app.Static("/pdf", "./static/pdf")
 	app.Static("/pdf-vendor", "./static/pdf-vendor")
@@ -79,10 +100,51 @@ func mapUrls(app *fiber.App) {
 	apiAuthAdmin.Delete("/users/:user_id", userHandler.Delete)
 	apiAuthAdmin.Get("/users/:user_id/reset-password", userHandler.ResetPassword)
 
+	// API KEY ADMIN
+	apiAuthAdmin.Get("/api-keys", apiKeyHandler.Find)
+	apiAuthAdmin.Post("/api-keys", apiKeyHandler.Insert)
+	apiAuthAdmin.Post("/api-keys/:id/rotate", apiKeyHandler.Rotate)
+	apiAuthAdmin.Delete("/api-keys/:id", apiKeyHandler.Revoke)
+	apiAuthAdmin.Post("/alert-rules", alertHandler.UpsertRule)
+	apiAuthAdmin.Delete("/alert-rules/:id", alertHandler.DeleteRule)
+	apiAuthAdmin.Put("/custom-fields", customFieldHandler.UpsertSchema)
+	apiAuthAdmin.Delete("/custom-fields/:category", customFieldHandler.DeleteSchema)
+	apiAuthAdmin.Get("/data-quality", dataQualityHandler.GetReport)
+	apiAuthAdmin.Post("/data-quality/scan", dataQualityHandler.Scan)
+	apiAuthAdmin.Post("/master-data", masterDataHandler.Insert)
+	apiAuthAdmin.Put("/master-data/:id", masterDataHandler.Edit)
+	apiAuthAdmin.Delete("/master-data/:id", masterDataHandler.Delete)
+	apiAuthAdmin.Post("/sla-policies", slaHandler.UpsertPolicy)
+	apiAuthAdmin.Delete("/sla-policies/:id", slaHandler.DeletePolicy)
+	apiAuthAdmin.Get("/jobs", jobHandler.Find)
+	apiAuthAdmin.Post("/jobs", jobHandler.Insert)
+	apiAuthAdmin.Get("/jobs/:id", jobHandler.Get)
+	apiAuthAdmin.Put("/jobs/:id", jobHandler.Edit)
+	apiAuthAdmin.Delete("/jobs/:id", jobHandler.Delete)
+	apiAuthAdmin.Post("/jobs/:id/run", jobHandler.RunNow)
+	apiAuthAdmin.Get("/job-runs", jobHandler.FindRun)
+	apiAuthAdmin.Get("/audits", auditHandler.Find)
+	apiAuthAdmin.Get("/trash", trashHandler.Find)
+	apiAuthAdmin.Post("/trash/:entity/:id/restore", trashHandler.Restore)
+	apiAuthAdmin.Post("/import/:category", importHandler.Import)
+
 	// Unit GENERAL
 	api.Get("/general", middleware.NormalAuth(), genUnitHandler.Find)
-	api.Get("/general-ip", genUnitHandler.GetIPList)
-	api.Post("/general-ip-state", genUnitHandler.UpdatePingState)
+	api.Put("/general/:id/uplink", middleware.NormalAuth(), genUnitHandler.SetUplink)
+	api.Delete("/general/:id/uplink", middleware.NormalAuth(), genUnitHandler.RemoveUplink)
+	api.Get("/general-topology", middleware.NormalAuth(), genUnitHandler.Topology)
+	api.Get("/general-ip", middleware.ApiKeyAuth(apiKeyService, apiscope.GeneralIP), genUnitHandler.GetIPList)
+	api.Post("/general-ip-state", middleware.ApiKeyAuth(apiKeyService, apiscope.GeneralIPState), genUnitHandler.UpdatePingState)
+	api.Get("/general/:id/uptime", middleware.NormalAuth(), uptimeHandler.GetUnitUptime)
+	api.Get("/general-uptime", middleware.NormalAuth(), uptimeHandler.GetBranchUptime)
+
+	// ALERT
+	api.Get("/alerts", middleware.NormalAuth(), alertHandler.FindAlert)
+	api.Post("/alerts/:id/ack", middleware.NormalAuth(), alertHandler.Acknowledge)
+	api.Get("/alert-rules", middleware.NormalAuth(), alertHandler.FindRule)
+	api.Get("/custom-fields", middleware.NormalAuth(), customFieldHandler.FindSchema)
+	api.Get("/master-data", middleware.NormalAuth(), masterDataHandler.Find)
+	api.Get("/sla-policies", middleware.NormalAuth(), slaHandler.FindPolicy)
 
 	// History
 	api.Get("/histories", middleware.NormalAuth(), historyHandler.Find)
@@ -94,6 +156,15 @@ func mapUrls(app *fiber.App) {
 	api.Get("/histories-user/:id", middleware.NormalAuth(), historyHandler.FindFromUser)
 	api.Post("/histories", middleware.NormalAuth(), historyHandler.Insert)
 	api.Put("/histories/:id", middleware.NormalAuth(), historyHandler.Edit)
+	api.Put("/histories/:id/assignee", middleware.NormalAuth(), historyHandler.Assign)
+	api.Put("/histories/:id/watchers", middleware.NormalAuth(), historyHandler.SetWatchers)
+	api.Get("/histories-mine", middleware.NormalAuth(), historyHandler.FindMine)
+	api.Get("/histories-workload", middleware.NormalAuth(), historyHandler.Workload)
+	api.Get("/histories/:id/comments", middleware.NormalAuth(), commentHandler.Find)
+	api.Post("/histories/:id/comments", middleware.NormalAuth(), commentHandler.Insert)
+	api.Put("/comments/:id", middleware.NormalAuth(), commentHandler.Edit)
+	api.Delete("/comments/:id", middleware.NormalAuth(), commentHandler.Delete)
+	api.Post("/comment-attachments", middleware.NormalAuth(), commentHandler.UploadAttachment)
 	api.Post("/history-image/:id", middleware.NormalAuth(), historyHandler.UploadImage)
 	api.Post("/upload-image/", middleware.NormalAuth(), historyHandler.UploadImageWithoutParent)
 
@@ -106,6 +177,8 @@ func mapUrls(app *fiber.App) {
 	api.Get("/cctv-avail/:id/:status", middleware.NormalAuth(), cctvHandler.DisableCctv)
 	api.Post("/cctv-image/:id", middleware.NormalAuth(), cctvHandler.UploadImage)
 	api.Get("/cctv-merge/:cctv1/to/:cctv2", middleware.NormalAuth(roles.RoleAdmin), cctvHandler.Merge)
+	api.Get("/unit-merge/:unit1/to/:unit2", middleware.NormalAuth(roles.RoleAdmin), mergeHandler.Preview)
+	api.Post("/unit-merge/:unit1/to/:unit2", middleware.NormalAuth(roles.RoleAdmin), mergeHandler.Merge)
 
 	// COMPUTER
 	api.Post("/computer", middleware.NormalAuth(), computerHandler.Insert)
@@ -137,6 +210,35 @@ func mapUrls(app *fiber.App) {
 	api.Get("/stock-avail/:id/:status", middleware.NormalAuth(), stockHandler.DisableStock)
 	api.Post("/stock-image/:id", middleware.NormalAuth(), stockHandler.UploadImage)
 
+	// EXPORT
+	api.Get("/export/cctv", middleware.NormalAuth(), exportHandler.Cctv)
+	api.Get("/export/computer", middleware.NormalAuth(), exportHandler.Computer)
+	api.Get("/export/others/:cat", middleware.NormalAuth(), exportHandler.Other)
+	api.Get("/export/stock", middleware.NormalAuth(), exportHandler.Stock)
+
+	// LABEL QR
+	api.Get("/labels/:kind", middleware.NormalAuth(), labelHandler.Generate)
+	api.Get("/scan/:code", middleware.NormalAuth(), labelHandler.Scan)
+
+	// LIFECYCLE
+	api.Get("/lifecycle/flags", middleware.NormalAuth(), lifecycleHandler.FindFlagged)
+	api.Get("/lifecycle/report", middleware.NormalAuth(), lifecycleHandler.Report)
+
+	// TRANSFER
+	api.Post("/transfers", middleware.NormalAuth(), transferHandler.Insert)
+	api.Get("/transfers", middleware.NormalAuth(), transferHandler.Find)
+	api.Get("/transfers/:id", middleware.NormalAuth(), transferHandler.Get)
+	api.Post("/transfers/:id/accept", middleware.NormalAuth(roles.RoleApprove), transferHandler.Accept)
+	api.Post("/transfers/:id/reject", middleware.NormalAuth(roles.RoleApprove), transferHandler.Reject)
+	api.Post("/transfers/:id/cancel", middleware.NormalAuth(), transferHandler.Cancel)
+
+	// MAP
+	api.Get("/map", middleware.NormalAuth(), mapHandler.FindMap)
+	api.Get("/map/near", middleware.NormalAuth(), mapHandler.FindNear)
+
+	// SEARCH
+	api.Get("/search", middleware.NormalAuth(), searchHandler.Search)
+
 	// CHECK ITEM
 	api.Post("/check-item", middleware.NormalAuth(), checkItemHandler.Insert)
 	api.Get("/check-item/:id", middleware.NormalAuth(), checkItemHandler.GetCheckItem)
@@ -0,0 +1,16 @@
+package alertstate
+
+// state dari alert state machine gen_unit
+// OK -> DEGRADED -> DOWN -> RECOVERED -> OK
+// UNREACHABLE menggantikan DOWN jika unit induk (uplink) sedang DOWN / UNREACHABLE
+const (
+	OK          = "OK"
+	Degraded    = "DEGRADED"
+	Down        = "DOWN"
+	Recovered   = "RECOVERED"
+	Unreachable = "UNREACHABLE"
+)
+
+func GetAlertStateAvailable() []string {
+	return []string{OK, Degraded, Down, Recovered, Unreachable}
+}
@@ -0,0 +1,11 @@
+package apiscope
+
+// scope endpoint yang dapat diakses menggunakan api key (machine to machine)
+const (
+	GeneralIP      = "GENERAL-IP"
+	GeneralIPState = "GENERAL-IP-STATE"
+)
+
+func GetScopeAvailable() []string {
+	return []string{GeneralIP, GeneralIPState}
+}
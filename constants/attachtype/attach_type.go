@@ -0,0 +1,11 @@
+package attachtype
+
+// jenis lampiran komentar history, ditentukan dari ekstensi file
+const (
+	Image = "IMAGE"
+	PDF   = "PDF"
+)
+
+func GetAttachTypeAvailable() []string {
+	return []string{Image, PDF}
+}
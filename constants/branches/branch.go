@@ -9,6 +9,7 @@ const (
 	Other       = "OTHER"
 )
 
+// GetBranchesAvailable nilai awal master data BRANCH, validasi membaca dari utils/masterdata
 func GetBranchesAvailable() []string {
 	return []string{Banjarmasin, Sampit, Kumai, Kotabaru, Batulicin, Other}
 }
@@ -21,6 +21,7 @@ func GetCategoryAvailable() []string {
 	return []string{Cctv, PC, Stock}
 }
 
+// GetSubCategoryAvailable nilai awal master data SUB_CATEGORY, validasi membaca dari utils/masterdata
 func GetSubCategoryAvailable() []string {
 	return []string{
 		Application,
@@ -11,6 +11,7 @@ const (
 	Lainnya     = "LAINNYA"
 )
 
+// GetCheckTypeAvailable nilai awal master data CHECK_TYPE, validasi membaca dari utils/masterdata
 func GetCheckTypeAvailable() []string {
 	return []string{Outstanding, Jaringan, Gate, Aplikasi, Ups, Server, Improvement, Lainnya}
 }
@@ -0,0 +1,12 @@
+package dataquality
+
+// jenis temuan pada pemindaian data quality
+const (
+	IPConflict         = "IP_CONFLICT"
+	DuplicateInventory = "DUPLICATE_INVENTORY"
+	SimilarName        = "SIMILAR_NAME"
+)
+
+func GetFindingTypeAvailable() []string {
+	return []string{IPConflict, DuplicateInventory, SimilarName}
+}
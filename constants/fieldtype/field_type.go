@@ -0,0 +1,14 @@
+package fieldtype
+
+// tipe custom field yang dapat didefinisikan admin per kategori
+const (
+	Text   = "TEXT"
+	Number = "NUMBER"
+	Bool   = "BOOL"
+	Date   = "DATE" // unix detik
+	Enum   = "ENUM"
+)
+
+func GetFieldTypeAvailable() []string {
+	return []string{Text, Number, Bool, Date, Enum}
+}
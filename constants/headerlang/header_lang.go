@@ -0,0 +1,11 @@
+package headerlang
+
+// bahasa header export yang disimpan pada master data BRANCH
+const (
+	ID = "id"
+	EN = "en"
+)
+
+func GetHeaderLangAvailable() []string {
+	return []string{ID, EN}
+}
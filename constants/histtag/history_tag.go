@@ -0,0 +1,7 @@
+package histtag
+
+const (
+	// SystemAuto tag untuk insiden yang dibuat otomatis oleh sistem dari hasil ping,
+	// membedakan dengan insiden yang dilaporkan oleh user
+	SystemAuto = "SYSTEM-AUTO"
+)
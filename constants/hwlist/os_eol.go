@@ -0,0 +1,22 @@
+package hwlist
+
+import "time"
+
+// GetPCOSEndOfLife tanggal berakhirnya dukungan vendor (unix detik) untuk OS pada GetPCOSAvailable.
+// OS tanpa versi yang jelas (Windows Server, Mac Os, Linux, other) tidak terdaftar sehingga dianggap tidak diketahui
+func GetPCOSEndOfLife() map[string]int64 {
+	return map[string]int64{
+		winXp:  eolDate(2014, time.April, 8),
+		win732: eolDate(2020, time.January, 14),
+		win764: eolDate(2020, time.January, 14),
+		// windows 8 memakai tanggal windows 8.1 karena daftar OS tidak membedakannya
+		win832:  eolDate(2023, time.January, 10),
+		win864:  eolDate(2023, time.January, 10),
+		win1032: eolDate(2025, time.October, 14),
+		win1064: eolDate(2025, time.October, 14),
+	}
+}
+
+func eolDate(year int, month time.Month, day int) int64 {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()
+}
@@ -0,0 +1,21 @@
+package jobtype
+
+const (
+	PingAlert      = "PING-ALERT"
+	VendorMonthly  = "VENDOR-MONTHLY"
+	VendorDaily    = "VENDOR-DAILY"
+	StockRestock   = "STOCK-RESTOCK"
+	TrashPurge     = "TRASH-PURGE"
+	LifecycleAlert = "LIFECYCLE-ALERT"
+	DataQuality    = "DATA-QUALITY"
+	SearchReindex  = "SEARCH-REINDEX"
+	SLAEscalation  = "SLA-ESCALATION"
+
+	// Trigger menandai asal eksekusi job pada run log
+	TriggerSchedule = "SCHEDULE"
+	TriggerManual   = "MANUAL"
+)
+
+func GetJobTypeAvailable() []string {
+	return []string{PingAlert, VendorMonthly, VendorDaily, StockRestock, TrashPurge, LifecycleAlert, DataQuality, SearchReindex, SLAEscalation}
+}
@@ -0,0 +1,36 @@
+package lifecycle
+
+// alasan aset ditandai pada pengecekan lifecycle
+const (
+	WarrantyExpiring = "WARRANTY-EXPIRING"
+	WarrantyExpired  = "WARRANTY-EXPIRED"
+	OSEndOfLife      = "OS-EOL"
+	OSEndOfLifeSoon  = "OS-EOL-SOON"
+	EndOfLife        = "END-OF-LIFE"
+)
+
+// status aset pada laporan lifecycle, satu aset hanya memiliki satu status dengan urutan prioritas
+// EOL -> WARRANTY-EXPIRED -> IN-WARRANTY -> UNKNOWN
+const (
+	StatusEOL             = "EOL"
+	StatusWarrantyExpired = "WARRANTY-EXPIRED"
+	StatusInWarranty      = "IN-WARRANTY"
+	StatusUnknown         = "UNKNOWN"
+)
+
+// kelompok umur aset dalam tahun
+const (
+	AgeUnder1  = "0-1"
+	Age1To3    = "1-3"
+	Age3To5    = "3-5"
+	AgeOver5   = ">5"
+	AgeUnknown = "UNKNOWN"
+)
+
+func GetStatusAvailable() []string {
+	return []string{StatusEOL, StatusWarrantyExpired, StatusInWarranty, StatusUnknown}
+}
+
+func GetAgeAvailable() []string {
+	return []string{AgeUnder1, Age1To3, Age3To5, AgeOver5, AgeUnknown}
+}
@@ -16,6 +16,7 @@ const (
 	divServer         = "Server"
 )
 
+// GetDivisionAvailable nilai awal master data DIVISION, validasi membaca dari utils/masterdata
 func GetDivisionAvailable() []string {
 	return []string{
 		divSdm,
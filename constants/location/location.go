@@ -19,6 +19,7 @@ const (
 	Lainnya   = "Lainnya"
 )
 
+// GetLocationAvailable nilai awal master data LOCATION, validasi membaca dari utils/masterdata
 func GetLocationAvailable() []string {
 	return []string{
 		Regional,
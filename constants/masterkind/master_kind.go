@@ -0,0 +1,46 @@
+package masterkind
+
+// jenis master data yang dapat dikelola admin.
+// category utama (CCTV, PC, STOCK) tetap berupa konstanta karena masing-masing memiliki collection sendiri
+const (
+	Branch        = "BRANCH"
+	SubCategory   = "SUB_CATEGORY"
+	Location      = "LOCATION"
+	Division      = "DIVISION"
+	StockCategory = "STOCK_CATEGORY"
+	CheckType     = "CHECK_TYPE"
+	CctvType      = "CCTV_TYPE"
+	ComputerType  = "COMPUTER_TYPE"
+	PcOS          = "PC_OS"
+	PcProcessor   = "PC_PROCESSOR"
+	PcRam         = "PC_RAM"
+	PcHDD         = "PC_HDD"
+)
+
+func GetKindAvailable() []string {
+	return []string{
+		Branch,
+		SubCategory,
+		Location,
+		Division,
+		StockCategory,
+		CheckType,
+		CctvType,
+		ComputerType,
+		PcOS,
+		PcProcessor,
+		PcRam,
+		PcHDD,
+	}
+}
+
+// GetUpperCaseKind nilai master data yang disimpan dalam huruf kapital karena dicocokkan secara exact
+// dengan field pada collection lain (misalnya branch dan category)
+func GetUpperCaseKind() []string {
+	return []string{Branch, SubCategory, StockCategory, CheckType}
+}
+
+// GetNumericKind nilai master data yang dikembalikan sebagai angka pada option
+func GetNumericKind() []string {
+	return []string{PcRam, PcHDD}
+}
@@ -0,0 +1,16 @@
+package priority
+
+// prioritas insiden (history), menentukan target SLA respon dan penyelesaian
+const (
+	Low      = "LOW"
+	Medium   = "MEDIUM"
+	High     = "HIGH"
+	Critical = "CRITICAL"
+
+	// Default digunakan jika history tidak menyertakan prioritas
+	Default = Medium
+)
+
+func GetPriorityAvailable() []string {
+	return []string{Low, Medium, High, Critical}
+}
@@ -0,0 +1,13 @@
+package searchtype
+
+// tipe dokumen pada index pencarian global
+const (
+	Unit          = "UNIT"
+	History       = "HISTORY"
+	Stock         = "STOCK"
+	PendingReport = "PENDING_REPORT"
+)
+
+func GetSearchTypeAvailable() []string {
+	return []string{Unit, History, Stock, PendingReport}
+}
@@ -0,0 +1,18 @@
+package slastate
+
+// state SLA insiden, dihitung dari date_start, update pertama dan date_end
+// ON_TRACK -> RESPONSE_BREACHED -> RESOLVE_BREACHED untuk insiden yang masih berjalan,
+// MET / MISSED untuk insiden yang sudah selesai, PAUSED selama insiden pending
+const (
+	None             = ""
+	OnTrack          = "ON_TRACK"
+	ResponseBreached = "RESPONSE_BREACHED"
+	ResolveBreached  = "RESOLVE_BREACHED"
+	Paused           = "PAUSED"
+	Met              = "MET"
+	Missed           = "MISSED"
+)
+
+func GetSLAStateAvailable() []string {
+	return []string{OnTrack, ResponseBreached, ResolveBreached, Paused, Met, Missed}
+}
@@ -14,6 +14,7 @@ const (
 	Lainnya      = "LAINNYA"
 )
 
+// GetStockCategoryAvailable nilai awal master data STOCK_CATEGORY, validasi membaca dari utils/masterdata
 func GetStockCategoryAvailable() []string {
 	return []string{
 		Computer,
@@ -0,0 +1,14 @@
+package transferstate
+
+// state dari permintaan transfer aset antar cabang
+// PENDING -> ACCEPTED | REJECTED | CANCELLED
+const (
+	Pending   = "PENDING"
+	Accepted  = "ACCEPTED"
+	Rejected  = "REJECTED"
+	Cancelled = "CANCELLED"
+)
+
+func GetTransferStateAvailable() []string {
+	return []string{Pending, Accepted, Rejected, Cancelled}
+}
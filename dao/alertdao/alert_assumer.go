@@ -0,0 +1,25 @@
+package alertdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AlertDaoAssumer interface {
+	AlertSaver
+	AlertLoader
+}
+
+type AlertSaver interface {
+	UpsertRule(ctx context.Context, input dto.AlertRule) (*dto.AlertRule, rest_err.APIError)
+	DeleteRule(ctx context.Context, ruleID primitive.ObjectID) rest_err.APIError
+	InsertLog(ctx context.Context, input dto.AlertLog) (*string, rest_err.APIError)
+	AckLog(ctx context.Context, input dto.AlertLogAck) (*dto.AlertLog, rest_err.APIError)
+}
+
+type AlertLoader interface {
+	FindRule(ctx context.Context) ([]dto.AlertRule, rest_err.APIError)
+	FindLog(ctx context.Context, filter dto.FilterAlertLog, page dto.PageRequest) ([]dto.AlertLog, dto.PageInfo, rest_err.APIError)
+}
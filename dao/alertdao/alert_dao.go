@@ -0,0 +1,294 @@
+package alertdao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout   = 3
+	keyAlertRuleColl = "alertRule"
+	keyAlertLogColl  = "alertLog"
+	defaultLimitLog  = 100
+
+	keyAlertID          = "_id"
+	keyAlertBranch      = "branch"
+	keyAlertCategory    = "category"
+	keyAlertUpdatedAt   = "updated_at"
+	keyAlertUpdatedBy   = "updated_by"
+	keyAlertUpdatedByID = "updated_by_id"
+	keyAlertDegradedTh  = "degraded_threshold"
+	keyAlertDownTh      = "down_threshold"
+	keyAlertRecoverTh   = "recover_threshold"
+	keyAlertNotifyDeg   = "notify_degraded"
+	keyAlertAutoCase    = "auto_case_after"
+	keyAlertDisable     = "disable"
+
+	keyLogTime         = "time"
+	keyLogUnitID       = "unit_id"
+	keyLogToState      = "to_state"
+	keyLogAcknowledged = "acknowledged"
+	keyLogAckAt        = "ack_at"
+	keyLogAckBy        = "ack_by"
+	keyLogAckByID      = "ack_by_id"
+	keyLogAckNote      = "ack_note"
+)
+
+func NewAlertDao() AlertDaoAssumer {
+	return &alertDao{}
+}
+
+type alertDao struct{}
+
+// UpsertRule menyimpan rule, rule bersifat unik per branch dan category
+func (a *alertDao) UpsertRule(ctx context.Context, input dto.AlertRule) (*dto.AlertRule, rest_err.APIError) {
+	coll := db.DB.Collection(keyAlertRuleColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetUpsert(true)
+
+	filter := bson.M{
+		keyAlertBranch:   strings.ToUpper(input.Branch),
+		keyAlertCategory: strings.ToUpper(input.Category),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyAlertUpdatedAt:   input.UpdatedAt,
+			keyAlertUpdatedBy:   input.UpdatedBy,
+			keyAlertUpdatedByID: input.UpdatedByID,
+			keyAlertDegradedTh:  input.DegradedThreshold,
+			keyAlertDownTh:      input.DownThreshold,
+			keyAlertRecoverTh:   input.RecoverThreshold,
+			keyAlertNotifyDeg:   input.NotifyDegraded,
+			keyAlertAutoCase:    input.AutoCaseAfter,
+			keyAlertDisable:     input.Disable,
+		},
+	}
+
+	// rule dicari berdasarkan branch dan kategori, snapshot tidak bisa memakai id
+	var before bson.M
+	_ = coll.FindOne(ctxt, filter).Decode(&before)
+
+	var rule dto.AlertRule
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&rule); err != nil {
+		logger.Error("Gagal menyimpan alert rule ke database (UpsertRule)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan alert rule ke database", err)
+		return nil, apiErr
+	}
+
+	action := audit.ActionEdit
+	if before == nil {
+		action = audit.ActionInsert
+	}
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   action,
+		Entity:   keyAlertRuleColl,
+		EntityID: rule.ID.Hex(),
+		Branch:   rule.Branch,
+		Before:   before,
+		After:    rule,
+	})
+
+	return &rule, nil
+}
+
+func (a *alertDao) DeleteRule(ctx context.Context, ruleID primitive.ObjectID) rest_err.APIError {
+	coll := db.DB.Collection(keyAlertRuleColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	before := auditdao.Snapshot(ctx, keyAlertRuleColl, ruleID)
+	result, err := coll.DeleteOne(ctxt, bson.M{keyAlertID: ruleID})
+	if err != nil {
+		logger.Error("Gagal menghapus alert rule dari database (DeleteRule)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus alert rule dari database", err)
+		return apiErr
+	}
+
+	if result.DeletedCount == 0 {
+		return rest_err.NewBadRequestError("Alert rule gagal dihapus, dokumen tidak ditemukan")
+	}
+
+	branch, _ := before[keyAlertBranch].(string)
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyAlertRuleColl,
+		EntityID: ruleID.Hex(),
+		Branch:   branch,
+		Before:   before,
+	})
+
+	return nil
+}
+
+func (a *alertDao) InsertLog(ctx context.Context, input dto.AlertLog) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyAlertLogColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.Branch = strings.ToUpper(input.Branch)
+	input.Category = strings.ToUpper(input.Category)
+	if input.PingsState == nil {
+		input.PingsState = []dto.PingState{}
+	}
+	input.Acknowledged = false
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan alert log ke database", err)
+		logger.Error("Gagal menyimpan alert log ke database (InsertLog)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	return &insertID, nil
+}
+
+func (a *alertDao) AckLog(ctx context.Context, input dto.AlertLogAck) (*dto.AlertLog, rest_err.APIError) {
+	coll := db.DB.Collection(keyAlertLogColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyAlertID:         input.FilterID,
+		keyLogAcknowledged: false,
+	}
+	if input.FilterBranch != "" {
+		filter[keyAlertBranch] = strings.ToUpper(input.FilterBranch)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyLogAcknowledged: true,
+			keyLogAckAt:        input.AckAt,
+			keyLogAckBy:        input.AckBy,
+			keyLogAckByID:      input.AckByID,
+			keyLogAckNote:      input.AckNote,
+		},
+	}
+
+	var log dto.AlertLog
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&log); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Alert tidak diupdate karena ID tidak valid atau sudah di acknowledge")
+		}
+
+		logger.Error("Gagal acknowledge alert log (AckLog)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal acknowledge alert log", err)
+		return nil, apiErr
+	}
+
+	return &log, nil
+}
+
+func (a *alertDao) FindRule(ctx context.Context) ([]dto.AlertRule, rest_err.APIError) {
+	coll := db.DB.Collection(keyAlertRuleColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyAlertCategory, Value: 1}, {Key: keyAlertBranch, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan alert rule dari database (FindRule)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.AlertRule{}, apiErr
+	}
+
+	rules := make([]dto.AlertRule, 0)
+	if err = cursor.All(ctxt, &rules); err != nil {
+		logger.Error("Gagal decode alert rule cursor ke objek slice (FindRule)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.AlertRule{}, apiErr
+	}
+
+	return rules, nil
+}
+
+func (a *alertDao) FindLog(ctx context.Context, filterA dto.FilterAlertLog, page dto.PageRequest) ([]dto.AlertLog, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyAlertLogColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, defaultLimitLog, paging.SortKey{Field: keyLogTime, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filter := bson.M{}
+	if filterA.FilterBranch != "" {
+		filter[keyAlertBranch] = strings.ToUpper(filterA.FilterBranch)
+	}
+	if filterA.FilterCategory != "" {
+		filter[keyAlertCategory] = strings.ToUpper(filterA.FilterCategory)
+	}
+	if filterA.FilterUnitID != "" {
+		filter[keyLogUnitID] = filterA.FilterUnitID
+	}
+	if filterA.FilterToState != "" {
+		filter[keyLogToState] = strings.ToUpper(filterA.FilterToState)
+	}
+	if filterA.FilterUnAckOnly {
+		filter[keyLogAcknowledged] = false
+	}
+	if filterA.FilterStart != 0 || filterA.FilterEnd != 0 {
+		timeFilter := bson.M{}
+		if filterA.FilterStart != 0 {
+			timeFilter["$gte"] = filterA.FilterStart
+		}
+		if filterA.FilterEnd != 0 {
+			timeFilter["$lte"] = filterA.FilterEnd
+		}
+		filter[keyLogTime] = timeFilter
+	}
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah alert log dari database (FindLog)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan alert log dari database (FindLog)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.AlertLog{}, dto.PageInfo{}, apiErr
+	}
+
+	logs := make([]dto.AlertLog, 0)
+	if err = cursor.All(ctxt, &logs); err != nil {
+		logger.Error("Gagal decode alert log cursor ke objek slice (FindLog)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.AlertLog{}, dto.PageInfo{}, apiErr
+	}
+
+	return logs, query.Info(&logs, total), nil
+}
@@ -9,8 +9,11 @@ import (
 
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -67,6 +70,14 @@ func (c *checkAltaiDao) InsertCheck(ctx context.Context, input dto.AltaiCheck) (
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -98,6 +109,7 @@ func (c *checkAltaiDao) EditCheck(ctx context.Context, input dto.AltaiCheckEdit)
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var check dto.AltaiCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -109,6 +121,15 @@ func (c *checkAltaiDao) EditCheck(ctx context.Context, input dto.AltaiCheckEdit)
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -135,6 +156,14 @@ func (c *checkAltaiDao) DeleteCheck(ctx context.Context, input dto.FilterIDBranc
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -157,6 +186,7 @@ func (c *checkAltaiDao) UploadChildImage(ctx context.Context, filterA dto.Filter
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, filterA.FilterParentID)
 	var check dto.AltaiCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -168,6 +198,15 @@ func (c *checkAltaiDao) UploadChildImage(ctx context.Context, filterA dto.Filter
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -196,6 +235,7 @@ func (c *checkAltaiDao) UpdateCheckItem(ctx context.Context, input dto.AltaiChec
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterParentID)
 	var check dto.AltaiCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -207,6 +247,15 @@ func (c *checkAltaiDao) UpdateCheckItem(ctx context.Context, input dto.AltaiChec
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -220,7 +269,9 @@ func (c *checkAltaiDao) BulkUpdateItem(ctx context.Context, inputs []dto.AltaiCh
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -240,6 +291,7 @@ func (c *checkAltaiDao) BulkUpdateItem(ctx context.Context, inputs []dto.AltaiCh
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -252,6 +304,8 @@ func (c *checkAltaiDao) BulkUpdateItem(ctx context.Context, inputs []dto.AltaiCh
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -281,13 +335,14 @@ func (c *checkAltaiDao) GetCheckByID(ctx context.Context, checkID primitive.Obje
 	return &check, nil
 }
 
-func (c *checkAltaiDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.AltaiCheck, rest_err.APIError) {
+func (c *checkAltaiDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.AltaiCheck, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -303,31 +358,40 @@ func (c *checkAltaiDao) FindCheck(ctx context.Context, branch string, filterA dt
 		filter[keyCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah altai check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	if !detail {
 		opts.SetProjection(bson.M{
 			keyAltaiCheckItems: 0,
 		})
 	}
-	opts.SetSort(bson.D{{Key: keyUpdatedAt, Value: -1}}) //nolint:govet
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("gagal mendapatkan daftar altai check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.AltaiCheck{}, apiErr
+		return []dto.AltaiCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	var checkList []dto.AltaiCheck
+	checkList := []dto.AltaiCheck{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (AltaiFindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.AltaiCheck{}, apiErr
+		return []dto.AltaiCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 func (c *checkAltaiDao) GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string) (*dto.AltaiCheck, rest_err.APIError) {
@@ -369,3 +433,77 @@ func (c *checkAltaiDao) GetLastCheckCreateRange(ctx context.Context, start, end
 
 	return &check, nil
 }
+
+// CountUnitRef menghitung check altai pada branch yang memuat unit, digunakan pada preview penggabungan unit
+func (c *checkAltaiDao) CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyBranch: strings.ToUpper(branch),
+		keyChXId:  unitID,
+	}
+
+	count, err := coll.CountDocuments(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghitung referensi unit pada check altai (CountUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghitung referensi unit pada check altai", err)
+		return 0, apiErr
+	}
+
+	return count, nil
+}
+
+// ReplaceUnitRef mengganti item unit lama menjadi unit baru pada seluruh check altai branch.
+// jika check altai sudah memuat unit baru, item unit lama dihapus supaya tidak ganda
+func (c *checkAltaiDao) ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	branch := strings.ToUpper(input.Branch)
+
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyBranch: branch, keyChXId: input.FromID})
+	filterBoth := bson.M{
+		keyBranch: branch,
+		"$and":    bson.A{bson.M{keyChXId: input.FromID}, bson.M{keyChXId: input.ToID}},
+	}
+	pull := bson.M{
+		"$pull": bson.M{
+			keyAltaiCheckItems: bson.M{"id": input.FromID},
+		},
+	}
+	pulled, err := coll.UpdateMany(ctxt, filterBoth, pull)
+	if err != nil {
+		logger.Error("Gagal menghapus item unit ganda pada check altai (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check altai", err)
+		return 0, apiErr
+	}
+
+	opts := options.Update()
+	opts.SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.id": input.FromID}},
+	})
+
+	filter := bson.M{
+		keyBranch: branch,
+		keyChXId:  input.FromID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			keyAltaiCheckItems + ".$[item].id":   input.ToID,
+			keyAltaiCheckItems + ".$[item].name": input.ToName,
+		},
+	}
+	replaced, err := coll.UpdateMany(ctxt, filter, update, opts)
+	if err != nil {
+		logger.Error("Gagal mengganti referensi unit pada check altai (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check altai", err)
+		return 0, apiErr
+	}
+
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
+	return pulled.ModifiedCount + replaced.ModifiedCount, nil
+}
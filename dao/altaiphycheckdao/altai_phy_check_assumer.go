@@ -20,11 +20,13 @@ type CheckAltaiPhySaver interface {
 	UpdateCheckItem(ctx context.Context, input dto.AltaiPhyCheckItemUpdate) (*dto.AltaiPhyCheck, rest_err.APIError)
 	BulkUpdateItem(ctx context.Context, inputs []dto.AltaiPhyCheckItemUpdate) (int64, rest_err.APIError)
 	BulkUpdateItemForCheckUpdate(ctx context.Context, inputs []dto.AltaiPhyCheckItemUpdate) (int64, rest_err.APIError)
+	ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError)
 }
 
 type CheckAltaiPhyLoader interface {
 	GetCheckByID(ctx context.Context, checkID primitive.ObjectID, branchIfSpecific string) (*dto.AltaiPhyCheck, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.AltaiPhyCheck, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.AltaiPhyCheck, dto.PageInfo, rest_err.APIError)
 	FindCheckStillOpen(ctx context.Context, branch string, detail bool) ([]dto.AltaiPhyCheck, rest_err.APIError)
 	GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string, isQuarter bool) (*dto.AltaiPhyCheck, rest_err.APIError)
+	CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError)
 }
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -69,6 +72,14 @@ func (c *checkAltaiPhyDao) InsertCheck(ctx context.Context, input dto.AltaiPhyCh
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -104,6 +115,7 @@ func (c *checkAltaiPhyDao) EditCheck(ctx context.Context, input dto.AltaiPhyChec
 		"$set": set,
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var check dto.AltaiPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -115,6 +127,15 @@ func (c *checkAltaiPhyDao) EditCheck(ctx context.Context, input dto.AltaiPhyChec
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -141,6 +162,14 @@ func (c *checkAltaiPhyDao) DeleteCheck(ctx context.Context, input dto.FilterIDBr
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -163,6 +192,7 @@ func (c *checkAltaiPhyDao) UploadChildImage(ctx context.Context, filterA dto.Fil
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, filterA.FilterParentID)
 	var check dto.AltaiPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -174,6 +204,15 @@ func (c *checkAltaiPhyDao) UploadChildImage(ctx context.Context, filterA dto.Fil
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -203,6 +242,7 @@ func (c *checkAltaiPhyDao) UpdateCheckItem(ctx context.Context, input dto.AltaiP
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterParentID)
 	var check dto.AltaiPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -214,6 +254,15 @@ func (c *checkAltaiPhyDao) UpdateCheckItem(ctx context.Context, input dto.AltaiP
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -227,7 +276,9 @@ func (c *checkAltaiPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.Alta
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -248,6 +299,7 @@ func (c *checkAltaiPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.Alta
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -260,6 +312,8 @@ func (c *checkAltaiPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.Alta
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -275,7 +329,9 @@ func (c *checkAltaiPhyDao) BulkUpdateItemForCheckUpdate(ctx context.Context, inp
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -306,6 +362,7 @@ func (c *checkAltaiPhyDao) BulkUpdateItemForCheckUpdate(ctx context.Context, inp
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -318,6 +375,8 @@ func (c *checkAltaiPhyDao) BulkUpdateItemForCheckUpdate(ctx context.Context, inp
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -347,13 +406,14 @@ func (c *checkAltaiPhyDao) GetCheckByID(ctx context.Context, checkID primitive.O
 	return &check, nil
 }
 
-func (c *checkAltaiPhyDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.AltaiPhyCheck, rest_err.APIError) {
+func (c *checkAltaiPhyDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.AltaiPhyCheck, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -369,31 +429,40 @@ func (c *checkAltaiPhyDao) FindCheck(ctx context.Context, branch string, filterA
 		filter[keyCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah altai check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	if !detail {
 		opts.SetProjection(bson.M{
 			keyAltaiPhyCheckItems: 0,
 		})
 	}
-	opts.SetSort(bson.D{{keyUpdatedAt, -1}}) //nolint:govet
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("gagal mendapatkan daftar altai check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.AltaiPhyCheck{}, apiErr
+		return []dto.AltaiPhyCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	var checkList []dto.AltaiPhyCheck
+	checkList := []dto.AltaiPhyCheck{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (AltaiFindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.AltaiPhyCheck{}, apiErr
+		return []dto.AltaiPhyCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 func (c *checkAltaiPhyDao) FindCheckStillOpen(ctx context.Context, branch string, detail bool) ([]dto.AltaiPhyCheck, rest_err.APIError) {
@@ -478,3 +547,77 @@ func (c *checkAltaiPhyDao) GetLastCheckCreateRange(ctx context.Context, start, e
 
 	return &check, nil
 }
+
+// CountUnitRef menghitung check fisik altai pada branch yang memuat unit, digunakan pada preview penggabungan unit
+func (c *checkAltaiPhyDao) CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyBranch: strings.ToUpper(branch),
+		keyChXId:  unitID,
+	}
+
+	count, err := coll.CountDocuments(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghitung referensi unit pada check fisik altai (CountUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghitung referensi unit pada check fisik altai", err)
+		return 0, apiErr
+	}
+
+	return count, nil
+}
+
+// ReplaceUnitRef mengganti item unit lama menjadi unit baru pada seluruh check fisik altai branch.
+// jika check fisik altai sudah memuat unit baru, item unit lama dihapus supaya tidak ganda
+func (c *checkAltaiPhyDao) ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	branch := strings.ToUpper(input.Branch)
+
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyBranch: branch, keyChXId: input.FromID})
+	filterBoth := bson.M{
+		keyBranch: branch,
+		"$and":    bson.A{bson.M{keyChXId: input.FromID}, bson.M{keyChXId: input.ToID}},
+	}
+	pull := bson.M{
+		"$pull": bson.M{
+			keyAltaiPhyCheckItems: bson.M{"id": input.FromID},
+		},
+	}
+	pulled, err := coll.UpdateMany(ctxt, filterBoth, pull)
+	if err != nil {
+		logger.Error("Gagal menghapus item unit ganda pada check fisik altai (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check fisik altai", err)
+		return 0, apiErr
+	}
+
+	opts := options.Update()
+	opts.SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.id": input.FromID}},
+	})
+
+	filter := bson.M{
+		keyBranch: branch,
+		keyChXId:  input.FromID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			keyAltaiPhyCheckItems + ".$[item].id":   input.ToID,
+			keyAltaiPhyCheckItems + ".$[item].name": input.ToName,
+		},
+	}
+	replaced, err := coll.UpdateMany(ctxt, filter, update, opts)
+	if err != nil {
+		logger.Error("Gagal mengganti referensi unit pada check fisik altai (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check fisik altai", err)
+		return 0, apiErr
+	}
+
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
+	return pulled.ModifiedCount + replaced.ModifiedCount, nil
+}
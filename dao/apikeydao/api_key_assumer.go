@@ -0,0 +1,26 @@
+package apikeydao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApiKeyDaoAssumer interface {
+	ApiKeySaver
+	ApiKeyLoader
+}
+
+type ApiKeySaver interface {
+	InsertKey(ctx context.Context, input dto.ApiKey) (*string, rest_err.APIError)
+	RotateKey(ctx context.Context, input dto.ApiKeyRotate) (*dto.ApiKey, rest_err.APIError)
+	RevokeKey(ctx context.Context, keyID primitive.ObjectID, user mjwt.CustomClaim) (*dto.ApiKey, rest_err.APIError)
+	UpdateLastUsed(ctx context.Context, keyID primitive.ObjectID, ip string) rest_err.APIError
+}
+
+type ApiKeyLoader interface {
+	GetKeyByHash(ctx context.Context, hashKey string) (*dto.ApiKey, rest_err.APIError)
+	FindKey(ctx context.Context, branchIfSpecific string) ([]dto.ApiKey, rest_err.APIError)
+}
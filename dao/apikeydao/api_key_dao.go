@@ -0,0 +1,254 @@
+package apikeydao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout      = 3
+	keyApiKeyCollection = "apiKey"
+
+	keyApiID          = "_id"
+	keyApiCreatedAt   = "created_at"
+	keyApiUpdatedAt   = "updated_at"
+	keyApiUpdatedBy   = "updated_by"
+	keyApiUpdatedByID = "updated_by_id"
+	keyApiBranch      = "branch"
+	keyApiPrefix      = "prefix"
+	keyApiHashKey     = "hash_key"
+	keyApiRevoked     = "revoked"
+	keyApiRevokedAt   = "revoked_at"
+	keyApiRevokedBy   = "revoked_by"
+	keyApiLastUsedAt  = "last_used_at"
+	keyApiLastUsedIP  = "last_used_ip"
+)
+
+func NewApiKeyDao() ApiKeyDaoAssumer {
+	return &apiKeyDao{}
+}
+
+type apiKeyDao struct{}
+
+func (a *apiKeyDao) InsertKey(ctx context.Context, input dto.ApiKey) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.Name = strings.ToUpper(input.Name)
+	input.Branch = strings.ToUpper(input.Branch)
+	if input.Scopes == nil {
+		input.Scopes = []string{}
+	}
+	input.Revoked = false
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan api key ke database", err)
+		logger.Error("Gagal menyimpan api key ke database (InsertKey)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyApiKeyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
+	return &insertID, nil
+}
+
+// RotateKey mengganti hash key, key yang sudah direvoke tidak dapat dirotasi
+func (a *apiKeyDao) RotateKey(ctx context.Context, input dto.ApiKeyRotate) (*dto.ApiKey, rest_err.APIError) {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyApiID:      input.ID,
+		keyApiRevoked: false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyApiPrefix:      input.Prefix,
+			keyApiHashKey:     input.HashKey,
+			keyApiUpdatedAt:   input.UpdatedAt,
+			keyApiUpdatedBy:   input.UpdatedBy,
+			keyApiUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyApiKeyCollection, input.ID)
+	var key dto.ApiKey
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Api key tidak dirotasi karena ID tidak valid atau sudah direvoke")
+		}
+
+		logger.Error("Gagal merotasi api key dari database (RotateKey)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal merotasi api key dari database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyApiKeyCollection,
+		EntityID: key.ID.Hex(),
+		Branch:   key.Branch,
+		Before:   before,
+		After:    key,
+	})
+
+	return &key, nil
+}
+
+func (a *apiKeyDao) RevokeKey(ctx context.Context, keyID primitive.ObjectID, user mjwt.CustomClaim) (*dto.ApiKey, rest_err.APIError) {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	timeNow := time.Now().Unix()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyApiID:      keyID,
+		keyApiRevoked: false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyApiRevoked:     true,
+			keyApiRevokedAt:   timeNow,
+			keyApiRevokedBy:   user.Name,
+			keyApiUpdatedAt:   timeNow,
+			keyApiUpdatedBy:   user.Name,
+			keyApiUpdatedByID: user.Identity,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyApiKeyCollection, keyID)
+	var key dto.ApiKey
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Api key tidak direvoke karena ID tidak valid atau sudah direvoke")
+		}
+
+		logger.Error("Gagal merevoke api key dari database (RevokeKey)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal merevoke api key dari database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyApiKeyCollection,
+		EntityID: key.ID.Hex(),
+		Branch:   key.Branch,
+		Before:   before,
+		After:    key,
+	})
+
+	return &key, nil
+}
+
+func (a *apiKeyDao) UpdateLastUsed(ctx context.Context, keyID primitive.ObjectID, ip string) rest_err.APIError {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyApiID: keyID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyApiLastUsedAt: time.Now().Unix(),
+			keyApiLastUsedIP: ip,
+		},
+	}
+
+	if _, err := coll.UpdateOne(ctxt, filter, update); err != nil {
+		logger.Error("Gagal update last used api key (UpdateLastUsed)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal update last used api key", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *apiKeyDao) GetKeyByHash(ctx context.Context, hashKey string) (*dto.ApiKey, rest_err.APIError) {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyApiHashKey: hashKey,
+	}
+
+	var key dto.ApiKey
+	if err := coll.FindOne(ctxt, filter).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewUnauthorizedError("Api key tidak valid")
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan api key dari database (GetKeyByHash)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan api key dari database", err)
+		return nil, apiErr
+	}
+
+	return &key, nil
+}
+
+func (a *apiKeyDao) FindKey(ctx context.Context, branchIfSpecific string) ([]dto.ApiKey, rest_err.APIError) {
+	coll := db.DB.Collection(keyApiKeyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if branchIfSpecific != "" {
+		filter[keyApiBranch] = strings.ToUpper(branchIfSpecific)
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyApiCreatedAt, Value: -1}})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar api key dari database (FindKey)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.ApiKey{}, apiErr
+	}
+
+	keyList := make([]dto.ApiKey, 0)
+	if err = cursor.All(ctxt, &keyList); err != nil {
+		logger.Error("Gagal decode keyList cursor ke objek slice (FindKey)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.ApiKey{}, apiErr
+	}
+
+	return keyList, nil
+}
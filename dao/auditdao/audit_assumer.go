@@ -0,0 +1,20 @@
+package auditdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+type AuditDaoAssumer interface {
+	AuditSaver
+	AuditLoader
+}
+
+type AuditSaver interface {
+	InsertAudit(ctx context.Context, input dto.Audit) rest_err.APIError
+}
+
+type AuditLoader interface {
+	FindAudit(ctx context.Context, filter dto.FilterAudit, page dto.PageRequest) ([]dto.Audit, dto.PageInfo, rest_err.APIError)
+}
@@ -0,0 +1,244 @@
+package auditdao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	connectTimeout = 3
+	keyAuditColl   = "audit"
+
+	keyAuditTime     = "time"
+	keyAuditAction   = "action"
+	keyAuditEntity   = "entity"
+	keyAuditEntityID = "entity_id"
+	keyAuditBranch   = "branch"
+	keyAuditActorID  = "actor_id"
+	keyAuditActor    = "actor_name"
+	keyAuditField    = "changes.field"
+)
+
+func NewAuditDao() AuditDaoAssumer {
+	return &auditDao{}
+}
+
+type auditDao struct{}
+
+// Record mencatat audit dari dao lain. pelaku diambil dari context secara synchronous,
+// penyimpanan berjalan di background sehingga kegagalan audit tidak menggagalkan operasi utama
+func Record(ctx context.Context, input dto.AuditRecord) {
+	actorID, actorName := audit.ActorFromContext(ctx)
+	changes := audit.Diff(input.Before, input.After)
+	if input.Action == audit.ActionEdit && len(changes) == 0 {
+		return
+	}
+
+	data := dto.Audit{
+		Time:      time.Now().Unix(),
+		Action:    input.Action,
+		Entity:    input.Entity,
+		EntityID:  input.EntityID,
+		Branch:    strings.ToUpper(input.Branch),
+		ActorID:   actorID,
+		ActorName: actorName,
+		Changes:   changes,
+	}
+
+	// didalam transaction audit ditahan sampai commit agar perubahan yang di-rollback tidak tercatat
+	if buf, ok := ctx.Value(bufferKey{}).(*buffer); ok {
+		buf.mu.Lock()
+		buf.list = append(buf.list, data)
+		buf.mu.Unlock()
+		return
+	}
+
+	go func() {
+		_ = (&auditDao{}).InsertAudit(context.Background(), data)
+	}()
+}
+
+type bufferKey struct{}
+
+type buffer struct {
+	mu   sync.Mutex
+	list []dto.Audit
+}
+
+// WithBuffer menyiapkan penampung audit pada context, digunakan oleh transaction
+func WithBuffer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bufferKey{}, &buffer{})
+}
+
+// Flush menyimpan audit yang tertahan di context setelah transaction berhasil
+func Flush(ctx context.Context) {
+	buf, ok := ctx.Value(bufferKey{}).(*buffer)
+	if !ok {
+		return
+	}
+
+	buf.mu.Lock()
+	list := buf.list
+	buf.list = nil
+	buf.mu.Unlock()
+
+	go func() {
+		for _, data := range list {
+			_ = (&auditDao{}).InsertAudit(context.Background(), data)
+		}
+	}()
+}
+
+// Snapshot mendapatkan dokumen mentah sebelum diubah sebagai pembanding diff, nil jika tidak ditemukan
+func Snapshot(ctx context.Context, collection string, id interface{}) bson.M {
+	coll := db.DB.Collection(collection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var doc bson.M
+	if err := coll.FindOne(ctxt, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// SnapshotMany mendapatkan dokumen mentah yang akan terkena update banyak dokumen, pasangan dari RecordMany
+func SnapshotMany(ctx context.Context, collection string, filter interface{}) []bson.M {
+	coll := db.DB.Collection(collection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	cursor, err := coll.Find(ctxt, filter)
+	if err != nil {
+		return nil
+	}
+	var docs []bson.M
+	if err := cursor.All(ctxt, &docs); err != nil {
+		return nil
+	}
+	return docs
+}
+
+// RecordMany mencatat audit edit untuk setiap dokumen hasil SnapshotMany dengan membandingkan
+// kondisi terbaru di database, dokumen tanpa perubahan tidak dicatat
+func RecordMany(ctx context.Context, collection string, befores []bson.M) {
+	for _, before := range befores {
+		id := before["_id"]
+		branch, _ := before["branch"].(string)
+		entityID := fmt.Sprint(id)
+		if oid, ok := id.(primitive.ObjectID); ok {
+			entityID = oid.Hex()
+		}
+		Record(ctx, dto.AuditRecord{
+			Action:   audit.ActionEdit,
+			Entity:   collection,
+			EntityID: entityID,
+			Branch:   branch,
+			Before:   before,
+			After:    Snapshot(ctx, collection, id),
+		})
+	}
+}
+
+func (a *auditDao) InsertAudit(ctx context.Context, input dto.Audit) rest_err.APIError {
+	coll := db.DB.Collection(keyAuditColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if input.Changes == nil {
+		input.Changes = []dto.AuditChange{}
+	}
+
+	if _, err := coll.InsertOne(ctxt, input); err != nil {
+		logger.Error("Gagal menyimpan audit ke database (InsertAudit)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan audit ke database", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *auditDao) FindAudit(ctx context.Context, filter dto.FilterAudit, page dto.PageRequest) ([]dto.Audit, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyAuditColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyAuditTime, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filterM := bson.M{}
+	if filter.FilterEntity != "" {
+		filterM[keyAuditEntity] = filter.FilterEntity
+	}
+	if filter.FilterEntityID != "" {
+		filterM[keyAuditEntityID] = filter.FilterEntityID
+	}
+	if filter.FilterActor != "" {
+		filterM["$or"] = bson.A{
+			bson.M{keyAuditActorID: filter.FilterActor},
+			bson.M{keyAuditActor: strings.ToUpper(filter.FilterActor)},
+		}
+	}
+	if filter.FilterBranch != "" {
+		filterM[keyAuditBranch] = strings.ToUpper(filter.FilterBranch)
+	}
+	if filter.FilterAction != "" {
+		filterM[keyAuditAction] = strings.ToUpper(filter.FilterAction)
+	}
+	if filter.FilterField != "" {
+		filterM[keyAuditField] = filter.FilterField
+	}
+
+	// option range
+	if filter.FilterStart != 0 || filter.FilterEnd != 0 {
+		timeRange := bson.M{}
+		if filter.FilterStart != 0 {
+			timeRange["$gte"] = filter.FilterStart
+		}
+		if filter.FilterEnd != 0 {
+			timeRange["$lte"] = filter.FilterEnd
+		}
+		filterM[keyAuditTime] = timeRange
+	}
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filterM)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah audit dari database (FindAudit)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filterM), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan audit dari database (FindAudit)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Audit{}, dto.PageInfo{}, apiErr
+	}
+
+	auditList := make([]dto.Audit, 0)
+	if err = cursor.All(ctxt, &auditList); err != nil {
+		logger.Error("Gagal decode auditList cursor ke objek slice (FindAudit)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Audit{}, dto.PageInfo{}, apiErr
+	}
+
+	return auditList, query.Info(&auditList, total), nil
+}
@@ -6,19 +6,24 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
 	"strings"
 	"time"
 )
 
 const (
 	connectTimeout   = 3
+	iterateTimeout   = 120
 	keyCtvCollection = "cctv"
 
 	keyCtvID          = "_id"
@@ -29,6 +34,10 @@ const (
 	keyCtvUpdatedByID = "updated_by_id"
 	keyCtvBranch      = "branch"
 	keyCtvDisable     = "disable"
+	keyCtvDeleted     = "deleted"
+	keyCtvDeletedAt   = "deleted_at"
+	keyCtvDeletedBy   = "deleted_by"
+	keyCtvDeletedByID = "deleted_by_id"
 	keyCtvDisVendor   = "dis_vendor"
 
 	keyCtvIP              = "ip"
@@ -42,6 +51,9 @@ const (
 	keyCtvBrand           = "brand"
 	keyCtvType            = "type"
 	keyCtvNote            = "note"
+	keyCtvLifecycle       = "lifecycle"
+	keyCtvGeo             = "geo"
+	keyCtvCustomFields    = "custom_fields"
 )
 
 func NewCctvDao() CctvDaoAssumer {
@@ -72,6 +84,14 @@ func (c *cctvDao) InsertCctv(ctx context.Context, input dto.Cctv) (*string, rest
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCtvCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -92,6 +112,7 @@ func (c *cctvDao) EditCctv(ctx context.Context, input dto.CctvEdit) (*dto.Cctv,
 		keyCtvID:        input.ID,
 		keyCtvBranch:    input.FilterBranch,
 		keyCtvUpdatedAt: input.FilterTimestamp,
+		keyCtvDeleted:   bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -112,10 +133,17 @@ func (c *cctvDao) EditCctv(ctx context.Context, input dto.CctvEdit) (*dto.Cctv,
 			keyCtvBrand:     input.Brand,
 			keyCtvType:      input.Type,
 			keyCtvNote:      input.Note,
+			keyCtvLifecycle: input.Lifecycle,
+			keyCtvGeo:       input.Geo,
 			keyCtvDisVendor: input.DisVendor,
 		},
 	}
+	// custom_fields hanya ditimpa jika dikirim oleh client
+	if input.CustomFields != nil {
+		update["$set"].(bson.M)[keyCtvCustomFields] = input.CustomFields
+	}
 
+	before := auditdao.Snapshot(ctx, keyCtvCollection, input.ID)
 	var cctv dto.Cctv
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&cctv); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -127,10 +155,19 @@ func (c *cctvDao) EditCctv(ctx context.Context, input dto.CctvEdit) (*dto.Cctv,
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   before,
+		After:    cctv,
+	})
+
 	return &cctv, nil
 }
 
-func (c *cctvDao) DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreateGte) (*dto.Cctv, rest_err.APIError) {
+func (c *cctvDao) DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreateGte, user mjwt.CustomClaim) (*dto.Cctv, rest_err.APIError) {
 	coll := db.DB.Collection(keyCtvCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
@@ -139,10 +176,22 @@ func (c *cctvDao) DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreate
 		keyCtvID:        input.FilterID,
 		keyCtvBranch:    input.FilterBranch,
 		keyCtvCreatedAt: bson.M{"$gte": input.FilterCreateGTE},
+		keyCtvDeleted:   bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyCtvDeleted:     true,
+			keyCtvDeletedAt:   time.Now().Unix(),
+			keyCtvDeletedBy:   user.Name,
+			keyCtvDeletedByID: user.Identity,
+		},
 	}
 
+	// dokumen tidak dihapus permanen, hanya dipindahkan ke tempat sampah
+	// cctv yang dikembalikan adalah dokumen sebelum dihapus
 	var cctv dto.Cctv
-	err := coll.FindOneAndDelete(ctxt, filter).Decode(&cctv)
+	err := coll.FindOneAndUpdate(ctxt, filter, update).Decode(&cctv)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, rest_err.NewBadRequestError("Cctv tidak dihapus : validasi id branch time_reach")
@@ -153,6 +202,14 @@ func (c *cctvDao) DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreate
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   cctv,
+	})
+
 	return &cctv, nil
 }
 
@@ -166,8 +223,9 @@ func (c *cctvDao) DisableCctv(ctx context.Context, cctvID primitive.ObjectID, us
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyCtvID:     cctvID,
-		keyCtvBranch: user.Branch,
+		keyCtvID:      cctvID,
+		keyCtvBranch:  user.Branch,
+		keyCtvDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -179,6 +237,7 @@ func (c *cctvDao) DisableCctv(ctx context.Context, cctvID primitive.ObjectID, us
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCtvCollection, cctvID)
 	var cctv dto.Cctv
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&cctv); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -190,6 +249,112 @@ func (c *cctvDao) DisableCctv(ctx context.Context, cctvID primitive.ObjectID, us
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   before,
+		After:    cctv,
+	})
+
+	return &cctv, nil
+}
+
+// MoveCctvBranch memindahkan dokumen ke cabang tujuan, hanya berhasil jika dokumen masih berada di cabang asal
+func (c *cctvDao) MoveCctvBranch(ctx context.Context, input dto.BranchMove) (*dto.Cctv, rest_err.APIError) {
+	coll := db.DB.Collection(keyCtvCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyCtvID:      input.ID,
+		keyCtvBranch:  strings.ToUpper(input.FromBranch),
+		keyCtvDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyCtvBranch:      strings.ToUpper(input.ToBranch),
+			keyCtvUpdatedAt:   input.UpdatedAt,
+			keyCtvUpdatedBy:   input.UpdatedBy,
+			keyCtvUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyCtvCollection, input.ID)
+	var cctv dto.Cctv
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&cctv); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Cctv tidak dipindahkan : validasi id branch")
+		}
+
+		logger.Error("Gagal memindahkan branch cctv (MoveCctvBranch)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan branch cctv", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionMove,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   before,
+		After:    cctv,
+	})
+
+	return &cctv, nil
+}
+
+// EditCctvTagImage mengganti tag dan image, digunakan saat penggabungan unit
+func (c *cctvDao) EditCctvTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Cctv, rest_err.APIError) {
+	coll := db.DB.Collection(keyCtvCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyCtvID:      input.ID,
+		keyCtvBranch:  strings.ToUpper(input.FilterBranch),
+		keyCtvDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyCtvTag:         input.Tag,
+			keyCtvImage:       input.Image,
+			keyCtvUpdatedAt:   input.UpdatedAt,
+			keyCtvUpdatedBy:   input.UpdatedBy,
+			keyCtvUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyCtvCollection, input.ID)
+	var cctv dto.Cctv
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&cctv); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Cctv tidak diupdate : validasi id branch")
+		}
+
+		logger.Error("Gagal mengubah tag dan image cctv (EditCctvTagImage)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah tag dan image cctv", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   before,
+		After:    cctv,
+	})
+
 	return &cctv, nil
 }
 
@@ -202,8 +367,9 @@ func (c *cctvDao) UploadImage(ctx context.Context, cctvID primitive.ObjectID, im
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyCtvID:     cctvID,
-		keyCtvBranch: strings.ToUpper(filterBranch),
+		keyCtvID:      cctvID,
+		keyCtvBranch:  strings.ToUpper(filterBranch),
+		keyCtvDeleted: bson.M{"$ne": true},
 	}
 	update := bson.M{
 		"$set": bson.M{
@@ -211,6 +377,7 @@ func (c *cctvDao) UploadImage(ctx context.Context, cctvID primitive.ObjectID, im
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCtvCollection, cctvID)
 	var cctv dto.Cctv
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&cctv); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -222,6 +389,15 @@ func (c *cctvDao) UploadImage(ctx context.Context, cctvID primitive.ObjectID, im
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCtvCollection,
+		EntityID: cctv.ID.Hex(),
+		Branch:   cctv.Branch,
+		Before:   before,
+		After:    cctv,
+	})
+
 	return &cctv, nil
 }
 
@@ -230,7 +406,10 @@ func (c *cctvDao) GetCctvByID(ctx context.Context, cctvID primitive.ObjectID, br
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	filter := bson.M{keyCtvID: cctvID}
+	filter := bson.M{
+		keyCtvID:      cctvID,
+		keyCtvDeleted: bson.M{"$ne": true},
+	}
 	if branchIfSpecific != "" {
 		filter[keyCtvBranch] = strings.ToUpper(branchIfSpecific)
 	}
@@ -250,17 +429,122 @@ func (c *cctvDao) GetCctvByID(ctx context.Context, cctvID primitive.ObjectID, br
 	return &cctv, nil
 }
 
-func (c *cctvDao) FindCctv(ctx context.Context, filterA dto.FilterBranchLocIPNameDisable) (dto.CctvResponseMinList, rest_err.APIError) {
+// GetCctvByInventoryNumber mendapatkan cctv berdasarkan nomor inventaris pada cabang tertentu
+func (c *cctvDao) GetCctvByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Cctv, rest_err.APIError) {
+	coll := db.DB.Collection(keyCtvCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyCtvBranch:          strings.ToUpper(branch),
+		keyCtvInventoryNumber: inventoryNumber,
+		keyCtvDeleted:         bson.M{"$ne": true},
+	}
+
+	var cctv dto.Cctv
+	if err := coll.FindOne(ctxt, filter).Decode(&cctv); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError(fmt.Sprintf("Cctv dengan nomor inventaris %s tidak ditemukan", inventoryNumber))
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan cctv dari database (GetCctvByInventoryNumber)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan cctv dari database", err)
+		return nil, apiErr
+	}
+
+	return &cctv, nil
+}
+
+func (c *cctvDao) FindCctv(ctx context.Context, filterA dto.FilterBranchLocIPNameDisable, page dto.PageRequest) (dto.CctvResponseMinList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCtvCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
+	query, apiErr := paging.New(page, 500, paging.SortKey{Field: keyCtvLocation, Dir: -1}, paging.SortKey{Field: keyCtvName, Dir: 1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filter := cctvFilter(filterA)
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah cctv dari database (FindCctv)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar cctv dari database (FindCctv)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.CctvResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	cctvList := dto.CctvResponseMinList{}
+	if err = cursor.All(ctxt, &cctvList); err != nil {
+		logger.Error("Gagal decode cctvList cursor ke objek slice (FindCctv)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.CctvResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	return cctvList, query.Info(&cctvList, total), nil
+}
+
+// IterateCctv membaca cctv satu per satu menggunakan cursor tanpa memuat semuanya ke memory,
+// filter sama dengan FindCctv. digunakan untuk export, iterasi berhenti jika fn mengembalikan error
+func (c *cctvDao) IterateCctv(ctx context.Context, filterA dto.FilterBranchLocIPNameDisable, fn func(dto.Cctv) error) rest_err.APIError {
+	coll := db.DB.Collection(keyCtvCollection)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyCtvLocation, Value: -1}, {Key: keyCtvName, Value: 1}, {Key: keyCtvID, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, cctvFilter(filterA), opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar cctv dari database (IterateCctv)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+	defer cursor.Close(ctxt)
+
+	for cursor.Next(ctxt) {
+		var item dto.Cctv
+		if err := cursor.Decode(&item); err != nil {
+			logger.Error("Gagal decode cctv cursor ke objek (IterateCctv)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return apiErr
+		}
+		if err := fn(item); err != nil {
+			apiErr := rest_err.NewInternalServerError("Gagal menulis data cctv", err)
+			return apiErr
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Gagal membaca cctv cursor (IterateCctv)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// cctvFilter membangun filter yang sama untuk FindCctv dan iterasi export
+func cctvFilter(filterA dto.FilterBranchLocIPNameDisable) bson.M {
 	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
 	filterA.FilterName = strings.ToUpper(filterA.FilterName)
 
 	// filter
 	filter := bson.M{
 		keyCtvDisable: filterA.FilterDisable,
+		keyCtvDeleted: bson.M{"$ne": true},
 	}
 
 	// filter condition
@@ -279,23 +563,56 @@ func (c *cctvDao) FindCctv(ctx context.Context, filterA dto.FilterBranchLocIPNam
 		filter[keyCtvIP] = filterA.FilterIP
 	}
 
+	if filterA.FilterInventoryNumber != "" {
+		filter[keyCtvInventoryNumber] = primitive.Regex{Pattern: fmt.Sprintf("^%s$", regexp.QuoteMeta(filterA.FilterInventoryNumber)), Options: "i"}
+	}
+
+	for key, value := range filterA.FilterCustomFields {
+		filter[fmt.Sprintf("%s.%s", keyCtvCustomFields, key)] = value
+	}
+
+	return filter
+}
+
+// FindCctvNear mencari unit aktif dalam radius filter.MaxDistance meter, diurutkan dari yang terdekat (butuh index 2dsphere geo)
+func (c *cctvDao) FindCctvNear(ctx context.Context, filterA dto.FilterGeoNear) ([]dto.Cctv, rest_err.APIError) {
+	coll := db.DB.Collection(keyCtvCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyCtvDisable: false,
+		keyCtvDeleted: bson.M{"$ne": true},
+		keyCtvGeo: bson.M{
+			"$nearSphere": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": bson.A{filterA.Lon, filterA.Lat},
+				},
+				"$maxDistance": filterA.MaxDistance,
+			},
+		},
+	}
+	if filterA.FilterBranch != "" {
+		filter[keyCtvBranch] = strings.ToUpper(filterA.FilterBranch)
+	}
+
 	opts := options.Find()
-	opts.SetSort(bson.D{{keyCtvLocation, -1}, {keyCtvName, 1}}) //nolint:govet
-	opts.SetLimit(500)
+	opts.SetLimit(filterA.Limit)
 
 	cursor, err := coll.Find(ctxt, filter, opts)
 	if err != nil {
-		logger.Error("Gagal mendapatkan daftar cctv dari database (FindCctv)", err)
+		logger.Error("Gagal mendapatkan cctv terdekat dari database (FindCctvNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.CctvResponseMinList{}, apiErr
+		return []dto.Cctv{}, apiErr
 	}
 
-	cctvList := dto.CctvResponseMinList{}
-	if err = cursor.All(ctxt, &cctvList); err != nil {
-		logger.Error("Gagal decode cctvList cursor ke objek slice (FindCctv)", err)
+	units := make([]dto.Cctv, 0)
+	if err = cursor.All(ctxt, &units); err != nil {
+		logger.Error("Gagal decode cctv cursor ke objek slice (FindCctvNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.CctvResponseMinList{}, apiErr
+		return []dto.Cctv{}, apiErr
 	}
 
-	return cctvList, nil
+	return units, nil
 }
@@ -16,11 +16,16 @@ type CctvDaoAssumer interface {
 type CctvSaver interface {
 	InsertCctv(ctx context.Context, input dto.Cctv) (*string, rest_err.APIError)
 	EditCctv(ctx context.Context, input dto.CctvEdit) (*dto.Cctv, rest_err.APIError)
-	DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreateGte) (*dto.Cctv, rest_err.APIError)
+	DeleteCctv(ctx context.Context, input dto.FilterIDBranchCreateGte, user mjwt.CustomClaim) (*dto.Cctv, rest_err.APIError)
 	DisableCctv(ctx context.Context, cctvID primitive.ObjectID, user mjwt.CustomClaim, value bool) (*dto.Cctv, rest_err.APIError)
+	MoveCctvBranch(ctx context.Context, input dto.BranchMove) (*dto.Cctv, rest_err.APIError)
+	EditCctvTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Cctv, rest_err.APIError)
 	UploadImage(ctx context.Context, cctvID primitive.ObjectID, imagePath string, filterBranch string) (*dto.Cctv, rest_err.APIError)
 }
 type CctvLoader interface {
 	GetCctvByID(ctx context.Context, cctvID primitive.ObjectID, branchIfSpecific string) (*dto.Cctv, rest_err.APIError)
-	FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable) (dto.CctvResponseMinList, rest_err.APIError)
+	GetCctvByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Cctv, rest_err.APIError)
+	FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, page dto.PageRequest) (dto.CctvResponseMinList, dto.PageInfo, rest_err.APIError)
+	IterateCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, fn func(dto.Cctv) error) rest_err.APIError
+	FindCctvNear(ctx context.Context, filter dto.FilterGeoNear) ([]dto.Cctv, rest_err.APIError)
 }
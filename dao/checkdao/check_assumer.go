@@ -22,6 +22,6 @@ type CheckSaver interface {
 
 type CheckLoader interface {
 	GetCheckByID(ctx context.Context, checkID primitive.ObjectID, branchIfSpecific string) (*dto.Check, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit) (dto.CheckResponseMinList, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.CheckResponseMinList, dto.PageInfo, rest_err.APIError)
 	FindCheckForReports(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit) ([]dto.Check, rest_err.APIError)
 }
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -79,6 +82,14 @@ func (c *checkDao) InsertCheck(ctx context.Context, input dto.Check) (*string, r
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyChCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -110,6 +121,7 @@ func (c *checkDao) EditCheck(ctx context.Context, input dto.CheckEdit) (*dto.Che
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, input.FilterID)
 	var check dto.Check
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -121,6 +133,15 @@ func (c *checkDao) EditCheck(ctx context.Context, input dto.CheckEdit) (*dto.Che
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyChCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -147,6 +168,14 @@ func (c *checkDao) DeleteCheck(ctx context.Context, input dto.FilterIDBranchCrea
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyChCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -170,6 +199,7 @@ func (c *checkDao) UploadChildImage(ctx context.Context, filterA dto.FilterParen
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, filterA.FilterParentID)
 	var check dto.Check
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -181,6 +211,15 @@ func (c *checkDao) UploadChildImage(ctx context.Context, filterA dto.FilterParen
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyChCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -211,6 +250,7 @@ func (c *checkDao) UpdateCheckItem(ctx context.Context, input dto.CheckChildUpda
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, input.FilterParentID)
 	var check dto.Check
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -222,6 +262,15 @@ func (c *checkDao) UpdateCheckItem(ctx context.Context, input dto.CheckChildUpda
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyChCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -251,13 +300,14 @@ func (c *checkDao) GetCheckByID(ctx context.Context, checkID primitive.ObjectID,
 	return &check, nil
 }
 
-func (c *checkDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit) (dto.CheckResponseMinList, rest_err.APIError) {
+func (c *checkDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.CheckResponseMinList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyChCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyChID, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -273,28 +323,37 @@ func (c *checkDao) FindCheck(ctx context.Context, branch string, filterA dto.Fil
 		filter[keyChCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 	opts.SetProjection(bson.M{
 		keyChCheckItems: 0,
 	})
-	opts.SetSort(bson.D{{keyChID, -1}}) //nolint:govet
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("Gagal mendapatkan daftar check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.CheckResponseMinList{}, apiErr
+		return dto.CheckResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
 	checkList := dto.CheckResponseMinList{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.CheckResponseMinList{}, apiErr
+		return dto.CheckResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 // FindCheckForReports mengembalikan check detail dengan limit 2
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -76,6 +78,14 @@ func (c *checkItemDao) InsertCheckItem(ctx context.Context, input dto.CheckItem)
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyChCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -123,6 +133,7 @@ func (c *checkItemDao) EditCheckItem(ctx context.Context, input dto.CheckItemEdi
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, input.FilterID)
 	var checkItem dto.CheckItem
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&checkItem); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -134,6 +145,15 @@ func (c *checkItemDao) EditCheckItem(ctx context.Context, input dto.CheckItemEdi
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyChCollection,
+		EntityID: checkItem.ID.Hex(),
+		Branch:   checkItem.Branch,
+		Before:   before,
+		After:    checkItem,
+	})
+
 	return &checkItem, nil
 }
 
@@ -157,6 +177,7 @@ func (c *checkItemDao) EditCheckItemValue(ctx context.Context, input dto.CheckIt
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, input.FilterID)
 	var checkItem dto.CheckItem
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&checkItem); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -168,6 +189,15 @@ func (c *checkItemDao) EditCheckItemValue(ctx context.Context, input dto.CheckIt
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyChCollection,
+		EntityID: checkItem.ID.Hex(),
+		Branch:   checkItem.Branch,
+		Before:   before,
+		After:    checkItem,
+	})
+
 	return &checkItem, nil
 }
 
@@ -193,6 +223,14 @@ func (c *checkItemDao) DeleteCheckItem(ctx context.Context, input dto.FilterIDBr
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyChCollection,
+		EntityID: checkItem.ID.Hex(),
+		Branch:   checkItem.Branch,
+		Before:   checkItem,
+	})
+
 	return &checkItem, nil
 }
 
@@ -219,6 +257,7 @@ func (c *checkItemDao) DisableCheckItem(ctx context.Context, checkItemID primiti
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyChCollection, checkItemID)
 	var checkItem dto.CheckItem
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&checkItem); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -230,6 +269,15 @@ func (c *checkItemDao) DisableCheckItem(ctx context.Context, checkItemID primiti
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyChCollection,
+		EntityID: checkItem.ID.Hex(),
+		Branch:   checkItem.Branch,
+		Before:   before,
+		After:    checkItem,
+	})
+
 	return &checkItem, nil
 }
 
@@ -0,0 +1,26 @@
+package commentdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentDaoAssumer interface {
+	CommentSaver
+	CommentLoader
+}
+
+type CommentSaver interface {
+	InsertComment(ctx context.Context, input dto.HistoryComment) (*dto.HistoryComment, rest_err.APIError)
+	EditComment(ctx context.Context, input dto.CommentEdit) (*dto.HistoryComment, rest_err.APIError)
+	DeleteComment(ctx context.Context, commentID primitive.ObjectID) rest_err.APIError
+}
+
+type CommentLoader interface {
+	GetCommentByID(ctx context.Context, commentID primitive.ObjectID, branch string) (*dto.HistoryComment, rest_err.APIError)
+	FindComment(ctx context.Context, historyID string) ([]dto.HistoryComment, rest_err.APIError)
+	FindCommentForHistories(ctx context.Context, historyIDs []string) ([]dto.HistoryComment, rest_err.APIError)
+	CountReply(ctx context.Context, commentID string) (int64, rest_err.APIError)
+}
@@ -0,0 +1,243 @@
+package commentdao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout = 3
+	keyCommentColl = "historyComment"
+
+	keyCommentID          = "_id"
+	keyCommentHistoryID   = "history_id"
+	keyCommentParentID    = "parent_id"
+	keyCommentBranch      = "branch"
+	keyCommentCreatedAt   = "created_at"
+	keyCommentCreatedByID = "created_by_id"
+	keyCommentUpdatedAt   = "updated_at"
+	keyCommentEdited      = "edited"
+	keyCommentBody        = "body"
+	keyCommentMentions    = "mentions"
+	keyCommentAttachments = "attachments"
+)
+
+func NewCommentDao() CommentDaoAssumer {
+	return &commentDao{}
+}
+
+type commentDao struct{}
+
+func (cm *commentDao) InsertComment(ctx context.Context, input dto.HistoryComment) (*dto.HistoryComment, rest_err.APIError) {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.Branch = strings.ToUpper(input.Branch)
+	if input.Mentions == nil {
+		input.Mentions = []dto.HistoryUser{}
+	}
+	if input.Attachments == nil {
+		input.Attachments = []dto.CommentAttachment{}
+	}
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan komentar ke database", err)
+		logger.Error("Gagal menyimpan komentar ke database, (InsertComment)", err)
+		return nil, apiErr
+	}
+
+	input.ID = result.InsertedID.(primitive.ObjectID)
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCommentColl,
+		EntityID: input.ID.Hex(),
+		Branch:   input.Branch,
+		After:    input,
+	})
+
+	return &input, nil
+}
+
+// EditComment hanya berhasil jika dilakukan oleh pembuat komentar dan masih dalam batas waktu edit
+func (cm *commentDao) EditComment(ctx context.Context, input dto.CommentEdit) (*dto.HistoryComment, rest_err.APIError) {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if input.Mentions == nil {
+		input.Mentions = []dto.HistoryUser{}
+	}
+	if input.Attachments == nil {
+		input.Attachments = []dto.CommentAttachment{}
+	}
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyCommentID:          input.FilterID,
+		keyCommentCreatedByID: input.FilterAuthorID,
+		keyCommentCreatedAt:   bson.M{"$gte": input.FilterCreateGTE},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyCommentUpdatedAt:   input.UpdatedAt,
+			keyCommentEdited:      true,
+			keyCommentBody:        input.Body,
+			keyCommentMentions:    input.Mentions,
+			keyCommentAttachments: input.Attachments,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyCommentColl, input.FilterID)
+	var comment dto.HistoryComment
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&comment); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Komentar tidak diupdate : komentar tidak ditemukan atau batas waktu edit sudah habis")
+		}
+
+		logger.Error("Gagal mendapatkan komentar dari database (EditComment)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan komentar dari database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCommentColl,
+		EntityID: comment.ID.Hex(),
+		Branch:   comment.Branch,
+		Before:   before,
+		After:    comment,
+	})
+
+	return &comment, nil
+}
+
+// DeleteComment menghapus komentar beserta balasannya
+func (cm *commentDao) DeleteComment(ctx context.Context, commentID primitive.ObjectID) rest_err.APIError {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	before := auditdao.Snapshot(ctx, keyCommentColl, commentID)
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{keyCommentID: commentID},
+			bson.M{keyCommentParentID: commentID.Hex()},
+		},
+	}
+
+	result, err := coll.DeleteMany(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghapus komentar dari database (DeleteComment)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus komentar dari database", err)
+		return apiErr
+	}
+
+	if result.DeletedCount == 0 {
+		return rest_err.NewBadRequestError("Komentar gagal dihapus, dokumen tidak ditemukan")
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCommentColl,
+		EntityID: commentID.Hex(),
+		Before:   before,
+	})
+
+	return nil
+}
+
+func (cm *commentDao) GetCommentByID(ctx context.Context, commentID primitive.ObjectID, branch string) (*dto.HistoryComment, rest_err.APIError) {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyCommentID:     commentID,
+		keyCommentBranch: strings.ToUpper(branch),
+	}
+
+	var comment dto.HistoryComment
+	if err := coll.FindOne(ctxt, filter).Decode(&comment); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError("Komentar dengan ID tersebut tidak ditemukan")
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan komentar dari database (GetCommentByID)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan komentar dari database", err)
+		return nil, apiErr
+	}
+
+	return &comment, nil
+}
+
+// FindComment mendapatkan seluruh komentar history terurut dari yang paling lama
+func (cm *commentDao) FindComment(ctx context.Context, historyID string) ([]dto.HistoryComment, rest_err.APIError) {
+	return cm.findComment(ctx, bson.M{keyCommentHistoryID: historyID})
+}
+
+// FindCommentForHistories mendapatkan komentar untuk banyak history sekaligus, digunakan pada timeline unwind
+func (cm *commentDao) FindCommentForHistories(ctx context.Context, historyIDs []string) ([]dto.HistoryComment, rest_err.APIError) {
+	if len(historyIDs) == 0 {
+		return []dto.HistoryComment{}, nil
+	}
+	return cm.findComment(ctx, bson.M{keyCommentHistoryID: bson.M{"$in": historyIDs}})
+}
+
+func (cm *commentDao) findComment(ctx context.Context, filter bson.M) ([]dto.HistoryComment, rest_err.APIError) {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyCommentHistoryID, Value: 1}, {Key: keyCommentCreatedAt, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan komentar dari database (FindComment)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.HistoryComment{}, apiErr
+	}
+
+	comments := make([]dto.HistoryComment, 0)
+	if err = cursor.All(ctxt, &comments); err != nil {
+		logger.Error("Gagal decode komentar cursor ke objek slice (FindComment)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.HistoryComment{}, apiErr
+	}
+
+	return comments, nil
+}
+
+func (cm *commentDao) CountReply(ctx context.Context, commentID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCommentColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	count, err := coll.CountDocuments(ctxt, bson.M{keyCommentParentID: commentID})
+	if err != nil {
+		logger.Error("Gagal menghitung balasan komentar (CountReply)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return 0, apiErr
+	}
+	return count, nil
+}
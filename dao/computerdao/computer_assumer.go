@@ -16,12 +16,17 @@ type ComputerDaoAssumer interface {
 type ComputerSaver interface {
 	InsertPc(ctx context.Context, input dto.Computer) (*string, rest_err.APIError)
 	EditPc(ctx context.Context, input dto.ComputerEdit) (*dto.Computer, rest_err.APIError)
-	DeletePc(ctx context.Context, input dto.FilterIDBranchCreateGte) (*dto.Computer, rest_err.APIError)
+	DeletePc(ctx context.Context, input dto.FilterIDBranchCreateGte, user mjwt.CustomClaim) (*dto.Computer, rest_err.APIError)
 	DisablePc(ctx context.Context, pcID primitive.ObjectID, user mjwt.CustomClaim, value bool) (*dto.Computer, rest_err.APIError)
+	MovePcBranch(ctx context.Context, input dto.BranchMove) (*dto.Computer, rest_err.APIError)
+	EditPcTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Computer, rest_err.APIError)
 	UploadImage(ctx context.Context, pcID primitive.ObjectID, imagePath string, filterBranch string) (*dto.Computer, rest_err.APIError)
 }
 
 type ComputerLoader interface {
 	GetPcByID(ctx context.Context, pcID primitive.ObjectID, branchIfSpecific string) (*dto.Computer, rest_err.APIError)
-	FindPc(ctx context.Context, filter dto.FilterComputer) (dto.ComputerResponseMinList, rest_err.APIError)
+	GetPcByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Computer, rest_err.APIError)
+	FindPc(ctx context.Context, filter dto.FilterComputer, page dto.PageRequest) (dto.ComputerResponseMinList, dto.PageInfo, rest_err.APIError)
+	IteratePc(ctx context.Context, filter dto.FilterComputer, fn func(dto.Computer) error) rest_err.APIError
+	FindPcNear(ctx context.Context, filter dto.FilterGeoNear) ([]dto.Computer, rest_err.APIError)
 }
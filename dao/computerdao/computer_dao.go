@@ -6,19 +6,24 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
 	"strings"
 	"time"
 )
 
 const (
 	connectTimeout  = 3
+	iterateTimeout  = 120
 	keyPCCollection = "computer"
 
 	keyPCID          = "_id"
@@ -29,6 +34,10 @@ const (
 	keyPCUpdatedByID = "updated_by_id"
 	keyPCBranch      = "branch"
 	keyPCDisable     = "disable"
+	keyPCDeleted     = "deleted"
+	keyPCDeletedAt   = "deleted_at"
+	keyPCDeletedBy   = "deleted_by"
+	keyPCDeletedByID = "deleted_by_id"
 
 	keyPCHostname       = "hostname"
 	keyPCDivision       = "division"
@@ -49,6 +58,9 @@ const (
 	keyPCBrand           = "brand"
 	keyPCType            = "type"
 	keyPCNote            = "note"
+	keyPCLifecycle       = "lifecycle"
+	keyPCGeo             = "geo"
+	keyPCCustomFields    = "custom_fields"
 )
 
 func NewComputerDao() ComputerDaoAssumer {
@@ -79,6 +91,14 @@ func (c *computerDao) InsertPc(ctx context.Context, input dto.Computer) (*string
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyPCCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -99,6 +119,7 @@ func (c *computerDao) EditPc(ctx context.Context, input dto.ComputerEdit) (*dto.
 		keyPCID:        input.ID,
 		keyPCBranch:    input.FilterBranch,
 		keyPCUpdatedAt: input.FilterTimestamp,
+		keyPCDeleted:   bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -127,9 +148,17 @@ func (c *computerDao) EditPc(ctx context.Context, input dto.ComputerEdit) (*dto.
 			keyPCBrand: input.Brand,
 			keyPCType:  input.Type,
 			keyPCNote:  input.Note,
+
+			keyPCLifecycle: input.Lifecycle,
+			keyPCGeo:       input.Geo,
 		},
 	}
+	// custom_fields hanya ditimpa jika dikirim oleh client
+	if input.CustomFields != nil {
+		update["$set"].(bson.M)[keyPCCustomFields] = input.CustomFields
+	}
 
+	before := auditdao.Snapshot(ctx, keyPCCollection, input.ID)
 	var pc dto.Computer
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&pc); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -141,10 +170,19 @@ func (c *computerDao) EditPc(ctx context.Context, input dto.ComputerEdit) (*dto.
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyPCCollection,
+		EntityID: pc.ID.Hex(),
+		Branch:   pc.Branch,
+		Before:   before,
+		After:    pc,
+	})
+
 	return &pc, nil
 }
 
-func (c *computerDao) DeletePc(ctx context.Context, input dto.FilterIDBranchCreateGte) (*dto.Computer, rest_err.APIError) {
+func (c *computerDao) DeletePc(ctx context.Context, input dto.FilterIDBranchCreateGte, user mjwt.CustomClaim) (*dto.Computer, rest_err.APIError) {
 	coll := db.DB.Collection(keyPCCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
@@ -153,10 +191,22 @@ func (c *computerDao) DeletePc(ctx context.Context, input dto.FilterIDBranchCrea
 		keyPCID:        input.FilterID,
 		keyPCBranch:    input.FilterBranch,
 		keyPCCreatedAt: bson.M{"$gte": input.FilterCreateGTE},
+		keyPCDeleted:   bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyPCDeleted:     true,
+			keyPCDeletedAt:   time.Now().Unix(),
+			keyPCDeletedBy:   user.Name,
+			keyPCDeletedByID: user.Identity,
+		},
 	}
 
+	// dokumen tidak dihapus permanen, hanya dipindahkan ke tempat sampah
+	// pc yang dikembalikan adalah dokumen sebelum dihapus
 	var pc dto.Computer
-	err := coll.FindOneAndDelete(ctxt, filter).Decode(&pc)
+	err := coll.FindOneAndUpdate(ctxt, filter, update).Decode(&pc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, rest_err.NewBadRequestError("Pc tidak dihapus : validasi id branch time_reach")
@@ -167,6 +217,14 @@ func (c *computerDao) DeletePc(ctx context.Context, input dto.FilterIDBranchCrea
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyPCCollection,
+		EntityID: pc.ID.Hex(),
+		Branch:   pc.Branch,
+		Before:   pc,
+	})
+
 	return &pc, nil
 }
 
@@ -180,8 +238,9 @@ func (c *computerDao) DisablePc(ctx context.Context, pcID primitive.ObjectID, us
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyPCID:     pcID,
-		keyPCBranch: user.Branch,
+		keyPCID:      pcID,
+		keyPCBranch:  user.Branch,
+		keyPCDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -193,6 +252,7 @@ func (c *computerDao) DisablePc(ctx context.Context, pcID primitive.ObjectID, us
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyPCCollection, pcID)
 	var pc dto.Computer
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&pc); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -204,9 +264,115 @@ func (c *computerDao) DisablePc(ctx context.Context, pcID primitive.ObjectID, us
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyPCCollection,
+		EntityID: pc.ID.Hex(),
+		Branch:   pc.Branch,
+		Before:   before,
+		After:    pc,
+	})
+
+	return &pc, nil
+}
+
+// MovePcBranch memindahkan dokumen ke cabang tujuan, hanya berhasil jika dokumen masih berada di cabang asal
+func (c *computerDao) MovePcBranch(ctx context.Context, input dto.BranchMove) (*dto.Computer, rest_err.APIError) {
+	coll := db.DB.Collection(keyPCCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyPCID:      input.ID,
+		keyPCBranch:  strings.ToUpper(input.FromBranch),
+		keyPCDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyPCBranch:      strings.ToUpper(input.ToBranch),
+			keyPCUpdatedAt:   input.UpdatedAt,
+			keyPCUpdatedBy:   input.UpdatedBy,
+			keyPCUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyPCCollection, input.ID)
+	var pc dto.Computer
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&pc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Computer tidak dipindahkan : validasi id branch")
+		}
+
+		logger.Error("Gagal memindahkan branch computer (MovePcBranch)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan branch computer", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionMove,
+		Entity:   keyPCCollection,
+		EntityID: pc.ID.Hex(),
+		Branch:   pc.Branch,
+		Before:   before,
+		After:    pc,
+	})
+
 	return &pc, nil
 }
 
+// EditPcTagImage mengganti tag dan image, digunakan saat penggabungan unit
+func (c *computerDao) EditPcTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Computer, rest_err.APIError) {
+	coll := db.DB.Collection(keyPCCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyPCID:      input.ID,
+		keyPCBranch:  strings.ToUpper(input.FilterBranch),
+		keyPCDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyPCTag:         input.Tag,
+			keyPCImage:       input.Image,
+			keyPCUpdatedAt:   input.UpdatedAt,
+			keyPCUpdatedBy:   input.UpdatedBy,
+			keyPCUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyPCCollection, input.ID)
+	var computer dto.Computer
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&computer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Computer tidak diupdate : validasi id branch")
+		}
+
+		logger.Error("Gagal mengubah tag dan image computer (EditPcTagImage)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah tag dan image computer", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyPCCollection,
+		EntityID: computer.ID.Hex(),
+		Branch:   computer.Branch,
+		Before:   before,
+		After:    computer,
+	})
+
+	return &computer, nil
+}
+
 func (c *computerDao) UploadImage(ctx context.Context, pcID primitive.ObjectID, imagePath string, filterBranch string) (*dto.Computer, rest_err.APIError) {
 	coll := db.DB.Collection(keyPCCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
@@ -216,8 +382,9 @@ func (c *computerDao) UploadImage(ctx context.Context, pcID primitive.ObjectID,
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyPCID:     pcID,
-		keyPCBranch: strings.ToUpper(filterBranch),
+		keyPCID:      pcID,
+		keyPCBranch:  strings.ToUpper(filterBranch),
+		keyPCDeleted: bson.M{"$ne": true},
 	}
 	update := bson.M{
 		"$set": bson.M{
@@ -225,6 +392,7 @@ func (c *computerDao) UploadImage(ctx context.Context, pcID primitive.ObjectID,
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyPCCollection, pcID)
 	var pc dto.Computer
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&pc); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -236,6 +404,15 @@ func (c *computerDao) UploadImage(ctx context.Context, pcID primitive.ObjectID,
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyPCCollection,
+		EntityID: pc.ID.Hex(),
+		Branch:   pc.Branch,
+		Before:   before,
+		After:    pc,
+	})
+
 	return &pc, nil
 }
 
@@ -244,7 +421,10 @@ func (c *computerDao) GetPcByID(ctx context.Context, pcID primitive.ObjectID, br
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	filter := bson.M{keyPCID: pcID}
+	filter := bson.M{
+		keyPCID:      pcID,
+		keyPCDeleted: bson.M{"$ne": true},
+	}
 	if branchIfSpecific != "" {
 		filter[keyPCBranch] = strings.ToUpper(branchIfSpecific)
 	}
@@ -264,11 +444,115 @@ func (c *computerDao) GetPcByID(ctx context.Context, pcID primitive.ObjectID, br
 	return &pc, nil
 }
 
-func (c *computerDao) FindPc(ctx context.Context, filterA dto.FilterComputer) (dto.ComputerResponseMinList, rest_err.APIError) {
+// GetPcByInventoryNumber mendapatkan pc berdasarkan nomor inventaris pada cabang tertentu
+func (c *computerDao) GetPcByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Computer, rest_err.APIError) {
 	coll := db.DB.Collection(keyPCCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
+	filter := bson.M{
+		keyPCBranch:          strings.ToUpper(branch),
+		keyPCInventoryNumber: inventoryNumber,
+		keyPCDeleted:         bson.M{"$ne": true},
+	}
+
+	var pc dto.Computer
+	if err := coll.FindOne(ctxt, filter).Decode(&pc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError(fmt.Sprintf("Computer dengan nomor inventaris %s tidak ditemukan", inventoryNumber))
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan pc dari database (GetPcByInventoryNumber)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan pc dari database", err)
+		return nil, apiErr
+	}
+
+	return &pc, nil
+}
+
+func (c *computerDao) FindPc(ctx context.Context, filterA dto.FilterComputer, page dto.PageRequest) (dto.ComputerResponseMinList, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyPCCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, 500, paging.SortKey{Field: keyPCLocation, Dir: -1}, paging.SortKey{Field: keyPCDivision, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filter := pcFilter(filterA)
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah pc dari database (FindPc)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar pc dari database (FindPc)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.ComputerResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	pcList := dto.ComputerResponseMinList{}
+	if err = cursor.All(ctxt, &pcList); err != nil {
+		logger.Error("Gagal decode pcList cursor ke objek slice (FindPc)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.ComputerResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	return pcList, query.Info(&pcList, total), nil
+}
+
+// IteratePc membaca computer satu per satu menggunakan cursor tanpa memuat semuanya ke memory,
+// filter sama dengan FindPc. digunakan untuk export, iterasi berhenti jika fn mengembalikan error
+func (c *computerDao) IteratePc(ctx context.Context, filterA dto.FilterComputer, fn func(dto.Computer) error) rest_err.APIError {
+	coll := db.DB.Collection(keyPCCollection)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyPCLocation, Value: -1}, {Key: keyPCDivision, Value: -1}, {Key: keyPCID, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, pcFilter(filterA), opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar computer dari database (IteratePc)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+	defer cursor.Close(ctxt)
+
+	for cursor.Next(ctxt) {
+		var item dto.Computer
+		if err := cursor.Decode(&item); err != nil {
+			logger.Error("Gagal decode computer cursor ke objek (IteratePc)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return apiErr
+		}
+		if err := fn(item); err != nil {
+			apiErr := rest_err.NewInternalServerError("Gagal menulis data computer", err)
+			return apiErr
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Gagal membaca computer cursor (IteratePc)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// pcFilter membangun filter yang sama untuk FindPc dan iterasi export
+func pcFilter(filterA dto.FilterComputer) bson.M {
 	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
 	filterA.FilterName = strings.ToUpper(filterA.FilterName)
 	filterA.FilterDivision = strings.ToUpper(filterA.FilterDivision)
@@ -277,6 +561,7 @@ func (c *computerDao) FindPc(ctx context.Context, filterA dto.FilterComputer) (d
 	// filter
 	filter := bson.M{
 		keyPCDisable: filterA.FilterDisable,
+		keyPCDeleted: bson.M{"$ne": true},
 	}
 	// filter condition
 	if filterA.FilterBranch != "" {
@@ -306,23 +591,56 @@ func (c *computerDao) FindPc(ctx context.Context, filterA dto.FilterComputer) (d
 		// do nothing
 	}
 
+	if filterA.FilterInventoryNumber != "" {
+		filter[keyPCInventoryNumber] = primitive.Regex{Pattern: fmt.Sprintf("^%s$", regexp.QuoteMeta(filterA.FilterInventoryNumber)), Options: "i"}
+	}
+
+	for key, value := range filterA.FilterCustomFields {
+		filter[fmt.Sprintf("%s.%s", keyPCCustomFields, key)] = value
+	}
+
+	return filter
+}
+
+// FindPcNear mencari unit aktif dalam radius filter.MaxDistance meter, diurutkan dari yang terdekat (butuh index 2dsphere geo)
+func (c *computerDao) FindPcNear(ctx context.Context, filterA dto.FilterGeoNear) ([]dto.Computer, rest_err.APIError) {
+	coll := db.DB.Collection(keyPCCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyPCDisable: false,
+		keyPCDeleted: bson.M{"$ne": true},
+		keyPCGeo: bson.M{
+			"$nearSphere": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": bson.A{filterA.Lon, filterA.Lat},
+				},
+				"$maxDistance": filterA.MaxDistance,
+			},
+		},
+	}
+	if filterA.FilterBranch != "" {
+		filter[keyPCBranch] = strings.ToUpper(filterA.FilterBranch)
+	}
+
 	opts := options.Find()
-	opts.SetSort(bson.D{{keyPCLocation, -1}, {keyPCDivision, -1}}) //nolint:govet
-	opts.SetLimit(500)
+	opts.SetLimit(filterA.Limit)
 
 	cursor, err := coll.Find(ctxt, filter, opts)
 	if err != nil {
-		logger.Error("Gagal mendapatkan daftar pc dari database (FindPc)", err)
+		logger.Error("Gagal mendapatkan computer terdekat dari database (FindPcNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.ComputerResponseMinList{}, apiErr
+		return []dto.Computer{}, apiErr
 	}
 
-	pcList := dto.ComputerResponseMinList{}
-	if err = cursor.All(ctxt, &pcList); err != nil {
-		logger.Error("Gagal decode pcList cursor ke objek slice (FindPc)", err)
+	units := make([]dto.Computer, 0)
+	if err = cursor.All(ctxt, &units); err != nil {
+		logger.Error("Gagal decode computer cursor ke objek slice (FindPcNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.ComputerResponseMinList{}, apiErr
+		return []dto.Computer{}, apiErr
 	}
 
-	return pcList, nil
+	return units, nil
 }
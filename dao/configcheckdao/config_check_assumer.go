@@ -22,6 +22,6 @@ type CheckConfigSaver interface {
 
 type CheckConfigLoader interface {
 	GetCheckByID(ctx context.Context, checkID primitive.ObjectID, branchIfSpecific string) (*dto.ConfigCheck, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.ConfigCheck, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.ConfigCheck, dto.PageInfo, rest_err.APIError)
 	GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string) (*dto.ConfigCheck, rest_err.APIError)
 }
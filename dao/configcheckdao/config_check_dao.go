@@ -9,8 +9,11 @@ import (
 
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -69,6 +72,14 @@ func (c *checkConfigDao) InsertCheck(ctx context.Context, input dto.ConfigCheck)
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -100,6 +111,7 @@ func (c *checkConfigDao) EditCheck(ctx context.Context, input dto.ConfigCheckEdi
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var check dto.ConfigCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -111,6 +123,15 @@ func (c *checkConfigDao) EditCheck(ctx context.Context, input dto.ConfigCheckEdi
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -137,6 +158,14 @@ func (c *checkConfigDao) DeleteCheck(ctx context.Context, input dto.FilterIDBran
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -164,6 +193,7 @@ func (c *checkConfigDao) UpdateCheckItem(ctx context.Context, input dto.ConfigCh
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterParentID)
 	var check dto.ConfigCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -175,6 +205,15 @@ func (c *checkConfigDao) UpdateCheckItem(ctx context.Context, input dto.ConfigCh
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -212,6 +251,7 @@ func (c *checkConfigDao) UpdateManyItem(ctx context.Context, input dto.ConfigChe
 		Filters: []interface{}{bson.M{"elem.id": bson.M{"$in": input.ChildIDsUpdate}}},
 	})
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, filter)
 	_, err := coll.UpdateOne(ctxt, filter, update, opts)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -223,6 +263,8 @@ func (c *checkConfigDao) UpdateManyItem(ctx context.Context, input dto.ConfigChe
 		return apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return nil
 }
 
@@ -252,13 +294,14 @@ func (c *checkConfigDao) GetCheckByID(ctx context.Context, checkID primitive.Obj
 	return &check, nil
 }
 
-func (c *checkConfigDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.ConfigCheck, rest_err.APIError) {
+func (c *checkConfigDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.ConfigCheck, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -274,31 +317,40 @@ func (c *checkConfigDao) FindCheck(ctx context.Context, branch string, filterA d
 		filter[keyCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah config check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	if !detail {
 		opts.SetProjection(bson.M{
 			keyConfigCheckItems: 0,
 		})
 	}
-	opts.SetSort(bson.D{{Key: keyUpdatedAt, Value: -1}})
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("gagal mendapatkan daftar config check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.ConfigCheck{}, apiErr
+		return []dto.ConfigCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	var checkList []dto.ConfigCheck
+	checkList := []dto.ConfigCheck{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (ConfigFindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.ConfigCheck{}, apiErr
+		return []dto.ConfigCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 func (c *checkConfigDao) GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string) (*dto.ConfigCheck, rest_err.APIError) {
@@ -0,0 +1,21 @@
+package customfielddao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+type CustomFieldDaoAssumer interface {
+	CustomFieldSaver
+	CustomFieldLoader
+}
+
+type CustomFieldSaver interface {
+	UpsertSchema(ctx context.Context, input dto.CustomFieldSchema) (*dto.CustomFieldSchema, rest_err.APIError)
+	DeleteSchema(ctx context.Context, category string) rest_err.APIError
+}
+
+type CustomFieldLoader interface {
+	FindSchema(ctx context.Context) ([]dto.CustomFieldSchema, rest_err.APIError)
+}
@@ -0,0 +1,139 @@
+package customfielddao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout     = 3
+	keyCustomFieldColl = "customField"
+
+	keyCfCategory    = "category"
+	keyCfUpdatedAt   = "updated_at"
+	keyCfUpdatedBy   = "updated_by"
+	keyCfUpdatedByID = "updated_by_id"
+	keyCfFields      = "fields"
+)
+
+func NewCustomFieldDao() CustomFieldDaoAssumer {
+	return &customFieldDao{}
+}
+
+type customFieldDao struct{}
+
+// UpsertSchema menyimpan schema custom field, schema bersifat unik per category
+func (c *customFieldDao) UpsertSchema(ctx context.Context, input dto.CustomFieldSchema) (*dto.CustomFieldSchema, rest_err.APIError) {
+	coll := db.DB.Collection(keyCustomFieldColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetUpsert(true)
+
+	if input.Fields == nil {
+		input.Fields = []dto.CustomField{}
+	}
+
+	filter := bson.M{
+		keyCfCategory: strings.ToUpper(input.Category),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyCfUpdatedAt:   input.UpdatedAt,
+			keyCfUpdatedBy:   input.UpdatedBy,
+			keyCfUpdatedByID: input.UpdatedByID,
+			keyCfFields:      input.Fields,
+		},
+	}
+
+	// schema dicari berdasarkan kategori, snapshot tidak bisa memakai id
+	var before bson.M
+	_ = coll.FindOne(ctxt, filter).Decode(&before)
+
+	var schema dto.CustomFieldSchema
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&schema); err != nil {
+		logger.Error("Gagal menyimpan custom field ke database (UpsertSchema)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan custom field ke database", err)
+		return nil, apiErr
+	}
+
+	action := audit.ActionEdit
+	if before == nil {
+		action = audit.ActionInsert
+	}
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   action,
+		Entity:   keyCustomFieldColl,
+		EntityID: schema.ID.Hex(),
+		Before:   before,
+		After:    schema,
+	})
+
+	return &schema, nil
+}
+
+// DeleteSchema menghapus schema kategori, nilai custom_fields pada unit tidak ikut dihapus
+func (c *customFieldDao) DeleteSchema(ctx context.Context, category string) rest_err.APIError {
+	coll := db.DB.Collection(keyCustomFieldColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var before dto.CustomFieldSchema
+	if err := coll.FindOneAndDelete(ctxt, bson.M{keyCfCategory: strings.ToUpper(category)}).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return rest_err.NewBadRequestError("Custom field gagal dihapus, dokumen tidak ditemukan")
+		}
+		logger.Error("Gagal menghapus custom field dari database (DeleteSchema)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus custom field dari database", err)
+		return apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCustomFieldColl,
+		EntityID: before.ID.Hex(),
+		Before:   before,
+	})
+
+	return nil
+}
+
+func (c *customFieldDao) FindSchema(ctx context.Context) ([]dto.CustomFieldSchema, rest_err.APIError) {
+	coll := db.DB.Collection(keyCustomFieldColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyCfCategory, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan custom field dari database (FindSchema)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.CustomFieldSchema{}, apiErr
+	}
+
+	schemas := make([]dto.CustomFieldSchema, 0)
+	if err = cursor.All(ctxt, &schemas); err != nil {
+		logger.Error("Gagal decode custom field cursor ke objek slice (FindSchema)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.CustomFieldSchema{}, apiErr
+	}
+
+	return schemas, nil
+}
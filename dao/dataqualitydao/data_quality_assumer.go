@@ -0,0 +1,20 @@
+package dataqualitydao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+type DataQualityDaoAssumer interface {
+	DataQualitySaver
+	DataQualityLoader
+}
+
+type DataQualitySaver interface {
+	UpsertReport(ctx context.Context, input dto.DataQualityReport) (*dto.DataQualityReport, rest_err.APIError)
+}
+
+type DataQualityLoader interface {
+	GetReport(ctx context.Context, branch string) (*dto.DataQualityReport, rest_err.APIError)
+}
@@ -0,0 +1,91 @@
+package dataqualitydao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout     = 3
+	keyDataQualityColl = "dataQuality"
+
+	keyDqBranch      = "branch"
+	keyDqGeneratedAt = "generated_at"
+	keyDqGeneratedBy = "generated_by"
+	keyDqSummary     = "summary"
+	keyDqFindings    = "findings"
+)
+
+func NewDataQualityDao() DataQualityDaoAssumer {
+	return &dataQualityDao{}
+}
+
+type dataQualityDao struct{}
+
+// UpsertReport mengganti laporan data quality branch dengan hasil pemindaian terbaru
+func (d *dataQualityDao) UpsertReport(ctx context.Context, input dto.DataQualityReport) (*dto.DataQualityReport, rest_err.APIError) {
+	coll := db.DB.Collection(keyDataQualityColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetUpsert(true)
+
+	if input.Findings == nil {
+		input.Findings = []dto.DataQualityFinding{}
+	}
+
+	filter := bson.M{
+		keyDqBranch: strings.ToUpper(input.Branch),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyDqGeneratedAt: input.GeneratedAt,
+			keyDqGeneratedBy: input.GeneratedBy,
+			keyDqSummary:     input.Summary,
+			keyDqFindings:    input.Findings,
+		},
+	}
+
+	var report dto.DataQualityReport
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&report); err != nil {
+		logger.Error("Gagal menyimpan laporan data quality ke database (UpsertReport)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan laporan data quality ke database", err)
+		return nil, apiErr
+	}
+
+	return &report, nil
+}
+
+func (d *dataQualityDao) GetReport(ctx context.Context, branch string) (*dto.DataQualityReport, rest_err.APIError) {
+	coll := db.DB.Collection(keyDataQualityColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var report dto.DataQualityReport
+	if err := coll.FindOne(ctxt, bson.M{keyDqBranch: strings.ToUpper(branch)}).Decode(&report); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError(fmt.Sprintf("Laporan data quality %s belum tersedia", strings.ToUpper(branch)))
+			return nil, apiErr
+		}
+
+		logger.Error("Gagal mendapatkan laporan data quality dari database (GetReport)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan laporan data quality", err)
+		return nil, apiErr
+	}
+
+	return &report, nil
+}
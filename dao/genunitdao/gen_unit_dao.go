@@ -9,9 +9,14 @@ import (
 
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/alertstate"
 	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -31,6 +36,14 @@ const (
 	keyGenPingState = "pings_state"
 	keyGenLastPing  = "last_ping"
 	keyGenDisable   = "disable"
+	keyGenAlert     = "alert_state"
+	keyGenAlertTime = "alert_since"
+	keyGenAutoCase  = "auto_case_id"
+	keyGenUplink    = "uplink"
+	keyGenDeleted   = "deleted"
+	keyGenDelAt     = "deleted_at"
+	keyGenDelBy     = "deleted_by"
+	keyGenDelByID   = "deleted_by_id"
 
 	keyCaseID   = "case_id"
 	keyCaseNote = "case_note"
@@ -53,6 +66,8 @@ func (u *genUnitDao) InsertUnit(ctx context.Context, unit dto.GenUnit) (*string,
 	unit.CasesSize = 0
 	unit.PingsState = []dto.PingState{}
 	unit.LastPing = ""
+	unit.AlertState = alertstate.OK
+	unit.AlertSince = 0
 
 	result, err := coll.InsertOne(ctxt, unit)
 	if err != nil {
@@ -63,6 +78,14 @@ func (u *genUnitDao) InsertUnit(ctx context.Context, unit dto.GenUnit) (*string,
 
 	insertID := result.InsertedID.(string)
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyGenUnitColl,
+		EntityID: insertID,
+		Branch:   unit.Branch,
+		After:    unit,
+	})
+
 	return &insertID, nil
 }
 
@@ -77,7 +100,8 @@ func (u *genUnitDao) EditUnit(ctx context.Context, unitID string, unitRequest dt
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyGenID: unitID,
+		keyGenID:      unitID,
+		keyGenDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -89,6 +113,7 @@ func (u *genUnitDao) EditUnit(ctx context.Context, unitID string, unitRequest dt
 		},
 	}
 
+	before := snapshotUnit(ctx, unitID)
 	var unit dto.GenUnitResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -101,33 +126,55 @@ func (u *genUnitDao) EditUnit(ctx context.Context, unitID string, unitRequest dt
 		return nil, apiErr
 	}
 
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
 	return &unit, nil
 }
 
-func (u *genUnitDao) DeleteUnit(ctx context.Context, unitID string) rest_err.APIError {
+// DeleteUnit memindahkan unit ke tempat sampah, unit tidak lagi muncul pada pencarian dan ping
+func (u *genUnitDao) DeleteUnit(ctx context.Context, unitID string, user mjwt.CustomClaim) rest_err.APIError {
 	coll := db.DB.Collection(keyGenUnitColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
 	filter := bson.M{
-		keyGenID: unitID,
+		keyGenID:      unitID,
+		keyGenDeleted: bson.M{"$ne": true},
 	}
 
-	result, err := coll.DeleteOne(ctxt, filter)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return rest_err.NewBadRequestError("Unit gagal dihapus, dokumen tidak ditemukan")
-		}
+	update := bson.M{
+		"$set": bson.M{
+			keyGenDeleted: true,
+			keyGenDelAt:   time.Now().Unix(),
+			keyGenDelBy:   user.Name,
+			keyGenDelByID: user.Identity,
+		},
+	}
 
+	before := snapshotUnit(ctx, unitID)
+	result, err := coll.UpdateOne(ctxt, filter, update)
+	if err != nil {
 		logger.Error("Gagal menghapus unit dari database (DeleteUnit)", err)
 		apiErr := rest_err.NewInternalServerError("Gagal menghapus unit dari database", err)
 		return apiErr
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return rest_err.NewBadRequestError("Unit gagal dihapus, dokumen tidak ditemukan")
 	}
 
+	var branch string
+	if before != nil {
+		branch, _ = before[keyGenBranch].(string)
+	}
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyGenUnitColl,
+		EntityID: unitID,
+		Branch:   branch,
+		Before:   before,
+	})
+
 	return nil
 }
 
@@ -149,6 +196,7 @@ func (u *genUnitDao) DisableUnit(ctx context.Context, unitID string, value bool)
 		},
 	}
 
+	before := snapshotUnit(ctx, unitID)
 	var unit dto.GenUnitResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -160,9 +208,134 @@ func (u *genUnitDao) DisableUnit(ctx context.Context, unitID string, value bool)
 		return nil, apiErr
 	}
 
+	recordUnit(ctx, audit.ActionDisable, before, unit)
+
 	return &unit, nil
 }
 
+// MoveUnitBranch memindahkan gen_unit beserta cases nya ke cabang tujuan
+func (u *genUnitDao) MoveUnitBranch(ctx context.Context, unitID string, fromBranch string, toBranch string) (*dto.GenUnitResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyGenID:      unitID,
+		keyGenBranch:  strings.ToUpper(fromBranch),
+		keyGenDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyGenBranch: strings.ToUpper(toBranch),
+			// uplink hanya berlaku dalam satu branch
+			keyGenUplink: "",
+		},
+	}
+
+	before := snapshotUnit(ctx, unitID)
+	var unit dto.GenUnitResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Unit tidak dipindahkan karena ID atau branch tidak valid")
+		}
+
+		logger.Error("Gagal memindahkan branch unit (MoveUnitBranch)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan branch unit", err)
+		return nil, apiErr
+	}
+
+	recordUnit(ctx, audit.ActionMove, before, unit)
+
+	return &unit, nil
+}
+
+// ChangeUplink mengganti unit induk, validasi siklus dilakukan pada service
+func (u *genUnitDao) ChangeUplink(ctx context.Context, input dto.GenUnitUplinkEdit) (*dto.GenUnitResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetProjection(bson.M{keyGenPingState: 0})
+
+	filter := bson.M{
+		keyGenID:      input.UnitID,
+		keyGenBranch:  strings.ToUpper(input.FilterBranch),
+		keyGenDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyGenUplink: input.Uplink,
+		},
+	}
+
+	before := snapshotUnit(ctx, input.UnitID)
+	var unit dto.GenUnitResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Uplink tidak diubah karena ID atau branch tidak valid")
+		}
+
+		logger.Error("Gagal mengubah uplink unit (ChangeUplink)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah uplink unit", err)
+		return nil, apiErr
+	}
+
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
+	return &unit, nil
+}
+
+// ReplaceUplink memindahkan unit turunan dari uplink lama ke uplink baru, digunakan saat penggabungan unit.
+// unit uplink baru sendiri tidak ikut diubah agar tidak menunjuk ke dirinya sendiri
+func (u *genUnitDao) ReplaceUplink(ctx context.Context, fromUplinkID string, toUplinkID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyGenID:     bson.M{"$ne": toUplinkID},
+		keyGenUplink: fromUplinkID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyGenUplink: toUplinkID,
+		},
+	}
+
+	affected, apiErr := u.findUnitRefs(ctxt, filter)
+	if apiErr != nil {
+		return 0, apiErr
+	}
+
+	result, err := coll.UpdateMany(ctxt, filter, update)
+	if err != nil {
+		logger.Error("Gagal memindahkan uplink unit (ReplaceUplink)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan uplink unit", err)
+		return 0, apiErr
+	}
+
+	for _, unit := range affected {
+		auditdao.Record(ctx, dto.AuditRecord{
+			Action:   audit.ActionEdit,
+			Entity:   keyGenUnitColl,
+			EntityID: unit.ID,
+			Branch:   unit.Branch,
+			Before:   bson.M{keyGenUplink: fromUplinkID},
+			After:    bson.M{keyGenUplink: toUplinkID},
+		})
+	}
+
+	return result.ModifiedCount, nil
+}
+
 func (u *genUnitDao) InsertCase(ctx context.Context, payload dto.GenUnitCaseRequest) (*dto.GenUnitResponse, rest_err.APIError) {
 	coll := db.DB.Collection(keyGenUnitColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
@@ -174,8 +347,9 @@ func (u *genUnitDao) InsertCase(ctx context.Context, payload dto.GenUnitCaseRequ
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyGenID:     payload.UnitID,
-		keyGenBranch: payload.FilterBranch,
+		keyGenID:      payload.UnitID,
+		keyGenBranch:  payload.FilterBranch,
+		keyGenDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -187,6 +361,7 @@ func (u *genUnitDao) InsertCase(ctx context.Context, payload dto.GenUnitCaseRequ
 		},
 	}
 
+	before := snapshotUnit(ctx, payload.UnitID)
 	var unit dto.GenUnitResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -198,6 +373,8 @@ func (u *genUnitDao) InsertCase(ctx context.Context, payload dto.GenUnitCaseRequ
 		return nil, apiErr
 	}
 
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
 	return &unit, nil
 }
 
@@ -212,8 +389,9 @@ func (u *genUnitDao) DeleteCase(ctx context.Context, payload dto.GenUnitCaseRequ
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyGenID:     payload.UnitID,
-		keyGenBranch: payload.FilterBranch,
+		keyGenID:      payload.UnitID,
+		keyGenBranch:  payload.FilterBranch,
+		keyGenDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -225,6 +403,7 @@ func (u *genUnitDao) DeleteCase(ctx context.Context, payload dto.GenUnitCaseRequ
 		},
 	}
 
+	before := snapshotUnit(ctx, payload.UnitID)
 	var unit dto.GenUnitResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -236,6 +415,8 @@ func (u *genUnitDao) DeleteCase(ctx context.Context, payload dto.GenUnitCaseRequ
 		return nil, apiErr
 	}
 
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
 	return &unit, nil
 }
 
@@ -249,7 +430,10 @@ func (u *genUnitDao) GetUnitByID(ctx context.Context, unitID string, branchIfSpe
 	var unit dto.GenUnitResponse
 	opts := options.FindOne()
 
-	filter := bson.M{keyGenID: unitID}
+	filter := bson.M{
+		keyGenID:      unitID,
+		keyGenDeleted: bson.M{"$ne": true},
+	}
 
 	if branchIfSpecific != "" {
 		filter[keyGenBranch] = branchIfSpecific
@@ -270,17 +454,23 @@ func (u *genUnitDao) GetUnitByID(ctx context.Context, unitID string, branchIfSpe
 }
 
 // FindUnit wajib menyertakan branch
-func (u *genUnitDao) FindUnit(ctx context.Context, filterInput dto.GenUnitFilter) (dto.GenUnitResponseList, rest_err.APIError) {
+func (u *genUnitDao) FindUnit(ctx context.Context, filterInput dto.GenUnitFilter, page dto.PageRequest) (dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyGenUnitColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
+	query, apiErr := paging.New(page, 500, paging.SortKey{Field: keyGenName, Dir: 1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
 	filterInput.Name = strings.ToUpper(filterInput.Name)
 	filterInput.Category = strings.ToUpper(filterInput.Category)
 
 	filter := bson.M{
 		keyGenBranch:  filterInput.Branch,
-		keyGenDisable: false,
+		keyGenDisable: filterInput.Disable,
+		keyGenDeleted: bson.M{"$ne": true},
 	}
 	if filterInput.Category != "" {
 		filter[keyGenCategory] = filterInput.Category
@@ -296,22 +486,34 @@ func (u *genUnitDao) FindUnit(ctx context.Context, filterInput dto.GenUnitFilter
 	if filterInput.LastPing != "" {
 		filter[keyGenLastPing] = filterInput.LastPing
 	}
+	if filterInput.AlertState != "" {
+		filter[keyGenAlert] = strings.ToUpper(filterInput.AlertState)
+	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah unit dari database (FindUnit)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	// jika pings false (default) sembunyikan pingsState
 	if !filterInput.Pings {
 		opts.SetProjection(bson.M{keyGenPingState: 0})
 	}
 
-	opts.SetSort(bson.D{{Key: keyGenName, Value: 1}}) //nolint:govet
-	opts.SetLimit(500)
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 
 	if err != nil {
 		logger.Error("Gagal mendapatkan unit dari database (FindUnit)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.GenUnitResponseList{}, apiErr
+		return dto.GenUnitResponseList{}, dto.PageInfo{}, apiErr
 	}
 
 	units := dto.GenUnitResponseList{}
@@ -323,7 +525,7 @@ func (u *genUnitDao) FindUnit(ctx context.Context, filterInput dto.GenUnitFilter
 		if err != nil {
 			logger.Error("Gagal decode unitsCursor ke objek (FindUnit)", err)
 			apiErr := rest_err.NewInternalServerError("Database error", err)
-			return dto.GenUnitResponseList{}, apiErr
+			return dto.GenUnitResponseList{}, dto.PageInfo{}, apiErr
 		}
 
 		// set default value agar tidak nil karena projection 0
@@ -334,7 +536,7 @@ func (u *genUnitDao) FindUnit(ctx context.Context, filterInput dto.GenUnitFilter
 		units = append(units, unit)
 	}
 
-	return units, nil
+	return units, query.Info(&units, total), nil
 }
 
 func (u *genUnitDao) GetIPList(ctx context.Context, branchIfSpecific string, category string) ([]string, rest_err.APIError) {
@@ -346,6 +548,7 @@ func (u *genUnitDao) GetIPList(ctx context.Context, branchIfSpecific string, cat
 		keyGenCategory: strings.ToUpper(category),
 		keyGenIP:       bson.M{"$nin": bson.A{"0.0.0.0", ""}},
 		keyGenDisable:  false,
+		keyGenDeleted:  bson.M{"$ne": true},
 	}
 
 	if branchIfSpecific != "" {
@@ -379,6 +582,136 @@ func (u *genUnitDao) GetIPList(ctx context.Context, branchIfSpecific string, cat
 	return ipAddressList, nil
 }
 
+// ChangeAlertState memindahkan state alert unit secara atomic,
+// return nil tanpa error jika state unit sudah berubah oleh proses lain
+func (u *genUnitDao) ChangeAlertState(ctx context.Context, input dto.GenUnitAlertStateRequest) (*dto.GenUnitResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetProjection(bson.M{keyGenCases: 0})
+
+	filter := bson.M{
+		keyGenID:    input.UnitID,
+		keyGenAlert: input.FromState,
+	}
+	// unit lama belum memiliki field alert_state, dianggap OK
+	if input.FromState == alertstate.OK {
+		filter[keyGenAlert] = bson.M{"$in": bson.A{alertstate.OK, "", nil}}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyGenAlert:     input.ToState,
+			keyGenAlertTime: input.Since,
+		},
+	}
+
+	before := snapshotUnit(ctx, input.UnitID, keyGenCases)
+	var unit dto.GenUnitResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error("Gagal mengubah alert state unit (ChangeAlertState)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah alert state unit", err)
+		return nil, apiErr
+	}
+
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
+	return &unit, nil
+}
+
+// ChangeAutoCase menandai history yang dibuat otomatis oleh sistem pada unit.
+// return nil, nil jika auto_case_id sudah diubah sebelumnya
+func (u *genUnitDao) ChangeAutoCase(ctx context.Context, input dto.GenUnitAutoCaseRequest) (*dto.GenUnitResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetProjection(bson.M{keyGenCases: 0})
+
+	filter := bson.M{
+		keyGenID:       input.UnitID,
+		keyGenAutoCase: input.FromCaseID,
+	}
+	// unit lama belum memiliki field auto_case_id
+	if input.FromCaseID == "" {
+		filter[keyGenAutoCase] = bson.M{"$in": bson.A{"", nil}}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyGenAutoCase: input.ToCaseID,
+		},
+	}
+
+	before := snapshotUnit(ctx, input.UnitID, keyGenCases)
+	var unit dto.GenUnitResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&unit); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error("Gagal mengubah auto case unit (ChangeAutoCase)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah auto case unit", err)
+		return nil, apiErr
+	}
+
+	recordUnit(ctx, audit.ActionEdit, before, unit)
+
+	return &unit, nil
+}
+
+// FindUnitByIP mendapatkan unit aktif berdasarkan ip address, tanpa cases dan pings_state
+func (u *genUnitDao) FindUnitByIP(ctx context.Context, branchIfSpecific string, category string, ipAddresses []string) (dto.GenUnitResponseList, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if ipAddresses == nil {
+		ipAddresses = []string{}
+	}
+
+	filter := bson.M{
+		keyGenCategory: strings.ToUpper(category),
+		keyGenDisable:  false,
+		keyGenIP:       bson.M{"$in": ipAddresses},
+		keyGenDeleted:  bson.M{"$ne": true},
+	}
+
+	if branchIfSpecific != "" {
+		filter[keyGenBranch] = strings.ToUpper(branchIfSpecific)
+	}
+
+	opts := options.Find()
+	opts.SetProjection(bson.M{keyGenPingState: 0, keyGenCases: 0})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan unit dari database (FindUnitByIP)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.GenUnitResponseList{}, apiErr
+	}
+
+	units := dto.GenUnitResponseList{}
+	if err = cursor.All(ctxt, &units); err != nil {
+		logger.Error("Gagal decode units cursor ke objek slice (FindUnitByIP)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.GenUnitResponseList{}, apiErr
+	}
+
+	return units, nil
+}
+
+// AppendPingState tidak dicatat pada audit karena dipanggil untuk setiap batch ping,
+// perubahan kondisi unit tercatat melalui ChangeAlertState
 func (u *genUnitDao) AppendPingState(ctx context.Context, input dto.GenUnitPingStateRequest) (int64, rest_err.APIError) {
 	coll := db.DB.Collection(keyGenUnitColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
@@ -394,6 +727,7 @@ func (u *genUnitDao) AppendPingState(ctx context.Context, input dto.GenUnitPingS
 		keyGenCategory: strings.ToUpper(input.Category),
 		keyGenDisable:  false,
 		keyGenIP:       bson.M{"$in": input.IPAddresses},
+		keyGenDeleted:  bson.M{"$ne": true},
 	}
 
 	if input.Branch != "" {
@@ -430,3 +764,48 @@ func (u *genUnitDao) AppendPingState(ctx context.Context, input dto.GenUnitPingS
 
 	return result.ModifiedCount, nil
 }
+
+// findUnitRefs mendapatkan id dan branch unit yang akan terkena update banyak dokumen, digunakan untuk audit
+func (u *genUnitDao) findUnitRefs(ctx context.Context, filter bson.M) ([]dto.GenUnitResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyGenUnitColl)
+
+	opts := options.Find()
+	opts.SetProjection(bson.M{keyGenID: 1, keyGenBranch: 1})
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan unit dari database (findUnitRefs)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return nil, apiErr
+	}
+
+	units := make([]dto.GenUnitResponse, 0)
+	if err = cursor.All(ctx, &units); err != nil {
+		logger.Error("Gagal decode units cursor ke objek slice (findUnitRefs)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return nil, apiErr
+	}
+	return units, nil
+}
+
+// snapshotUnit dokumen unit sebelum diubah sebagai pembanding audit. pings_state tidak dicatat
+// karena berubah pada setiap ping, field yang tidak ikut projection hasil update juga dibuang
+func snapshotUnit(ctx context.Context, unitID string, omitFields ...string) bson.M {
+	before := auditdao.Snapshot(ctx, keyGenUnitColl, unitID)
+	for _, field := range append(omitFields, keyGenPingState) {
+		delete(before, field)
+	}
+	return before
+}
+
+func recordUnit(ctx context.Context, action string, before bson.M, unit dto.GenUnitResponse) {
+	unit.PingsState = nil
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   action,
+		Entity:   keyGenUnitColl,
+		EntityID: unit.ID,
+		Branch:   unit.Branch,
+		Before:   before,
+		After:    unit,
+	})
+}
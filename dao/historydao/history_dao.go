@@ -10,8 +10,11 @@ import (
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -21,6 +24,7 @@ import (
 
 const (
 	connectTimeout = 3
+	iterateTimeout = 120
 	keyHistColl    = "history"
 
 	keyHistID             = "_id"
@@ -32,10 +36,17 @@ const (
 	keyHistBranch         = "branch"
 	keyHistCategory       = "category"
 	keyHistParentID       = "parent_id"
+	keyHistParentName     = "parent_name"
 	keyHistStatus         = "status"
 	keyHistProblem        = "problem"
 	keyHistProblemResolve = "problem_resolve"
 	keyHistCompleteStatus = "complete_status"
+	keyHistPriority       = "priority"
+	keyHistSLA            = "sla"
+	keyHistAssigneeID     = "assignee_id"
+	keyHistAssigneeName   = "assignee_name"
+	keyHistAssignedAt     = "assigned_at"
+	keyHistWatchers       = "watchers"
 	keyHistDateStart      = "date_start"
 	keyHistDateEnd        = "date_end"
 	keyHistTag            = "tag"
@@ -63,6 +74,9 @@ func (h *historyDao) InsertHistory(ctx context.Context, input dto.History, isVen
 	if input.Updates == nil {
 		input.Updates = []dto.HistoryUpdate{}
 	}
+	if input.Watchers == nil {
+		input.Watchers = []dto.HistoryUser{}
+	}
 
 	// History versi 2 akan menambahkan detail riwayat perubahan dalam bentuk array
 	input.Version = 2
@@ -86,6 +100,14 @@ func (h *historyDao) InsertHistory(ctx context.Context, input dto.History, isVen
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyHistColl,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -104,6 +126,9 @@ func (h *historyDao) InsertManyHistory(ctx context.Context, dataList []dto.Histo
 		if data.Updates == nil {
 			data.Updates = []dto.HistoryUpdate{}
 		}
+		if data.Watchers == nil {
+			data.Watchers = []dto.HistoryUser{}
+		}
 
 		data.Updates = []dto.HistoryUpdate{{
 			Time:           data.CreatedAt,
@@ -129,11 +154,131 @@ func (h *historyDao) InsertManyHistory(ctx context.Context, dataList []dto.Histo
 		return 0, apiErr
 	}
 
+	for i, insertedID := range result.InsertedIDs {
+		oid, _ := insertedID.(primitive.ObjectID)
+		auditdao.Record(ctx, dto.AuditRecord{
+			Action:   audit.ActionInsert,
+			Entity:   keyHistColl,
+			EntityID: oid.Hex(),
+			Branch:   dataForInserts[i].(dto.History).Branch,
+			After:    dataForInserts[i],
+		})
+	}
+
 	totalInserted := len(result.InsertedIDs)
 
 	return totalInserted, nil
 }
 
+// MoveHistoryBranch memindahkan seluruh history milik parent (termasuk kasus yang masih terbuka) ke cabang tujuan
+func (h *historyDao) MoveHistoryBranch(ctx context.Context, parentID string, fromBranch string, toBranch string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyHistParentID: parentID,
+		keyHistBranch:   strings.ToUpper(fromBranch),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyHistBranch: strings.ToUpper(toBranch),
+		},
+	}
+
+	affected, apiErr := h.findHistoryRefs(ctxt, filter)
+	if apiErr != nil {
+		return 0, apiErr
+	}
+
+	result, err := coll.UpdateMany(ctxt, filter, update)
+	if err != nil {
+		logger.Error("Gagal memindahkan branch history (MoveHistoryBranch)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan branch history", err)
+		return 0, apiErr
+	}
+
+	for _, history := range affected {
+		auditdao.Record(ctx, dto.AuditRecord{
+			Action:   audit.ActionMove,
+			Entity:   keyHistColl,
+			EntityID: history.ID.Hex(),
+			Branch:   strings.ToUpper(toBranch),
+			Before:   bson.M{keyHistBranch: history.Branch},
+			After:    bson.M{keyHistBranch: strings.ToUpper(toBranch)},
+		})
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ReparentHistory memindahkan seluruh history unit lama ke unit baru, digunakan saat penggabungan unit
+func (h *historyDao) ReparentHistory(ctx context.Context, fromParentID string, toParentID string, toParentName string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyHistParentID: fromParentID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyHistParentID:   toParentID,
+			keyHistParentName: toParentName,
+		},
+	}
+
+	affected, apiErr := h.findHistoryRefs(ctxt, filter)
+	if apiErr != nil {
+		return 0, apiErr
+	}
+
+	result, err := coll.UpdateMany(ctxt, filter, update)
+	if err != nil {
+		logger.Error("Gagal memindahkan parent history (ReparentHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan parent history", err)
+		return 0, apiErr
+	}
+
+	for _, history := range affected {
+		auditdao.Record(ctx, dto.AuditRecord{
+			Action:   audit.ActionEdit,
+			Entity:   keyHistColl,
+			EntityID: history.ID.Hex(),
+			Branch:   history.Branch,
+			Before:   bson.M{keyHistParentID: history.ParentID, keyHistParentName: history.ParentName},
+			After:    bson.M{keyHistParentID: toParentID, keyHistParentName: toParentName},
+		})
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// findHistoryRefs mendapatkan id, branch dan parent history yang akan terkena update banyak dokumen, digunakan untuk audit
+func (h *historyDao) findHistoryRefs(ctx context.Context, filter bson.M) ([]dto.HistoryResponseMin, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+
+	opts := options.Find()
+	opts.SetProjection(bson.M{keyHistID: 1, keyHistBranch: 1, keyHistParentID: 1, keyHistParentName: 1})
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan history dari database (findHistoryRefs)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return nil, apiErr
+	}
+
+	histories := make([]dto.HistoryResponseMin, 0)
+	if err = cursor.All(ctx, &histories); err != nil {
+		logger.Error("Gagal decode histories cursor ke objek slice (findHistoryRefs)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return nil, apiErr
+	}
+	return histories, nil
+}
+
 func (h *historyDao) EditHistory(ctx context.Context, historyID primitive.ObjectID, input dto.HistoryEdit, isVendor bool) (*dto.HistoryResponse, rest_err.APIError) {
 	coll := db.DB.Collection(keyHistColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
@@ -178,6 +323,12 @@ func (h *historyDao) EditHistory(ctx context.Context, historyID primitive.Object
 		},
 	}
 
+	// prioritas kosong berarti prioritas lama dipertahankan
+	if input.Priority != "" {
+		update["$set"].(bson.M)[keyHistPriority] = input.Priority
+	}
+
+	before := auditdao.Snapshot(ctx, keyHistColl, historyID)
 	var history dto.HistoryResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&history); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -189,6 +340,189 @@ func (h *historyDao) EditHistory(ctx context.Context, historyID primitive.Object
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   before,
+		After:    history,
+	})
+
+	return &history, nil
+}
+
+// AppendUpdate menambahkan catatan update tanpa merubah isi history,
+// hanya untuk history yang belum complete
+func (h *historyDao) AppendUpdate(ctx context.Context, historyID primitive.ObjectID, input dto.HistoryUpdate) (*dto.HistoryResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyHistID:             historyID,
+		keyHistCompleteStatus: bson.M{"$nin": bson.A{enum.HComplete, enum.HCompleteWithBA, enum.HInfo}},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyHistUpdatedAt:   input.Time,
+			keyHistUpdatedBy:   input.UpdatedBy,
+			keyHistUpdatedByID: input.UpdatedByID,
+		},
+		"$push": bson.M{
+			keyHistUpdates: input,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyHistColl, historyID)
+	var history dto.HistoryResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&history); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("History tidak diupdate : history tidak ditemukan atau sudah complete")
+		}
+
+		logger.Error("Gagal menambahkan update history ke database (AppendUpdate)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menambahkan update history ke database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   before,
+		After:    history,
+	})
+
+	return &history, nil
+}
+
+// SetSLA menyimpan hasil perhitungan SLA tanpa merubah updated_at,
+// sehingga filter timestamp pada EditHistory tetap berlaku
+func (h *historyDao) SetSLA(ctx context.Context, historyID primitive.ObjectID, input dto.HistorySLA) rest_err.APIError {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{keyHistID: historyID}
+	update := bson.M{
+		"$set": bson.M{
+			keyHistSLA: input,
+		},
+	}
+
+	result, err := coll.UpdateOne(ctxt, filter, update)
+	if err != nil {
+		logger.Error("Gagal menyimpan sla history ke database (SetSLA)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan sla history ke database", err)
+		return apiErr
+	}
+
+	if result.MatchedCount == 0 {
+		return rest_err.NewBadRequestError("SLA tidak diupdate : history tidak ditemukan")
+	}
+
+	return nil
+}
+
+// AssignHistory mengganti assignee history yang belum complete tanpa merubah updated_at,
+// sehingga filter timestamp pada EditHistory tetap berlaku
+func (h *historyDao) AssignHistory(ctx context.Context, input dto.HistoryAssign) (*dto.HistoryResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyHistID:             input.FilterID,
+		keyHistBranch:         input.FilterBranch,
+		keyHistCompleteStatus: bson.M{"$nin": bson.A{enum.HComplete, enum.HCompleteWithBA, enum.HInfo, enum.HDataInfo}},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyHistAssigneeID:   input.AssigneeID,
+			keyHistAssigneeName: input.AssigneeName,
+			keyHistAssignedAt:   input.AssignedAt,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyHistColl, input.FilterID)
+	var history dto.HistoryResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&history); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("History tidak diupdate : history tidak ditemukan atau sudah complete")
+		}
+
+		logger.Error("Gagal menyimpan assignee history ke database (AssignHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan assignee history ke database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   before,
+		After:    history,
+	})
+
+	return &history, nil
+}
+
+// SetWatchers mengganti daftar watcher history tanpa merubah updated_at
+func (h *historyDao) SetWatchers(ctx context.Context, input dto.HistoryWatcherEdit) (*dto.HistoryResponse, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if input.Watchers == nil {
+		input.Watchers = []dto.HistoryUser{}
+	}
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyHistID:     input.FilterID,
+		keyHistBranch: input.FilterBranch,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyHistWatchers: input.Watchers,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyHistColl, input.FilterID)
+	var history dto.HistoryResponse
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&history); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("History tidak diupdate : validasi id branch")
+		}
+
+		logger.Error("Gagal menyimpan watcher history ke database (SetWatchers)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan watcher history ke database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   before,
+		After:    history,
+	})
+
 	return &history, nil
 }
 
@@ -215,6 +549,14 @@ func (h *historyDao) DeleteHistory(ctx context.Context, input dto.FilterIDBranch
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   history,
+	})
+
 	return &history, nil
 }
 
@@ -245,7 +587,7 @@ func (h *historyDao) GetHistoryByID(ctx context.Context, historyID primitive.Obj
 	return &history, nil
 }
 
-func (h *historyDao) FindHistory(ctx context.Context, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit) (dto.HistoryResponseMinList, rest_err.APIError) {
+func (h *historyDao) FindHistory(ctx context.Context, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.HistoryResponseMinList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyHistColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
@@ -253,9 +595,9 @@ func (h *historyDao) FindHistory(ctx context.Context, filterA dto.FilterBranchCa
 	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
 	filterA.FilterCategory = strings.ToUpper(filterA.FilterCategory)
 
-	// set default limit
-	if filterB.Limit == 0 {
-		filterB.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyHistUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// empty filter
@@ -287,6 +629,11 @@ func (h *historyDao) FindHistory(ctx context.Context, filterA dto.FilterBranchCa
 		}
 	}
 
+	// tag, ex SYSTEM-AUTO untuk insiden yang dibuat otomatis
+	if filterA.FilterTag != "" {
+		filter[keyHistTag] = strings.ToUpper(filterA.FilterTag)
+	}
+
 	// option range
 	if filterB.FilterStart != 0 {
 		filter[keyHistUpdatedAt] = bson.M{"$gte": filterB.FilterStart}
@@ -295,26 +642,32 @@ func (h *historyDao) FindHistory(ctx context.Context, filterA dto.FilterBranchCa
 		filter[keyHistCreatedAt] = bson.M{"$lte": filterB.FilterEnd}
 	}
 
-	opts := options.Find()
-	opts.SetSort(bson.D{{Key: keyHistUpdatedAt, Value: -1}})
-	opts.SetLimit(filterB.Limit)
-
-	cursor, err := coll.Find(ctxt, filter, opts)
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah history dari database (FindHistory)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
 
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
 	if err != nil {
 		logger.Error("Gagal mendapatkan daftar history dari database (FindHistory)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.HistoryResponseMinList{}, apiErr
+		return dto.HistoryResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
 	histories := dto.HistoryResponseMinList{}
 	if err = cursor.All(ctxt, &histories); err != nil {
 		logger.Error("Gagal decode histories cursor ke objek slice (FindHistory)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.HistoryResponseMinList{}, apiErr
+		return dto.HistoryResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
-	return histories, nil
+	return histories, query.Info(&histories, total), nil
 }
 
 /*
@@ -383,6 +736,11 @@ func (h *historyDao) SearchHistory(ctx context.Context, search string, filterA d
 		}
 	}
 
+	// tag, ex SYSTEM-AUTO untuk insiden yang dibuat otomatis
+	if filterA.FilterTag != "" {
+		filter[keyHistTag] = strings.ToUpper(filterA.FilterTag)
+	}
+
 	// option range
 	if filterB.FilterStart != 0 {
 		filter[keyHistUpdatedAt] = bson.M{"$gte": filterB.FilterStart}
@@ -436,11 +794,6 @@ func (h *historyDao) UnwindHistory(ctx context.Context, filterA dto.FilterBranch
 	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
 	filterA.FilterCategory = strings.ToUpper(filterA.FilterCategory)
 
-	// set default limit
-	if filterB.Limit == 0 {
-		filterB.Limit = 100
-	}
-
 	// empty filter
 	filter := bson.M{}
 
@@ -548,6 +901,62 @@ func (h *historyDao) FindHistoryForParent(ctx context.Context, parentID string)
 	return histories, nil
 }
 
+// FindLastHistoryPerParent mengembalikan satu history terakhir (updated_at terbaru) untuk setiap parent_id
+// FilterCategory dapat berisi beberapa kategori dipisah koma
+func (h *historyDao) FindLastHistoryPerParent(ctx context.Context, filterA dto.FilterBranchCategory) (dto.HistoryResponseMinList, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
+	filterA.FilterCategory = strings.ToUpper(filterA.FilterCategory)
+
+	filter := bson.M{}
+	if filterA.FilterBranch != "" {
+		filter[keyHistBranch] = filterA.FilterBranch
+	}
+	if filterA.FilterCategory != "" {
+		if strings.Contains(filterA.FilterCategory, ",") {
+			categories := strings.Split(filterA.FilterCategory, ",")
+			filter[keyHistCategory] = bson.M{"$in": categories}
+		} else {
+			filter[keyHistCategory] = filterA.FilterCategory
+		}
+	}
+
+	matchStage := bson.D{
+		{Key: "$match", Value: filter},
+	}
+	sortStage := bson.D{
+		{Key: "$sort", Value: bson.D{{Key: keyHistUpdatedAt, Value: -1}}},
+	}
+	groupStage := bson.D{
+		{Key: "$group", Value: bson.M{
+			"_id":  "$" + keyHistParentID,
+			"last": bson.M{"$first": "$$ROOT"},
+		}},
+	}
+	replaceStage := bson.D{
+		{Key: "$replaceRoot", Value: bson.M{"newRoot": "$last"}},
+	}
+
+	cursor, err := coll.Aggregate(ctxt, mongo.Pipeline{matchStage, sortStage, groupStage, replaceStage}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		logger.Error("Gagal mendapatkan history terakhir dari database (FindLastHistoryPerParent)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	histories := dto.HistoryResponseMinList{}
+	if err = cursor.All(ctxt, &histories); err != nil {
+		logger.Error("Gagal decode histories cursor ke objek slice (FindLastHistoryPerParent)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	return histories, nil
+}
+
 func (h *historyDao) FindHistoryForUser(ctx context.Context, userID string, filterOpt dto.FilterTimeRangeLimit) (dto.HistoryResponseMinList, rest_err.APIError) {
 	coll := db.DB.Collection(keyHistColl)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
@@ -667,6 +1076,7 @@ func (h *historyDao) UploadImage(ctx context.Context, historyID primitive.Object
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyHistColl, historyID)
 	var history dto.HistoryResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&history); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -678,6 +1088,15 @@ func (h *historyDao) UploadImage(ctx context.Context, historyID primitive.Object
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyHistColl,
+		EntityID: history.ID.Hex(),
+		Branch:   history.Branch,
+		Before:   before,
+		After:    history,
+	})
+
 	return &history, nil
 }
 
@@ -736,3 +1155,119 @@ func (h *historyDao) FindHistoryForReport(ctx context.Context, branchIfSpecific
 	histories := append(histories04, histories123...)
 	return histories, nil
 }
+
+// IterateHistory membaca history branch satu per satu menggunakan cursor tanpa memuat semuanya ke memory,
+// digunakan untuk membangun index pencarian. iterasi berhenti jika fn mengembalikan error
+func (h *historyDao) IterateHistory(ctx context.Context, branch string, fn func(dto.HistoryResponseMin) error) rest_err.APIError {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if branch != "" {
+		filter[keyHistBranch] = strings.ToUpper(branch)
+	}
+
+	opts := options.Find()
+	opts.SetProjection(bson.M{keyHistUpdates: 0})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan history dari database (IterateHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+	defer cursor.Close(ctxt)
+
+	for cursor.Next(ctxt) {
+		var item dto.HistoryResponseMin
+		if err := cursor.Decode(&item); err != nil {
+			logger.Error("Gagal decode history cursor ke objek (IterateHistory)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return apiErr
+		}
+		if err := fn(item); err != nil {
+			apiErr := rest_err.NewInternalServerError("Gagal membaca data history", err)
+			return apiErr
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Gagal membaca history cursor (IterateHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// FindHistoryForSLA mengembalikan history yang masih berjalan (progress dan pending) beserta updates,
+// digunakan oleh evaluator SLA. branch kosong berarti semua branch
+func (h *historyDao) FindHistoryForSLA(ctx context.Context, branch string) (dto.HistoryResponseMinList, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyHistCompleteStatus: bson.M{"$in": bson.A{enum.HProgress, enum.HRequestPending, enum.HPending}},
+	}
+	if branch != "" {
+		filter[keyHistBranch] = strings.ToUpper(branch)
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyHistDateStart, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar history dari database (FindHistoryForSLA)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	histories := dto.HistoryResponseMinList{}
+	if err = cursor.All(ctxt, &histories); err != nil {
+		logger.Error("Gagal decode histories cursor ke objek slice (FindHistoryForSLA)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	return histories, nil
+}
+
+// FindOpenHistory mengembalikan history yang belum complete pada branch,
+// assigneeID tidak kosong membatasi hanya history milik user tersebut
+func (h *historyDao) FindOpenHistory(ctx context.Context, branch string, assigneeID string) (dto.HistoryResponseMinList, rest_err.APIError) {
+	coll := db.DB.Collection(keyHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyHistCompleteStatus: bson.M{"$in": bson.A{enum.HProgress, enum.HRequestPending, enum.HPending, enum.HRequestComplete}},
+	}
+	if branch != "" {
+		filter[keyHistBranch] = strings.ToUpper(branch)
+	}
+	if assigneeID != "" {
+		filter[keyHistAssigneeID] = assigneeID
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyHistDateStart, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar history dari database (FindOpenHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	histories := dto.HistoryResponseMinList{}
+	if err = cursor.All(ctxt, &histories); err != nil {
+		logger.Error("Gagal decode histories cursor ke objek slice (FindOpenHistory)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.HistoryResponseMinList{}, apiErr
+	}
+
+	return histories, nil
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -62,6 +64,14 @@ func (s *improveDao) InsertImprove(ctx context.Context, input dto.Improve) (*str
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyImpCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -93,6 +103,7 @@ func (s *improveDao) EditImprove(ctx context.Context, input dto.ImproveEdit) (*d
 		}},
 	}
 
+	before := auditdao.Snapshot(ctx, keyImpCollection, input.FilterID)
 	var improve dto.Improve
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&improve); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -104,6 +115,15 @@ func (s *improveDao) EditImprove(ctx context.Context, input dto.ImproveEdit) (*d
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyImpCollection,
+		EntityID: improve.ID.Hex(),
+		Branch:   improve.Branch,
+		Before:   before,
+		After:    improve,
+	})
+
 	return &improve, nil
 }
 
@@ -127,6 +147,7 @@ func (s *improveDao) ChangeImprove(ctx context.Context, filterA dto.FilterIDBran
 		{"$push", bson.M{keyImpImproveChanges: data}},         //nolint:govet
 	}
 
+	before := auditdao.Snapshot(ctx, keyImpCollection, filterA.FilterID)
 	var improve dto.Improve
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&improve); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -138,6 +159,15 @@ func (s *improveDao) ChangeImprove(ctx context.Context, filterA dto.FilterIDBran
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyImpCollection,
+		EntityID: improve.ID.Hex(),
+		Branch:   improve.Branch,
+		Before:   before,
+		After:    improve,
+	})
+
 	return &improve, nil
 }
 
@@ -164,6 +194,14 @@ func (s *improveDao) DeleteImprove(ctx context.Context, input dto.FilterIDBranch
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyImpCollection,
+		EntityID: improve.ID.Hex(),
+		Branch:   improve.Branch,
+		Before:   improve,
+	})
+
 	return &improve, nil
 }
 
@@ -187,6 +225,7 @@ func (s *improveDao) ActivateImprove(ctx context.Context, improveID primitive.Ob
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyImpCollection, improveID)
 	var improve dto.Improve
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&improve); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -198,6 +237,15 @@ func (s *improveDao) ActivateImprove(ctx context.Context, improveID primitive.Ob
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyImpCollection,
+		EntityID: improve.ID.Hex(),
+		Branch:   improve.Branch,
+		Before:   before,
+		After:    improve,
+	})
+
 	return &improve, nil
 }
 
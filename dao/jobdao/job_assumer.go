@@ -0,0 +1,28 @@
+package jobdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobDaoAssumer interface {
+	JobSaver
+	JobLoader
+}
+
+type JobSaver interface {
+	InsertJob(ctx context.Context, input dto.Job) (*string, rest_err.APIError)
+	InsertManyJob(ctx context.Context, dataList []dto.Job) (int, rest_err.APIError)
+	EditJob(ctx context.Context, input dto.JobEdit) (*dto.Job, rest_err.APIError)
+	DeleteJob(ctx context.Context, jobID primitive.ObjectID) rest_err.APIError
+	UpdateLastRun(ctx context.Context, jobID primitive.ObjectID, runAt int64, runError string) rest_err.APIError
+	InsertRun(ctx context.Context, input dto.JobRun) (*string, rest_err.APIError)
+}
+
+type JobLoader interface {
+	GetJobByID(ctx context.Context, jobID primitive.ObjectID) (*dto.Job, rest_err.APIError)
+	FindJob(ctx context.Context, filter dto.FilterJob, page dto.PageRequest) ([]dto.Job, dto.PageInfo, rest_err.APIError)
+	FindRun(ctx context.Context, filter dto.FilterJobRun, page dto.PageRequest) ([]dto.JobRun, dto.PageInfo, rest_err.APIError)
+}
@@ -0,0 +1,335 @@
+package jobdao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout = 3
+	keyJobColl     = "job"
+	keyJobRunColl  = "jobRun"
+
+	keyJobID          = "_id"
+	keyJobUpdatedAt   = "updated_at"
+	keyJobUpdatedBy   = "updated_by"
+	keyJobUpdatedByID = "updated_by_id"
+	keyJobName        = "name"
+	keyJobType        = "type"
+	keyJobBranch      = "branch"
+	keyJobCategory    = "category"
+	keyJobCron        = "cron"
+	keyJobEnabled     = "enabled"
+	keyJobLastRunAt   = "last_run_at"
+	keyJobLastRunErr  = "last_run_error"
+
+	keyRunJobID     = "job_id"
+	keyRunBranch    = "branch"
+	keyRunStartedAt = "started_at"
+)
+
+func NewJobDao() JobDaoAssumer {
+	return &jobDao{}
+}
+
+type jobDao struct{}
+
+func (j *jobDao) InsertJob(ctx context.Context, input dto.Job) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.Name = strings.ToUpper(input.Name)
+	input.Branch = strings.ToUpper(input.Branch)
+	input.Category = strings.ToUpper(input.Category)
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan job ke database", err)
+		logger.Error("Gagal menyimpan job ke database (InsertJob)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyJobColl,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
+	return &insertID, nil
+}
+
+func (j *jobDao) InsertManyJob(ctx context.Context, dataList []dto.Job) (int, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var dataForInserts []interface{}
+	for _, data := range dataList {
+		data.Name = strings.ToUpper(data.Name)
+		data.Branch = strings.ToUpper(data.Branch)
+		data.Category = strings.ToUpper(data.Category)
+		dataForInserts = append(dataForInserts, data)
+	}
+
+	result, err := coll.InsertMany(ctxt, dataForInserts)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan banyak job ke database", err)
+		logger.Error("Gagal menyimpan banyak job ke database (InsertManyJob)", err)
+		return 0, apiErr
+	}
+
+	return len(result.InsertedIDs), nil
+}
+
+func (j *jobDao) EditJob(ctx context.Context, input dto.JobEdit) (*dto.Job, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyJobID: input.FilterID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyJobUpdatedAt:   input.UpdatedAt,
+			keyJobUpdatedBy:   input.UpdatedBy,
+			keyJobUpdatedByID: input.UpdatedByID,
+			keyJobName:        strings.ToUpper(input.Name),
+			keyJobType:        input.Type,
+			keyJobBranch:      strings.ToUpper(input.Branch),
+			keyJobCategory:    strings.ToUpper(input.Category),
+			keyJobCron:        input.Cron,
+			keyJobEnabled:     input.Enabled,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyJobColl, input.FilterID)
+	var job dto.Job
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Job tidak diupdate karena ID tidak valid")
+		}
+
+		logger.Error("Gagal mendapatkan job dari database (EditJob)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan job dari database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyJobColl,
+		EntityID: job.ID.Hex(),
+		Branch:   job.Branch,
+		Before:   before,
+		After:    job,
+	})
+
+	return &job, nil
+}
+
+func (j *jobDao) DeleteJob(ctx context.Context, jobID primitive.ObjectID) rest_err.APIError {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	before := auditdao.Snapshot(ctx, keyJobColl, jobID)
+	result, err := coll.DeleteOne(ctxt, bson.M{keyJobID: jobID})
+	if err != nil {
+		logger.Error("Gagal menghapus job dari database (DeleteJob)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus job dari database", err)
+		return apiErr
+	}
+
+	if result.DeletedCount == 0 {
+		return rest_err.NewBadRequestError("Job tidak dihapus karena ID tidak valid")
+	}
+
+	branch, _ := before[keyJobBranch].(string)
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyJobColl,
+		EntityID: jobID.Hex(),
+		Branch:   branch,
+		Before:   before,
+	})
+
+	return nil
+}
+
+// UpdateLastRun mencatat waktu dan error eksekusi terakhir tanpa merubah updated_at,
+// updated_at digunakan scheduler untuk mendeteksi perubahan jadwal
+func (j *jobDao) UpdateLastRun(ctx context.Context, jobID primitive.ObjectID, runAt int64, runError string) rest_err.APIError {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			keyJobLastRunAt:  runAt,
+			keyJobLastRunErr: runError,
+		},
+	}
+
+	if _, err := coll.UpdateOne(ctxt, bson.M{keyJobID: jobID}, update); err != nil {
+		logger.Error("Gagal mengupdate last run job (UpdateLastRun)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengupdate last run job", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+func (j *jobDao) InsertRun(ctx context.Context, input dto.JobRun) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobRunColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan run log job ke database", err)
+		logger.Error("Gagal menyimpan run log job ke database (InsertRun)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	return &insertID, nil
+}
+
+func (j *jobDao) GetJobByID(ctx context.Context, jobID primitive.ObjectID) (*dto.Job, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var job dto.Job
+	if err := coll.FindOne(ctxt, bson.M{keyJobID: jobID}).Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError("Job dengan ID tersebut tidak ditemukan")
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan job dari database (GetJobByID)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan job dari database", err)
+		return nil, apiErr
+	}
+
+	return &job, nil
+}
+
+func (j *jobDao) FindJob(ctx context.Context, filter dto.FilterJob, page dto.PageRequest) ([]dto.Job, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyJobBranch, Dir: 1}, paging.SortKey{Field: keyJobType, Dir: 1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filterM := bson.M{}
+	if filter.FilterBranch != "" {
+		filterM[keyJobBranch] = strings.ToUpper(filter.FilterBranch)
+	}
+	if filter.FilterType != "" {
+		filterM[keyJobType] = strings.ToUpper(filter.FilterType)
+	}
+	if filter.EnabledOnly {
+		filterM[keyJobEnabled] = true
+	}
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filterM)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah job dari database (FindJob)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filterM), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan job dari database (FindJob)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Job{}, dto.PageInfo{}, apiErr
+	}
+
+	jobList := make([]dto.Job, 0)
+	if err = cursor.All(ctxt, &jobList); err != nil {
+		logger.Error("Gagal decode jobList cursor ke objek slice (FindJob)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Job{}, dto.PageInfo{}, apiErr
+	}
+
+	return jobList, query.Info(&jobList, total), nil
+}
+
+func (j *jobDao) FindRun(ctx context.Context, filter dto.FilterJobRun, page dto.PageRequest) ([]dto.JobRun, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyJobRunColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyRunStartedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filterM := bson.M{}
+	if filter.FilterJobID != "" {
+		filterM[keyRunJobID] = filter.FilterJobID
+	}
+	if filter.FilterBranch != "" {
+		filterM[keyRunBranch] = strings.ToUpper(filter.FilterBranch)
+	}
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filterM)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah run log job dari database (FindRun)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filterM), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan run log job dari database (FindRun)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.JobRun{}, dto.PageInfo{}, apiErr
+	}
+
+	runList := make([]dto.JobRun, 0)
+	if err = cursor.All(ctxt, &runList); err != nil {
+		logger.Error("Gagal decode runList cursor ke objek slice (FindRun)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.JobRun{}, dto.PageInfo{}, apiErr
+	}
+
+	return runList, query.Info(&runList, total), nil
+}
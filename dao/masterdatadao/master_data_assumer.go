@@ -0,0 +1,24 @@
+package masterdatadao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MasterDataDaoAssumer interface {
+	MasterDataSaver
+	MasterDataLoader
+}
+
+type MasterDataSaver interface {
+	InsertMaster(ctx context.Context, input dto.MasterData) (*string, rest_err.APIError)
+	EditMaster(ctx context.Context, input dto.MasterDataEdit) (*dto.MasterData, rest_err.APIError)
+	DeleteMaster(ctx context.Context, id primitive.ObjectID) rest_err.APIError
+}
+
+type MasterDataLoader interface {
+	GetMasterByID(ctx context.Context, id primitive.ObjectID) (*dto.MasterData, rest_err.APIError)
+	FindMaster(ctx context.Context, filter dto.FilterMasterData) ([]dto.MasterData, rest_err.APIError)
+}
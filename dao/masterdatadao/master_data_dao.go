@@ -0,0 +1,200 @@
+package masterdatadao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout    = 3
+	keyMasterDataColl = "masterData"
+
+	keyMdID          = "_id"
+	keyMdUpdatedAt   = "updated_at"
+	keyMdUpdatedBy   = "updated_by"
+	keyMdUpdatedByID = "updated_by_id"
+	keyMdKind        = "kind"
+	keyMdValue       = "value"
+	keyMdBranch      = "branch"
+	keyMdLang        = "lang"
+	keyMdOrder       = "order"
+)
+
+func NewMasterDataDao() MasterDataDaoAssumer {
+	return &masterDataDao{}
+}
+
+type masterDataDao struct{}
+
+func (m *masterDataDao) InsertMaster(ctx context.Context, input dto.MasterData) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyMasterDataColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.Kind = strings.ToUpper(input.Kind)
+	input.Branch = strings.ToUpper(input.Branch)
+	input.Lang = strings.ToLower(input.Lang)
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan master data ke database", err)
+		logger.Error("Gagal menyimpan master data ke database (InsertMaster)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyMasterDataColl,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
+	return &insertID, nil
+}
+
+func (m *masterDataDao) EditMaster(ctx context.Context, input dto.MasterDataEdit) (*dto.MasterData, rest_err.APIError) {
+	coll := db.DB.Collection(keyMasterDataColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyMdID: input.FilterID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyMdUpdatedAt:   input.UpdatedAt,
+			keyMdUpdatedBy:   input.UpdatedBy,
+			keyMdUpdatedByID: input.UpdatedByID,
+			keyMdValue:       input.Value,
+			keyMdBranch:      strings.ToUpper(input.Branch),
+			keyMdLang:        strings.ToLower(input.Lang),
+			keyMdOrder:       input.Order,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyMasterDataColl, input.FilterID)
+	var master dto.MasterData
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&master); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Master data tidak diupdate karena ID tidak valid")
+		}
+
+		logger.Error("Gagal mendapatkan master data dari database (EditMaster)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan master data dari database", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyMasterDataColl,
+		EntityID: master.ID.Hex(),
+		Branch:   master.Branch,
+		Before:   before,
+		After:    master,
+	})
+
+	return &master, nil
+}
+
+func (m *masterDataDao) DeleteMaster(ctx context.Context, id primitive.ObjectID) rest_err.APIError {
+	coll := db.DB.Collection(keyMasterDataColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	before := auditdao.Snapshot(ctx, keyMasterDataColl, id)
+	result, err := coll.DeleteOne(ctxt, bson.M{keyMdID: id})
+	if err != nil {
+		logger.Error("Gagal menghapus master data dari database (DeleteMaster)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus master data dari database", err)
+		return apiErr
+	}
+
+	if result.DeletedCount == 0 {
+		return rest_err.NewBadRequestError("Master data tidak dihapus karena ID tidak valid")
+	}
+
+	branch, _ := before[keyMdBranch].(string)
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyMasterDataColl,
+		EntityID: id.Hex(),
+		Branch:   branch,
+		Before:   before,
+	})
+
+	return nil
+}
+
+func (m *masterDataDao) GetMasterByID(ctx context.Context, id primitive.ObjectID) (*dto.MasterData, rest_err.APIError) {
+	coll := db.DB.Collection(keyMasterDataColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	var master dto.MasterData
+	if err := coll.FindOne(ctxt, bson.M{keyMdID: id}).Decode(&master); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError("Master data dengan ID yang dimasukkan tidak ditemukan")
+			return nil, apiErr
+		}
+
+		logger.Error("Gagal mendapatkan master data dari database (GetMasterByID)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan master data dari database", err)
+		return nil, apiErr
+	}
+
+	return &master, nil
+}
+
+// FindMaster diurutkan berdasarkan kind, order lalu value
+func (m *masterDataDao) FindMaster(ctx context.Context, filter dto.FilterMasterData) ([]dto.MasterData, rest_err.APIError) {
+	coll := db.DB.Collection(keyMasterDataColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filterM := bson.M{}
+	if filter.FilterKind != "" {
+		filterM[keyMdKind] = strings.ToUpper(filter.FilterKind)
+	}
+	if filter.FilterBranch != "" {
+		filterM[keyMdBranch] = bson.M{"$in": bson.A{strings.ToUpper(filter.FilterBranch), ""}}
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyMdKind, Value: 1}, {Key: keyMdOrder, Value: 1}, {Key: keyMdValue, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, filterM, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan master data dari database (FindMaster)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.MasterData{}, apiErr
+	}
+
+	masters := make([]dto.MasterData, 0)
+	if err = cursor.All(ctxt, &masters); err != nil {
+		logger.Error("Gagal decode master data cursor ke objek slice (FindMaster)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.MasterData{}, apiErr
+	}
+
+	return masters, nil
+}
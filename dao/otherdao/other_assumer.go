@@ -16,12 +16,17 @@ type OtherDaoAssumer interface {
 type OtherSaver interface {
 	InsertOther(ctx context.Context, input dto.Other) (*string, rest_err.APIError)
 	EditOther(ctx context.Context, input dto.OtherEdit) (*dto.Other, rest_err.APIError)
-	DeleteOther(ctx context.Context, input dto.FilterIDBranchCategoryCreateGte) (*dto.Other, rest_err.APIError)
+	DeleteOther(ctx context.Context, input dto.FilterIDBranchCategoryCreateGte, user mjwt.CustomClaim) (*dto.Other, rest_err.APIError)
 	DisableOther(ctx context.Context, pcID primitive.ObjectID, user mjwt.CustomClaim, subCategory string, value bool) (*dto.Other, rest_err.APIError)
+	MoveOtherBranch(ctx context.Context, input dto.BranchMove) (*dto.Other, rest_err.APIError)
+	EditOtherTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Other, rest_err.APIError)
 	UploadImage(ctx context.Context, pcID primitive.ObjectID, imagePath string, filterBranch string) (*dto.Other, rest_err.APIError)
 }
 
 type OtherLoader interface {
 	GetOtherByID(ctx context.Context, pcID primitive.ObjectID, branchIfSpecific string) (*dto.Other, rest_err.APIError)
-	FindOther(ctx context.Context, filter dto.FilterOther) (dto.OtherResponseMinList, rest_err.APIError)
+	GetOtherByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Other, rest_err.APIError)
+	FindOther(ctx context.Context, filter dto.FilterOther, page dto.PageRequest) (dto.OtherResponseMinList, dto.PageInfo, rest_err.APIError)
+	IterateOther(ctx context.Context, filter dto.FilterOther, fn func(dto.Other) error) rest_err.APIError
+	FindOtherNear(ctx context.Context, filter dto.FilterGeoNear) ([]dto.Other, rest_err.APIError)
 }
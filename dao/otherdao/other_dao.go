@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -20,6 +24,7 @@ import (
 
 const (
 	connectTimeout     = 3
+	iterateTimeout     = 120
 	keyOtherCollection = "other"
 
 	keyOtherID          = "_id"
@@ -30,6 +35,10 @@ const (
 	keyOtherUpdatedByID = "updated_by_id"
 	keyOtherBranch      = "branch"
 	keyOtherDisable     = "disable"
+	keyOtherDeleted     = "deleted"
+	keyOtherDeletedAt   = "deleted_at"
+	keyOtherDeletedBy   = "deleted_by"
+	keyOtherDeletedByID = "deleted_by_id"
 	keyOtherDisVendor   = "dis_vendor"
 
 	keyOtherDetail      = "detail"
@@ -47,6 +56,9 @@ const (
 	keyOtherBrand           = "brand"
 	keyOtherType            = "type"
 	keyOtherNote            = "note"
+	keyOtherLifecycle       = "lifecycle"
+	keyOtherGeo             = "geo"
+	keyOtherCustomFields    = "custom_fields"
 )
 
 func NewOtherDao() OtherDaoAssumer {
@@ -77,6 +89,14 @@ func (c *otherDao) InsertOther(ctx context.Context, input dto.Other) (*string, r
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyOtherCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -100,6 +120,7 @@ func (c *otherDao) EditOther(ctx context.Context, input dto.OtherEdit) (*dto.Oth
 		keyOtherBranch:      input.FilterBranch,
 		keyOtherSubCategory: input.FilterSubCategory,
 		keyOtherUpdatedAt:   input.FilterTimestamp,
+		keyOtherDeleted:     bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -123,10 +144,17 @@ func (c *otherDao) EditOther(ctx context.Context, input dto.OtherEdit) (*dto.Oth
 			keyOtherBrand:     input.Brand,
 			keyOtherType:      input.Type,
 			keyOtherNote:      input.Note,
+			keyOtherLifecycle: input.Lifecycle,
+			keyOtherGeo:       input.Geo,
 			keyOtherDisVendor: input.DisVendor,
 		},
 	}
+	// custom_fields hanya ditimpa jika dikirim oleh client
+	if input.CustomFields != nil {
+		update["$set"].(bson.M)[keyOtherCustomFields] = input.CustomFields
+	}
 
+	before := auditdao.Snapshot(ctx, keyOtherCollection, input.ID)
 	var other dto.Other
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&other); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -138,10 +166,19 @@ func (c *otherDao) EditOther(ctx context.Context, input dto.OtherEdit) (*dto.Oth
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   before,
+		After:    other,
+	})
+
 	return &other, nil
 }
 
-func (c *otherDao) DeleteOther(ctx context.Context, input dto.FilterIDBranchCategoryCreateGte) (*dto.Other, rest_err.APIError) {
+func (c *otherDao) DeleteOther(ctx context.Context, input dto.FilterIDBranchCategoryCreateGte, user mjwt.CustomClaim) (*dto.Other, rest_err.APIError) {
 	coll := db.DB.Collection(keyOtherCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
@@ -151,10 +188,22 @@ func (c *otherDao) DeleteOther(ctx context.Context, input dto.FilterIDBranchCate
 		keyOtherBranch:      strings.ToUpper(input.FilterBranch),
 		keyOtherSubCategory: strings.ToUpper(input.FilterSubCategory),
 		keyOtherCreatedAt:   bson.M{"$gte": input.FilterCreateGTE},
+		keyOtherDeleted:     bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyOtherDeleted:     true,
+			keyOtherDeletedAt:   time.Now().Unix(),
+			keyOtherDeletedBy:   user.Name,
+			keyOtherDeletedByID: user.Identity,
+		},
 	}
 
+	// dokumen tidak dihapus permanen, hanya dipindahkan ke tempat sampah
+	// other yang dikembalikan adalah dokumen sebelum dihapus
 	var other dto.Other
-	err := coll.FindOneAndDelete(ctxt, filter).Decode(&other)
+	err := coll.FindOneAndUpdate(ctxt, filter, update).Decode(&other)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, rest_err.NewBadRequestError("Data tidak diupdate : validasi id branch category time_reach")
@@ -165,6 +214,14 @@ func (c *otherDao) DeleteOther(ctx context.Context, input dto.FilterIDBranchCate
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   other,
+	})
+
 	return &other, nil
 }
 
@@ -181,6 +238,7 @@ func (c *otherDao) DisableOther(ctx context.Context, otherID primitive.ObjectID,
 		keyOtherID:          otherID,
 		keyOtherBranch:      strings.ToUpper(user.Branch),
 		keyOtherSubCategory: strings.ToUpper(subCategory),
+		keyOtherDeleted:     bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -192,6 +250,7 @@ func (c *otherDao) DisableOther(ctx context.Context, otherID primitive.ObjectID,
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyOtherCollection, otherID)
 	var other dto.Other
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&other); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -203,6 +262,112 @@ func (c *otherDao) DisableOther(ctx context.Context, otherID primitive.ObjectID,
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   before,
+		After:    other,
+	})
+
+	return &other, nil
+}
+
+// MoveOtherBranch memindahkan dokumen ke cabang tujuan, hanya berhasil jika dokumen masih berada di cabang asal
+func (c *otherDao) MoveOtherBranch(ctx context.Context, input dto.BranchMove) (*dto.Other, rest_err.APIError) {
+	coll := db.DB.Collection(keyOtherCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyOtherID:      input.ID,
+		keyOtherBranch:  strings.ToUpper(input.FromBranch),
+		keyOtherDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyOtherBranch:      strings.ToUpper(input.ToBranch),
+			keyOtherUpdatedAt:   input.UpdatedAt,
+			keyOtherUpdatedBy:   input.UpdatedBy,
+			keyOtherUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyOtherCollection, input.ID)
+	var other dto.Other
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&other); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Data tidak dipindahkan : validasi id branch")
+		}
+
+		logger.Error("Gagal memindahkan branch data (MoveOtherBranch)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal memindahkan branch data", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionMove,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   before,
+		After:    other,
+	})
+
+	return &other, nil
+}
+
+// EditOtherTagImage mengganti tag dan image, digunakan saat penggabungan unit
+func (c *otherDao) EditOtherTagImage(ctx context.Context, input dto.UnitTagImageEdit) (*dto.Other, rest_err.APIError) {
+	coll := db.DB.Collection(keyOtherCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyOtherID:      input.ID,
+		keyOtherBranch:  strings.ToUpper(input.FilterBranch),
+		keyOtherDeleted: bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyOtherTag:         input.Tag,
+			keyOtherImage:       input.Image,
+			keyOtherUpdatedAt:   input.UpdatedAt,
+			keyOtherUpdatedBy:   input.UpdatedBy,
+			keyOtherUpdatedByID: input.UpdatedByID,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyOtherCollection, input.ID)
+	var other dto.Other
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&other); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Other tidak diupdate : validasi id branch")
+		}
+
+		logger.Error("Gagal mengubah tag dan image other (EditOtherTagImage)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah tag dan image other", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   before,
+		After:    other,
+	})
+
 	return &other, nil
 }
 
@@ -215,8 +380,9 @@ func (c *otherDao) UploadImage(ctx context.Context, pcID primitive.ObjectID, ima
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyOtherID:     pcID,
-		keyOtherBranch: strings.ToUpper(filterBranch),
+		keyOtherID:      pcID,
+		keyOtherBranch:  strings.ToUpper(filterBranch),
+		keyOtherDeleted: bson.M{"$ne": true},
 	}
 	update := bson.M{
 		"$set": bson.M{
@@ -224,6 +390,7 @@ func (c *otherDao) UploadImage(ctx context.Context, pcID primitive.ObjectID, ima
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyOtherCollection, pcID)
 	var other dto.Other
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&other); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -235,6 +402,15 @@ func (c *otherDao) UploadImage(ctx context.Context, pcID primitive.ObjectID, ima
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyOtherCollection,
+		EntityID: other.ID.Hex(),
+		Branch:   other.Branch,
+		Before:   before,
+		After:    other,
+	})
+
 	return &other, nil
 }
 
@@ -243,7 +419,10 @@ func (c *otherDao) GetOtherByID(ctx context.Context, pcID primitive.ObjectID, br
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	filter := bson.M{keyOtherID: pcID}
+	filter := bson.M{
+		keyOtherID:      pcID,
+		keyOtherDeleted: bson.M{"$ne": true},
+	}
 	if branchIfSpecific != "" {
 		filter[keyOtherBranch] = strings.ToUpper(branchIfSpecific)
 	}
@@ -263,11 +442,115 @@ func (c *otherDao) GetOtherByID(ctx context.Context, pcID primitive.ObjectID, br
 	return &other, nil
 }
 
-func (c *otherDao) FindOther(ctx context.Context, filterA dto.FilterOther) (dto.OtherResponseMinList, rest_err.APIError) {
+// GetOtherByInventoryNumber mendapatkan other berdasarkan nomor inventaris pada cabang tertentu
+func (c *otherDao) GetOtherByInventoryNumber(ctx context.Context, branch string, inventoryNumber string) (*dto.Other, rest_err.APIError) {
+	coll := db.DB.Collection(keyOtherCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyOtherBranch:          strings.ToUpper(branch),
+		keyOtherInventoryNumber: inventoryNumber,
+		keyOtherDeleted:         bson.M{"$ne": true},
+	}
+
+	var other dto.Other
+	if err := coll.FindOne(ctxt, filter).Decode(&other); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError(fmt.Sprintf("Other dengan nomor inventaris %s tidak ditemukan", inventoryNumber))
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan other dari database (GetOtherByInventoryNumber)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan other dari database", err)
+		return nil, apiErr
+	}
+
+	return &other, nil
+}
+
+func (c *otherDao) FindOther(ctx context.Context, filterA dto.FilterOther, page dto.PageRequest) (dto.OtherResponseMinList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyOtherCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
+	query, apiErr := paging.New(page, 500, paging.SortKey{Field: keyOtherLocation, Dir: -1}, paging.SortKey{Field: keyOtherDivision, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filter := otherFilter(filterA)
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah other dari database (FindOther)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Gagal mendapatkan daftar %s dari database (FindOther)", filterA.FilterSubCategory), err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.OtherResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	otherList := dto.OtherResponseMinList{}
+	if err = cursor.All(ctxt, &otherList); err != nil {
+		logger.Error("Gagal decode otherList cursor ke objek slice (FindOther)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return dto.OtherResponseMinList{}, dto.PageInfo{}, apiErr
+	}
+
+	return otherList, query.Info(&otherList, total), nil
+}
+
+// IterateOther membaca other satu per satu menggunakan cursor tanpa memuat semuanya ke memory,
+// filter sama dengan FindOther. digunakan untuk export, iterasi berhenti jika fn mengembalikan error
+func (c *otherDao) IterateOther(ctx context.Context, filterA dto.FilterOther, fn func(dto.Other) error) rest_err.APIError {
+	coll := db.DB.Collection(keyOtherCollection)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyOtherLocation, Value: -1}, {Key: keyOtherDivision, Value: -1}, {Key: keyOtherID, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, otherFilter(filterA), opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar other dari database (IterateOther)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+	defer cursor.Close(ctxt)
+
+	for cursor.Next(ctxt) {
+		var item dto.Other
+		if err := cursor.Decode(&item); err != nil {
+			logger.Error("Gagal decode other cursor ke objek (IterateOther)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return apiErr
+		}
+		if err := fn(item); err != nil {
+			apiErr := rest_err.NewInternalServerError("Gagal menulis data other", err)
+			return apiErr
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Gagal membaca other cursor (IterateOther)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// otherFilter membangun filter yang sama untuk FindOther dan iterasi export
+func otherFilter(filterA dto.FilterOther) bson.M {
 	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
 	filterA.FilterSubCategory = strings.ToUpper(filterA.FilterSubCategory)
 	filterA.FilterName = strings.ToUpper(filterA.FilterName)
@@ -277,6 +560,7 @@ func (c *otherDao) FindOther(ctx context.Context, filterA dto.FilterOther) (dto.
 	// filter
 	filter := bson.M{
 		keyOtherDisable: filterA.FilterDisable,
+		keyOtherDeleted: bson.M{"$ne": true},
 	}
 	// filter condition
 	if filterA.FilterBranch != "" {
@@ -309,23 +593,56 @@ func (c *otherDao) FindOther(ctx context.Context, filterA dto.FilterOther) (dto.
 		filter[keyOtherIP] = filterA.FilterIP
 	}
 
+	if filterA.FilterInventoryNumber != "" {
+		filter[keyOtherInventoryNumber] = primitive.Regex{Pattern: fmt.Sprintf("^%s$", regexp.QuoteMeta(filterA.FilterInventoryNumber)), Options: "i"}
+	}
+
+	for key, value := range filterA.FilterCustomFields {
+		filter[fmt.Sprintf("%s.%s", keyOtherCustomFields, key)] = value
+	}
+
+	return filter
+}
+
+// FindOtherNear mencari unit aktif dalam radius filter.MaxDistance meter, diurutkan dari yang terdekat (butuh index 2dsphere geo)
+func (c *otherDao) FindOtherNear(ctx context.Context, filterA dto.FilterGeoNear) ([]dto.Other, rest_err.APIError) {
+	coll := db.DB.Collection(keyOtherCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyOtherDisable: false,
+		keyOtherDeleted: bson.M{"$ne": true},
+		keyOtherGeo: bson.M{
+			"$nearSphere": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": bson.A{filterA.Lon, filterA.Lat},
+				},
+				"$maxDistance": filterA.MaxDistance,
+			},
+		},
+	}
+	if filterA.FilterBranch != "" {
+		filter[keyOtherBranch] = strings.ToUpper(filterA.FilterBranch)
+	}
+
 	opts := options.Find()
-	opts.SetSort(bson.D{{Key: keyOtherLocation, Value: -1}, {Key: keyOtherDivision, Value: -1}}) //nolint:govet
-	opts.SetLimit(500)
+	opts.SetLimit(filterA.Limit)
 
 	cursor, err := coll.Find(ctxt, filter, opts)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Gagal mendapatkan daftar %s dari database (FindOther)", filterA.FilterSubCategory), err)
+		logger.Error("Gagal mendapatkan other terdekat dari database (FindOtherNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.OtherResponseMinList{}, apiErr
+		return []dto.Other{}, apiErr
 	}
 
-	otherList := dto.OtherResponseMinList{}
-	if err = cursor.All(ctxt, &otherList); err != nil {
-		logger.Error("Gagal decode otherList cursor ke objek slice (FindOther)", err)
+	units := make([]dto.Other, 0)
+	if err = cursor.All(ctxt, &units); err != nil {
+		logger.Error("Gagal decode other cursor ke objek slice (FindOtherNear)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.OtherResponseMinList{}, apiErr
+		return []dto.Other{}, apiErr
 	}
 
-	return otherList, nil
+	return units, nil
 }
@@ -7,8 +7,11 @@ import (
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -42,6 +45,7 @@ const (
 	keyImages         = "images"
 
 	keyParticipantsID = "id" // id inner participant
+	keyEquipmentsID   = "equipments.id"
 )
 
 func NewPR() PRAssumer {
@@ -61,7 +65,9 @@ type PRAssumer interface {
 	DeleteImage(ctx context.Context, id primitive.ObjectID, imagePath string, filterBranch string) (*dto.PendingReportModel, rest_err.APIError)
 	GetPRByID(ctx context.Context, id primitive.ObjectID, branchIfSpecific string) (*dto.PendingReportModel, rest_err.APIError)
 	GetPRByNumber(ctx context.Context, number string, branchIfSpecific string) (*dto.PendingReportModel, rest_err.APIError)
-	FindDoc(ctx context.Context, inFilter dto.FilterFindPendingReport) ([]dto.PendingReportMin, rest_err.APIError)
+	FindDoc(ctx context.Context, inFilter dto.FilterFindPendingReport, page dto.PageRequest) ([]dto.PendingReportMin, dto.PageInfo, rest_err.APIError)
+	CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError)
+	ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError)
 }
 
 type prDao struct{}
@@ -82,6 +88,14 @@ func (pd *prDao) InsertPR(ctx context.Context, input dto.PendingReportModel) (*s
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -116,6 +130,7 @@ func (pd *prDao) EditPR(ctx context.Context, input dto.PendingReportEditModel) (
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -127,6 +142,15 @@ func (pd *prDao) EditPR(ctx context.Context, input dto.PendingReportEditModel) (
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -151,6 +175,7 @@ func (pd *prDao) ChangeCompleteStatus(ctx context.Context, id primitive.ObjectID
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, id)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -162,6 +187,15 @@ func (pd *prDao) ChangeCompleteStatus(ctx context.Context, id primitive.ObjectID
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -189,6 +223,7 @@ func (pd *prDao) AddApprover(ctx context.Context, input ParticipantParams) (*dto
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.ID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -200,6 +235,15 @@ func (pd *prDao) AddApprover(ctx context.Context, input ParticipantParams) (*dto
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -227,6 +271,7 @@ func (pd *prDao) AddParticipant(ctx context.Context, input ParticipantParams) (*
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.ID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -238,6 +283,15 @@ func (pd *prDao) AddParticipant(ctx context.Context, input ParticipantParams) (*
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -267,6 +321,7 @@ func (pd *prDao) RemoveApprover(ctx context.Context, input ParticipantParams) (*
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.ID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -278,6 +333,15 @@ func (pd *prDao) RemoveApprover(ctx context.Context, input ParticipantParams) (*
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -307,6 +371,7 @@ func (pd *prDao) RemoveParticipant(ctx context.Context, input ParticipantParams)
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.ID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -318,6 +383,15 @@ func (pd *prDao) RemoveParticipant(ctx context.Context, input ParticipantParams)
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -344,6 +418,7 @@ func (pd *prDao) EditParticipantApprover(ctx context.Context, input EditParticip
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.ID)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -355,6 +430,15 @@ func (pd *prDao) EditParticipantApprover(ctx context.Context, input EditParticip
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -378,6 +462,7 @@ func (pd *prDao) UploadImage(ctx context.Context, id primitive.ObjectID, imagePa
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, id)
 	var res dto.PendingReportModel
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&res); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -389,6 +474,15 @@ func (pd *prDao) UploadImage(ctx context.Context, id primitive.ObjectID, imagePa
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: res.ID.Hex(),
+		Branch:   res.Branch,
+		Before:   before,
+		After:    res,
+	})
+
 	return &res, nil
 }
 
@@ -430,6 +524,8 @@ func (pd *prDao) DeleteImage(ctx context.Context, id primitive.ObjectID, imagePa
 		finalImages = []string{}
 	}
 
+	before := pr
+
 	update := bson.M{
 		"$set": bson.M{
 			keyImages: finalImages,
@@ -446,6 +542,15 @@ func (pd *prDao) DeleteImage(ctx context.Context, id primitive.ObjectID, imagePa
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: pr.ID.Hex(),
+		Branch:   pr.Branch,
+		Before:   before,
+		After:    pr,
+	})
+
 	return &pr, nil
 }
 
@@ -499,11 +604,16 @@ func (pd *prDao) GetPRByNumber(ctx context.Context, number string, branchIfSpeci
 	return &res, nil
 }
 
-func (pd *prDao) FindDoc(ctx context.Context, inFilter dto.FilterFindPendingReport) ([]dto.PendingReportMin, rest_err.APIError) {
+func (pd *prDao) FindDoc(ctx context.Context, inFilter dto.FilterFindPendingReport, page dto.PageRequest) ([]dto.PendingReportMin, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyID, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
 	inFilter.FilterBranch = strings.ToUpper(inFilter.FilterBranch)
 
 	// filter
@@ -543,27 +653,85 @@ func (pd *prDao) FindDoc(ctx context.Context, inFilter dto.FilterFindPendingRepo
 		}
 	}
 
-	if inFilter.Limit == 0 {
-		inFilter.Limit = 100
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah document dari database (FindDoc)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
 	}
 
-	opts := options.Find()
-	opts.SetSort(bson.D{{Key: keyID, Value: -1}})
-	opts.SetLimit(inFilter.Limit)
-
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
 	if err != nil {
 		logger.Error("Gagal mendapatkan daftar document dari database (FindDoc)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.PendingReportMin{}, apiErr
+		return []dto.PendingReportMin{}, dto.PageInfo{}, apiErr
 	}
 
 	docList := make([]dto.PendingReportMin, 0)
 	if err = cursor.All(ctxt, &docList); err != nil {
 		logger.Error("Gagal decode docList cursor ke objek slice (FindDoc)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.PendingReportMin{}, apiErr
+		return []dto.PendingReportMin{}, dto.PageInfo{}, apiErr
 	}
 
-	return docList, nil
+	return docList, query.Info(&docList, total), nil
+}
+
+// CountUnitRef menghitung dokumen pending report pada branch yang memuat unit sebagai equipment
+func (pd *prDao) CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyBranch:       strings.ToUpper(branch),
+		keyEquipmentsID: unitID,
+	}
+
+	count, err := coll.CountDocuments(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghitung referensi unit pada pending report (CountUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghitung referensi unit pada pending report", err)
+		return 0, apiErr
+	}
+
+	return count, nil
+}
+
+// ReplaceUnitRef mengganti id equipment unit lama menjadi unit baru, deskripsi equipment tidak diubah
+func (pd *prDao) ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Update()
+	opts.SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"equip.id": input.FromID}},
+	})
+
+	filter := bson.M{
+		keyBranch:       strings.ToUpper(input.Branch),
+		keyEquipmentsID: input.FromID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			keyEquipments + ".$[equip].id": input.ToID,
+		},
+	}
+
+	befores := auditdao.SnapshotMany(ctx, keyCollection, filter)
+	result, err := coll.UpdateMany(ctxt, filter, update, opts)
+	if err != nil {
+		logger.Error("Gagal mengganti referensi unit pada pending report (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada pending report", err)
+		return 0, apiErr
+	}
+
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
+	return result.ModifiedCount, nil
 }
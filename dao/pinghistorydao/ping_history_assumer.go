@@ -0,0 +1,20 @@
+package pinghistorydao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+type PingHistoryDaoAssumer interface {
+	PingHistorySaver
+	PingHistoryLoader
+}
+
+type PingHistorySaver interface {
+	InsertSamples(ctx context.Context, samples []dto.PingSample) rest_err.APIError
+}
+
+type PingHistoryLoader interface {
+	FindSamples(ctx context.Context, filter dto.PingSampleFilter) ([]dto.PingSample, rest_err.APIError)
+}
@@ -0,0 +1,99 @@
+package pinghistorydao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout = 3
+	// queryTimeout lebih panjang karena rentang waktu laporan bisa berisi puluhan ribu sample
+	queryTimeout    = 30
+	keyPingHistColl = "pingHistory"
+
+	keyPingTime         = "time"
+	keyPingMetaUnitID   = "meta.unit_id"
+	keyPingMetaBranch   = "meta.branch"
+	keyPingMetaCategory = "meta.category"
+)
+
+func NewPingHistoryDao() PingHistoryDaoAssumer {
+	return &pingHistoryDao{}
+}
+
+type pingHistoryDao struct{}
+
+func (p *pingHistoryDao) InsertSamples(ctx context.Context, samples []dto.PingSample) rest_err.APIError {
+	coll := db.DB.Collection(keyPingHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(samples))
+	for i := range samples {
+		samples[i].Meta.Branch = strings.ToUpper(samples[i].Meta.Branch)
+		samples[i].Meta.Category = strings.ToUpper(samples[i].Meta.Category)
+		docs[i] = samples[i]
+	}
+
+	if _, err := coll.InsertMany(ctxt, docs); err != nil {
+		logger.Error("Gagal menyimpan ping sample ke database (InsertSamples)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan ping sample ke database", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// FindSamples mengembalikan sample diurutkan berdasarkan unit lalu waktu (ascending)
+func (p *pingHistoryDao) FindSamples(ctx context.Context, filterA dto.PingSampleFilter) ([]dto.PingSample, rest_err.APIError) {
+	coll := db.DB.Collection(keyPingHistColl)
+	ctxt, cancel := context.WithTimeout(ctx, queryTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyPingTime: bson.M{
+			"$gte": time.Unix(filterA.Start, 0),
+			"$lte": time.Unix(filterA.End, 0),
+		},
+	}
+	if filterA.UnitID != "" {
+		filter[keyPingMetaUnitID] = filterA.UnitID
+	}
+	if filterA.Branch != "" {
+		filter[keyPingMetaBranch] = strings.ToUpper(filterA.Branch)
+	}
+	if filterA.Category != "" {
+		filter[keyPingMetaCategory] = strings.ToUpper(filterA.Category)
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyPingMetaUnitID, Value: 1}, {Key: keyPingTime, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, filter, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan ping sample dari database (FindSamples)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.PingSample{}, apiErr
+	}
+
+	samples := make([]dto.PingSample, 0)
+	if err = cursor.All(ctxt, &samples); err != nil {
+		logger.Error("Gagal decode ping sample cursor ke objek slice (FindSamples)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.PingSample{}, apiErr
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,22 @@
+package sladao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SLADaoAssumer interface {
+	SLASaver
+	SLALoader
+}
+
+type SLASaver interface {
+	UpsertPolicy(ctx context.Context, input dto.SLAPolicy) (*dto.SLAPolicy, rest_err.APIError)
+	DeletePolicy(ctx context.Context, policyID primitive.ObjectID) rest_err.APIError
+}
+
+type SLALoader interface {
+	FindPolicy(ctx context.Context) ([]dto.SLAPolicy, rest_err.APIError)
+}
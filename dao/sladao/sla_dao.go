@@ -0,0 +1,140 @@
+package sladao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout   = 3
+	keySLAPolicyColl = "slaPolicy"
+
+	keySLAID          = "_id"
+	keySLACategory    = "category"
+	keySLAPriority    = "priority"
+	keySLAUpdatedAt   = "updated_at"
+	keySLAUpdatedBy   = "updated_by"
+	keySLAUpdatedByID = "updated_by_id"
+	keySLAResponseMin = "response_minutes"
+	keySLAResolveMin  = "resolve_minutes"
+)
+
+func NewSLADao() SLADaoAssumer {
+	return &slaDao{}
+}
+
+type slaDao struct{}
+
+// UpsertPolicy menyimpan target SLA, policy bersifat unik per category dan priority
+func (s *slaDao) UpsertPolicy(ctx context.Context, input dto.SLAPolicy) (*dto.SLAPolicy, rest_err.APIError) {
+	coll := db.DB.Collection(keySLAPolicyColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+	opts.SetUpsert(true)
+
+	filter := bson.M{
+		keySLACategory: strings.ToUpper(input.Category),
+		keySLAPriority: strings.ToUpper(input.Priority),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keySLAUpdatedAt:   input.UpdatedAt,
+			keySLAUpdatedBy:   input.UpdatedBy,
+			keySLAUpdatedByID: input.UpdatedByID,
+			keySLAResponseMin: input.ResponseMinutes,
+			keySLAResolveMin:  input.ResolveMinutes,
+		},
+	}
+
+	// policy dicari berdasarkan category dan priority, snapshot tidak bisa memakai id
+	var before bson.M
+	_ = coll.FindOne(ctxt, filter).Decode(&before)
+
+	var policy dto.SLAPolicy
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&policy); err != nil {
+		logger.Error("Gagal menyimpan sla policy ke database (UpsertPolicy)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan sla policy ke database", err)
+		return nil, apiErr
+	}
+
+	action := audit.ActionEdit
+	if before == nil {
+		action = audit.ActionInsert
+	}
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   action,
+		Entity:   keySLAPolicyColl,
+		EntityID: policy.ID.Hex(),
+		Before:   before,
+		After:    policy,
+	})
+
+	return &policy, nil
+}
+
+func (s *slaDao) DeletePolicy(ctx context.Context, policyID primitive.ObjectID) rest_err.APIError {
+	coll := db.DB.Collection(keySLAPolicyColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	before := auditdao.Snapshot(ctx, keySLAPolicyColl, policyID)
+	result, err := coll.DeleteOne(ctxt, bson.M{keySLAID: policyID})
+	if err != nil {
+		logger.Error("Gagal menghapus sla policy dari database (DeletePolicy)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus sla policy dari database", err)
+		return apiErr
+	}
+
+	if result.DeletedCount == 0 {
+		return rest_err.NewBadRequestError("SLA policy gagal dihapus, dokumen tidak ditemukan")
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keySLAPolicyColl,
+		EntityID: policyID.Hex(),
+		Before:   before,
+	})
+
+	return nil
+}
+
+func (s *slaDao) FindPolicy(ctx context.Context) ([]dto.SLAPolicy, rest_err.APIError) {
+	coll := db.DB.Collection(keySLAPolicyColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keySLACategory, Value: 1}, {Key: keySLAPriority, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan sla policy dari database (FindPolicy)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.SLAPolicy{}, apiErr
+	}
+
+	policies := make([]dto.SLAPolicy, 0)
+	if err = cursor.All(ctxt, &policies); err != nil {
+		logger.Error("Gagal decode sla policy cursor ke objek slice (FindPolicy)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.SLAPolicy{}, apiErr
+	}
+
+	return policies, nil
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -20,6 +23,7 @@ import (
 
 const (
 	connectTimeout   = 3
+	iterateTimeout   = 120
 	keyStoCollection = "stock"
 
 	keyStoID          = "_id"
@@ -30,6 +34,10 @@ const (
 	keyStoUpdatedByID = "updated_by_id"
 	keyStoBranch      = "branch"
 	keyStoDisable     = "disable"
+	keyStoDeleted     = "deleted"
+	keyStoDeletedAt   = "deleted_at"
+	keyStoDeletedBy   = "deleted_by"
+	keyStoDeletedByID = "deleted_by_id"
 	keyStoCategory    = "stock_category"
 	keyStoUnit        = "unit"
 	keyStoQty         = "qty"
@@ -75,6 +83,14 @@ func (s *stockDao) InsertStock(ctx context.Context, input dto.Stock) (*string, r
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyStoCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -95,6 +111,7 @@ func (s *stockDao) EditStock(ctx context.Context, input dto.StockEdit) (*dto.Sto
 		keyStoID:        input.ID,
 		keyStoBranch:    input.FilterBranch,
 		keyStoUpdatedAt: input.FilterTimestamp,
+		keyStoDeleted:   bson.M{"$ne": true},
 	}
 
 	update := bson.D{
@@ -111,6 +128,7 @@ func (s *stockDao) EditStock(ctx context.Context, input dto.StockEdit) (*dto.Sto
 		}},
 	}
 
+	before := auditdao.Snapshot(ctx, keyStoCollection, input.ID)
 	var stock dto.Stock
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&stock); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -122,10 +140,19 @@ func (s *stockDao) EditStock(ctx context.Context, input dto.StockEdit) (*dto.Sto
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyStoCollection,
+		EntityID: stock.ID.Hex(),
+		Branch:   stock.Branch,
+		Before:   before,
+		After:    stock,
+	})
+
 	return &stock, nil
 }
 
-func (s *stockDao) DeleteStock(ctx context.Context, input dto.FilterIDBranchCreateGte) (*dto.Stock, rest_err.APIError) {
+func (s *stockDao) DeleteStock(ctx context.Context, input dto.FilterIDBranchCreateGte, user mjwt.CustomClaim) (*dto.Stock, rest_err.APIError) {
 	coll := db.DB.Collection(keyStoCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
@@ -134,10 +161,22 @@ func (s *stockDao) DeleteStock(ctx context.Context, input dto.FilterIDBranchCrea
 		keyStoID:        input.FilterID,
 		keyStoBranch:    input.FilterBranch,
 		keyStoCreatedAt: bson.M{"$gte": input.FilterCreateGTE},
+		keyStoDeleted:   bson.M{"$ne": true},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyStoDeleted:     true,
+			keyStoDeletedAt:   time.Now().Unix(),
+			keyStoDeletedBy:   user.Name,
+			keyStoDeletedByID: user.Identity,
+		},
 	}
 
+	// dokumen tidak dihapus permanen, hanya dipindahkan ke tempat sampah
+	// stock yang dikembalikan adalah dokumen sebelum dihapus
 	var stock dto.Stock
-	err := coll.FindOneAndDelete(ctxt, filter).Decode(&stock)
+	err := coll.FindOneAndUpdate(ctxt, filter, update).Decode(&stock)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, rest_err.NewBadRequestError("Stock tidak diupdate : validasi id branch time_reach")
@@ -148,6 +187,14 @@ func (s *stockDao) DeleteStock(ctx context.Context, input dto.FilterIDBranchCrea
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyStoCollection,
+		EntityID: stock.ID.Hex(),
+		Branch:   stock.Branch,
+		Before:   stock,
+	})
+
 	return &stock, nil
 }
 
@@ -161,8 +208,9 @@ func (s *stockDao) DisableStock(ctx context.Context, stockID primitive.ObjectID,
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyStoID:     stockID,
-		keyStoBranch: user.Branch,
+		keyStoID:      stockID,
+		keyStoBranch:  user.Branch,
+		keyStoDeleted: bson.M{"$ne": true},
 	}
 
 	update := bson.M{
@@ -174,6 +222,7 @@ func (s *stockDao) DisableStock(ctx context.Context, stockID primitive.ObjectID,
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyStoCollection, stockID)
 	var stock dto.Stock
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&stock); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -185,6 +234,15 @@ func (s *stockDao) DisableStock(ctx context.Context, stockID primitive.ObjectID,
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDisable,
+		Entity:   keyStoCollection,
+		EntityID: stock.ID.Hex(),
+		Branch:   stock.Branch,
+		Before:   before,
+		After:    stock,
+	})
+
 	return &stock, nil
 }
 
@@ -197,8 +255,9 @@ func (s *stockDao) UploadImage(ctx context.Context, stockID primitive.ObjectID,
 	opts.SetReturnDocument(1)
 
 	filter := bson.M{
-		keyStoID:     stockID,
-		keyStoBranch: strings.ToUpper(filterBranch),
+		keyStoID:      stockID,
+		keyStoBranch:  strings.ToUpper(filterBranch),
+		keyStoDeleted: bson.M{"$ne": true},
 	}
 	update := bson.M{
 		"$set": bson.M{
@@ -206,6 +265,7 @@ func (s *stockDao) UploadImage(ctx context.Context, stockID primitive.ObjectID,
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyStoCollection, stockID)
 	var stock dto.Stock
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&stock); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -217,6 +277,15 @@ func (s *stockDao) UploadImage(ctx context.Context, stockID primitive.ObjectID,
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyStoCollection,
+		EntityID: stock.ID.Hex(),
+		Branch:   stock.Branch,
+		Before:   before,
+		After:    stock,
+	})
+
 	return &stock, nil
 }
 
@@ -225,7 +294,10 @@ func (s *stockDao) GetStockByID(ctx context.Context, stockID primitive.ObjectID,
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	filter := bson.M{keyStoID: stockID}
+	filter := bson.M{
+		keyStoID:      stockID,
+		keyStoDeleted: bson.M{"$ne": true},
+	}
 	if branchIfSpecific != "" {
 		filter[keyStoBranch] = strings.ToUpper(branchIfSpecific)
 	}
@@ -245,51 +317,44 @@ func (s *stockDao) GetStockByID(ctx context.Context, stockID primitive.ObjectID,
 	return &stock, nil
 }
 
-func (s *stockDao) FindStock(ctx context.Context, filterA dto.FilterBranchNameCatDisable) (dto.StockResponseMinList, rest_err.APIError) {
+func (s *stockDao) FindStock(ctx context.Context, filterA dto.FilterBranchNameCatDisable, page dto.PageRequest) (dto.StockResponseMinList, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyStoCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
-	filterA.FilterName = strings.ToUpper(filterA.FilterName)
-
-	// filter
-	filter := bson.M{
-		keyStoDisable: filterA.FilterDisable,
+	query, apiErr := paging.New(page, 500, paging.SortKey{Field: keyStoCategory, Dir: -1}, paging.SortKey{Field: keyStoName, Dir: 1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
-	// filter condition
-	if filterA.FilterBranch != "" {
-		filter[keyStoBranch] = filterA.FilterBranch
-	}
-	if filterA.FilterCategory != "" {
-		filter[keyStoCategory] = filterA.FilterCategory
-	}
-	if filterA.FilterName != "" {
-		filter[keyStoName] = bson.M{
-			"$regex": fmt.Sprintf(".*%s", filterA.FilterName),
+	filter := stockFilter(filterA)
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah stock dari database (FindStock)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
 		}
+		total = count
 	}
 
-	opts := options.Find()
-	opts.SetSort(bson.D{{Key: keyStoCategory, Value: -1}, {Key: keyStoName, Value: 1}})
-	opts.SetLimit(500)
-
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
 	if err != nil {
 		logger.Error("Gagal mendapatkan daftar stock dari database (FindStock)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.StockResponseMinList{}, apiErr
+		return dto.StockResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
 	stockList := dto.StockResponseMinList{}
 	if err = cursor.All(ctxt, &stockList); err != nil {
 		logger.Error("Gagal decode stockList cursor ke objek slice (FindStock)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return dto.StockResponseMinList{}, apiErr
+		return dto.StockResponseMinList{}, dto.PageInfo{}, apiErr
 	}
 
-	return stockList, nil
+	return stockList, query.Info(&stockList, total), nil
 }
 
 func (s *stockDao) FindStockNeedRestock(ctx context.Context, filterA dto.FilterBranchCatDisable) ([]dto.Stock, rest_err.APIError) {
@@ -302,6 +367,7 @@ func (s *stockDao) FindStockNeedRestock(ctx context.Context, filterA dto.FilterB
 	// filter
 	filter := bson.M{
 		keyStoDisable: filterA.FilterDisable,
+		keyStoDeleted: bson.M{"$ne": true},
 	}
 
 	// filter condition
@@ -406,6 +472,7 @@ func (s *stockDao) ChangeQtyStock(ctx context.Context, filterA dto.FilterIDBranc
 		}
 	}
 
+	before := auditdao.Snapshot(ctx, keyStoCollection, filterA.FilterID)
 	var stock dto.Stock
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&stock); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -417,5 +484,82 @@ func (s *stockDao) ChangeQtyStock(ctx context.Context, filterA dto.FilterIDBranc
 		return nil, apiErr
 	}
 
+	// riwayat increment dan decrement sudah tersimpan di dokumen, audit cukup mencatat perubahan qty
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyStoCollection,
+		EntityID: stock.ID.Hex(),
+		Branch:   stock.Branch,
+		Before:   bson.M{keyStoQty: before[keyStoQty]},
+		After:    bson.M{keyStoQty: stock.Qty},
+	})
+
 	return &stock, nil
 }
+
+// IterateStock membaca stock satu per satu menggunakan cursor tanpa memuat semuanya ke memory,
+// filter sama dengan FindStock. digunakan untuk export, iterasi berhenti jika fn mengembalikan error
+func (s *stockDao) IterateStock(ctx context.Context, filterA dto.FilterBranchNameCatDisable, fn func(dto.Stock) error) rest_err.APIError {
+	coll := db.DB.Collection(keyStoCollection)
+	ctxt, cancel := context.WithTimeout(ctx, iterateTimeout*time.Second)
+	defer cancel()
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: keyStoCategory, Value: -1}, {Key: keyStoName, Value: 1}, {Key: keyStoID, Value: 1}})
+
+	cursor, err := coll.Find(ctxt, stockFilter(filterA), opts)
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar stock dari database (IterateStock)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+	defer cursor.Close(ctxt)
+
+	for cursor.Next(ctxt) {
+		var item dto.Stock
+		if err := cursor.Decode(&item); err != nil {
+			logger.Error("Gagal decode stock cursor ke objek (IterateStock)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return apiErr
+		}
+		if err := fn(item); err != nil {
+			apiErr := rest_err.NewInternalServerError("Gagal menulis data stock", err)
+			return apiErr
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Gagal membaca stock cursor (IterateStock)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// stockFilter membangun filter yang sama untuk FindStock dan iterasi export
+func stockFilter(filterA dto.FilterBranchNameCatDisable) bson.M {
+	filterA.FilterBranch = strings.ToUpper(filterA.FilterBranch)
+	filterA.FilterName = strings.ToUpper(filterA.FilterName)
+
+	// filter
+	filter := bson.M{
+		keyStoDisable: filterA.FilterDisable,
+		keyStoDeleted: bson.M{"$ne": true},
+	}
+
+	// filter condition
+	if filterA.FilterBranch != "" {
+		filter[keyStoBranch] = filterA.FilterBranch
+	}
+	if filterA.FilterCategory != "" {
+		filter[keyStoCategory] = filterA.FilterCategory
+	}
+	if filterA.FilterName != "" {
+		filter[keyStoName] = bson.M{
+			"$regex": fmt.Sprintf(".*%s", filterA.FilterName),
+		}
+	}
+
+	return filter
+}
@@ -0,0 +1,12 @@
+package transactiondao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+)
+
+type TransactionDaoAssumer interface {
+	// WithTransaction menjalankan fn sebagai satu unit of work. ctx yang diterima fn
+	// wajib diteruskan ke setiap dao agar semua penulisan ikut dalam transaction yang sama
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) rest_err.APIError) rest_err.APIError
+}
@@ -0,0 +1,61 @@
+package transactiondao
+
+import (
+	"context"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewTransactionDao() TransactionDaoAssumer {
+	return &transactionDao{}
+}
+
+type transactionDao struct {
+}
+
+// WithTransaction menjalankan fn didalam mongo session transaction, jika fn mengembalikan error
+// seluruh penulisan di-rollback. Pada mongodb standalone fn tidak dijalankan sama sekali agar tidak terjadi
+// penulisan sebagian. Transaction yang sudah berjalan pada ctx akan digunakan kembali (nested)
+func (t *transactionDao) WithTransaction(ctx context.Context, fn func(ctx context.Context) rest_err.APIError) rest_err.APIError {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	if !db.TransactionSupported {
+		logger.Error("Transaction tidak didukung mongodb standalone (WithTransaction)", nil)
+		return rest_err.NewInternalServerError("Database tidak mendukung transaction, gunakan replica set", nil)
+	}
+
+	session, err := db.DB.Client().StartSession()
+	if err != nil {
+		logger.Error("Gagal membuat session database (WithTransaction)", err)
+		return rest_err.NewInternalServerError("Gagal membuat session database", err)
+	}
+	defer session.EndSession(ctx)
+
+	var bufCtx context.Context
+	var apiErr rest_err.APIError
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// callback bisa diulang oleh driver, penampung audit dibuat ulang setiap percobaan
+		bufCtx = auditdao.WithBuffer(sessCtx)
+		apiErr = fn(bufCtx)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return nil, nil
+	})
+	if apiErr != nil {
+		return apiErr
+	}
+	if err != nil {
+		logger.Error("Gagal menjalankan transaction (WithTransaction)", err)
+		return rest_err.NewInternalServerError("Gagal menjalankan transaction", err)
+	}
+
+	auditdao.Flush(bufCtx)
+	return nil
+}
@@ -0,0 +1,23 @@
+package transferdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TransferDaoAssumer interface {
+	TransferSaver
+	TransferLoader
+}
+
+type TransferSaver interface {
+	InsertTransfer(ctx context.Context, input dto.Transfer) (*string, rest_err.APIError)
+	DecideTransfer(ctx context.Context, input dto.TransferDecide) (*dto.Transfer, rest_err.APIError)
+}
+
+type TransferLoader interface {
+	GetTransferByID(ctx context.Context, transferID primitive.ObjectID, branchIfSpecific string) (*dto.Transfer, rest_err.APIError)
+	FindTransfer(ctx context.Context, filter dto.FilterTransfer, page dto.PageRequest) ([]dto.Transfer, dto.PageInfo, rest_err.APIError)
+}
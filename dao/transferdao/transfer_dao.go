@@ -0,0 +1,218 @@
+package transferdao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/transferstate"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout       = 3
+	keyTransferColl      = "transfer"
+	defaultLimitTransfer = 100
+
+	keyTransferID           = "_id"
+	keyTransferCreatedAt    = "created_at"
+	keyTransferUpdatedAt    = "updated_at"
+	keyTransferUpdatedBy    = "updated_by"
+	keyTransferUpdatedByID  = "updated_by_id"
+	keyTransferFromBranch   = "from_branch"
+	keyTransferToBranch     = "to_branch"
+	keyTransferUnitID       = "units.id"
+	keyTransferState        = "state"
+	keyTransferDecidedAt    = "decided_at"
+	keyTransferDecidedBy    = "decided_by"
+	keyTransferDecidedByID  = "decided_by_id"
+	keyTransferDecisionNote = "decision_note"
+)
+
+func NewTransferDao() TransferDaoAssumer {
+	return &transferDao{}
+}
+
+type transferDao struct{}
+
+func (t *transferDao) InsertTransfer(ctx context.Context, input dto.Transfer) (*string, rest_err.APIError) {
+	coll := db.DB.Collection(keyTransferColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	input.FromBranch = strings.ToUpper(input.FromBranch)
+	input.ToBranch = strings.ToUpper(input.ToBranch)
+	input.State = transferstate.Pending
+	if input.Units == nil {
+		input.Units = []dto.TransferUnit{}
+	}
+
+	result, err := coll.InsertOne(ctxt, input)
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("Gagal menyimpan transfer ke database", err)
+		logger.Error("Gagal menyimpan transfer ke database (InsertTransfer)", err)
+		return nil, apiErr
+	}
+
+	insertID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyTransferColl,
+		EntityID: insertID,
+		Branch:   input.FromBranch,
+		After:    input,
+	})
+
+	return &insertID, nil
+}
+
+// DecideTransfer hanya mengubah transfer yang masih PENDING sehingga keputusan ganda akan gagal
+func (t *transferDao) DecideTransfer(ctx context.Context, input dto.TransferDecide) (*dto.Transfer, rest_err.APIError) {
+	coll := db.DB.Collection(keyTransferColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(1)
+
+	filter := bson.M{
+		keyTransferID:    input.FilterID,
+		keyTransferState: transferstate.Pending,
+	}
+	branch := strings.ToUpper(input.FilterToBranch)
+	if input.FilterFromBranch != "" {
+		branch = strings.ToUpper(input.FilterFromBranch)
+		filter[keyTransferFromBranch] = branch
+	} else {
+		filter[keyTransferToBranch] = branch
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			keyTransferState:        input.State,
+			keyTransferUpdatedAt:    input.DecidedAt,
+			keyTransferUpdatedBy:    input.DecidedBy,
+			keyTransferUpdatedByID:  input.DecidedByID,
+			keyTransferDecidedAt:    input.DecidedAt,
+			keyTransferDecidedBy:    input.DecidedBy,
+			keyTransferDecidedByID:  input.DecidedByID,
+			keyTransferDecisionNote: input.DecisionNote,
+		},
+	}
+
+	before := auditdao.Snapshot(ctx, keyTransferColl, input.FilterID)
+	var transfer dto.Transfer
+	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&transfer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError("Transfer tidak diupdate : validasi id branch atau transfer sudah diputuskan")
+		}
+
+		logger.Error("Gagal mengubah state transfer (DecideTransfer)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengubah state transfer", err)
+		return nil, apiErr
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyTransferColl,
+		EntityID: transfer.ID.Hex(),
+		Branch:   branch,
+		Before:   before,
+		After:    transfer,
+	})
+
+	return &transfer, nil
+}
+
+// GetTransferByID branchIfSpecific mencocokkan cabang asal maupun tujuan
+func (t *transferDao) GetTransferByID(ctx context.Context, transferID primitive.ObjectID, branchIfSpecific string) (*dto.Transfer, rest_err.APIError) {
+	coll := db.DB.Collection(keyTransferColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{keyTransferID: transferID}
+	if branchIfSpecific != "" {
+		filter["$or"] = bson.A{
+			bson.M{keyTransferFromBranch: strings.ToUpper(branchIfSpecific)},
+			bson.M{keyTransferToBranch: strings.ToUpper(branchIfSpecific)},
+		}
+	}
+
+	var transfer dto.Transfer
+	if err := coll.FindOne(ctxt, filter).Decode(&transfer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			apiErr := rest_err.NewNotFoundError("Transfer dengan ID tersebut tidak ditemukan")
+			return nil, apiErr
+		}
+
+		logger.Error("gagal mendapatkan transfer dari database (GetTransferByID)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mendapatkan transfer dari database", err)
+		return nil, apiErr
+	}
+
+	return &transfer, nil
+}
+
+func (t *transferDao) FindTransfer(ctx context.Context, filterA dto.FilterTransfer, page dto.PageRequest) ([]dto.Transfer, dto.PageInfo, rest_err.APIError) {
+	coll := db.DB.Collection(keyTransferColl)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	query, apiErr := paging.New(page, defaultLimitTransfer, paging.SortKey{Field: keyTransferCreatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filter := bson.M{}
+	if filterA.FilterBranch != "" {
+		filter["$or"] = bson.A{
+			bson.M{keyTransferFromBranch: strings.ToUpper(filterA.FilterBranch)},
+			bson.M{keyTransferToBranch: strings.ToUpper(filterA.FilterBranch)},
+		}
+	}
+	if filterA.FilterState != "" {
+		filter[keyTransferState] = strings.ToUpper(filterA.FilterState)
+	}
+	if len(filterA.FilterUnitIDs) != 0 {
+		filter[keyTransferUnitID] = bson.M{"$in": filterA.FilterUnitIDs}
+	}
+
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah transfer dari database (FindTransfer)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	cursor, err := coll.Find(ctxt, query.Filter(filter), query.Options())
+	if err != nil {
+		logger.Error("Gagal mendapatkan transfer dari database (FindTransfer)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Transfer{}, dto.PageInfo{}, apiErr
+	}
+
+	transfers := make([]dto.Transfer, 0)
+	if err = cursor.All(ctxt, &transfers); err != nil {
+		logger.Error("Gagal decode transfer cursor ke objek slice (FindTransfer)", err)
+		apiErr := rest_err.NewInternalServerError("Database error", err)
+		return []dto.Transfer{}, dto.PageInfo{}, apiErr
+	}
+
+	return transfers, query.Info(&transfers, total), nil
+}
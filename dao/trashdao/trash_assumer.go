@@ -0,0 +1,21 @@
+package trashdao
+
+import (
+	"context"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+type TrashDaoAssumer interface {
+	TrashSaver
+	TrashLoader
+}
+
+type TrashSaver interface {
+	RestoreTrash(ctx context.Context, input dto.TrashRestore) (*dto.TrashItem, rest_err.APIError)
+	PurgeTrash(ctx context.Context, input dto.TrashPurge) (int64, rest_err.APIError)
+}
+
+type TrashLoader interface {
+	FindTrash(ctx context.Context, filter dto.FilterTrash, page dto.PageRequest) ([]dto.TrashItem, dto.PageInfo, rest_err.APIError)
+}
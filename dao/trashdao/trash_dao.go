@@ -0,0 +1,299 @@
+package trashdao
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/db"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	connectTimeout = 3
+
+	keyCctvColl    = "cctv"
+	keyPcColl      = "computer"
+	keyOtherColl   = "other"
+	keyStockColl   = "stock"
+	keyGenUnitColl = "genUnit"
+
+	keyID          = "_id"
+	keyName        = "name"
+	keyBranch      = "branch"
+	keySubCategory = "sub_category"
+	keyDeleted     = "deleted"
+	keyDeletedAt   = "deleted_at"
+	keyDeletedBy   = "deleted_by"
+	keyDeletedByID = "deleted_by_id"
+
+	defaultLimit = 100
+)
+
+// entityWithUnit daftar collection yang mendukung soft delete,
+// bernilai true jika dokumen memiliki pasangan di gen_unit dengan id yang sama
+var entityWithUnit = map[string]bool{
+	keyCctvColl:  true,
+	keyPcColl:    true,
+	keyOtherColl: true,
+	keyStockColl: false,
+}
+
+func NewTrashDao() TrashDaoAssumer {
+	return &trashDao{}
+}
+
+type trashDao struct {
+}
+
+// entityList mengembalikan semua collection jika entity kosong
+func entityList(entity string) ([]string, rest_err.APIError) {
+	if entity == "" {
+		return []string{keyCctvColl, keyPcColl, keyOtherColl, keyStockColl}, nil
+	}
+	entity = strings.ToLower(entity)
+	if _, ok := entityWithUnit[entity]; !ok {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("Entity %s tidak memiliki tempat sampah", entity))
+	}
+	return []string{entity}, nil
+}
+
+// FindTrash menggabungkan dokumen terhapus dari beberapa collection. cursor yang sama diterapkan pada setiap
+// collection lalu hasilnya diurutkan ulang dengan kunci deleted_at dan _id sebelum dipotong sesuai ukuran halaman
+func (t *trashDao) FindTrash(ctx context.Context, filter dto.FilterTrash, page dto.PageRequest) ([]dto.TrashItem, dto.PageInfo, rest_err.APIError) {
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	entities, apiErr := entityList(filter.FilterEntity)
+	if apiErr != nil {
+		return []dto.TrashItem{}, dto.PageInfo{}, apiErr
+	}
+
+	query, apiErr := paging.New(page, defaultLimit, paging.SortKey{Field: keyDeletedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
+	}
+
+	filterM := bson.M{
+		keyDeleted: true,
+	}
+	if filter.FilterBranch != "" {
+		filterM[keyBranch] = strings.ToUpper(filter.FilterBranch)
+	}
+
+	var total int64
+	if query.CountTotal() {
+		for _, entity := range entities {
+			count, err := db.DB.Collection(entity).CountDocuments(ctxt, filterM)
+			if err != nil {
+				logger.Error("Gagal menghitung jumlah tempat sampah dari database (FindTrash)", err)
+				apiErr := rest_err.NewInternalServerError("Database error", err)
+				return nil, dto.PageInfo{}, apiErr
+			}
+			total += count
+		}
+	}
+
+	opts := query.Options()
+	opts.SetProjection(bson.M{
+		keyName:        1,
+		keyBranch:      1,
+		keySubCategory: 1,
+		keyDeletedAt:   1,
+		keyDeletedBy:   1,
+		keyDeletedByID: 1,
+	})
+
+	pageFilter := query.Filter(filterM)
+	trashList := make([]dto.TrashItem, 0)
+	for _, entity := range entities {
+		cursor, err := db.DB.Collection(entity).Find(ctxt, pageFilter, opts)
+		if err != nil {
+			logger.Error("Gagal mendapatkan tempat sampah dari database (FindTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return []dto.TrashItem{}, dto.PageInfo{}, apiErr
+		}
+
+		var items []dto.TrashItem
+		if err = cursor.All(ctxt, &items); err != nil {
+			logger.Error("Gagal decode trash cursor ke objek slice (FindTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return []dto.TrashItem{}, dto.PageInfo{}, apiErr
+		}
+
+		for i := range items {
+			items[i].Entity = entity
+		}
+		trashList = append(trashList, items...)
+	}
+
+	// hasil dari beberapa collection digabung sehingga perlu diurutkan ulang mengikuti arah query
+	sortTrash(trashList, query.Backward())
+
+	return trashList, query.Info(&trashList, total), nil
+}
+
+// sortTrash mengurutkan deleted_at lalu _id secara descending, ascending jika halaman diambil mundur
+func sortTrash(trashList []dto.TrashItem, backward bool) {
+	sort.SliceStable(trashList, func(i, j int) bool {
+		a, b := trashList[i], trashList[j]
+		newer := a.DeletedAt > b.DeletedAt
+		if a.DeletedAt == b.DeletedAt {
+			newer = bytes.Compare(a.ID[:], b.ID[:]) > 0
+		}
+		return newer != backward
+	})
+}
+
+// RestoreTrash mengembalikan dokumen dari tempat sampah beserta gen_unit nya.
+// dijalankan didalam WithTransaction oleh service agar kedua penulisan atomic
+func (t *trashDao) RestoreTrash(ctx context.Context, input dto.TrashRestore) (*dto.TrashItem, rest_err.APIError) {
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	if input.Entity == "" {
+		return nil, rest_err.NewBadRequestError("Entity tidak boleh kosong")
+	}
+	entities, apiErr := entityList(input.Entity)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	entity := entities[0]
+
+	filter := bson.M{
+		keyID:      input.ID,
+		keyDeleted: true,
+	}
+	if input.FilterBranch != "" {
+		filter[keyBranch] = strings.ToUpper(input.FilterBranch)
+	}
+
+	update := bson.M{
+		"$unset": bson.M{
+			keyDeleted:     "",
+			keyDeletedAt:   "",
+			keyDeletedBy:   "",
+			keyDeletedByID: "",
+		},
+	}
+
+	// item yang dikembalikan adalah dokumen sebelum direstore agar informasi penghapus tetap terlihat
+	var item dto.TrashItem
+	if err := db.DB.Collection(entity).FindOneAndUpdate(ctxt, filter, update).Decode(&item); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("Dokumen %s dengan id %s tidak ada di tempat sampah", entity, input.ID.Hex()))
+		}
+
+		logger.Error("Gagal merestore dokumen dari tempat sampah (RestoreTrash)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal merestore dokumen dari tempat sampah", err)
+		return nil, apiErr
+	}
+	item.Entity = entity
+
+	if entityWithUnit[entity] {
+		unitFilter := bson.M{
+			keyID:      input.ID.Hex(),
+			keyDeleted: true,
+		}
+		if _, err := db.DB.Collection(keyGenUnitColl).UpdateOne(ctxt, unitFilter, update); err != nil {
+			logger.Error("Gagal merestore unit dari tempat sampah (RestoreTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Gagal merestore unit dari tempat sampah", err)
+			return nil, apiErr
+		}
+	}
+
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionRestore,
+		Entity:   entity,
+		EntityID: item.ID.Hex(),
+		Branch:   item.Branch,
+		Before:   bson.M{keyDeleted: true},
+		After:    bson.M{keyDeleted: false},
+	})
+
+	return &item, nil
+}
+
+// PurgeTrash menghapus permanen dokumen di tempat sampah yang dihapus sebelum DeletedBefore,
+// return jumlah dokumen entity yang terhapus (tidak termasuk gen_unit)
+func (t *trashDao) PurgeTrash(ctx context.Context, input dto.TrashPurge) (int64, rest_err.APIError) {
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*4*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyDeleted:   true,
+		keyDeletedAt: bson.M{"$lt": input.DeletedBefore},
+	}
+	if input.FilterBranch != "" {
+		filter[keyBranch] = strings.ToUpper(input.FilterBranch)
+	}
+
+	entities, _ := entityList("")
+
+	opts := options.Find()
+	opts.SetProjection(bson.M{keyID: 1, keyBranch: 1})
+
+	var purged int64
+	for _, entity := range entities {
+		coll := db.DB.Collection(entity)
+		cursor, err := coll.Find(ctxt, filter, opts)
+		if err != nil {
+			logger.Error("Gagal mendapatkan tempat sampah dari database (PurgeTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return purged, apiErr
+		}
+
+		var items []dto.TrashItem
+		if err = cursor.All(ctxt, &items); err != nil {
+			logger.Error("Gagal decode trash cursor ke objek slice (PurgeTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return purged, apiErr
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		ids := make(bson.A, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+
+		result, err := coll.DeleteMany(ctxt, bson.M{keyID: bson.M{"$in": ids}})
+		if err != nil {
+			logger.Error("Gagal menghapus permanen tempat sampah (PurgeTrash)", err)
+			apiErr := rest_err.NewInternalServerError("Gagal menghapus permanen tempat sampah", err)
+			return purged, apiErr
+		}
+		purged += result.DeletedCount
+
+		for _, item := range items {
+			auditdao.Record(ctx, dto.AuditRecord{
+				Action:   audit.ActionPurge,
+				Entity:   entity,
+				EntityID: item.ID.Hex(),
+				Branch:   item.Branch,
+			})
+		}
+	}
+
+	// gen_unit dihapus bersamaan dengan entity sehingga memiliki deleted_at yang sama
+	if _, err := db.DB.Collection(keyGenUnitColl).DeleteMany(ctxt, filter); err != nil {
+		logger.Error("Gagal menghapus permanen unit di tempat sampah (PurgeTrash)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghapus permanen unit di tempat sampah", err)
+		return purged, apiErr
+	}
+
+	return purged, nil
+}
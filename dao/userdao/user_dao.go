@@ -6,6 +6,7 @@ import (
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"context"
 	"go.mongodb.org/mongo-driver/bson"
 
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 )
 
@@ -79,6 +81,14 @@ func (u *userDao) InsertUser(ctx context.Context, user dto.UserRequest) (*string
 	// insertID := result.InsertedID.(primitive.ObjectID).Hex()
 	insertID := result.InsertedID.(string)
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyUserColl,
+		EntityID: insertID,
+		Branch:   user.Branch,
+		After:    user,
+	})
+
 	return &insertID, nil
 }
 
@@ -112,6 +122,7 @@ func (u *userDao) EditUser(ctx context.Context, userID string, userRequest dto.U
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyUserColl, userID)
 	var user dto.UserResponse
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&user); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -123,6 +134,15 @@ func (u *userDao) EditUser(ctx context.Context, userID string, userRequest dto.U
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyUserColl,
+		EntityID: user.ID,
+		Branch:   user.Branch,
+		Before:   before,
+		After:    user,
+	})
+
 	return &user, nil
 }
 
@@ -169,6 +189,7 @@ func (u *userDao) DeleteUser(ctx context.Context, userID string) rest_err.APIErr
 		keyUserID: strings.ToUpper(userID),
 	}
 
+	before := auditdao.Snapshot(ctx, keyUserColl, strings.ToUpper(userID))
 	result, err := coll.DeleteOne(ctxt, filter)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -184,6 +205,15 @@ func (u *userDao) DeleteUser(ctx context.Context, userID string) rest_err.APIErr
 		return rest_err.NewBadRequestError("User gagal dihapus, dokumen tidak ditemukan")
 	}
 
+	branch, _ := before[keyUserBranch].(string)
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyUserColl,
+		EntityID: strings.ToUpper(userID),
+		Branch:   branch,
+		Before:   before,
+	})
+
 	return nil
 }
 
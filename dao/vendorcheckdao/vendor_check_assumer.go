@@ -19,10 +19,12 @@ type CheckVendorSaver interface {
 	UploadChildImage(ctx context.Context, filterA dto.FilterParentIDChildIDAuthor, imagePath string) (*dto.VendorCheck, rest_err.APIError)
 	UpdateCheckItem(ctx context.Context, input dto.VendorCheckItemUpdate) (*dto.VendorCheck, rest_err.APIError)
 	BulkUpdateItem(ctx context.Context, inputs []dto.VendorCheckItemUpdate) (int64, rest_err.APIError)
+	ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError)
 }
 
 type CheckVendorLoader interface {
 	GetCheckByID(ctx context.Context, checkID primitive.ObjectID, branchIfSpecific string) (*dto.VendorCheck, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.VendorCheck, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.VendorCheck, dto.PageInfo, rest_err.APIError)
 	GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string) (*dto.VendorCheck, rest_err.APIError)
+	CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError)
 }
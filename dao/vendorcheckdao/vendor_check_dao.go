@@ -9,8 +9,11 @@ import (
 
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -71,6 +74,14 @@ func (c *checkVendorDao) InsertCheck(ctx context.Context, input dto.VendorCheck)
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -102,6 +113,7 @@ func (c *checkVendorDao) EditCheck(ctx context.Context, input dto.VendorCheckEdi
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var check dto.VendorCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -113,6 +125,15 @@ func (c *checkVendorDao) EditCheck(ctx context.Context, input dto.VendorCheckEdi
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -139,6 +160,14 @@ func (c *checkVendorDao) DeleteCheck(ctx context.Context, input dto.FilterIDBran
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -161,6 +190,7 @@ func (c *checkVendorDao) UploadChildImage(ctx context.Context, filterA dto.Filte
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, filterA.FilterParentID)
 	var check dto.VendorCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -172,6 +202,15 @@ func (c *checkVendorDao) UploadChildImage(ctx context.Context, filterA dto.Filte
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -201,6 +240,7 @@ func (c *checkVendorDao) UpdateCheckItem(ctx context.Context, input dto.VendorCh
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterParentID)
 	var check dto.VendorCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -212,6 +252,15 @@ func (c *checkVendorDao) UpdateCheckItem(ctx context.Context, input dto.VendorCh
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -225,7 +274,9 @@ func (c *checkVendorDao) BulkUpdateItem(ctx context.Context, inputs []dto.Vendor
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -246,6 +297,7 @@ func (c *checkVendorDao) BulkUpdateItem(ctx context.Context, inputs []dto.Vendor
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -258,6 +310,8 @@ func (c *checkVendorDao) BulkUpdateItem(ctx context.Context, inputs []dto.Vendor
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -287,13 +341,14 @@ func (c *checkVendorDao) GetCheckByID(ctx context.Context, checkID primitive.Obj
 	return &check, nil
 }
 
-func (c *checkVendorDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.VendorCheck, rest_err.APIError) {
+func (c *checkVendorDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.VendorCheck, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -309,31 +364,40 @@ func (c *checkVendorDao) FindCheck(ctx context.Context, branch string, filterA d
 		filter[keyCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah cctv check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	if !detail {
 		opts.SetProjection(bson.M{
 			keyVendorCheckItems: 0,
 		})
 	}
-	opts.SetSort(bson.D{{Key: keyUpdatedAt, Value: -1}}) //nolint:govet
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("gagal mendapatkan daftar cctv check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.VendorCheck{}, apiErr
+		return []dto.VendorCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	var checkList []dto.VendorCheck
+	checkList := []dto.VendorCheck{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (VendorFindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.VendorCheck{}, apiErr
+		return []dto.VendorCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 func (c *checkVendorDao) GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string) (*dto.VendorCheck, rest_err.APIError) {
@@ -377,3 +441,77 @@ func (c *checkVendorDao) GetLastCheckCreateRange(ctx context.Context, start, end
 
 	return &check, nil
 }
+
+// CountUnitRef menghitung check vendor pada branch yang memuat unit, digunakan pada preview penggabungan unit
+func (c *checkVendorDao) CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyBranch: strings.ToUpper(branch),
+		keyChXId:  unitID,
+	}
+
+	count, err := coll.CountDocuments(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghitung referensi unit pada check vendor (CountUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghitung referensi unit pada check vendor", err)
+		return 0, apiErr
+	}
+
+	return count, nil
+}
+
+// ReplaceUnitRef mengganti item unit lama menjadi unit baru pada seluruh check vendor branch.
+// jika check vendor sudah memuat unit baru, item unit lama dihapus supaya tidak ganda
+func (c *checkVendorDao) ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	branch := strings.ToUpper(input.Branch)
+
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyBranch: branch, keyChXId: input.FromID})
+	filterBoth := bson.M{
+		keyBranch: branch,
+		"$and":    bson.A{bson.M{keyChXId: input.FromID}, bson.M{keyChXId: input.ToID}},
+	}
+	pull := bson.M{
+		"$pull": bson.M{
+			keyVendorCheckItems: bson.M{"id": input.FromID},
+		},
+	}
+	pulled, err := coll.UpdateMany(ctxt, filterBoth, pull)
+	if err != nil {
+		logger.Error("Gagal menghapus item unit ganda pada check vendor (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check vendor", err)
+		return 0, apiErr
+	}
+
+	opts := options.Update()
+	opts.SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.id": input.FromID}},
+	})
+
+	filter := bson.M{
+		keyBranch: branch,
+		keyChXId:  input.FromID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			keyVendorCheckItems + ".$[item].id":   input.ToID,
+			keyVendorCheckItems + ".$[item].name": input.ToName,
+		},
+	}
+	replaced, err := coll.UpdateMany(ctxt, filter, update, opts)
+	if err != nil {
+		logger.Error("Gagal mengganti referensi unit pada check vendor (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check vendor", err)
+		return 0, apiErr
+	}
+
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
+	return pulled.ModifiedCount + replaced.ModifiedCount, nil
+}
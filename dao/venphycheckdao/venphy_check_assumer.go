@@ -22,11 +22,13 @@ type CheckVenPhySaver interface {
 	BulkUpdateItemForUpdateCheckItem(ctx context.Context, inputs []dto.VenPhyCheckItemUpdate) (int64, rest_err.APIError)
 	OverwriteChecklist(ctx context.Context, id primitive.ObjectID, checkItems []dto.VenPhyCheckItemEmbed) (*dto.VenPhyCheck, rest_err.APIError)
 	UndoFinishCheck(ctx context.Context, filterID primitive.ObjectID, filterBranch string) (*dto.VenPhyCheck, rest_err.APIError)
+	ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError)
 }
 
 type CheckVenPhyLoader interface {
 	GetCheckByID(ctx context.Context, checkID primitive.ObjectID, branchIfSpecific string) (*dto.VenPhyCheck, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.VenPhyCheck, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.VenPhyCheck, dto.PageInfo, rest_err.APIError)
 	FindCheckStillOpen(ctx context.Context, branch string, detail bool) ([]dto.VenPhyCheck, rest_err.APIError)
 	GetLastCheckCreateRange(ctx context.Context, start, end int64, branch string, isQuarter bool) (*dto.VenPhyCheck, rest_err.APIError)
+	CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError)
 }
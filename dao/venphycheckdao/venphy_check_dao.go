@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
 	"github.com/muchlist/risa_restfull/db"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/audit"
+	"github.com/muchlist/risa_restfull/utils/paging"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -70,6 +73,14 @@ func (c *checkVenPhyDao) InsertCheck(ctx context.Context, input dto.VenPhyCheck)
 
 	insertID := result.InsertedID.(primitive.ObjectID).Hex()
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionInsert,
+		Entity:   keyCollection,
+		EntityID: insertID,
+		Branch:   input.Branch,
+		After:    input,
+	})
+
 	return &insertID, nil
 }
 
@@ -105,6 +116,7 @@ func (c *checkVenPhyDao) EditCheck(ctx context.Context, input dto.VenPhyCheckEdi
 		"$set": set,
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterID)
 	var check dto.VenPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -116,6 +128,15 @@ func (c *checkVenPhyDao) EditCheck(ctx context.Context, input dto.VenPhyCheckEdi
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -142,6 +163,7 @@ func (c *checkVenPhyDao) UndoFinishCheck(ctx context.Context, filterID primitive
 		"$set": set,
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, filterID)
 	var check dto.VenPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -153,6 +175,15 @@ func (c *checkVenPhyDao) UndoFinishCheck(ctx context.Context, filterID primitive
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -177,6 +208,7 @@ func (c *checkVenPhyDao) OverwriteChecklist(ctx context.Context, id primitive.Ob
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, id)
 	var check dto.VenPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -188,6 +220,15 @@ func (c *checkVenPhyDao) OverwriteChecklist(ctx context.Context, id primitive.Ob
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -214,6 +255,14 @@ func (c *checkVenPhyDao) DeleteCheck(ctx context.Context, input dto.FilterIDBran
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionDelete,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   check,
+	})
+
 	return &check, nil
 }
 
@@ -236,6 +285,7 @@ func (c *checkVenPhyDao) UploadChildImage(ctx context.Context, filterA dto.Filte
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, filterA.FilterParentID)
 	var check dto.VenPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -247,6 +297,15 @@ func (c *checkVenPhyDao) UploadChildImage(ctx context.Context, filterA dto.Filte
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -277,6 +336,7 @@ func (c *checkVenPhyDao) UpdateCheckItem(ctx context.Context, input dto.VenPhyCh
 		},
 	}
 
+	before := auditdao.Snapshot(ctx, keyCollection, input.FilterParentID)
 	var check dto.VenPhyCheck
 	if err := coll.FindOneAndUpdate(ctxt, filter, update, opts).Decode(&check); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -288,6 +348,15 @@ func (c *checkVenPhyDao) UpdateCheckItem(ctx context.Context, input dto.VenPhyCh
 		return nil, apiErr
 	}
 
+	auditdao.Record(ctx, dto.AuditRecord{
+		Action:   audit.ActionEdit,
+		Entity:   keyCollection,
+		EntityID: check.ID.Hex(),
+		Branch:   check.Branch,
+		Before:   before,
+		After:    check,
+	})
+
 	return &check, nil
 }
 
@@ -301,7 +370,9 @@ func (c *checkVenPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.VenPhy
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -326,6 +397,7 @@ func (c *checkVenPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.VenPhy
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -338,6 +410,8 @@ func (c *checkVenPhyDao) BulkUpdateItem(ctx context.Context, inputs []dto.VenPhy
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -353,7 +427,9 @@ func (c *checkVenPhyDao) BulkUpdateItemForUpdateCheckItem(ctx context.Context, i
 	}
 
 	operations := make([]mongo.WriteModel, len(inputs))
+	parentIDs := make(bson.A, len(inputs))
 	for i, input := range inputs {
+		parentIDs[i] = input.FilterParentID
 		filter := bson.M{
 			keyID:       input.FilterParentID,
 			keyChXId:    input.FilterChildID,
@@ -383,6 +459,7 @@ func (c *checkVenPhyDao) BulkUpdateItemForUpdateCheckItem(ctx context.Context, i
 		operations[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(false)
 	}
 
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyID: bson.M{"$in": parentIDs}})
 	opts := options.BulkWrite().SetOrdered(false)
 	result, err := coll.BulkWrite(ctxt, operations, opts)
 	if err != nil {
@@ -395,6 +472,8 @@ func (c *checkVenPhyDao) BulkUpdateItemForUpdateCheckItem(ctx context.Context, i
 		return 0, apiErr
 	}
 
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
 	return result.ModifiedCount, nil
 }
 
@@ -424,13 +503,14 @@ func (c *checkVenPhyDao) GetCheckByID(ctx context.Context, checkID primitive.Obj
 	return &check, nil
 }
 
-func (c *checkVenPhyDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, detail bool) ([]dto.VenPhyCheck, rest_err.APIError) {
+func (c *checkVenPhyDao) FindCheck(ctx context.Context, branch string, filterA dto.FilterTimeRangeLimit, page dto.PageRequest, detail bool) ([]dto.VenPhyCheck, dto.PageInfo, rest_err.APIError) {
 	coll := db.DB.Collection(keyCollection)
 	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
 	defer cancel()
 
-	if filterA.Limit == 0 {
-		filterA.Limit = 100
+	query, apiErr := paging.New(page, 100, paging.SortKey{Field: keyUpdatedAt, Dir: -1})
+	if apiErr != nil {
+		return nil, dto.PageInfo{}, apiErr
 	}
 
 	// filter
@@ -446,31 +526,40 @@ func (c *checkVenPhyDao) FindCheck(ctx context.Context, branch string, filterA d
 		filter[keyCreatedAt] = bson.M{"$lte": filterA.FilterEnd}
 	}
 
-	opts := options.Find()
+	var total int64
+	if query.CountTotal() {
+		count, err := coll.CountDocuments(ctxt, filter)
+		if err != nil {
+			logger.Error("Gagal menghitung jumlah vendor check dari database (FindCheck)", err)
+			apiErr := rest_err.NewInternalServerError("Database error", err)
+			return nil, dto.PageInfo{}, apiErr
+		}
+		total = count
+	}
+
+	opts := query.Options()
 
 	if !detail {
 		opts.SetProjection(bson.M{
 			keyVenPhyCheckItems: 0,
 		})
 	}
-	opts.SetSort(bson.D{{keyUpdatedAt, -1}}) //nolint:govet
-	opts.SetLimit(filterA.Limit)
 
-	cursor, err := coll.Find(ctxt, filter, opts)
+	cursor, err := coll.Find(ctxt, query.Filter(filter), opts)
 	if err != nil {
 		logger.Error("gagal mendapatkan daftar vendor check dari database (FindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.VenPhyCheck{}, apiErr
+		return []dto.VenPhyCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	var checkList []dto.VenPhyCheck
+	checkList := []dto.VenPhyCheck{}
 	if err = cursor.All(ctxt, &checkList); err != nil {
 		logger.Error("Gagal decode checkList cursor ke objek slice (VendorFindCheck)", err)
 		apiErr := rest_err.NewInternalServerError("Database error", err)
-		return []dto.VenPhyCheck{}, apiErr
+		return []dto.VenPhyCheck{}, dto.PageInfo{}, apiErr
 	}
 
-	return checkList, nil
+	return checkList, query.Info(&checkList, total), nil
 }
 
 func (c *checkVenPhyDao) FindCheckStillOpen(ctx context.Context, branch string, detail bool) ([]dto.VenPhyCheck, rest_err.APIError) {
@@ -554,3 +643,77 @@ func (c *checkVenPhyDao) GetLastCheckCreateRange(ctx context.Context, start, end
 
 	return &check, nil
 }
+
+// CountUnitRef menghitung check fisik vendor pada branch yang memuat unit, digunakan pada preview penggabungan unit
+func (c *checkVenPhyDao) CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		keyBranch: strings.ToUpper(branch),
+		keyChXId:  unitID,
+	}
+
+	count, err := coll.CountDocuments(ctxt, filter)
+	if err != nil {
+		logger.Error("Gagal menghitung referensi unit pada check fisik vendor (CountUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal menghitung referensi unit pada check fisik vendor", err)
+		return 0, apiErr
+	}
+
+	return count, nil
+}
+
+// ReplaceUnitRef mengganti item unit lama menjadi unit baru pada seluruh check fisik vendor branch.
+// jika check fisik vendor sudah memuat unit baru, item unit lama dihapus supaya tidak ganda
+func (c *checkVenPhyDao) ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	coll := db.DB.Collection(keyCollection)
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	branch := strings.ToUpper(input.Branch)
+
+	befores := auditdao.SnapshotMany(ctx, keyCollection, bson.M{keyBranch: branch, keyChXId: input.FromID})
+	filterBoth := bson.M{
+		keyBranch: branch,
+		"$and":    bson.A{bson.M{keyChXId: input.FromID}, bson.M{keyChXId: input.ToID}},
+	}
+	pull := bson.M{
+		"$pull": bson.M{
+			keyVenPhyCheckItems: bson.M{"id": input.FromID},
+		},
+	}
+	pulled, err := coll.UpdateMany(ctxt, filterBoth, pull)
+	if err != nil {
+		logger.Error("Gagal menghapus item unit ganda pada check fisik vendor (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check fisik vendor", err)
+		return 0, apiErr
+	}
+
+	opts := options.Update()
+	opts.SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.id": input.FromID}},
+	})
+
+	filter := bson.M{
+		keyBranch: branch,
+		keyChXId:  input.FromID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			keyVenPhyCheckItems + ".$[item].id":   input.ToID,
+			keyVenPhyCheckItems + ".$[item].name": input.ToName,
+		},
+	}
+	replaced, err := coll.UpdateMany(ctxt, filter, update, opts)
+	if err != nil {
+		logger.Error("Gagal mengganti referensi unit pada check fisik vendor (ReplaceUnitRef)", err)
+		apiErr := rest_err.NewInternalServerError("Gagal mengganti referensi unit pada check fisik vendor", err)
+		return 0, apiErr
+	}
+
+	auditdao.RecordMany(ctx, keyCollection, befores)
+
+	return pulled.ModifiedCount + replaced.ModifiedCount, nil
+}
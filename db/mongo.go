@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"github.com/muchlist/erru_utils_go/logger"
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -20,6 +22,10 @@ var (
 	// DB objek sebagai database objek
 	DB       *mongo.Database
 	mongoURL = "mongodb://localhost:27017"
+
+	// TransactionSupported bernilai true jika server berjalan sebagai replica set atau sharded cluster.
+	// mongodb standalone tidak mendukung multi-document transaction sehingga aplikasi menolak berjalan
+	TransactionSupported bool
 )
 
 // Init menginisiasi database
@@ -46,5 +52,25 @@ func Init() (*mongo.Client, context.Context, context.CancelFunc) {
 	logger.Info("database berhasil terkoneksi")
 	DB = Client.Database(databaseName)
 
+	TransactionSupported = detectTransaction(ctx)
+	if !TransactionSupported {
+		err = errors.New("mongodb harus berjalan sebagai replica set atau sharded cluster agar transaction dapat digunakan")
+		logger.Error("mongodb berjalan standalone", err)
+		panic(err)
+	}
+
 	return Client, ctx, cancel
 }
+
+// detectTransaction memeriksa topologi server melalui perintah isMaster
+func detectTransaction(ctx context.Context) bool {
+	var result struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := DB.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&result); err != nil {
+		logger.Error("gagal memeriksa topologi mongodb", err)
+		return false
+	}
+	return result.SetName != "" || result.Msg == "isdbgrid"
+}
@@ -0,0 +1,77 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// DefaultAutoCaseAfter nilai AutoCaseAfter (detik) untuk rule bawaan dan rule lama tanpa auto_case_after
+const DefaultAutoCaseAfter int64 = 30 * 60
+
+// AlertRule aturan debounce alert per branch dan category.
+// Branch kosong berarti berlaku untuk semua branch pada category tersebut.
+// Threshold adalah jumlah ping berurutan terbaru (maksimal 12 sesuai panjang pings_state).
+// AutoCaseAfter dalam detik, insiden dibuat otomatis jika unit DOWN selama waktu tersebut (0 berarti nonaktif)
+type AlertRule struct {
+	ID                primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UpdatedAt         int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy         string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID       string             `json:"updated_by_id" bson:"updated_by_id"`
+	Branch            string             `json:"branch" bson:"branch"`
+	Category          string             `json:"category" bson:"category"`
+	DegradedThreshold int                `json:"degraded_threshold" bson:"degraded_threshold"`
+	DownThreshold     int                `json:"down_threshold" bson:"down_threshold"`
+	RecoverThreshold  int                `json:"recover_threshold" bson:"recover_threshold"`
+	NotifyDegraded    bool               `json:"notify_degraded" bson:"notify_degraded"`
+	AutoCaseAfter     int64              `json:"auto_case_after" bson:"auto_case_after"`
+	Disable           bool               `json:"disable" bson:"disable"`
+}
+
+type AlertRuleRequest struct {
+	Branch            string `json:"branch"`
+	Category          string `json:"category"`
+	DegradedThreshold int    `json:"degraded_threshold"`
+	DownThreshold     int    `json:"down_threshold"`
+	RecoverThreshold  int    `json:"recover_threshold"`
+	NotifyDegraded    bool   `json:"notify_degraded"`
+	AutoCaseAfter     int64  `json:"auto_case_after"`
+	Disable           bool   `json:"disable"`
+}
+
+// AlertLog dicatat setiap terjadi perpindahan state alert pada gen_unit
+type AlertLog struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Time         int64              `json:"time" bson:"time"`
+	UnitID       string             `json:"unit_id" bson:"unit_id"`
+	UnitName     string             `json:"unit_name" bson:"unit_name"`
+	Branch       string             `json:"branch" bson:"branch"`
+	Category     string             `json:"category" bson:"category"`
+	FromState    string             `json:"from_state" bson:"from_state"`
+	ToState      string             `json:"to_state" bson:"to_state"`
+	PingsState   []PingState        `json:"pings_state" bson:"pings_state"`
+	Acknowledged bool               `json:"acknowledged" bson:"acknowledged"`
+	AckAt        int64              `json:"ack_at" bson:"ack_at"`
+	AckBy        string             `json:"ack_by" bson:"ack_by"`
+	AckByID      string             `json:"ack_by_id" bson:"ack_by_id"`
+	AckNote      string             `json:"ack_note" bson:"ack_note"`
+}
+
+type AlertAckRequest struct {
+	Note string `json:"note"`
+}
+
+type AlertLogAck struct {
+	FilterID     primitive.ObjectID
+	FilterBranch string
+	AckAt        int64
+	AckBy        string
+	AckByID      string
+	AckNote      string
+}
+
+type FilterAlertLog struct {
+	FilterBranch    string
+	FilterCategory  string
+	FilterUnitID    string
+	FilterToState   string
+	FilterUnAckOnly bool
+	FilterStart     int64
+	FilterEnd       int64
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (a AlertRuleRequest) Validate() error {
+	if err := validation.ValidateStruct(&a,
+		validation.Field(&a.Category, validation.Required),
+		validation.Field(&a.DegradedThreshold, validation.Required, validation.Min(1), validation.Max(12)),
+		validation.Field(&a.DownThreshold, validation.Required, validation.Min(1), validation.Max(12)),
+		validation.Field(&a.RecoverThreshold, validation.Required, validation.Min(1), validation.Max(12)),
+		validation.Field(&a.AutoCaseAfter, validation.Min(int64(0))),
+	); err != nil {
+		return err
+	}
+
+	// validate branch, boleh kosong untuk semua branch
+	if a.Branch != "" {
+		if err := branchValidation(a.Branch); err != nil {
+			return err
+		}
+	}
+
+	return categoryValidation(a.Category)
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ApiKey struct penuh dari service account (machine to machine).
+// Key plaintext tidak pernah disimpan, hanya Prefix untuk identifikasi dan HashKey untuk validasi
+type ApiKey struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	CreatedAt   int64              `json:"created_at" bson:"created_at"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedByID string             `json:"created_by_id" bson:"created_by_id"`
+	UpdatedAt   int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy   string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID string             `json:"updated_by_id" bson:"updated_by_id"`
+	Name        string             `json:"name" bson:"name"`
+	Branch      string             `json:"branch" bson:"branch"`
+	Scopes      []string           `json:"scopes" bson:"scopes"`
+	Prefix      string             `json:"prefix" bson:"prefix"`
+	HashKey     string             `json:"-" bson:"hash_key"`
+	Revoked     bool               `json:"revoked" bson:"revoked"`
+	RevokedAt   int64              `json:"revoked_at" bson:"revoked_at"`
+	RevokedBy   string             `json:"revoked_by" bson:"revoked_by"`
+	LastUsedAt  int64              `json:"last_used_at" bson:"last_used_at"`
+	LastUsedIP  string             `json:"last_used_ip" bson:"last_used_ip"`
+}
+
+type ApiKeyRequest struct {
+	Name   string   `json:"name"`
+	Branch string   `json:"branch"`
+	Scopes []string `json:"scopes"`
+}
+
+// ApiKeyRotate digunakan dao untuk mengganti hash key lama dengan yang baru
+type ApiKeyRotate struct {
+	ID          primitive.ObjectID
+	UpdatedAt   int64
+	UpdatedBy   string
+	UpdatedByID string
+	Prefix      string
+	HashKey     string
+}
+
+// ApiKeyCreated dikembalikan saat pembuatan dan rotasi key,
+// Key plaintext hanya ditampilkan sekali ini saja
+type ApiKeyCreated struct {
+	Key    string `json:"key"`
+	ApiKey ApiKey `json:"api_key"`
+}
+
+// ApiKeyClaim identitas service account yang disimpan di c.Locals setelah lolos middleware
+type ApiKeyClaim struct {
+	ID     string
+	Name   string
+	Branch string
+	Scopes []string
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (a ApiKeyRequest) Validate() error {
+	if err := validation.ValidateStruct(&a,
+		validation.Field(&a.Name, validation.Required),
+		validation.Field(&a.Branch, validation.Required),
+		validation.Field(&a.Scopes, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// validate scope
+	if err := apiScopeValidation(a.Scopes); err != nil {
+		return err
+	}
+
+	// validate branch
+	return branchValidation(a.Branch)
+}
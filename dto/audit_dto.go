@@ -0,0 +1,44 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Audit catatan setiap perubahan data (insert, edit, delete, disable) yang ditulis dari layer dao.
+// Entity adalah nama collection, Changes berisi perbedaan per field sebelum dan sesudah
+type Audit struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Time      int64              `json:"time" bson:"time"`
+	Action    string             `json:"action" bson:"action"`
+	Entity    string             `json:"entity" bson:"entity"`
+	EntityID  string             `json:"entity_id" bson:"entity_id"`
+	Branch    string             `json:"branch" bson:"branch"`
+	ActorID   string             `json:"actor_id" bson:"actor_id"`
+	ActorName string             `json:"actor_name" bson:"actor_name"`
+	Changes   []AuditChange      `json:"changes" bson:"changes"`
+}
+
+type AuditChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// AuditRecord input dari dao, Before nil untuk insert dan After nil untuk delete
+type AuditRecord struct {
+	Action   string
+	Entity   string
+	EntityID string
+	Branch   string
+	Before   interface{}
+	After    interface{}
+}
+
+type FilterAudit struct {
+	FilterEntity   string
+	FilterEntityID string
+	FilterActor    string
+	FilterBranch   string
+	FilterAction   string
+	FilterField    string
+	FilterStart    int64
+	FilterEnd      int64
+}
@@ -13,6 +13,10 @@ type Cctv struct {
 	UpdatedByID     string             `json:"updated_by_id" bson:"updated_by_id"`
 	Branch          string             `json:"branch" bson:"branch"`
 	Disable         bool               `json:"disable" bson:"disable"`
+	Deleted         bool               `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt       int64              `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy       string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedByID     string             `json:"deleted_by_id,omitempty" bson:"deleted_by_id,omitempty"`
 	Name            string             `json:"name" bson:"name"`
 	IP              string             `json:"ip" bson:"ip"`
 	InventoryNumber string             `json:"inventory_number" bson:"inventory_number"`
@@ -27,6 +31,11 @@ type Cctv struct {
 	Note            string             `json:"note" bson:"note"`
 	Extra           GenExtra           `json:"extra" bson:"extra,omitempty"`
 	DisVendor       bool               `json:"dis_vendor" bson:"dis_vendor"` // if true, disable from report vendor
+
+	Lifecycle Lifecycle `json:"lifecycle" bson:"lifecycle"`
+	Geo       *GeoPoint `json:"geo,omitempty" bson:"geo,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
 }
 
 // CctvRequest user input, id tidak diinput oleh user
@@ -46,6 +55,12 @@ type CctvRequest struct {
 	Brand string `json:"brand" bson:"brand"`
 	Type  string `json:"type" bson:"type"`
 	Note  string `json:"note" bson:"note"`
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type CctvEdit struct {
@@ -70,6 +85,11 @@ type CctvEdit struct {
 	Brand string
 	Type  string
 	Note  string
+
+	Lifecycle Lifecycle
+	Geo       *GeoPoint
+
+	CustomFields CustomFields
 }
 
 // CctvEditRequest user input
@@ -89,6 +109,12 @@ type CctvEditRequest struct {
 	Brand string `json:"brand" bson:"brand"`
 	Type  string `json:"type" bson:"type"`
 	Note  string `json:"note" bson:"note"`
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type CctvResponseMinList []CctvResponseMin
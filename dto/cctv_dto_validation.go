@@ -18,6 +18,16 @@ func (c CctvRequest) Validate() error {
 		return err
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		return err
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		return err
+	}
+
 	// validate location
 	return locationValidation(c.Location)
 }
@@ -37,6 +47,16 @@ func (c CctvEditRequest) Validate() error {
 		return err
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		return err
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		return err
+	}
+
 	// validate location
 	return locationValidation(c.Location)
 }
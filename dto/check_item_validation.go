@@ -4,7 +4,8 @@ import (
 	"errors"
 	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
-	"github.com/muchlist/risa_restfull/constants/location"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
 )
 
@@ -70,7 +71,7 @@ func (c CheckItemEditRequest) Validate() error {
 	if c.Shifts != nil {
 		shiftsAvailable := []int{1, 2, 3}
 		if !sfunc.ValueIntInSliceIsAvailable(c.Shifts, shiftsAvailable) {
-			errorList = append(errorList, fmt.Sprintf("Lokasi yang dimasukkan tidak tersedia. Gunakan %s", location.GetLocationAvailable()))
+			errorList = append(errorList, fmt.Sprintf("Lokasi yang dimasukkan tidak tersedia. Gunakan %s", masterdata.Values(masterkind.Location)))
 		}
 	}
 
@@ -0,0 +1,57 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// HistoryComment komentar pada history. ParentID berisi id komentar utama jika komentar merupakan balasan,
+// balasan dari balasan tetap menginduk pada komentar utama sehingga thread hanya satu tingkat
+type HistoryComment struct {
+	ID          primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	HistoryID   string              `json:"history_id" bson:"history_id"`
+	ParentID    string              `json:"parent_id" bson:"parent_id"`
+	Branch      string              `json:"branch" bson:"branch"`
+	CreatedAt   int64               `json:"created_at" bson:"created_at"`
+	CreatedBy   string              `json:"created_by" bson:"created_by"`
+	CreatedByID string              `json:"created_by_id" bson:"created_by_id"`
+	UpdatedAt   int64               `json:"updated_at" bson:"updated_at"`
+	Edited      bool                `json:"edited" bson:"edited"`
+	Body        string              `json:"body" bson:"body"`
+	Mentions    []HistoryUser       `json:"mentions" bson:"mentions"`
+	Attachments []CommentAttachment `json:"attachments" bson:"attachments"`
+}
+
+// CommentAttachment Thumbnail hanya tersedia untuk lampiran gambar
+type CommentAttachment struct {
+	Type      string `json:"type" bson:"type"`
+	Path      string `json:"path" bson:"path"`
+	Thumbnail string `json:"thumbnail" bson:"thumbnail"`
+}
+
+// HistoryCommentThread komentar utama beserta balasannya terurut dari yang paling lama
+type HistoryCommentThread struct {
+	HistoryComment
+	Replies []HistoryComment `json:"replies"`
+}
+
+// CommentRequest user input, Attachments berisi path hasil upload /comment-attachments
+type CommentRequest struct {
+	ParentID    string   `json:"parent_id"`
+	Body        string   `json:"body"`
+	MentionIDs  []string `json:"mention_ids"`
+	Attachments []string `json:"attachments"`
+}
+
+type CommentEditRequest struct {
+	Body        string   `json:"body"`
+	MentionIDs  []string `json:"mention_ids"`
+	Attachments []string `json:"attachments"`
+}
+
+type CommentEdit struct {
+	FilterID        primitive.ObjectID
+	FilterAuthorID  string
+	FilterCreateGTE int64
+	UpdatedAt       int64
+	Body            string
+	Mentions        []HistoryUser
+	Attachments     []CommentAttachment
+}
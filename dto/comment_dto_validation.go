@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	// CommentAttachmentFolder lokasi lampiran komentar pada folder static/image
+	CommentAttachmentFolder = "comment"
+	MaxCommentAttachments   = 5
+	maxCommentLength        = 2000
+	pdfExtension            = ".pdf"
+)
+
+func (c CommentRequest) Validate() error {
+	if err := validation.ValidateStruct(&c,
+		validation.Field(&c.MentionIDs, validation.Length(0, 20)),
+		validation.Field(&c.Attachments, validation.Length(0, MaxCommentAttachments)),
+	); err != nil {
+		return err
+	}
+	return commentContentValidation(c.Body, c.Attachments)
+}
+
+func (c CommentEditRequest) Validate() error {
+	if err := validation.ValidateStruct(&c,
+		validation.Field(&c.MentionIDs, validation.Length(0, 20)),
+		validation.Field(&c.Attachments, validation.Length(0, MaxCommentAttachments)),
+	); err != nil {
+		return err
+	}
+	return commentContentValidation(c.Body, c.Attachments)
+}
+
+// commentContentValidation komentar boleh hanya berisi lampiran,
+// lampiran harus merupakan hasil upload lampiran komentar
+func commentContentValidation(body string, attachments []string) error {
+	if strings.TrimSpace(body) == "" && len(attachments) == 0 {
+		return fmt.Errorf("komentar atau lampiran tidak boleh kosong")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return fmt.Errorf("komentar tidak boleh melebihi %d karakter", maxCommentLength)
+	}
+
+	prefix := fmt.Sprintf("image/%s/", CommentAttachmentFolder)
+	for _, path := range attachments {
+		if !strings.HasPrefix(path, prefix) || strings.Contains(path, "..") {
+			return fmt.Errorf("lampiran %s tidak valid, upload melalui /comment-attachments", path)
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !(ext == jpgExtension || ext == pngExtension || ext == jpegExtension || ext == pdfExtension) {
+			return fmt.Errorf("ekstensi lampiran %s tidak didukung", path)
+		}
+	}
+	return nil
+}
@@ -13,6 +13,10 @@ type Computer struct {
 	UpdatedByID string             `json:"updated_by_id" bson:"updated_by_id"`
 	Branch      string             `json:"branch" bson:"branch"`
 	Disable     bool               `json:"disable" bson:"disable"`
+	Deleted     bool               `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt   int64              `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy   string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedByID string             `json:"deleted_by_id,omitempty" bson:"deleted_by_id,omitempty"`
 
 	Name           string `json:"name" bson:"name"`
 	Hostname       string `json:"hostname" bson:"hostname"`
@@ -35,6 +39,11 @@ type Computer struct {
 	Type            string   `json:"type" bson:"type"` // Desktop, All in One Laptop
 	Note            string   `json:"note" bson:"note"`
 	Extra           GenExtra `json:"extra" bson:"extra,omitempty"`
+
+	Lifecycle Lifecycle `json:"lifecycle" bson:"lifecycle"`
+	Geo       *GeoPoint `json:"geo,omitempty" bson:"geo,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
 }
 
 // ComputerRequest user input, id tidak diinput oleh user
@@ -60,6 +69,12 @@ type ComputerRequest struct {
 	Brand string `json:"brand" bson:"brand"`
 	Type  string `json:"type" bson:"type"`
 	Note  string `json:"note" bson:"note"`
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type ComputerEdit struct {
@@ -90,6 +105,11 @@ type ComputerEdit struct {
 	Brand string
 	Type  string
 	Note  string
+
+	Lifecycle Lifecycle
+	Geo       *GeoPoint
+
+	CustomFields CustomFields
 }
 
 // ComputerEditRequest user input
@@ -116,6 +136,12 @@ type ComputerEditRequest struct {
 	Brand string `json:"brand" bson:"brand"`
 	Type  string `json:"type" bson:"type"`
 	Note  string `json:"note" bson:"note"`
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type ComputerResponseMinList []ComputerResponseMin
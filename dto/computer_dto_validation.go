@@ -32,6 +32,16 @@ func (c ComputerRequest) Validate() error {
 		errorList = append(errorList, err.Error())
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
 	if len(errorList) != 0 {
 		errorString := ""
 		for _, v := range errorList {
@@ -70,6 +80,16 @@ func (c ComputerEditRequest) Validate() error {
 		errorList = append(errorList, err.Error())
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
 	if len(errorList) != 0 {
 		errorString := ""
 		for _, v := range errorList {
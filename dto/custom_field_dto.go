@@ -0,0 +1,32 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// CustomFieldSchema definisi custom field untuk satu kategori, yaitu CCTV, PC atau sub category other.
+// schema bersifat unik per kategori
+type CustomFieldSchema struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UpdatedAt   int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy   string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID string             `json:"updated_by_id" bson:"updated_by_id"`
+	Category    string             `json:"category" bson:"category"`
+	Fields      []CustomField      `json:"fields" bson:"fields"`
+}
+
+// CustomField Key digunakan sebagai key penyimpanan pada custom_fields unit,
+// Options hanya digunakan oleh tipe ENUM
+type CustomField struct {
+	Key      string   `json:"key" bson:"key"`
+	Label    string   `json:"label" bson:"label"`
+	Type     string   `json:"type" bson:"type"`
+	Required bool     `json:"required" bson:"required"`
+	Options  []string `json:"options" bson:"options"`
+}
+
+type CustomFieldSchemaRequest struct {
+	Category string        `json:"category"`
+	Fields   []CustomField `json:"fields"`
+}
+
+// CustomFields nilai custom field pada unit dengan key sesuai CustomField.Key
+type CustomFields map[string]interface{}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+)
+
+const maxCustomField = 30
+
+// customFieldKey key disimpan sebagai nama field mongo sehingga dibatasi huruf kecil, angka dan underscore
+var customFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+func (c CustomFieldSchemaRequest) Validate() error {
+	if err := validation.ValidateStruct(&c,
+		validation.Field(&c.Category, validation.Required),
+		validation.Field(&c.Fields, validation.Length(0, maxCustomField)),
+	); err != nil {
+		return err
+	}
+
+	// validate category, CCTV, PC atau sub category other
+	available := append([]string{category.Cctv, category.PC}, masterdata.Values(masterkind.SubCategory)...)
+	if !sfunc.InSlice(strings.ToUpper(c.Category), available) {
+		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", available)
+	}
+
+	keys := make(map[string]bool, len(c.Fields))
+	for _, field := range c.Fields {
+		if !customFieldKey.MatchString(field.Key) {
+			return fmt.Errorf("key %s tidak valid, gunakan huruf kecil, angka dan underscore", field.Key)
+		}
+		if keys[field.Key] {
+			return fmt.Errorf("key %s ganda", field.Key)
+		}
+		keys[field.Key] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("label %s tidak boleh kosong", field.Key)
+		}
+		if !sfunc.InSlice(field.Type, fieldtype.GetFieldTypeAvailable()) {
+			return fmt.Errorf("tipe %s tidak tersedia. gunakan %s", field.Type, fieldtype.GetFieldTypeAvailable())
+		}
+		if field.Type == fieldtype.Enum && len(field.Options) == 0 {
+			return errors.New("tipe ENUM wajib memiliki options")
+		}
+	}
+
+	return nil
+}
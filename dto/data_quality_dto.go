@@ -0,0 +1,39 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// DataQualityReport hasil pemindaian data quality terakhir, disimpan satu dokumen per branch
+type DataQualityReport struct {
+	ID          primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	Branch      string               `json:"branch" bson:"branch"`
+	GeneratedAt int64                `json:"generated_at" bson:"generated_at"`
+	GeneratedBy string               `json:"generated_by" bson:"generated_by"`
+	Summary     map[string]int       `json:"summary" bson:"summary"`
+	Findings    []DataQualityFinding `json:"findings" bson:"findings"`
+}
+
+// DataQualityFinding Value berisi ip, nomor inventaris atau nama yang bermasalah
+type DataQualityFinding struct {
+	Type  string            `json:"type" bson:"type"`
+	Value string            `json:"value" bson:"value"`
+	Units []DataQualityUnit `json:"units" bson:"units"`
+}
+
+type DataQualityUnit struct {
+	ID              string `json:"id" bson:"id"`
+	Category        string `json:"category" bson:"category"`
+	Name            string `json:"name" bson:"name"`
+	Branch          string `json:"branch" bson:"branch"`
+	IP              string `json:"ip,omitempty" bson:"ip,omitempty"`
+	InventoryNumber string `json:"inventory_number,omitempty" bson:"inventory_number,omitempty"`
+}
+
+// UnitUniqueCheck input pengecekan ip dan nomor inventaris saat insert / edit,
+// ID diisi saat edit agar unit itu sendiri tidak dianggap konflik
+type UnitUniqueCheck struct {
+	ID              string
+	Branch          string
+	IP              string
+	InventoryNumber string
+	Force           bool
+}
@@ -6,6 +6,7 @@ type FilterBranchCatComplete struct {
 	FilterBranch         string
 	FilterCategory       string // ex "CCTV,ALTAI"
 	FilterCompleteStatus []int
+	FilterTag            string
 }
 
 type FilterBranchCatInCompleteIn struct {
@@ -20,6 +21,10 @@ type FilterBranchLocIPNameDisable struct {
 	FilterIP       string
 	FilterName     string
 	FilterDisable  bool
+	// FilterInventoryNumber dicocokkan utuh tanpa membedakan huruf besar kecil
+	FilterInventoryNumber string
+	// FilterCustomFields key custom field dengan nilai sesuai tipe pada schema
+	FilterCustomFields map[string]interface{}
 }
 
 type FilterBranchNameDisable struct {
@@ -109,28 +114,31 @@ type FilterBranchCategory struct {
 }
 
 type FilterComputer struct {
-	FilterBranch         string
-	FilterLocation       string
-	FilterDivision       string
-	FilterIP             string
-	FilterName           string
-	FilterDisable        bool
-	FilterSeatManagement int // -1 all , 0 false, 1 true
+	FilterBranch          string
+	FilterLocation        string
+	FilterDivision        string
+	FilterIP              string
+	FilterName            string
+	FilterDisable         bool
+	FilterSeatManagement  int // -1 all , 0 false, 1 true
+	FilterInventoryNumber string
+	FilterCustomFields    map[string]interface{}
 }
 
 type FilterOther struct {
-	FilterBranch      string
-	FilterSubCategory string
-	FilterLocation    string
-	FilterDivision    string
-	FilterIP          string
-	FilterName        string
-	FilterDisable     bool
+	FilterBranch          string
+	FilterSubCategory     string
+	FilterLocation        string
+	FilterDivision        string
+	FilterIP              string
+	FilterName            string
+	FilterDisable         bool
+	FilterInventoryNumber string
+	FilterCustomFields    map[string]interface{}
 }
 
 type FilterFindPendingReport struct {
 	FilterBranch   string `json:"branch"`
 	FilterTitle    string `json:"title"`
 	CompleteStatus string `json:"complete_status"`
-	Limit          int64  `json:"limit"`
 }
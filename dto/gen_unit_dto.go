@@ -15,16 +15,24 @@ type Case struct {
 
 // GenUnit struct lengkap dari document all_unit di Mongodb, ID harus sama dengan ID unitDetail
 type GenUnit struct {
-	ID         string      `json:"id,omitempty" bson:"_id,omitempty"`
-	Category   string      `json:"category" bson:"category"`
-	Name       string      `json:"name" bson:"name"`
-	IP         string      `json:"ip" bson:"ip"`
-	Branch     string      `json:"branch" bson:"branch"`
-	Disable    bool        `json:"disable" bson:"disable"`
-	Cases      []Case      `json:"cases" bson:"cases"`
-	CasesSize  int         `json:"cases_size" bson:"cases_size"`
-	PingsState []PingState `json:"pings_state,omitempty" bson:"pings_state,omitempty"`
-	LastPing   string      `json:"last_ping,omitempty" bson:"last_ping,omitempty"`
+	ID          string      `json:"id,omitempty" bson:"_id,omitempty"`
+	Category    string      `json:"category" bson:"category"`
+	Name        string      `json:"name" bson:"name"`
+	IP          string      `json:"ip" bson:"ip"`
+	Branch      string      `json:"branch" bson:"branch"`
+	Disable     bool        `json:"disable" bson:"disable"`
+	Deleted     bool        `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt   int64       `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy   string      `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedByID string      `json:"deleted_by_id,omitempty" bson:"deleted_by_id,omitempty"`
+	Cases       []Case      `json:"cases" bson:"cases"`
+	CasesSize   int         `json:"cases_size" bson:"cases_size"`
+	PingsState  []PingState `json:"pings_state,omitempty" bson:"pings_state,omitempty"`
+	LastPing    string      `json:"last_ping,omitempty" bson:"last_ping,omitempty"`
+	AlertState  string      `json:"alert_state" bson:"alert_state"`
+	AlertSince  int64       `json:"alert_since" bson:"alert_since"`
+	AutoCaseID  string      `json:"auto_case_id" bson:"auto_case_id"`
+	Uplink      string      `json:"uplink" bson:"uplink"`
 }
 
 type GenUnitRequest struct {
@@ -36,13 +44,14 @@ type GenUnitRequest struct {
 }
 
 type GenUnitFilter struct {
-	Branch   string
-	Name     string
-	Category string
-	IP       string
-	Disable  bool
-	Pings    bool
-	LastPing string
+	Branch     string
+	Name       string
+	Category   string
+	IP         string
+	Disable    bool
+	Pings      bool
+	LastPing   string
+	AlertState string
 }
 
 // GenUnitCaseRequest,
@@ -56,6 +65,44 @@ type GenUnitCaseRequest struct {
 	CaseNote     string
 }
 
+// GenUnitAlertStateRequest perpindahan state alert, hanya berhasil jika state saat ini sama dengan FromState
+type GenUnitAlertStateRequest struct {
+	UnitID    string
+	FromState string
+	ToState   string
+	Since     int64
+}
+
+// GenUnitAutoCaseRequest mengganti auto_case_id, hanya berhasil jika auto_case_id saat ini sama dengan FromCaseID
+type GenUnitAutoCaseRequest struct {
+	UnitID     string
+	FromCaseID string
+	ToCaseID   string
+}
+
+// GenUnitUplinkEdit mengganti unit induk (uplink), Uplink kosong berarti unit tidak memiliki induk
+type GenUnitUplinkEdit struct {
+	UnitID       string
+	FilterBranch string
+	Uplink       string
+}
+
+// GenUnitUplinkRequest unit induk tempat unit terhubung ke jaringan, misalnya switch untuk cctv
+type GenUnitUplinkRequest struct {
+	UplinkID string `json:"uplink_id"`
+}
+
+// TopologyNode unit beserta unit turunan yang terhubung melalui uplink
+type TopologyNode struct {
+	ID         string         `json:"id"`
+	Category   string         `json:"category"`
+	Name       string         `json:"name"`
+	IP         string         `json:"ip"`
+	LastPing   string         `json:"last_ping"`
+	AlertState string         `json:"alert_state"`
+	Children   []TopologyNode `json:"children"`
+}
+
 type GenUnitEditRequest struct {
 	Category string `json:"category" bson:"category"`
 	Name     string `json:"name" bson:"name"`
@@ -76,6 +123,10 @@ type GenUnitResponse struct {
 	CasesSize  int         `json:"cases_size" bson:"cases_size"`
 	PingsState []PingState `json:"pings_state" bson:"pings_state"`
 	LastPing   string      `json:"last_ping" bson:"last_ping"`
+	AlertState string      `json:"alert_state" bson:"alert_state"`
+	AlertSince int64       `json:"alert_since" bson:"alert_since"`
+	AutoCaseID string      `json:"auto_case_id" bson:"auto_case_id"`
+	Uplink     string      `json:"uplink" bson:"uplink"`
 	Disable    bool        `json:"-" bson:"disable"`
 }
 
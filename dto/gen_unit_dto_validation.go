@@ -17,3 +17,9 @@ func (g GenUnitPingStateRequest) Validate() error {
 	// validate location
 	return categoryValidation(g.Category)
 }
+
+func (g GenUnitUplinkRequest) Validate() error {
+	return validation.ValidateStruct(&g,
+		validation.Field(&g.UplinkID, validation.Required),
+	)
+}
@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"strconv"
+	"strings"
+)
+
+const geoPointType = "Point"
+
+// GeoPoint GeoJSON Point yang diindex 2dsphere, Coordinates berurutan [longitude, latitude]
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// NewGeoPoint membuat GeoPoint dari location_lat dan location_lon berbentuk string.
+// mengembalikan nil jika salah satu kosong atau bukan koordinat yang valid
+func NewGeoPoint(lat string, lon string) *GeoPoint {
+	latitude, longitude, ok := parseCoordinate(lat, lon)
+	if !ok {
+		return nil
+	}
+	return &GeoPoint{
+		Type:        geoPointType,
+		Coordinates: []float64{longitude, latitude},
+	}
+}
+
+// Lat dan Lon mengembalikan 0 jika point tidak memiliki koordinat
+func (g GeoPoint) Lat() float64 {
+	if len(g.Coordinates) != 2 {
+		return 0
+	}
+	return g.Coordinates[1]
+}
+
+func (g GeoPoint) Lon() float64 {
+	if len(g.Coordinates) != 2 {
+		return 0
+	}
+	return g.Coordinates[0]
+}
+
+func parseCoordinate(lat string, lon string) (float64, float64, bool) {
+	lat = strings.TrimSpace(strings.ReplaceAll(lat, ",", "."))
+	lon = strings.TrimSpace(strings.ReplaceAll(lon, ",", "."))
+	if lat == "" || lon == "" {
+		return 0, 0, false
+	}
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return 0, 0, false
+	}
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return 0, 0, false
+	}
+	return latitude, longitude, true
+}
+
+// GeoFeatureCollection GeoJSON FeatureCollection untuk endpoint peta
+type GeoFeatureCollection struct {
+	Type     string       `json:"type"`
+	Features []GeoFeature `json:"features"`
+}
+
+type GeoFeature struct {
+	Type       string        `json:"type"`
+	Geometry   GeoPoint      `json:"geometry"`
+	Properties GeoProperties `json:"properties"`
+}
+
+// GeoProperties Distance dalam meter, hanya diisi pada pencarian unit terdekat
+type GeoProperties struct {
+	ID         string  `json:"id"`
+	Category   string  `json:"category"`
+	Name       string  `json:"name"`
+	Branch     string  `json:"branch"`
+	Location   string  `json:"location"`
+	IP         string  `json:"ip"`
+	LastPing   string  `json:"last_ping"`
+	AlertState string  `json:"alert_state"`
+	CasesSize  int     `json:"cases_size"`
+	Distance   float64 `json:"distance,omitempty"`
+}
+
+type FilterMap struct {
+	FilterBranch   string
+	FilterCategory string
+}
+
+// FilterGeoNear MaxDistance dalam meter
+type FilterGeoNear struct {
+	FilterBranch string
+	Lat          float64
+	Lon          float64
+	MaxDistance  float64
+	Limit        int64
+}
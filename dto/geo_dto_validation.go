@@ -0,0 +1,15 @@
+package dto
+
+import "errors"
+
+// coordinateValidation location_lat dan location_lon boleh kosong keduanya,
+// jika diisi harus berupa angka latitude -90..90 dan longitude -180..180
+func coordinateValidation(lat string, lon string) error {
+	if lat == "" && lon == "" {
+		return nil
+	}
+	if _, _, ok := parseCoordinate(lat, lon); !ok {
+		return errors.New("location_lat dan location_lon harus berupa koordinat yang valid")
+	}
+	return nil
+}
@@ -23,10 +23,16 @@ type History struct {
 	Problem        string             `json:"problem" bson:"problem"`
 	ProblemResolve string             `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int                `json:"complete_status" bson:"complete_status"`
+	Priority       string             `json:"priority" bson:"priority"`
 	DateStart      int64              `json:"date_start" bson:"date_start"`
 	DateEnd        int64              `json:"date_end" bson:"date_end"`
 	Tag            []string           `json:"tag" bson:"tag"`
 	Image          string             `json:"image" bson:"image"`
+	SLA            HistorySLA         `json:"sla" bson:"sla"`
+	AssigneeID     string             `json:"assignee_id" bson:"assignee_id"`
+	AssigneeName   string             `json:"assignee_name" bson:"assignee_name"`
+	AssignedAt     int64              `json:"assigned_at" bson:"assigned_at"`
+	Watchers       []HistoryUser      `json:"watchers" bson:"watchers"`
 	Updates        []HistoryUpdate    `json:"updates" bson:"updates"`
 	Link           string             `json:"link" bson:"link"`
 }
@@ -39,6 +45,15 @@ type HistoryUpdate struct {
 	ProblemResolve string `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int    `json:"complete_status" bson:"complete_status"`
 	Vendor         bool   `json:"vendor" bson:"vendor"`
+	// CommentID dan Comment hanya terisi pada timeline unwind untuk baris yang berasal dari komentar
+	CommentID string `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Comment   string `json:"comment,omitempty" bson:"comment,omitempty"`
+}
+
+// HistoryUser user yang terkait dengan history sebagai assignee atau watcher
+type HistoryUser struct {
+	ID   string `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
 }
 
 // HistoryRequest user input
@@ -49,6 +64,7 @@ type HistoryRequest struct {
 	Problem        string   `json:"problem" bson:"problem"`
 	ProblemResolve string   `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int      `json:"complete_status" bson:"complete_status"`
+	Priority       string   `json:"priority" bson:"priority"`
 	DateStart      int64    `json:"date_start" bson:"date_start"`
 	DateEnd        int64    `json:"date_end" bson:"date_end"`
 	Tag            []string `json:"tag" bson:"tag"`
@@ -61,6 +77,7 @@ type HistoryResponse struct {
 	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	CreatedAt      int64              `json:"created_at" bson:"created_at"`
 	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	CreatedByID    string             `json:"created_by_id" bson:"created_by_id"`
 	UpdatedAt      int64              `json:"updated_at" bson:"updated_at"`
 	UpdatedBy      string             `json:"updated_by" bson:"updated_by"`
 	Category       string             `json:"category" bson:"category"`
@@ -71,10 +88,16 @@ type HistoryResponse struct {
 	Problem        string             `json:"problem" bson:"problem"`
 	ProblemResolve string             `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int                `json:"complete_status" bson:"complete_status"`
+	Priority       string             `json:"priority" bson:"priority"`
 	DateStart      int64              `json:"date_start" bson:"date_start"`
 	DateEnd        int64              `json:"date_end" bson:"date_end"`
 	Tag            []string           `json:"tag" bson:"tag"`
 	Image          string             `json:"image" bson:"image"`
+	SLA            HistorySLA         `json:"sla" bson:"sla"`
+	AssigneeID     string             `json:"assignee_id" bson:"assignee_id"`
+	AssigneeName   string             `json:"assignee_name" bson:"assignee_name"`
+	AssignedAt     int64              `json:"assigned_at" bson:"assigned_at"`
+	Watchers       []HistoryUser      `json:"watchers" bson:"watchers"`
 	Updates        []HistoryUpdate    `json:"updates" bson:"updates"`
 	Link           string             `json:"link" bson:"link"`
 }
@@ -95,10 +118,12 @@ type HistoryUnwindResponse struct {
 	Problem        string             `json:"problem" bson:"problem"`
 	ProblemResolve string             `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int                `json:"complete_status" bson:"complete_status"`
+	Priority       string             `json:"priority" bson:"priority"`
 	DateStart      int64              `json:"date_start" bson:"date_start"`
 	DateEnd        int64              `json:"date_end" bson:"date_end"`
 	Tag            []string           `json:"tag" bson:"tag"`
 	Image          string             `json:"image" bson:"image"`
+	SLA            HistorySLA         `json:"sla" bson:"sla"`
 	Updates        HistoryUpdate      `json:"updates" bson:"updates"`
 }
 
@@ -119,10 +144,16 @@ type HistoryResponseMin struct {
 	Problem        string             `json:"problem" bson:"problem"`
 	ProblemResolve string             `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus int                `json:"complete_status" bson:"complete_status"`
+	Priority       string             `json:"priority" bson:"priority"`
 	DateStart      int64              `json:"date_start" bson:"date_start"`
 	DateEnd        int64              `json:"date_end" bson:"date_end"`
 	Tag            []string           `json:"tag" bson:"tag"`
 	Image          string             `json:"image" bson:"image"`
+	SLA            HistorySLA         `json:"sla" bson:"sla"`
+	AssigneeID     string             `json:"assignee_id" bson:"assignee_id"`
+	AssigneeName   string             `json:"assignee_name" bson:"assignee_name"`
+	AssignedAt     int64              `json:"assigned_at" bson:"assigned_at"`
+	Watchers       []HistoryUser      `json:"watchers" bson:"watchers"`
 	Updates        []HistoryUpdate    `json:"-" bson:"updates"`
 }
 
@@ -141,6 +172,7 @@ type HistoryEdit struct {
 	Problem         string   `json:"problem" bson:"problem"`
 	ProblemResolve  string   `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus  int      `json:"complete_status" bson:"complete_status"`
+	Priority        string   `json:"priority" bson:"priority"`
 	DateEnd         int64    `json:"date_end" bson:"date_end"`
 	Tag             []string `json:"tag" bson:"tag"`
 	UpdatedAt       int64    `json:"updated_at" bson:"updated_at"`
@@ -155,6 +187,49 @@ type HistoryEditRequest struct {
 	Problem         string   `json:"problem" bson:"problem"`
 	ProblemResolve  string   `json:"problem_resolve" bson:"problem_resolve"`
 	CompleteStatus  int      `json:"complete_status" bson:"complete_status"`
+	Priority        string   `json:"priority" bson:"priority"`
 	DateEnd         int64    `json:"date_end" bson:"date_end"`
 	Tag             []string `json:"tag" bson:"tag"`
 }
+
+// HistoryAssignRequest user input, assignee_id kosong berarti melepas penugasan
+type HistoryAssignRequest struct {
+	AssigneeID string `json:"assignee_id"`
+}
+
+type HistoryWatcherRequest struct {
+	WatcherIDs []string `json:"watcher_ids"`
+}
+
+type HistoryAssign struct {
+	FilterID     primitive.ObjectID
+	FilterBranch string
+	AssigneeID   string
+	AssigneeName string
+	AssignedAt   int64
+}
+
+type HistoryWatcherEdit struct {
+	FilterID     primitive.ObjectID
+	FilterBranch string
+	Watchers     []HistoryUser
+}
+
+// WorkloadSummary beban insiden terbuka per teknisi pada branch
+type WorkloadSummary struct {
+	Branch      string               `json:"branch"`
+	GeneratedAt int64                `json:"generated_at"`
+	Technicians []TechnicianWorkload `json:"technicians"`
+	Unassigned  TechnicianWorkload   `json:"unassigned"`
+}
+
+// TechnicianWorkload ByStatus menggunakan nama status (enum.GetProgressString), ByAge umur insiden dihitung dari date_start
+type TechnicianWorkload struct {
+	UserID      string         `json:"user_id"`
+	Name        string         `json:"name"`
+	Total       int            `json:"total"`
+	ByStatus    map[string]int `json:"by_status"`
+	ByAge       map[string]int `json:"by_age"`
+	SLABreached int            `json:"sla_breached"`
+	OldestStart int64          `json:"oldest_start"`
+}
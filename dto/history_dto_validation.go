@@ -27,6 +27,13 @@ func (h HistoryRequest) Validate() error {
 		errorList = append(errorList, err.Error())
 	}
 
+	// prioritas boleh kosong, diisi default oleh service
+	if h.Priority != "" {
+		if err := priorityValidation(h.Priority); err != nil {
+			errorList = append(errorList, err.Error())
+		}
+	}
+
 	if h.Image != "" {
 		// cek ekstensi
 		fileExtension := strings.ToLower(filepath.Ext(h.Image))
@@ -47,10 +54,18 @@ func (h HistoryRequest) Validate() error {
 }
 
 func (h HistoryEditRequest) Validate() error {
-	return validation.ValidateStruct(&h,
+	if err := validation.ValidateStruct(&h,
 		validation.Field(&h.FilterTimestamp, validation.Required),
 		validation.Field(&h.Status, validation.Required),
 		validation.Field(&h.Problem, validation.Required),
 		validation.Field(&h.CompleteStatus, validation.Max(enum.HCompleteWithBA), validation.Min(-1)),
-	)
+	); err != nil {
+		return err
+	}
+
+	// prioritas kosong berarti tidak diubah
+	if h.Priority != "" {
+		return priorityValidation(h.Priority)
+	}
+	return nil
 }
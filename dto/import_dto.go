@@ -0,0 +1,26 @@
+package dto
+
+// ImportRowResult hasil validasi atau penulisan satu baris file import.
+// Action bernilai CREATE atau UPDATE sesuai keberadaan nomor inventaris di database
+type ImportRowResult struct {
+	Line            int    `json:"line"`
+	InventoryNumber string `json:"inventory_number"`
+	Name            string `json:"name"`
+	Action          string `json:"action"`
+	ID              string `json:"id,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ImportResult ringkasan import. Committed false berarti tidak ada data yang ditulis,
+// baik karena dry-run maupun karena masih ada baris yang tidak valid
+type ImportResult struct {
+	Category  string            `json:"category"`
+	Branch    string            `json:"branch"`
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
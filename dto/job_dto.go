@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/muchlist/risa_restfull/constants/branches"
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job jadwal yang dijalankan scheduler, Cron menggunakan format standar 5 field dengan zona waktu WITA.
+// Category opsional untuk PING-ALERT dan wajib untuk STOCK-RESTOCK
+type Job struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	CreatedAt    int64              `json:"created_at" bson:"created_at"`
+	CreatedBy    string             `json:"created_by" bson:"created_by"`
+	CreatedByID  string             `json:"created_by_id" bson:"created_by_id"`
+	UpdatedAt    int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy    string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID  string             `json:"updated_by_id" bson:"updated_by_id"`
+	Name         string             `json:"name" bson:"name"`
+	Type         string             `json:"type" bson:"type"`
+	Branch       string             `json:"branch" bson:"branch"`
+	Category     string             `json:"category" bson:"category"`
+	Cron         string             `json:"cron" bson:"cron"`
+	Enabled      bool               `json:"enabled" bson:"enabled"`
+	LastRunAt    int64              `json:"last_run_at" bson:"last_run_at"`
+	LastRunError string             `json:"last_run_error" bson:"last_run_error"`
+}
+
+type JobRequest struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Branch   string `json:"branch"`
+	Category string `json:"category"`
+	Cron     string `json:"cron"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type JobEdit struct {
+	FilterID    primitive.ObjectID
+	UpdatedAt   int64
+	UpdatedBy   string
+	UpdatedByID string
+	Name        string
+	Type        string
+	Branch      string
+	Category    string
+	Cron        string
+	Enabled     bool
+}
+
+type FilterJob struct {
+	FilterBranch string
+	FilterType   string
+	EnabledOnly  bool
+}
+
+// JobRun catatan setiap eksekusi job
+type JobRun struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	JobID       string             `json:"job_id" bson:"job_id"`
+	JobName     string             `json:"job_name" bson:"job_name"`
+	Type        string             `json:"type" bson:"type"`
+	Branch      string             `json:"branch" bson:"branch"`
+	Trigger     string             `json:"trigger" bson:"trigger"`
+	TriggeredBy string             `json:"triggered_by" bson:"triggered_by"`
+	StartedAt   int64              `json:"started_at" bson:"started_at"`
+	FinishedAt  int64              `json:"finished_at" bson:"finished_at"`
+	DurationMs  int64              `json:"duration_ms" bson:"duration_ms"`
+	Success     bool               `json:"success" bson:"success"`
+	Error       string             `json:"error" bson:"error"`
+	Result      string             `json:"result" bson:"result"`
+}
+
+type FilterJobRun struct {
+	FilterJobID  string
+	FilterBranch string
+}
+
+// DefaultJobs jadwal bawaan untuk satu branch. ping alert aktif untuk semua branch, vendor monthly hanya aktif
+// untuk BANJARMASIN sesuai jadwal lama, trash purge memakai masa retensi dari env TRASH_RETENTION_DAYS,
+// lifecycle alert aktif setiap senin pagi dengan rentang hari dari env LIFECYCLE_WARRANTY_DAYS,
+// data quality aktif setiap hari untuk memindai ip, nomor inventaris dan nama unit yang ganda
+func DefaultJobs(branch string, timeNow int64) []Job {
+	newJob := func(name, jobType, cron string, enabled bool) Job {
+		return Job{
+			CreatedAt:   timeNow,
+			CreatedBy:   "SYSTEM",
+			CreatedByID: "SYSTEM",
+			UpdatedAt:   timeNow,
+			UpdatedBy:   "SYSTEM",
+			UpdatedByID: "SYSTEM",
+			Name:        fmt.Sprintf("%s %s", name, branch),
+			Type:        jobType,
+			Branch:      branch,
+			Cron:        cron,
+			Enabled:     enabled,
+		}
+	}
+
+	return []Job{
+		newJob("ping alert", jobtype.PingAlert, "*/10 * * * *", true),
+		newJob("vendor monthly", jobtype.VendorMonthly, "0 1 1 * *", branch == branches.Banjarmasin),
+		newJob("trash purge", jobtype.TrashPurge, "0 2 * * *", true),
+		newJob("lifecycle alert", jobtype.LifecycleAlert, "0 8 * * 1", true),
+		newJob("data quality", jobtype.DataQuality, "0 3 * * *", true),
+		newJob("search reindex", jobtype.SearchReindex, "0 */6 * * *", true),
+		newJob("sla escalation", jobtype.SLAEscalation, "*/30 * * * *", true),
+	}
+}
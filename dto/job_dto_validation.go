@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+)
+
+func (j JobRequest) Validate() error {
+	if err := validation.ValidateStruct(&j,
+		validation.Field(&j.Name, validation.Required),
+		validation.Field(&j.Type, validation.Required),
+		validation.Field(&j.Branch, validation.Required),
+		validation.Field(&j.Cron, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	if err := jobTypeValidation(j.Type); err != nil {
+		return err
+	}
+
+	if err := cronValidation(j.Cron); err != nil {
+		return err
+	}
+
+	// stock restock membutuhkan category stock
+	if j.Type == jobtype.StockRestock {
+		if j.Category == "" {
+			return errors.New("category wajib diisi untuk job STOCK-RESTOCK")
+		}
+		if err := stockCategoryValidation(j.Category); err != nil {
+			return err
+		}
+	}
+
+	// category ping alert boleh kosong untuk semua category
+	if j.Type == jobtype.PingAlert && j.Category != "" {
+		if err := categoryValidation(j.Category); err != nil {
+			return err
+		}
+	}
+
+	return branchValidation(j.Branch)
+}
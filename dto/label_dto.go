@@ -0,0 +1,22 @@
+package dto
+
+// FilterLabel filter unit yang dicetak labelnya.
+// FilterSubCategory hanya digunakan untuk OTHER, FilterIDs membatasi ke unit tertentu saja
+type FilterLabel struct {
+	FilterBranch      string
+	FilterSubCategory string
+	FilterLocation    string
+	FilterDivision    string
+	FilterName        string
+	FilterIDs         []string
+}
+
+// ScanResponse hasil scan QR label. Unit berisi dto.Cctv, dto.Computer atau dto.Other sesuai Kind,
+// Cases adalah kasus yang masih terbuka pada gen_unit
+type ScanResponse struct {
+	Kind      string                 `json:"kind"`
+	Category  string                 `json:"category"`
+	Unit      interface{}            `json:"unit"`
+	Cases     []Case                 `json:"cases"`
+	Histories HistoryResponseMinList `json:"histories"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+// Lifecycle data pengadaan dan masa pakai aset, tanggal dalam unix detik dan 0 berarti belum diisi.
+// PurchaseDate dipisah dari Date lama yang artinya tidak konsisten antar cabang
+type Lifecycle struct {
+	PurchaseDate   int64  `json:"purchase_date" bson:"purchase_date"`
+	WarrantyExpiry int64  `json:"warranty_expiry" bson:"warranty_expiry"`
+	EndOfLife      int64  `json:"end_of_life" bson:"end_of_life"` // rencana penggantian
+	Vendor         string `json:"vendor" bson:"vendor"`
+	Cost           int64  `json:"cost" bson:"cost"` // rupiah
+}
+
+// LifecycleFlag penanda aset yang perlu perhatian
+type LifecycleFlag struct {
+	ID             string   `json:"id"`
+	Category       string   `json:"category"`
+	Name           string   `json:"name"`
+	Branch         string   `json:"branch"`
+	Location       string   `json:"location"`
+	OS             string   `json:"os,omitempty"`
+	WarrantyExpiry int64    `json:"warranty_expiry"`
+	OSEndOfLife    int64    `json:"os_end_of_life,omitempty"`
+	Reasons        []string `json:"reasons"`
+}
+
+// LifecycleReportGroup jumlah aset per kelompok umur dan status
+type LifecycleReportGroup struct {
+	Category  string           `json:"category"`
+	Total     int              `json:"total"`
+	TotalCost int64            `json:"total_cost"`
+	ByAge     map[string]int   `json:"by_age"`
+	ByStatus  map[string]int   `json:"by_status"`
+	CostByAge map[string]int64 `json:"cost_by_age"`
+}
+
+type LifecycleReport struct {
+	Branch      string                 `json:"branch"`
+	GeneratedAt int64                  `json:"generated_at"`
+	Groups      []LifecycleReportGroup `json:"groups"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (l Lifecycle) Validate() error {
+	if err := validation.ValidateStruct(&l,
+		validation.Field(&l.PurchaseDate, validation.Min(int64(0))),
+		validation.Field(&l.WarrantyExpiry, validation.Min(int64(0))),
+		validation.Field(&l.EndOfLife, validation.Min(int64(0))),
+		validation.Field(&l.Cost, validation.Min(int64(0))),
+	); err != nil {
+		return err
+	}
+
+	if l.PurchaseDate != 0 && l.WarrantyExpiry != 0 && l.WarrantyExpiry < l.PurchaseDate {
+		return errors.New("warranty_expiry tidak boleh sebelum purchase_date")
+	}
+	if l.PurchaseDate != 0 && l.EndOfLife != 0 && l.EndOfLife < l.PurchaseDate {
+		return errors.New("end_of_life tidak boleh sebelum purchase_date")
+	}
+	return nil
+}
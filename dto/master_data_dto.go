@@ -0,0 +1,54 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MasterData satu nilai pilihan yang dapat dikelola admin, misalnya branch, lokasi atau tipe cctv.
+// Branch hanya digunakan oleh LOCATION, kosong berarti lokasi tersedia di semua cabang.
+// Lang hanya digunakan oleh BRANCH sebagai bahasa header export (id / en).
+// kombinasi Kind, Value dan Branch bersifat unik
+type MasterData struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	CreatedAt   int64              `json:"created_at" bson:"created_at"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedByID string             `json:"created_by_id" bson:"created_by_id"`
+	UpdatedAt   int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy   string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID string             `json:"updated_by_id" bson:"updated_by_id"`
+	Kind        string             `json:"kind" bson:"kind"`
+	Value       string             `json:"value" bson:"value"`
+	Branch      string             `json:"branch" bson:"branch"`
+	Lang        string             `json:"lang,omitempty" bson:"lang,omitempty"`
+	Order       int                `json:"order" bson:"order"`
+}
+
+type MasterDataRequest struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Branch string `json:"branch"`
+	Lang   string `json:"lang"`
+	Order  int    `json:"order"`
+}
+
+// MasterDataEditRequest kind tidak dapat diubah, nilai lama pada dokumen lain tidak ikut berubah
+type MasterDataEditRequest struct {
+	Value  string `json:"value"`
+	Branch string `json:"branch"`
+	Lang   string `json:"lang"`
+	Order  int    `json:"order"`
+}
+
+type MasterDataEdit struct {
+	FilterID    primitive.ObjectID
+	UpdatedAt   int64
+	UpdatedBy   string
+	UpdatedByID string
+	Value       string
+	Branch      string
+	Lang        string
+	Order       int
+}
+
+type FilterMasterData struct {
+	FilterKind   string
+	FilterBranch string
+}
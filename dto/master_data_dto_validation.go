@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/muchlist/risa_restfull/constants/headerlang"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+)
+
+func (m MasterDataRequest) Validate() error {
+	if err := validation.ValidateStruct(&m,
+		validation.Field(&m.Kind, validation.Required),
+		validation.Field(&m.Value, validation.Required, validation.Length(1, 60)),
+	); err != nil {
+		return err
+	}
+
+	if !sfunc.InSlice(strings.ToUpper(m.Kind), masterkind.GetKindAvailable()) {
+		return fmt.Errorf("kind yang dimasukkan tidak tersedia. gunakan %s", masterkind.GetKindAvailable())
+	}
+
+	return masterValueValidation(strings.ToUpper(m.Kind), m.Value, m.Branch, strings.ToLower(m.Lang))
+}
+
+func (m MasterDataEditRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Value, validation.Required, validation.Length(1, 60)),
+	)
+}
+
+// masterValueValidation branch hanya untuk LOCATION dan harus terdaftar, lang hanya untuk BRANCH,
+// nilai PC_RAM dan PC_HDD berupa angka
+func masterValueValidation(kind string, value string, branch string, lang string) error {
+	if branch != "" {
+		if kind != masterkind.Location {
+			return errors.New("branch hanya dapat diisi untuk kind LOCATION")
+		}
+		if err := branchValidation(strings.ToUpper(branch)); err != nil {
+			return err
+		}
+	}
+
+	if lang != "" {
+		if kind != masterkind.Branch {
+			return errors.New("lang hanya dapat diisi untuk kind BRANCH")
+		}
+		if !sfunc.InSlice(lang, headerlang.GetHeaderLangAvailable()) {
+			return fmt.Errorf("lang yang dimasukkan tidak tersedia. gunakan %s", headerlang.GetHeaderLangAvailable())
+		}
+	}
+
+	if sfunc.InSlice(kind, masterkind.GetNumericKind()) {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("nilai %s harus berupa angka", kind)
+		}
+	}
+	return nil
+}
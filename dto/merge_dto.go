@@ -0,0 +1,54 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// UnitRefReplace mengganti referensi unit lama dengan unit baru pada dokumen lain di satu branch
+type UnitRefReplace struct {
+	Branch string
+	FromID string
+	ToID   string
+	ToName string
+}
+
+// UnitTagImageEdit tag gabungan dan image untuk unit yang dipertahankan saat penggabungan
+type UnitTagImageEdit struct {
+	ID           primitive.ObjectID
+	FilterBranch string
+	Tag          []string
+	Image        string
+	UpdatedAt    int64
+	UpdatedBy    string
+	UpdatedByID  string
+}
+
+// MergeUnit ringkasan unit yang digabungkan
+type MergeUnit struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	IP              string   `json:"ip"`
+	InventoryNumber string   `json:"inventory_number"`
+	Tag             []string `json:"tag"`
+	Image           string   `json:"image"`
+}
+
+// MergeReference jumlah dokumen pada collection yang referensinya diganti
+type MergeReference struct {
+	Collection string `json:"collection"`
+	Count      int64  `json:"count"`
+}
+
+// MergePreview daftar perubahan saat unit Loser digabungkan ke unit Winner,
+// Executed false berarti belum ada perubahan yang disimpan
+type MergePreview struct {
+	Category   string           `json:"category"`
+	Branch     string           `json:"branch"`
+	Loser      MergeUnit        `json:"loser"`
+	Winner     MergeUnit        `json:"winner"`
+	Histories  int              `json:"histories"`
+	OpenCases  []Case           `json:"open_cases"`
+	TagsAdded  []string         `json:"tags_added"`
+	ImageMoved bool             `json:"image_moved"`
+	Downlinks  int              `json:"downlinks"`
+	References []MergeReference `json:"references"`
+	Executed   bool             `json:"executed"`
+}
@@ -13,6 +13,10 @@ type Other struct {
 	UpdatedByID string             `json:"updated_by_id" bson:"updated_by_id"`
 	Branch      string             `json:"branch" bson:"branch"`
 	Disable     bool               `json:"disable" bson:"disable"`
+	Deleted     bool               `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt   int64              `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy   string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedByID string             `json:"deleted_by_id,omitempty" bson:"deleted_by_id,omitempty"`
 
 	Name        string `json:"name" bson:"name"`
 	Detail      string `json:"detail" bson:"detail"`
@@ -32,6 +36,11 @@ type Other struct {
 	Note            string   `json:"note" bson:"note"`
 	Extra           GenExtra `json:"extra" bson:"extra,omitempty"`
 	DisVendor       bool     `json:"dis_vendor" bson:"dis_vendor"` // if true, disable from report vendor
+
+	Lifecycle Lifecycle `json:"lifecycle" bson:"lifecycle"`
+	Geo       *GeoPoint `json:"geo,omitempty" bson:"geo,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
 }
 
 // OtherRequest user input, id tidak diinput oleh user
@@ -54,6 +63,12 @@ type OtherRequest struct {
 	Type      string `json:"type" bson:"type"`
 	Note      string `json:"note" bson:"note"`
 	DisVendor bool   `json:"dis_vendor" bson:"dis_vendor"` // if true, disable from report vendor
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type OtherEdit struct {
@@ -81,6 +96,11 @@ type OtherEdit struct {
 	Type      string
 	Note      string
 	DisVendor bool `json:"dis_vendor" bson:"dis_vendor"` // if true, disable from report vendor
+
+	Lifecycle Lifecycle
+	Geo       *GeoPoint
+
+	CustomFields CustomFields
 }
 
 // OtherEditRequest user input
@@ -104,6 +124,12 @@ type OtherEditRequest struct {
 	Type      string `json:"type" bson:"type"`
 	Note      string `json:"note" bson:"note"`
 	DisVendor bool   `json:"dis_vendor" bson:"dis_vendor"` // if true, disable from report vendor
+
+	Lifecycle    Lifecycle    `json:"lifecycle" bson:"lifecycle"`
+	CustomFields CustomFields `json:"custom_fields" bson:"custom_fields"`
+
+	// ForceDuplicate tetap menyimpan meskipun ip / nomor inventaris sudah dipakai, hanya untuk admin
+	ForceDuplicate bool `json:"force_duplicate" bson:"-"`
 }
 
 type OtherResponseMinList []OtherResponseMin
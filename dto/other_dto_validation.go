@@ -26,6 +26,16 @@ func (c OtherRequest) Validate() error {
 		errorList = append(errorList, err.Error())
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
 	if len(errorList) != 0 {
 		errorString := ""
 		for _, v := range errorList {
@@ -59,6 +69,16 @@ func (c OtherEditRequest) Validate() error {
 		errorList = append(errorList, err.Error())
 	}
 
+	// validate lifecycle
+	if err := c.Lifecycle.Validate(); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
+	// validate koordinat
+	if err := coordinateValidation(c.LocationLat, c.LocationLon); err != nil {
+		errorList = append(errorList, err.Error())
+	}
+
 	if len(errorList) != 0 {
 		errorString := ""
 		for _, v := range errorList {
@@ -0,0 +1,18 @@
+package dto
+
+// PageRequest parameter pagination berbasis cursor.
+// Cursor kosong berarti halaman pertama, Size 0 berarti memakai ukuran default masing-masing endpoint.
+// All digunakan pemanggil internal (laporan, pengecekan) yang membutuhkan seluruh dokumen tanpa batas
+type PageRequest struct {
+	Cursor string
+	Size   int64
+	All    bool
+}
+
+// PageInfo informasi pagination yang disertakan pada response daftar
+type PageInfo struct {
+	Size       int64  `json:"size"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor"`
+	PrevCursor string `json:"prev_cursor"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// PingSample satu hasil ping perangkat, disimpan pada time-series collection pingHistory.
+// Time bertipe time.Time karena time-series collection mewajibkan timeField bertipe date
+type PingSample struct {
+	Time   time.Time `json:"time" bson:"time"`
+	Meta   PingMeta  `json:"meta" bson:"meta"`
+	Code   int       `json:"code" bson:"code"`
+	Status string    `json:"status" bson:"status"`
+}
+
+// PingMeta metaField dari PingSample, UnitID sama dengan ID gen_unit
+type PingMeta struct {
+	UnitID   string `json:"unit_id" bson:"unit_id"`
+	Branch   string `json:"branch" bson:"branch"`
+	Category string `json:"category" bson:"category"`
+}
+
+type PingSampleFilter struct {
+	UnitID   string
+	Branch   string
+	Category string
+	Start    int64
+	End      int64
+}
+
+// UptimeStat hasil perhitungan ketersediaan perangkat, satuan waktu dalam detik.
+// Uptime dalam persen dihitung dari waktu yang teramati saja (ObservedSeconds)
+type UptimeStat struct {
+	Samples         int     `json:"samples"`
+	ObservedSeconds int64   `json:"observed_seconds"`
+	UpSeconds       int64   `json:"up_seconds"`
+	DownSeconds     int64   `json:"down_seconds"`
+	Uptime          float64 `json:"uptime"`
+	Failures        int     `json:"failures"`
+	MTBF            int64   `json:"mtbf"`
+	MTTR            int64   `json:"mttr"`
+}
+
+type UptimeResponse struct {
+	UnitID   string `json:"unit_id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Branch   string `json:"branch"`
+	Start    int64  `json:"start"`
+	End      int64  `json:"end"`
+	UptimeStat
+}
+
+type UptimeBranchSummary struct {
+	Branch    string `json:"branch"`
+	Category  string `json:"category"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	UnitCount int    `json:"unit_count"`
+	UptimeStat
+	Units []UptimeResponse `json:"units"`
+}
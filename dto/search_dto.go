@@ -0,0 +1,27 @@
+package dto
+
+// FilterSearch Types dapat berisi beberapa tipe dipisah koma, kosong berarti semua tipe
+type FilterSearch struct {
+	Query  string
+	Branch string
+	Types  string
+	Limit  int
+}
+
+type SearchResult struct {
+	Query string      `json:"query"`
+	Total int         `json:"total"`
+	Hits  []SearchHit `json:"hits"`
+}
+
+// SearchHit Extra berisi field tambahan sesuai tipe, misalnya category dan ip untuk UNIT
+type SearchHit struct {
+	Type    string            `json:"type"`
+	ID      string            `json:"id"`
+	Branch  string            `json:"branch"`
+	Title   string            `json:"title"`
+	Snippet string            `json:"snippet"`
+	Time    int64             `json:"time"`
+	Score   float64           `json:"score"`
+	Extra   map[string]string `json:"extra"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// SLAPolicy target waktu respon dan penyelesaian insiden per category dan priority dalam menit.
+// Category kosong berarti berlaku untuk semua category pada priority tersebut
+type SLAPolicy struct {
+	ID              primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UpdatedAt       int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy       string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID     string             `json:"updated_by_id" bson:"updated_by_id"`
+	Category        string             `json:"category" bson:"category"`
+	Priority        string             `json:"priority" bson:"priority"`
+	ResponseMinutes int64              `json:"response_minutes" bson:"response_minutes"`
+	ResolveMinutes  int64              `json:"resolve_minutes" bson:"resolve_minutes"`
+}
+
+type SLAPolicyRequest struct {
+	Category        string `json:"category"`
+	Priority        string `json:"priority"`
+	ResponseMinutes int64  `json:"response_minutes"`
+	ResolveMinutes  int64  `json:"resolve_minutes"`
+}
+
+// HistorySLA status SLA yang disimpan pada history, diperbarui saat history diedit dan oleh job SLA-ESCALATION.
+// EscalationLevel 1 berarti pelanggaran respon sudah dieskalasi, 2 pelanggaran penyelesaian
+type HistorySLA struct {
+	ResponseDue      int64  `json:"response_due" bson:"response_due"`
+	ResolveDue       int64  `json:"resolve_due" bson:"resolve_due"`
+	RespondedAt      int64  `json:"responded_at" bson:"responded_at"`
+	ResolvedAt       int64  `json:"resolved_at" bson:"resolved_at"`
+	ResponseBreached bool   `json:"response_breached" bson:"response_breached"`
+	ResolveBreached  bool   `json:"resolve_breached" bson:"resolve_breached"`
+	State            string `json:"state" bson:"state"`
+	EscalationLevel  int    `json:"escalation_level" bson:"escalation_level"`
+	EscalatedAt      int64  `json:"escalated_at" bson:"escalated_at"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (s SLAPolicyRequest) Validate() error {
+	if err := validation.ValidateStruct(&s,
+		validation.Field(&s.Priority, validation.Required),
+		validation.Field(&s.ResponseMinutes, validation.Required, validation.Min(int64(1))),
+		validation.Field(&s.ResolveMinutes, validation.Required, validation.Min(int64(1))),
+	); err != nil {
+		return err
+	}
+
+	if s.ResolveMinutes < s.ResponseMinutes {
+		return errors.New("resolve_minutes tidak boleh lebih kecil dari response_minutes")
+	}
+
+	// validate category, boleh kosong untuk semua category
+	if s.Category != "" {
+		if err := categoryValidation(s.Category); err != nil {
+			return err
+		}
+	}
+
+	return priorityValidation(s.Priority)
+}
@@ -13,6 +13,10 @@ type Stock struct {
 	UpdatedByID   string             `json:"updated_by_id" bson:"updated_by_id"`
 	Branch        string             `json:"branch" bson:"branch"`
 	Disable       bool               `json:"disable" bson:"disable"`
+	Deleted       bool               `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt     int64              `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy     string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedByID   string             `json:"deleted_by_id,omitempty" bson:"deleted_by_id,omitempty"`
 	Name          string             `json:"name" bson:"name"`
 	StockCategory string             `json:"stock_category" bson:"stock_category"`
 	Unit          string             `json:"unit" bson:"unit"`
@@ -0,0 +1,76 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Transfer permintaan pemindahan aset dari FromBranch ke ToBranch.
+// Permintaan dibuat oleh cabang asal dan diterima / ditolak oleh cabang tujuan
+type Transfer struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt    int64              `json:"created_at" bson:"created_at"`
+	CreatedBy    string             `json:"created_by" bson:"created_by"`
+	CreatedByID  string             `json:"created_by_id" bson:"created_by_id"`
+	UpdatedAt    int64              `json:"updated_at" bson:"updated_at"`
+	UpdatedBy    string             `json:"updated_by" bson:"updated_by"`
+	UpdatedByID  string             `json:"updated_by_id" bson:"updated_by_id"`
+	FromBranch   string             `json:"from_branch" bson:"from_branch"`
+	ToBranch     string             `json:"to_branch" bson:"to_branch"`
+	Units        []TransferUnit     `json:"units" bson:"units"`
+	Reason       string             `json:"reason" bson:"reason"`
+	State        string             `json:"state" bson:"state"`
+	DecidedAt    int64              `json:"decided_at" bson:"decided_at"`
+	DecidedBy    string             `json:"decided_by" bson:"decided_by"`
+	DecidedByID  string             `json:"decided_by_id" bson:"decided_by_id"`
+	DecisionNote string             `json:"decision_note" bson:"decision_note"`
+}
+
+// TransferUnit unit yang dipindahkan, Category berisi CCTV, PC atau sub category other
+type TransferUnit struct {
+	ID       string `json:"id" bson:"id"`
+	Category string `json:"category" bson:"category"`
+	Name     string `json:"name" bson:"name"`
+}
+
+type TransferRequest struct {
+	ToBranch string                `json:"to_branch"`
+	Units    []TransferUnitRequest `json:"units"`
+	Reason   string                `json:"reason"`
+}
+
+type TransferUnitRequest struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+}
+
+type TransferDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// TransferDecide mengubah state transfer yang masih PENDING,
+// isi FilterFromBranch atau FilterToBranch sesuai pihak yang berhak memutuskan
+type TransferDecide struct {
+	FilterID         primitive.ObjectID
+	FilterFromBranch string
+	FilterToBranch   string
+	State            string
+	DecidedAt        int64
+	DecidedBy        string
+	DecidedByID      string
+	DecisionNote     string
+}
+
+// FilterTransfer FilterBranch mencocokkan cabang asal maupun tujuan
+type FilterTransfer struct {
+	FilterBranch  string
+	FilterState   string
+	FilterUnitIDs []string
+}
+
+// BranchMove memindahkan dokumen unit dari FromBranch ke ToBranch saat transfer diterima
+type BranchMove struct {
+	ID          primitive.ObjectID
+	FromBranch  string
+	ToBranch    string
+	UpdatedAt   int64
+	UpdatedBy   string
+	UpdatedByID string
+}
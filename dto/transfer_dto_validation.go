@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+)
+
+// maxTransferUnit batas jumlah unit dalam satu permintaan transfer
+const maxTransferUnit = 50
+
+func (t TransferRequest) Validate() error {
+	if err := validation.ValidateStruct(&t,
+		validation.Field(&t.ToBranch, validation.Required),
+		validation.Field(&t.Units, validation.Required, validation.Length(1, maxTransferUnit)),
+		validation.Field(&t.Reason, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	if err := branchValidation(t.ToBranch); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(t.Units))
+	for _, unit := range t.Units {
+		if err := unit.Validate(); err != nil {
+			return err
+		}
+		if seen[unit.ID] {
+			return fmt.Errorf("unit %s dimasukkan lebih dari sekali", unit.ID)
+		}
+		seen[unit.ID] = true
+	}
+	return nil
+}
+
+func (t TransferUnitRequest) Validate() error {
+	if err := validation.ValidateStruct(&t,
+		validation.Field(&t.ID, validation.Required),
+		validation.Field(&t.Category, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// stock tidak memiliki gen_unit sehingga tidak bisa ditransfer
+	if t.Category == category.Cctv || t.Category == category.PC {
+		return nil
+	}
+	if !masterdata.IsAvailable(masterkind.SubCategory, t.Category) {
+		return errors.New("category unit transfer harus CCTV, PC atau sub category other")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// TrashItem ringkasan dokumen yang berada di tempat sampah.
+// Entity adalah nama collection asal dokumen (cctv, computer, other, stock)
+type TrashItem struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Entity      string             `json:"entity" bson:"-"`
+	Name        string             `json:"name" bson:"name"`
+	Branch      string             `json:"branch" bson:"branch"`
+	SubCategory string             `json:"sub_category,omitempty" bson:"sub_category,omitempty"`
+	DeletedAt   int64              `json:"deleted_at" bson:"deleted_at"`
+	DeletedBy   string             `json:"deleted_by" bson:"deleted_by"`
+	DeletedByID string             `json:"deleted_by_id" bson:"deleted_by_id"`
+}
+
+type TrashRestore struct {
+	Entity       string
+	ID           primitive.ObjectID
+	FilterBranch string
+}
+
+// TrashPurge menghapus permanen dokumen yang masuk tempat sampah sebelum DeletedBefore
+type TrashPurge struct {
+	FilterBranch  string
+	DeletedBefore int64
+}
+
+type FilterTrash struct {
+	FilterEntity string
+	FilterBranch string
+}
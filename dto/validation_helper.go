@@ -2,23 +2,25 @@ package dto
 
 import (
 	"fmt"
-	"github.com/muchlist/risa_restfull/constants/branches"
+	"github.com/muchlist/risa_restfull/constants/apiscope"
 	"github.com/muchlist/risa_restfull/constants/category"
-	"github.com/muchlist/risa_restfull/constants/checktype"
-	"github.com/muchlist/risa_restfull/constants/hwlist"
-	"github.com/muchlist/risa_restfull/constants/location"
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/constants/priority"
 	"github.com/muchlist/risa_restfull/constants/roles"
-	"github.com/muchlist/risa_restfull/constants/stocklist"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"github.com/robfig/cron/v3"
 )
 
 func locationValidation(loc string) error {
-	if !sfunc.InSlice(loc, location.GetLocationAvailable()) {
-		return fmt.Errorf("lokasi yang dimasukkan tidak tersedia. gunakan %s", location.GetLocationAvailable())
+	if !masterdata.IsAvailable(masterkind.Location, loc) {
+		return fmt.Errorf("lokasi yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.Location))
 	}
 	return nil
 }
 
+// categoryValidation category utama tidak termasuk master data karena masing-masing memiliki collection sendiri
 func categoryValidation(cat string) error {
 	if !sfunc.InSlice(cat, category.GetCategoryAvailable()) {
 		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", category.GetCategoryAvailable())
@@ -27,8 +29,8 @@ func categoryValidation(cat string) error {
 }
 
 func subCategoryValidation(cat string) error {
-	if !sfunc.InSlice(cat, category.GetSubCategoryAvailable()) {
-		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", category.GetSubCategoryAvailable())
+	if !masterdata.IsAvailable(masterkind.SubCategory, cat) {
+		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.SubCategory))
 	}
 	return nil
 }
@@ -43,44 +45,73 @@ func roleValidation(rolesIn []string) error {
 }
 
 func branchValidation(branch string) error {
-	if !sfunc.InSlice(branch, branches.GetBranchesAvailable()) {
-		return fmt.Errorf("branch yang dimasukkan tidak tersedia. gunakan %s", branches.GetBranchesAvailable())
+	if !masterdata.IsAvailable(masterkind.Branch, branch) {
+		return fmt.Errorf("branch yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.Branch))
 	}
 
 	return nil
 }
 
 func stockCategoryValidation(stockCategory string) error {
-	if !sfunc.InSlice(stockCategory, stocklist.GetStockCategoryAvailable()) {
-		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", stocklist.GetStockCategoryAvailable())
+	if !masterdata.IsAvailable(masterkind.StockCategory, stockCategory) {
+		return fmt.Errorf("category yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.StockCategory))
 	}
 	return nil
 }
 
 func checkTypeValidation(checkType string) error {
-	if !sfunc.InSlice(checkType, checktype.GetCheckTypeAvailable()) {
-		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", checktype.GetCheckTypeAvailable())
+	if !masterdata.IsAvailable(masterkind.CheckType, checkType) {
+		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.CheckType))
 	}
 	return nil
 }
 
 func cctvTypeValidation(cctvType string) error {
-	if !sfunc.InSlice(cctvType, hwlist.GetCctvTypeAvailable()) {
-		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", hwlist.GetCctvTypeAvailable())
+	if !masterdata.IsAvailable(masterkind.CctvType, cctvType) {
+		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.CctvType))
 	}
 	return nil
 }
 
 func computerTypeValidation(computerType string) error {
-	if !sfunc.InSlice(computerType, hwlist.GetComputerTypeAvailable()) {
-		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", hwlist.GetComputerTypeAvailable())
+	if !masterdata.IsAvailable(masterkind.ComputerType, computerType) {
+		return fmt.Errorf("tipe yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.ComputerType))
 	}
 	return nil
 }
 
 func divisionValidation(division string) error {
-	if !sfunc.InSlice(division, location.GetDivisionAvailable()) {
-		return fmt.Errorf("divisi yang dimasukkan tidak tersedia. gunakan %s", location.GetDivisionAvailable())
+	if !masterdata.IsAvailable(masterkind.Division, division) {
+		return fmt.Errorf("divisi yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.Division))
+	}
+	return nil
+}
+
+func priorityValidation(prior string) error {
+	if !sfunc.InSlice(prior, priority.GetPriorityAvailable()) {
+		return fmt.Errorf("prioritas yang dimasukkan tidak tersedia. gunakan %s", priority.GetPriorityAvailable())
+	}
+	return nil
+}
+
+func apiScopeValidation(scopes []string) error {
+	if !sfunc.ValueInSliceIsAvailable(scopes, apiscope.GetScopeAvailable()) {
+		return fmt.Errorf("scope yang dimasukkan tidak tersedia. gunakan %s", apiscope.GetScopeAvailable())
+	}
+	return nil
+}
+
+func jobTypeValidation(jobType string) error {
+	if !sfunc.InSlice(jobType, jobtype.GetJobTypeAvailable()) {
+		return fmt.Errorf("tipe job yang dimasukkan tidak tersedia. gunakan %s", jobtype.GetJobTypeAvailable())
+	}
+	return nil
+}
+
+// cronValidation menggunakan parser yang sama dengan scheduler (5 field, menit jam tanggal bulan hari)
+func cronValidation(expression string) error {
+	if _, err := cron.ParseStandard(expression); err != nil {
+		return fmt.Errorf("cron tidak valid (contoh \"0 1 1 * *\") : %s", err.Error())
 	}
 	return nil
 }
@@ -14,6 +14,7 @@ require (
 	github.com/joho/godotenv v1.3.0
 	github.com/klauspost/compress v1.11.7 // indirect
 	github.com/muchlist/erru_utils_go v1.0.4
+	github.com/robfig/cron/v3 v3.0.1
 	//github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/spf13/cast v1.4.1
 	github.com/stretchr/testify v1.7.0
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"strings"
+)
+
+func NewAlertHandler(alertService service.AlertServiceAssumer) *alertHandler {
+	return &alertHandler{
+		service: alertService,
+	}
+}
+
+type alertHandler struct {
+	service service.AlertServiceAssumer
+}
+
+// FindAlert menampilkan list perpindahan state alert
+// Query [branch, category, unit_id, state, unack, start, end, limit, cursor]
+func (a *alertHandler) FindAlert(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = claims.Branch
+	}
+
+	filter := dto.FilterAlertLog{
+		FilterBranch:    branch,
+		FilterCategory:  c.Query("category"),
+		FilterUnitID:    c.Query("unit_id"),
+		FilterToState:   strings.ToUpper(c.Query("state")),
+		FilterUnAckOnly: c.Query("unack") == "true",
+		FilterStart:     int64(stringToInt(c.Query("start"))),
+		FilterEnd:       int64(stringToInt(c.Query("end"))),
+	}
+
+	alertList, page, apiErr := a.service.FindAlertLog(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": alertList, "page": page})
+}
+
+// Acknowledge menandai alert sudah diketahui oleh user
+func (a *alertHandler) Acknowledge(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	var req dto.AlertAckRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	alertAck, apiErr := a.service.AcknowledgeAlert(c.Context(), *claims, id, req.Note)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": alertAck})
+}
+
+// UpsertRule membuat atau mengganti rule alert untuk branch dan category
+func (a *alertHandler) UpsertRule(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.AlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	rule, apiErr := a.service.UpsertRule(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": rule})
+}
+
+// DeleteRule menghapus rule alert, unit akan kembali memakai rule yang lebih umum
+func (a *alertHandler) DeleteRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	apiErr := a.service.DeleteRule(c.Context(), id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("rule alert %s berhasil dihapus", id)})
+}
+
+// FindRule menampilkan semua rule alert
+func (a *alertHandler) FindRule(c *fiber.Ctx) error {
+	rules, apiErr := a.service.FindRule(c.Context())
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": rules})
+}
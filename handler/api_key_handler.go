@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewApiKeyHandler(apiKeyService service.ApiKeyServiceAssumer) *apiKeyHandler {
+	return &apiKeyHandler{
+		service: apiKeyService,
+	}
+}
+
+type apiKeyHandler struct {
+	service service.ApiKeyServiceAssumer
+}
+
+// Insert membuat api key baru, key plaintext hanya ditampilkan sekali
+func (a *apiKeyHandler) Insert(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.ApiKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	keyCreated, apiErr := a.service.InsertApiKey(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": keyCreated})
+}
+
+// Rotate mengganti key lama dengan key baru, key baru hanya ditampilkan sekali
+func (a *apiKeyHandler) Rotate(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	keyRotated, apiErr := a.service.RotateApiKey(c.Context(), *claims, id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": keyRotated})
+}
+
+// Revoke menonaktifkan api key secara permanen
+func (a *apiKeyHandler) Revoke(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	keyRevoked, apiErr := a.service.RevokeApiKey(c.Context(), *claims, id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": keyRevoked})
+}
+
+// Find menampilkan list api key. Query branch
+func (a *apiKeyHandler) Find(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+
+	keyList, apiErr := a.service.FindApiKey(c.Context(), branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": keyList})
+}
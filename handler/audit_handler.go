@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+)
+
+func NewAuditHandler(auditService service.AuditServiceAssumer) *auditHandler {
+	return &auditHandler{
+		service: auditService,
+	}
+}
+
+type auditHandler struct {
+	service service.AuditServiceAssumer
+}
+
+// Find menampilkan riwayat perubahan data
+// Query [entity, entity_id, actor, branch, action, field, start, end, limit, cursor]
+func (a *auditHandler) Find(c *fiber.Ctx) error {
+	filter := dto.FilterAudit{
+		FilterEntity:   c.Query("entity"),
+		FilterEntityID: c.Query("entity_id"),
+		FilterActor:    c.Query("actor"),
+		FilterBranch:   c.Query("branch"),
+		FilterAction:   c.Query("action"),
+		FilterField:    c.Query("field"),
+		FilterStart:    int64(stringToInt(c.Query("start"))),
+		FilterEnd:      int64(stringToInt(c.Query("end"))),
+	}
+
+	auditList, page, apiErr := a.service.FindAudit(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": auditList, "page": page})
+}
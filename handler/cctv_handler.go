@@ -62,7 +62,7 @@ func (ctv *cctvHandler) GetCctv(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list cctv
-// Query [branch, name, ip, location, disable]
+// Query [branch, name, ip, location, disable, limit, cursor, cf_<key>]
 func (ctv *cctvHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	name := c.Query("name")
@@ -78,14 +78,15 @@ func (ctv *cctvHandler) Find(c *fiber.Ctx) error {
 	}
 
 	filterA := dto.FilterBranchLocIPNameDisable{
-		FilterBranch:   branch,
-		FilterLocation: location,
-		FilterIP:       ip,
-		FilterName:     name,
-		FilterDisable:  disable,
+		FilterBranch:       branch,
+		FilterLocation:     location,
+		FilterIP:           ip,
+		FilterName:         name,
+		FilterDisable:      disable,
+		FilterCustomFields: customFieldQuery(c),
 	}
 
-	cctvList, generalList, apiErr := ctv.service.FindCctv(c.Context(), filterA)
+	cctvList, generalList, page, apiErr := ctv.service.FindCctv(c.Context(), filterA, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
@@ -93,7 +94,7 @@ func (ctv *cctvHandler) Find(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"error": nil, "data": fiber.Map{
 		"cctv_list":  cctvList,
 		"extra_list": generalList,
-	}})
+	}, "page": page})
 }
 
 // DisableCctv menghilangkan cctv dari list
@@ -192,10 +193,11 @@ func (ctv *cctvHandler) UploadImage(c *fiber.Ctx) error {
 }
 
 func (ctv *cctvHandler) Merge(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
 	cctvID1 := c.Params("cctv1")
 	cctvID2 := c.Params("cctv2")
 
-	message, apiErr := ctv.service.MergeCctv(c.Context(), cctvID1, cctvID2)
+	message, apiErr := ctv.service.MergeCctv(c.Context(), *claims, cctvID1, cctvID2)
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
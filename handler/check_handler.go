@@ -59,23 +59,21 @@ func (ch *checkHandler) GetCheck(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list check
-// Query [branch, start, end, limit]
+// Query [branch, start, end, limit, cursor]
 func (ch *checkHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	start := stringToInt(c.Query("start"))
 	end := stringToInt(c.Query("end"))
-	limit := stringToInt(c.Query("limit"))
 
-	checkList, apiErr := ch.service.FindCheck(c.Context(), branch, dto.FilterTimeRangeLimit{
+	checkList, page, apiErr := ch.service.FindCheck(c.Context(), branch, dto.FilterTimeRangeLimit{
 		FilterStart: int64(start),
 		FilterEnd:   int64(end),
-		Limit:       int64(limit),
-	})
+	}, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	return c.JSON(fiber.Map{"error": nil, "data": checkList})
+	return c.JSON(fiber.Map{"error": nil, "data": checkList, "page": page})
 }
 
 func (ch *checkHandler) Delete(c *fiber.Ctx) error {
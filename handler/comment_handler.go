@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewCommentHandler(commentService service.CommentServiceAssumer) *commentHandler {
+	return &commentHandler{
+		service: commentService,
+	}
+}
+
+type commentHandler struct {
+	service service.CommentServiceAssumer
+}
+
+// Insert menambahkan komentar pada history, isi parent_id untuk membalas komentar
+func (cm *commentHandler) Insert(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	historyID := c.Params("id")
+
+	var req dto.CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	comment, apiErr := cm.service.InsertComment(c.Context(), *claims, historyID, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": comment})
+}
+
+// Edit merubah komentar, hanya pembuat komentar sebelum batas waktu edit berakhir
+func (cm *commentHandler) Edit(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	commentID := c.Params("id")
+
+	var req dto.CommentEditRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	comment, apiErr := cm.service.EditComment(c.Context(), *claims, commentID, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": comment})
+}
+
+func (cm *commentHandler) Delete(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	commentID := c.Params("id")
+
+	apiErr := cm.service.DeleteComment(c.Context(), *claims, commentID)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("komentar %s berhasil dihapus", commentID)})
+}
+
+// Find menampilkan komentar history dalam bentuk thread
+func (cm *commentHandler) Find(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	historyID := c.Params("id")
+
+	threads, apiErr := cm.service.FindComment(c.Context(), *claims, historyID)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": threads})
+}
+
+// UploadAttachment menyimpan file dari form "attachments" (gambar atau pdf) dan mengembalikan path
+// yang kemudian dikirim pada field attachments saat membuat atau merubah komentar
+func (cm *commentHandler) UploadAttachment(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	namePrefix := fmt.Sprintf("%s%v", claims.Identity, time.Now().UnixNano())
+	paths, apiErr := saveAttachments(c, *claims, dto.CommentAttachmentFolder, namePrefix)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": paths})
+}
@@ -62,7 +62,7 @@ func (pc *computerHandler) GetComputer(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list computer
-// Query [branch, name, ip, location, disable, division, seat]
+// Query [branch, name, ip, location, disable, division, seat, limit, cursor, cf_<key>]
 func (pc *computerHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	division := c.Query("division")
@@ -94,9 +94,10 @@ func (pc *computerHandler) Find(c *fiber.Ctx) error {
 		FilterName:           name,
 		FilterDisable:        disable,
 		FilterSeatManagement: seatManagement,
+		FilterCustomFields:   customFieldQuery(c),
 	}
 
-	computerList, generalList, apiErr := pc.service.FindComputer(c.Context(), filterA)
+	computerList, generalList, page, apiErr := pc.service.FindComputer(c.Context(), filterA, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
@@ -104,7 +105,7 @@ func (pc *computerHandler) Find(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"error": nil, "data": fiber.Map{
 		"computer_list": computerList,
 		"extra_list":    generalList,
-	}})
+	}, "page": page})
 }
 
 // DisableComputer menghilangkan computer dari list
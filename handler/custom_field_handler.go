@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"strings"
+)
+
+// customFieldQueryPrefix prefix query untuk memfilter custom field pada endpoint find, contoh ?cf_vendor=abc
+const customFieldQueryPrefix = "cf_"
+
+func NewCustomFieldHandler(customFieldService service.CustomFieldServiceAssumer) *customFieldHandler {
+	return &customFieldHandler{
+		service: customFieldService,
+	}
+}
+
+type customFieldHandler struct {
+	service service.CustomFieldServiceAssumer
+}
+
+// UpsertSchema membuat atau mengganti definisi custom field untuk satu category
+func (cf *customFieldHandler) UpsertSchema(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.CustomFieldSchemaRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	schema, apiErr := cf.service.UpsertSchema(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": schema})
+}
+
+// DeleteSchema menghapus definisi custom field category, nilai yang sudah tersimpan pada unit tidak dihapus
+func (cf *customFieldHandler) DeleteSchema(c *fiber.Ctx) error {
+	cat := c.Params("category")
+
+	apiErr := cf.service.DeleteSchema(c.Context(), cat)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("custom field %s berhasil dihapus", cat)})
+}
+
+// FindSchema menampilkan definisi custom field seluruh category
+func (cf *customFieldHandler) FindSchema(c *fiber.Ctx) error {
+	schemas, apiErr := cf.service.FindSchema(c.Context())
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": schemas})
+}
+
+// customFieldQuery mengambil seluruh query berprefix cf_ sebagai filter custom field,
+// nilai masih berupa string dan dikonversi oleh service sesuai schema
+func customFieldQuery(c *fiber.Ctx) map[string]interface{} {
+	var filter map[string]interface{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		if !strings.HasPrefix(k, customFieldQueryPrefix) || len(value) == 0 {
+			return
+		}
+		if filter == nil {
+			filter = make(map[string]interface{})
+		}
+		filter[strings.TrimPrefix(k, customFieldQueryPrefix)] = string(value)
+	})
+	return filter
+}
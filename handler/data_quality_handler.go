@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewDataQualityHandler(dataQualityService service.DataQualityServiceAssumer) *dataQualityHandler {
+	return &dataQualityHandler{
+		service: dataQualityService,
+	}
+}
+
+type dataQualityHandler struct {
+	service service.DataQualityServiceAssumer
+}
+
+// GetReport menampilkan hasil pemindaian data quality terakhir
+// Query [branch]
+func (d *dataQualityHandler) GetReport(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	report, apiErr := d.service.GetReport(c.Context(), branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": report})
+}
+
+// Scan menjalankan pemindaian data quality tanpa menunggu jadwal job
+// Query [branch]
+func (d *dataQualityHandler) Scan(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = claims.Branch
+	}
+
+	report, apiErr := d.service.Scan(c.Context(), branch, claims.Name)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": report})
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/tabular"
+	"github.com/muchlist/risa_restfull/utils/timegen"
+)
+
+func NewExportHandler(exportService service.ExportServiceAssumer) *exportHandler {
+	return &exportHandler{
+		service: exportService,
+	}
+}
+
+type exportHandler struct {
+	service service.ExportServiceAssumer
+}
+
+// Cctv export daftar cctv
+// Query [format : csv, xlsx, lang : id, en, branch, name, ip, location, disable]
+func (e *exportHandler) Cctv(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+	var disable bool
+	if c.Query("disable") != "" {
+		disable = true
+	}
+
+	filter := dto.FilterBranchLocIPNameDisable{
+		FilterBranch:   branch,
+		FilterLocation: c.Query("location"),
+		FilterIP:       c.Query("ip"),
+		FilterName:     c.Query("name"),
+		FilterDisable:  disable,
+	}
+
+	format, apiErr := exportFormat(c)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	streamer, apiErr := e.service.ExportCctv(c.Context(), filter, c.Query("lang"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return streamExport(c, "cctv", branch, format, streamer)
+}
+
+// Computer export daftar computer
+// Query [format : csv, xlsx, lang : id, en, branch, name, ip, location, disable, division, seat]
+func (e *exportHandler) Computer(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+	var disable bool
+	if c.Query("disable") != "" {
+		disable = true
+	}
+	seatManagement := -1
+	if c.Query("seat") == "1" {
+		seatManagement = 1
+	}
+	if c.Query("seat") == "0" {
+		seatManagement = 0
+	}
+
+	filter := dto.FilterComputer{
+		FilterBranch:         branch,
+		FilterLocation:       c.Query("location"),
+		FilterDivision:       c.Query("division"),
+		FilterIP:             c.Query("ip"),
+		FilterName:           c.Query("name"),
+		FilterDisable:        disable,
+		FilterSeatManagement: seatManagement,
+	}
+
+	format, apiErr := exportFormat(c)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	streamer, apiErr := e.service.ExportComputer(c.Context(), filter, c.Query("lang"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return streamExport(c, "computer", branch, format, streamer)
+}
+
+// Other export daftar other
+// Param [cat]
+// Query [format : csv, xlsx, lang : id, en, branch, name, ip, location, disable, division]
+func (e *exportHandler) Other(c *fiber.Ctx) error {
+	cat := c.Params("cat")
+	if apiErr := subCategoryValidation(cat); apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+	var disable bool
+	if c.Query("disable") != "" {
+		disable = true
+	}
+
+	filter := dto.FilterOther{
+		FilterBranch:      branch,
+		FilterSubCategory: cat,
+		FilterLocation:    c.Query("location"),
+		FilterDivision:    c.Query("division"),
+		FilterIP:          c.Query("ip"),
+		FilterName:        c.Query("name"),
+		FilterDisable:     disable,
+	}
+
+	format, apiErr := exportFormat(c)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	streamer, apiErr := e.service.ExportOther(c.Context(), filter, c.Query("lang"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return streamExport(c, strings.ToLower(cat), branch, format, streamer)
+}
+
+// Stock export daftar stock
+// Query [format : csv, xlsx, lang : id, en, branch, name, category, disable]
+func (e *exportHandler) Stock(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	var disable bool
+	if c.Query("disable") != "" {
+		disable = true
+	}
+
+	filter := dto.FilterBranchNameCatDisable{
+		FilterBranch:   branch,
+		FilterName:     c.Query("name"),
+		FilterCategory: c.Query("category"),
+		FilterDisable:  disable,
+	}
+
+	format, apiErr := exportFormat(c)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	streamer, apiErr := e.service.ExportStock(c.Context(), filter, c.Query("lang"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return streamExport(c, "stock", branch, format, streamer)
+}
+
+// exportFormat membaca query format, default csv
+func exportFormat(c *fiber.Ctx) (string, rest_err.APIError) {
+	format := strings.ToLower(c.Query("format", tabular.FormatCSV))
+	if tabular.ContentType(format) == "" {
+		return "", rest_err.NewBadRequestError(fmt.Sprintf("format tidak tersedia. gunakan %s", []string{tabular.FormatCSV, tabular.FormatXLSX}))
+	}
+	return format, nil
+}
+
+// streamExport mengirim header response lalu menulis baris secara bertahap ke body.
+// error saat streaming hanya bisa dicatat di log karena status response sudah terkirim
+func streamExport(c *fiber.Ctx, name string, branch string, format string, streamer service.ExportStreamer) error {
+	timeName, _ := timegen.GetTimeAsName(time.Now().Unix())
+	fileName := fmt.Sprintf("%s-%s-%s.%s", name, strings.ToLower(branch), timeName, format)
+
+	c.Set(fiber.HeaderContentType, tabular.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, fileName))
+
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		w, err := tabular.NewWriter(format, bw)
+		if err != nil {
+			logger.Error(fmt.Sprintf("gagal membuat export %s", fileName), err)
+			return
+		}
+		// request context sudah selesai saat body di stream
+		if apiErr := streamer(context.Background(), w); apiErr != nil {
+			logger.Error(fmt.Sprintf("gagal menulis export %s", fileName), apiErr)
+		}
+		if err := w.Close(); err != nil {
+			logger.Error(fmt.Sprintf("gagal menutup export %s", fileName), err)
+		}
+		_ = bw.Flush()
+	})
+	return nil
+}
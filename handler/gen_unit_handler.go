@@ -7,6 +7,7 @@ import (
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/apikey"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 )
 
@@ -20,7 +21,7 @@ type genUnitHandler struct {
 	service service.GenUnitServiceAssumer
 }
 
-// Find menampilkan list unit. Query name, category, ip, last_ping, pings
+// Find menampilkan list unit. Query name, category, ip, last_ping, pings, limit, cursor
 func (u *genUnitHandler) Find(c *fiber.Ctx) error {
 	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
 	nameSearch := c.Query("name")
@@ -41,17 +42,19 @@ func (u *genUnitHandler) Find(c *fiber.Ctx) error {
 		LastPing: lastPing,
 	}
 
-	userList, apiErr := u.service.FindUnit(c.Context(), payload)
+	userList, page, apiErr := u.service.FindUnit(c.Context(), payload, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	return c.JSON(fiber.Map{"error": nil, "data": userList})
+	return c.JSON(fiber.Map{"error": nil, "data": userList, "page": page})
 }
 
-// Find menampilkan list ip address. Query branch, category
+// GetIPList menampilkan list ip address. Query category.
+// branch mengikuti branch api key yang digunakan
 func (u *genUnitHandler) GetIPList(c *fiber.Ctx) error {
-	branch := c.Query("branch")
+	keyClaims := c.Locals(apikey.KEYCLAIMS).(*dto.ApiKeyClaim)
+	branch := keyClaims.Branch
 	category := c.Query("category")
 
 	ipList, apiErr := u.service.GetIPList(c.Context(), branch, category)
@@ -62,17 +65,21 @@ func (u *genUnitHandler) GetIPList(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"error": nil, "data": ipList})
 }
 
+// UpdatePingState menambahkan hasil ping. branch mengikuti branch api key yang digunakan
 func (u genUnitHandler) UpdatePingState(c *fiber.Ctx) error {
+	keyClaims := c.Locals(apikey.KEYCLAIMS).(*dto.ApiKeyClaim)
+
 	var req dto.GenUnitPingStateRequest
 	if err := c.BodyParser(&req); err != nil {
 		apiErr := rest_err.NewBadRequestError(err.Error())
-		logger.Info(fmt.Sprintf("u: %s | parse | %s", c.IP(), err.Error()))
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", keyClaims.Name, err.Error()))
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
+	req.Branch = keyClaims.Branch
 
 	if err := req.Validate(); err != nil {
 		apiErr := rest_err.NewBadRequestError(err.Error())
-		logger.Info(fmt.Sprintf("u: %s | validate | %s", c.IP(), err.Error()))
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", keyClaims.Name, err.Error()))
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
@@ -84,3 +91,57 @@ func (u genUnitHandler) UpdatePingState(c *fiber.Ctx) error {
 	res := fmt.Sprintf("%d ip diupdate", count)
 	return c.JSON(fiber.Map{"error": nil, "data": res})
 }
+
+// SetUplink menghubungkan unit ke unit induk (uplink) pada branch user
+func (u *genUnitHandler) SetUplink(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	unitID := c.Params("id")
+
+	var req dto.GenUnitUplinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	unit, apiErr := u.service.SetUplink(c.Context(), *claims, unitID, req.UplinkID)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": unit})
+}
+
+// RemoveUplink melepas hubungan unit dengan unit induknya
+func (u *genUnitHandler) RemoveUplink(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	unitID := c.Params("id")
+
+	unit, apiErr := u.service.SetUplink(c.Context(), *claims, unitID, "")
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": unit})
+}
+
+// Topology menampilkan pohon unit berdasarkan uplink. Query branch
+func (u *genUnitHandler) Topology(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	nodes, apiErr := u.service.Topology(c.Context(), branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": nodes})
+}
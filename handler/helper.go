@@ -5,12 +5,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	//"github.com/nfnt/resize"
 	"github.com/disintegration/imaging"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -22,6 +24,7 @@ const (
 	jpgExtension  = ".jpg"
 	pngExtension  = ".png"
 	jpegExtension = ".jpeg"
+	pdfExtension  = ".pdf"
 )
 
 // saveImage return path to save in db
@@ -33,15 +36,55 @@ func saveImage(c *fiber.Ctx, claims mjwt.CustomClaim, folder string, imageName s
 		return "", apiErr
 	}
 
+	return saveUploadedFile(c, claims, file, folder, imageName, needThumbnail, false)
+}
+
+// saveAttachments menyimpan banyak file dari form field attachments, return path to save in db.
+// gambar selalu dibuatkan thumbnail, pdf disimpan apa adanya
+func saveAttachments(c *fiber.Ctx, claims mjwt.CustomClaim, folder string, namePrefix string) ([]string, rest_err.APIError) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		apiErr := rest_err.NewAPIError("File gagal di upload", http.StatusBadRequest, "bad_request", []interface{}{err.Error()})
+		logger.Info(fmt.Sprintf("u: %s | formfile | %s", claims.Name, err.Error()))
+		return nil, apiErr
+	}
+
+	files := form.File["attachments"]
+	if len(files) == 0 || len(files) > dto.MaxCommentAttachments {
+		apiErr := rest_err.NewBadRequestError(fmt.Sprintf("Jumlah lampiran harus antara 1 sampai %d file", dto.MaxCommentAttachments))
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, apiErr.Error()))
+		return nil, apiErr
+	}
+
+	paths := make([]string, 0, len(files))
+	for i, file := range files {
+		path, apiErr := saveUploadedFile(c, claims, file, folder, fmt.Sprintf("%s-%d", namePrefix, i), true, true)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// saveUploadedFile validasi ekstensi dan ukuran lalu menyimpan file, return path to save in db
+func saveUploadedFile(c *fiber.Ctx, claims mjwt.CustomClaim, file *multipart.FileHeader, folder string, fileNameWithoutExt string, needThumbnail bool, allowPDF bool) (string, rest_err.APIError) {
 	fileName := file.Filename
 	fileExtension := strings.ToLower(filepath.Ext(fileName))
-	if !(fileExtension == jpgExtension || fileExtension == pngExtension || fileExtension == jpegExtension) {
+	isPDF := allowPDF && fileExtension == pdfExtension
+	if !(fileExtension == jpgExtension || fileExtension == pngExtension || fileExtension == jpegExtension || isPDF) {
 		apiErr := rest_err.NewBadRequestError("Ektensi file tidak di support")
 		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, apiErr.Error()))
 		return "", apiErr
 	}
 
-	if file.Size > 2*1024*1024 { // 1 MB
+	if isPDF && file.Size > 5*1024*1024 {
+		apiErr := rest_err.NewBadRequestError("Ukuran file pdf tidak dapat melebihi 5MB")
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, apiErr.Error()))
+		return "", apiErr
+	}
+
+	if !isPDF && file.Size > 2*1024*1024 { // 1 MB
 		apiErr := rest_err.NewBadRequestError("Ukuran file tidak dapat melebihi 2MB")
 		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, apiErr.Error()))
 		return "", apiErr
@@ -50,10 +93,10 @@ func saveImage(c *fiber.Ctx, claims mjwt.CustomClaim, folder string, imageName s
 	// rename image
 	// path := filepath.Join("static", "image", folder, imageName + fileExtension)
 	// pathInDB := filepath.Join("image", folder, imageName + fileExtension)
-	path := fmt.Sprintf("static/image/%s/%s", folder, imageName+fileExtension)
-	pathInDB := fmt.Sprintf("image/%s/%s", folder, imageName+fileExtension)
+	path := fmt.Sprintf("static/image/%s/%s", folder, fileNameWithoutExt+fileExtension)
+	pathInDB := fmt.Sprintf("image/%s/%s", folder, fileNameWithoutExt+fileExtension)
 
-	err = c.SaveFile(file, path)
+	err := c.SaveFile(file, path)
 	if err != nil {
 		logger.Error(fmt.Sprintf("%s gagal mengupload file", claims.Name), err)
 		apiErr := rest_err.NewInternalServerError("File gagal di upload", err)
@@ -61,9 +104,9 @@ func saveImage(c *fiber.Ctx, claims mjwt.CustomClaim, folder string, imageName s
 	}
 
 	// generate thumbnail
-	if needThumbnail {
+	if needThumbnail && !isPDF {
 		go func() {
-			err = generateThumbnail(path, imageName, fileExtension, folder)
+			err := generateThumbnail(path, fileNameWithoutExt, fileExtension, folder)
 			if err != nil {
 				logger.Error(fmt.Sprintf("%s gagal menggenerate thumbnail file", claims.Name), err)
 			}
@@ -133,3 +176,11 @@ func stringToInt(queryString string) int {
 	}
 	return number
 }
+
+// pageRequest membaca query pagination [cursor, limit] yang sama untuk semua endpoint daftar
+func pageRequest(c *fiber.Ctx) dto.PageRequest {
+	return dto.PageRequest{
+		Cursor: c.Query("cursor"),
+		Size:   int64(stringToInt(c.Query("limit"))),
+	}
+}
@@ -72,7 +72,8 @@ func (h *historyHandler) Edit(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list history
-// Query [branch, category, c_status, start, end, limit, search]
+// Query [branch, category, c_status, start, end, limit, cursor, search, tag]
+// search tidak dapat digabung dengan cursor, hasil pencarian hanya satu halaman sebanyak limit
 func (h *historyHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	category := c.Query("category")
@@ -81,6 +82,7 @@ func (h *historyHandler) Find(c *fiber.Ctx) error {
 	end := stringToInt(c.Query("end"))
 	limit := stringToInt(c.Query("limit"))
 	search := c.Query("search")
+	tag := c.Query("tag")
 
 	filterCompleteStatus := make([]int, 0)
 	if cStatus != 0 {
@@ -91,6 +93,7 @@ func (h *historyHandler) Find(c *fiber.Ctx) error {
 		FilterBranch:         branch,
 		FilterCategory:       category,
 		FilterCompleteStatus: filterCompleteStatus,
+		FilterTag:            tag,
 	}
 
 	filterB := dto.FilterTimeRangeLimit{
@@ -99,12 +102,12 @@ func (h *historyHandler) Find(c *fiber.Ctx) error {
 		Limit:       int64(limit),
 	}
 
-	histories, apiErr := h.service.FindHistory(context.Background(), search, filterA, filterB)
+	histories, page, apiErr := h.service.FindHistory(context.Background(), search, filterA, filterB, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	return c.JSON(fiber.Map{"error": nil, "data": histories})
+	return c.JSON(fiber.Map{"error": nil, "data": histories, "page": page})
 }
 
 // FindForHome menampilkan list history
@@ -203,6 +206,73 @@ func (h *historyHandler) GetHistory(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"error": nil, "data": history})
 }
 
+// Assign menugaskan history ke user, assignee_id kosong melepas penugasan
+func (h *historyHandler) Assign(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	historyID := c.Params("id")
+
+	var req dto.HistoryAssignRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	history, apiErr := h.service.AssignHistory(context.Background(), *claims, historyID, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+	return c.JSON(fiber.Map{"error": nil, "data": history})
+}
+
+// SetWatchers mengganti daftar watcher history
+func (h *historyHandler) SetWatchers(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	historyID := c.Params("id")
+
+	var req dto.HistoryWatcherRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	history, apiErr := h.service.SetWatchers(context.Background(), *claims, historyID, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+	return c.JSON(fiber.Map{"error": nil, "data": history})
+}
+
+// FindMine menampilkan history yang belum complete dan ditugaskan kepada user yang login
+func (h *historyHandler) FindMine(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	histories, apiErr := h.service.FindMyOpenHistory(context.Background(), *claims)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": histories})
+}
+
+// Workload menampilkan jumlah insiden terbuka per teknisi
+// Query [branch]
+func (h *historyHandler) Workload(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = claims.Branch
+	}
+
+	workload, apiErr := h.service.GetWorkload(context.Background(), branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": workload})
+}
+
 func (h *historyHandler) Delete(c *fiber.Ctx) error {
 	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
 	id := c.Params("id")
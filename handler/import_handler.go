@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/tabular"
+)
+
+func NewImportHandler(importService service.ImportServiceAssumer) *importHandler {
+	return &importHandler{
+		service: importService,
+	}
+}
+
+type importHandler struct {
+	service service.ImportServiceAssumer
+}
+
+// Import membaca file csv/xlsx dan membuat atau mengupdate unit berdasarkan inventory_number.
+// Param [category : cctv, computer, other]
+// Form [file, branch, dry_run]. dry_run default true, isi false untuk menulis data
+func (i *importHandler) Import(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	category := c.Params("category")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		apiErr := rest_err.NewBadRequestError("file import (csv/xlsx) wajib disertakan")
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		apiErr := rest_err.NewInternalServerError("gagal membuka file import", err)
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+	defer file.Close()
+
+	rows, err := tabular.Read(fileHeader.Filename, file)
+	if err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | import | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	// admin dapat mengisi data untuk cabang baru
+	user := *claims
+	if branch := c.FormValue("branch"); branch != "" {
+		branch = strings.ToUpper(branch)
+		if !masterdata.IsAvailable(masterkind.Branch, branch) {
+			apiErr := rest_err.NewBadRequestError(fmt.Sprintf("branch yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.Branch)))
+			return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+		}
+		user.Branch = branch
+	}
+	dryRun := strings.ToLower(c.FormValue("dry_run")) != "false"
+
+	result, apiErr := i.service.Import(c.Context(), user, category, rows, dryRun)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": result})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewJobHandler(jobService service.JobServiceAssumer) *jobHandler {
+	return &jobHandler{
+		service: jobService,
+	}
+}
+
+type jobHandler struct {
+	service service.JobServiceAssumer
+}
+
+// Insert menambahkan job ke registry, scheduler akan memuat ulang jadwal
+func (j *jobHandler) Insert(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.JobRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	insertID, apiErr := j.service.InsertJob(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	res := fmt.Sprintf("Menambahkan job berhasil, ID: %s", *insertID)
+	return c.JSON(fiber.Map{"error": nil, "data": res})
+}
+
+func (j *jobHandler) Edit(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	var req dto.JobRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	jobEdited, apiErr := j.service.EditJob(c.Context(), *claims, id, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": jobEdited})
+}
+
+func (j *jobHandler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	apiErr := j.service.DeleteJob(c.Context(), id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("job %s berhasil dihapus", id)})
+}
+
+func (j *jobHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	job, apiErr := j.service.GetJob(c.Context(), id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": job})
+}
+
+// Find menampilkan list job
+// Query [branch, type, limit, cursor]
+func (j *jobHandler) Find(c *fiber.Ctx) error {
+	filter := dto.FilterJob{
+		FilterBranch: c.Query("branch"),
+		FilterType:   c.Query("type"),
+	}
+
+	jobList, page, apiErr := j.service.FindJob(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": jobList, "page": page})
+}
+
+// RunNow menjalankan job saat itu juga di background
+func (j *jobHandler) RunNow(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	job, apiErr := j.service.RunJobNow(c.Context(), *claims, id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("job %s sedang dijalankan, cek run log untuk hasilnya", job.Name)})
+}
+
+// FindRun menampilkan run log job
+// Query [job_id, branch, limit, cursor]
+func (j *jobHandler) FindRun(c *fiber.Ctx) error {
+	filter := dto.FilterJobRun{
+		FilterJobID:  c.Query("job_id"),
+		FilterBranch: c.Query("branch"),
+	}
+
+	runList, page, apiErr := j.service.FindJobRun(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": runList, "page": page})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewLabelHandler(labelService service.LabelServiceAssumer) *labelHandler {
+	return &labelHandler{
+		service: labelService,
+	}
+}
+
+type labelHandler struct {
+	service service.LabelServiceAssumer
+}
+
+// Generate membuat pdf lembar label QR
+// Param [kind : cctv, computer, other]
+// Query [branch, cat (khusus other), location, division, name, ids (dipisah koma)]
+func (l *labelHandler) Generate(c *fiber.Ctx) error {
+	kind := c.Params("kind")
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	var ids []string
+	if c.Query("ids") != "" {
+		ids = strings.Split(c.Query("ids"), ",")
+	}
+
+	filter := dto.FilterLabel{
+		FilterBranch:      branch,
+		FilterSubCategory: c.Query("cat"),
+		FilterLocation:    c.Query("location"),
+		FilterDivision:    c.Query("division"),
+		FilterName:        c.Query("name"),
+		FilterIDs:         ids,
+	}
+
+	pdfBuffer, apiErr := l.service.GenerateLabel(c.Context(), kind, filter)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="label-%s-%s.pdf"`, strings.ToLower(kind), strings.ToLower(branch)))
+	return c.Send(pdfBuffer.Bytes())
+}
+
+// Scan mengembalikan unit, kasus terbuka dan history terbaru dari kode QR label
+// Param [code]
+func (l *labelHandler) Scan(c *fiber.Ctx) error {
+	result, apiErr := l.service.Scan(c.Context(), c.Params("code"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": result})
+}
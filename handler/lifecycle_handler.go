@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewLifecycleHandler(lifecycleService service.LifecycleServiceAssumer) *lifecycleHandler {
+	return &lifecycleHandler{
+		service: lifecycleService,
+	}
+}
+
+type lifecycleHandler struct {
+	service service.LifecycleServiceAssumer
+}
+
+// FindFlagged menampilkan aset yang garansinya akan / sudah habis atau memakai OS end of life
+// Query [branch, days : default 60]
+func (l *lifecycleHandler) FindFlagged(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	flags, apiErr := l.service.FindFlagged(c.Context(), branch, stringToInt(c.Query("days")))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": flags})
+}
+
+// Report menampilkan jumlah dan biaya aset per kategori dikelompokkan berdasarkan umur dan status EOL
+// Query [branch]
+func (l *lifecycleHandler) Report(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	report, apiErr := l.service.Report(c.Context(), branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": report})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewMapHandler(mapService service.MapServiceAssumer) *mapHandler {
+	return &mapHandler{
+		service: mapService,
+	}
+}
+
+type mapHandler struct {
+	service service.MapServiceAssumer
+}
+
+// FindMap menampilkan unit yang memiliki koordinat dalam bentuk GeoJSON FeatureCollection
+// Query [branch, category : CCTV | PC | sub category other]
+func (m *mapHandler) FindMap(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	collection, apiErr := m.service.FindMap(c.Context(), dto.FilterMap{
+		FilterBranch:   branch,
+		FilterCategory: c.Query("category"),
+	})
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": collection})
+}
+
+// FindNear menampilkan unit terdekat dari posisi teknisi, diurutkan berdasarkan jarak
+// Query [lat, lon, radius : meter default 500, limit : default 20, branch]
+func (m *mapHandler) FindNear(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(c.Query("lat")), 64)
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(c.Query("lon")), 64)
+	if errLat != nil || errLon != nil {
+		apiErr := rest_err.NewBadRequestError("query lat dan lon wajib diisi dengan angka")
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	collection, apiErr := m.service.FindNear(c.Context(), dto.FilterGeoNear{
+		FilterBranch: strings.ToUpper(branch),
+		Lat:          lat,
+		Lon:          lon,
+		MaxDistance:  float64(stringToInt(c.Query("radius"))),
+		Limit:        int64(stringToInt(c.Query("limit"))),
+	})
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": collection})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewMergeHandler(mergeService service.MergeServiceAssumer) *mergeHandler {
+	return &mergeHandler{
+		service: mergeService,
+	}
+}
+
+type mergeHandler struct {
+	service service.MergeServiceAssumer
+}
+
+// Preview menampilkan perubahan jika unit1 digabungkan ke unit2 tanpa menyimpan perubahan
+func (m *mergeHandler) Preview(c *fiber.Ctx) error {
+	preview, apiErr := m.service.Preview(c.Context(), c.Params("unit1"), c.Params("unit2"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": preview})
+}
+
+// Merge menggabungkan unit1 ke unit2 lalu memindahkan unit1 ke tempat sampah
+func (m *mergeHandler) Merge(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	result, apiErr := m.service.Merge(c.Context(), *claims, c.Params("unit1"), c.Params("unit2"))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": result})
+}
@@ -2,19 +2,23 @@ package handler
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/muchlist/risa_restfull/constants/branches"
-	"github.com/muchlist/risa_restfull/constants/checktype"
-	"github.com/muchlist/risa_restfull/constants/hwlist"
-	"github.com/muchlist/risa_restfull/constants/location"
-	"github.com/muchlist/risa_restfull/constants/stocklist"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
 	"strings"
 )
 
-func NewOptionHandler() *optionHandler {
-	return &optionHandler{}
+func NewOptionHandler(customFieldService service.CustomFieldServiceAssumer) *optionHandler {
+	return &optionHandler{
+		customFieldService: customFieldService,
+	}
 }
 
-type optionHandler struct{}
+type optionHandler struct {
+	customFieldService service.CustomFieldServiceAssumer
+}
 
 // OptCreateCheckItem mengembalikan location, dan type
 func (o *optionHandler) OptCreateCheckItem(c *fiber.Ctx) error {
@@ -22,12 +26,12 @@ func (o *optionHandler) OptCreateCheckItem(c *fiber.Ctx) error {
 	var optLocation []string
 
 	if branch != "" {
-		optLocation = location.GetLocationAvailableFrom(strings.ToUpper(branch))
+		optLocation = masterdata.ValuesFrom(masterkind.Location, strings.ToUpper(branch))
 	} else {
-		optLocation = location.GetLocationAvailable()
+		optLocation = masterdata.Values(masterkind.Location)
 	}
 
-	optType := checktype.GetCheckTypeAvailable()
+	optType := masterdata.Values(masterkind.CheckType)
 	options := fiber.Map{
 		"location": optLocation,
 		"type":     optType,
@@ -37,7 +41,7 @@ func (o *optionHandler) OptCreateCheckItem(c *fiber.Ctx) error {
 
 // OptCreateStock mengembalikan stock category
 func (o *optionHandler) OptCreateStock(c *fiber.Ctx) error {
-	stockCategory := stocklist.GetStockCategoryAvailable()
+	stockCategory := masterdata.Values(masterkind.StockCategory)
 	options := fiber.Map{
 		"category": stockCategory,
 	}
@@ -50,15 +54,21 @@ func (o *optionHandler) OptCreateCctv(c *fiber.Ctx) error {
 	var optLocation []string
 
 	if branch != "" {
-		optLocation = location.GetLocationAvailableFrom(strings.ToUpper(branch))
+		optLocation = masterdata.ValuesFrom(masterkind.Location, strings.ToUpper(branch))
 	} else {
-		optLocation = location.GetLocationAvailable()
+		optLocation = masterdata.Values(masterkind.Location)
+	}
+
+	cctvType := masterdata.Values(masterkind.CctvType)
+	customFields, apiErr := o.customFieldService.GetFields(c.Context(), category.Cctv)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	cctvType := hwlist.GetCctvTypeAvailable()
 	options := fiber.Map{
-		"location": optLocation,
-		"type":     cctvType,
+		"location":      optLocation,
+		"type":          cctvType,
+		"custom_fields": customFields,
 	}
 	return c.JSON(options)
 }
@@ -69,52 +79,82 @@ func (o *optionHandler) OptCreateComputer(c *fiber.Ctx) error {
 	var optLocation []string
 
 	if branch != "" {
-		optLocation = location.GetLocationAvailableFrom(strings.ToUpper(branch))
+		optLocation = masterdata.ValuesFrom(masterkind.Location, strings.ToUpper(branch))
 	} else {
-		optLocation = location.GetLocationAvailable()
+		optLocation = masterdata.Values(masterkind.Location)
+	}
+	division := masterdata.Values(masterkind.Division)
+	computerType := masterdata.Values(masterkind.ComputerType)
+	os := masterdata.Values(masterkind.PcOS)
+	processor := masterdata.Values(masterkind.PcProcessor)
+	hardisk := masterdata.IntValues(masterkind.PcHDD)
+	ram := masterdata.IntValues(masterkind.PcRam)
+	customFields, apiErr := o.customFieldService.GetFields(c.Context(), category.PC)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
-	division := location.GetDivisionAvailable()
-	computerType := hwlist.GetComputerTypeAvailable()
-	os := hwlist.GetPCOSAvailable()
-	processor := hwlist.GetPCProcessor()
-	hardisk := hwlist.GetPCHDD()
-	ram := hwlist.GetPCRam()
 
 	options := fiber.Map{
-		"location":  optLocation,
-		"division":  division,
-		"type":      computerType,
-		"os":        os,
-		"processor": processor,
-		"hardisk":   hardisk,
-		"ram":       ram,
+		"location":      optLocation,
+		"division":      division,
+		"type":          computerType,
+		"os":            os,
+		"processor":     processor,
+		"hardisk":       hardisk,
+		"ram":           ram,
+		"custom_fields": customFields,
 	}
 	return c.JSON(options)
 }
 
 // OptBranch mengembalikan branch yang tersedia
 func (o *optionHandler) OptBranch(c *fiber.Ctx) error {
-	optBranch := branches.GetBranchesAvailable()
+	optBranch := masterdata.Values(masterkind.Branch)
 	options := fiber.Map{
 		"branch": optBranch,
 	}
 	return c.JSON(options)
 }
 
-// OptBranch mengembalikan branch yang tersedia
+// OptLocationDivision mengembalikan lokasi, divisi dan custom field other.
+// Query [sub_category] mengembalikan custom field sub category tersebut,
+// jika kosong custom field dikelompokkan per sub category
 func (o *optionHandler) OptLocationDivision(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	var optLocation []string
 
 	if branch != "" {
-		optLocation = location.GetLocationAvailableFrom(strings.ToUpper(branch))
+		optLocation = masterdata.ValuesFrom(masterkind.Location, strings.ToUpper(branch))
+	} else {
+		optLocation = masterdata.Values(masterkind.Location)
+	}
+	division := masterdata.Values(masterkind.Division)
+
+	var customFields interface{}
+	if subCategory := c.Query("sub_category"); subCategory != "" {
+		fields, apiErr := o.customFieldService.GetFields(c.Context(), subCategory)
+		if apiErr != nil {
+			return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+		}
+		customFields = fields
 	} else {
-		optLocation = location.GetLocationAvailable()
+		schemas, apiErr := o.customFieldService.FindSchema(c.Context())
+		if apiErr != nil {
+			return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+		}
+		fieldsPerSub := make(map[string][]dto.CustomField)
+		for _, schema := range schemas {
+			if schema.Category != category.Cctv && schema.Category != category.PC {
+				fieldsPerSub[schema.Category] = schema.Fields
+			}
+		}
+		customFields = fieldsPerSub
 	}
-	division := location.GetDivisionAvailable()
+
 	options := fiber.Map{
-		"location": optLocation,
-		"division": division,
+		"location":      optLocation,
+		"division":      division,
+		"custom_fields": customFields,
 	}
 	return c.JSON(options)
 }
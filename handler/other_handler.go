@@ -5,10 +5,11 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
-	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
 	"github.com/muchlist/risa_restfull/constants/statuses"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
 	"time"
@@ -64,7 +65,7 @@ func (ot *otherHandler) GetOther(c *fiber.Ctx) error {
 
 // Find menampilkan list other
 // Param [cat]
-// Query [branch, name, ip, location, disable, division, seat]
+// Query [branch, name, ip, location, disable, division, seat, limit, cursor, cf_<key> : butuh satu category]
 func (ot *otherHandler) Find(c *fiber.Ctx) error {
 	cat := c.Params("cat")
 	branch := c.Query("branch")
@@ -88,16 +89,17 @@ func (ot *otherHandler) Find(c *fiber.Ctx) error {
 	}
 
 	filterA := dto.FilterOther{
-		FilterBranch:      branch,
-		FilterSubCategory: cat,
-		FilterLocation:    location,
-		FilterDivision:    division,
-		FilterIP:          ip,
-		FilterName:        name,
-		FilterDisable:     disable,
+		FilterBranch:       branch,
+		FilterSubCategory:  cat,
+		FilterLocation:     location,
+		FilterDivision:     division,
+		FilterIP:           ip,
+		FilterName:         name,
+		FilterDisable:      disable,
+		FilterCustomFields: customFieldQuery(c),
 	}
 
-	otherList, generalList, apiErr := ot.service.FindOther(c.Context(), filterA)
+	otherList, generalList, page, apiErr := ot.service.FindOther(c.Context(), filterA, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
@@ -105,7 +107,7 @@ func (ot *otherHandler) Find(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"error": nil, "data": fiber.Map{
 		"other_list": otherList,
 		"extra_list": generalList,
-	}})
+	}, "page": page})
 }
 
 // DisableOther menghilangkan other dari list
@@ -217,11 +219,11 @@ func (ot *otherHandler) UploadImage(c *fiber.Ctx) error {
 
 func subCategoryValidation(cat string) rest_err.APIError {
 	if cat == "" {
-		return rest_err.NewBadRequestError(fmt.Sprintf("param category wajib disertakan. gunakan %s", category.GetSubCategoryAvailable()))
+		return rest_err.NewBadRequestError(fmt.Sprintf("param category wajib disertakan. gunakan %s", masterdata.Values(masterkind.SubCategory)))
 	}
 
-	if !sfunc.InSlice(cat, category.GetSubCategoryAvailable()) {
-		return rest_err.NewBadRequestError(fmt.Sprintf("category yang dimasukkan tidak tersedia. gunakan %s", category.GetSubCategoryAvailable()))
+	if !masterdata.IsAvailable(masterkind.SubCategory, cat) {
+		return rest_err.NewBadRequestError(fmt.Sprintf("category yang dimasukkan tidak tersedia. gunakan %s", masterdata.Values(masterkind.SubCategory)))
 	}
 	return nil
 }
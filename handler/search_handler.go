@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewSearchHandler(searchService service.SearchServiceAssumer) *searchHandler {
+	return &searchHandler{
+		service: searchService,
+	}
+}
+
+type searchHandler struct {
+	service service.SearchServiceAssumer
+}
+
+// Search mencari unit, history, stock dan pending report dalam satu endpoint, hasil diurutkan berdasarkan relevansi
+// Query [q, branch, type : UNIT,HISTORY,STOCK,PENDING_REPORT dipisah koma, limit : default 20]
+func (s *searchHandler) Search(c *fiber.Ctx) error {
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim).Branch
+	}
+
+	result, apiErr := s.service.Search(c.Context(), dto.FilterSearch{
+		Query:  c.Query("q"),
+		Branch: branch,
+		Types:  c.Query("type"),
+		Limit:  stringToInt(c.Query("limit")),
+	})
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": result})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewSLAHandler(slaService service.SLAServiceAssumer) *slaHandler {
+	return &slaHandler{
+		service: slaService,
+	}
+}
+
+type slaHandler struct {
+	service service.SLAServiceAssumer
+}
+
+// UpsertPolicy membuat atau mengganti target SLA untuk category dan priority
+func (s *slaHandler) UpsertPolicy(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.SLAPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	policy, apiErr := s.service.UpsertPolicy(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": policy})
+}
+
+// DeletePolicy menghapus target SLA, history akan kembali memakai policy yang lebih umum
+func (s *slaHandler) DeletePolicy(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	apiErr := s.service.DeletePolicy(c.Context(), id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": fmt.Sprintf("sla policy %s berhasil dihapus", id)})
+}
+
+// FindPolicy menampilkan semua target SLA
+func (s *slaHandler) FindPolicy(c *fiber.Ctx) error {
+	policies, apiErr := s.service.FindPolicy(c.Context())
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": policies})
+}
@@ -108,7 +108,7 @@ func (s *stockHandler) GetStock(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list stock
-// Query [branch, name, category, disable]
+// Query [branch, name, category, disable, limit, cursor]
 func (s *stockHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	name := c.Query("name")
@@ -125,12 +125,12 @@ func (s *stockHandler) Find(c *fiber.Ctx) error {
 		FilterDisable:  disable,
 	}
 
-	stockList, apiErr := s.service.FindStock(c.Context(), filterA)
+	stockList, page, apiErr := s.service.FindStock(c.Context(), filterA, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	return c.JSON(fiber.Map{"error": nil, "data": stockList})
+	return c.JSON(fiber.Map{"error": nil, "data": stockList, "page": page})
 }
 
 // FindNeedRestock1 menampilkan list stock
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+)
+
+func NewTransferHandler(transferService service.TransferServiceAssumer) *transferHandler {
+	return &transferHandler{
+		service: transferService,
+	}
+}
+
+type transferHandler struct {
+	service service.TransferServiceAssumer
+}
+
+// Insert membuat permintaan transfer unit dari cabang user ke cabang tujuan
+func (t *transferHandler) Insert(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+
+	var req dto.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	req.ToBranch = strings.ToUpper(req.ToBranch)
+	for i := range req.Units {
+		req.Units[i].Category = strings.ToUpper(req.Units[i].Category)
+	}
+
+	if err := req.Validate(); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | validate | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	insertID, apiErr := t.service.InsertTransfer(c.Context(), *claims, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	res := fmt.Sprintf("Menambahkan permintaan transfer berhasil, ID: %s", *insertID)
+	return c.JSON(fiber.Map{"error": nil, "data": res})
+}
+
+// Accept menerima transfer, hanya bisa dilakukan oleh cabang tujuan
+func (t *transferHandler) Accept(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	var req dto.TransferDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	transfer, apiErr := t.service.AcceptTransfer(c.Context(), *claims, id, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": transfer})
+}
+
+// Reject menolak transfer, hanya bisa dilakukan oleh cabang tujuan
+func (t *transferHandler) Reject(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	var req dto.TransferDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		apiErr := rest_err.NewBadRequestError(err.Error())
+		logger.Info(fmt.Sprintf("u: %s | parse | %s", claims.Name, err.Error()))
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	transfer, apiErr := t.service.RejectTransfer(c.Context(), *claims, id, req)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": transfer})
+}
+
+// Cancel membatalkan transfer, hanya bisa dilakukan oleh cabang asal
+func (t *transferHandler) Cancel(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	transfer, apiErr := t.service.CancelTransfer(c.Context(), *claims, id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": transfer})
+}
+
+// Get menampilkan transfer yang melibatkan cabang user
+func (t *transferHandler) Get(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	id := c.Params("id")
+
+	transfer, apiErr := t.service.GetTransferByID(c.Context(), id, claims.Branch)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": transfer})
+}
+
+// Find menampilkan transfer masuk dan keluar cabang
+// Query [branch, state, unit_id, limit, cursor]
+func (t *transferHandler) Find(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = claims.Branch
+	}
+
+	var unitIDs []string
+	if c.Query("unit_id") != "" {
+		unitIDs = []string{c.Query("unit_id")}
+	}
+
+	filter := dto.FilterTransfer{
+		FilterBranch:  branch,
+		FilterState:   c.Query("state"),
+		FilterUnitIDs: unitIDs,
+	}
+
+	transfers, page, apiErr := t.service.FindTransfer(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": transfers, "page": page})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/service"
+)
+
+func NewTrashHandler(trashService service.TrashServiceAssumer) *trashHandler {
+	return &trashHandler{
+		service: trashService,
+	}
+}
+
+type trashHandler struct {
+	service service.TrashServiceAssumer
+}
+
+// Find menampilkan dokumen yang berada di tempat sampah
+// Query [entity, branch, limit, cursor]
+func (t *trashHandler) Find(c *fiber.Ctx) error {
+	filter := dto.FilterTrash{
+		FilterEntity: c.Query("entity"),
+		FilterBranch: c.Query("branch"),
+	}
+
+	trashList, page, apiErr := t.service.FindTrash(c.Context(), filter, pageRequest(c))
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": trashList, "page": page})
+}
+
+// Restore mengembalikan dokumen dari tempat sampah beserta gen_unit nya
+// Param [entity : cctv, computer, other, stock]
+func (t *trashHandler) Restore(c *fiber.Ctx) error {
+	entity := c.Params("entity")
+	id := c.Params("id")
+
+	item, apiErr := t.service.RestoreTrash(c.Context(), entity, id)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": item})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/risa_restfull/service"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"time"
+)
+
+// defaultUptimeRange rentang waktu default perhitungan uptime (30 hari)
+const defaultUptimeRange = 60 * 60 * 24 * 30
+
+func NewUptimeHandler(uptimeService service.UptimeServiceAssumer) *uptimeHandler {
+	return &uptimeHandler{
+		service: uptimeService,
+	}
+}
+
+type uptimeHandler struct {
+	service service.UptimeServiceAssumer
+}
+
+// GetUnitUptime menampilkan uptime, MTBF dan MTTR satu unit
+// Query [start, end] default 30 hari terakhir
+func (u *uptimeHandler) GetUnitUptime(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	unitID := c.Params("id")
+	start, end := uptimeRangeFromQuery(c)
+
+	uptime, apiErr := u.service.GetUnitUptime(c.Context(), unitID, claims.Branch, start, end)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": uptime})
+}
+
+// GetBranchUptime menampilkan rangkuman uptime semua unit pada branch
+// Query [branch, category, start, end] default branch user dan 30 hari terakhir
+func (u *uptimeHandler) GetBranchUptime(c *fiber.Ctx) error {
+	claims := c.Locals(mjwt.CLAIMS).(*mjwt.CustomClaim)
+	branch := c.Query("branch")
+	if branch == "" {
+		branch = claims.Branch
+	}
+	category := c.Query("category")
+	start, end := uptimeRangeFromQuery(c)
+
+	summary, apiErr := u.service.GetBranchUptime(c.Context(), branch, category, start, end)
+	if apiErr != nil {
+		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
+	}
+
+	return c.JSON(fiber.Map{"error": nil, "data": summary})
+}
+
+func uptimeRangeFromQuery(c *fiber.Ctx) (int64, int64) {
+	end := int64(stringToInt(c.Query("end")))
+	if end == 0 {
+		end = time.Now().Unix()
+	}
+	start := int64(stringToInt(c.Query("start")))
+	if start == 0 {
+		start = end - defaultUptimeRange
+	}
+	return start, end
+}
@@ -84,23 +84,21 @@ func (vc *venPhyCheckHandler) Get(c *fiber.Ctx) error {
 }
 
 // Find menampilkan list check
-// Query [branch, start, end, limit]
+// Query [branch, start, end, limit, cursor]
 func (vc *venPhyCheckHandler) Find(c *fiber.Ctx) error {
 	branch := c.Query("branch")
 	start := stringToInt(c.Query("start"))
 	end := stringToInt(c.Query("end"))
-	limit := stringToInt(c.Query("limit"))
 
-	checkList, apiErr := vc.service.FindVenPhyCheck(c.Context(), branch, dto.FilterTimeRangeLimit{
+	checkList, page, apiErr := vc.service.FindVenPhyCheck(c.Context(), branch, dto.FilterTimeRangeLimit{
 		FilterStart: int64(start),
 		FilterEnd:   int64(end),
-		Limit:       int64(limit),
-	})
+	}, pageRequest(c))
 	if apiErr != nil {
 		return c.Status(apiErr.Status()).JSON(fiber.Map{"error": apiErr, "data": nil})
 	}
 
-	return c.JSON(fiber.Map{"error": nil, "data": checkList})
+	return c.JSON(fiber.Map{"error": nil, "data": checkList, "page": page})
 }
 
 func (vc *venPhyCheckHandler) UpdateCheckItem(c *fiber.Ctx) error {
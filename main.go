@@ -1,16 +1,27 @@
 package main
 
 import (
+	"flag"
 	"github.com/joho/godotenv"
 	"github.com/muchlist/risa_restfull/app"
 	"log"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate", false, "jalankan migrasi database lalu keluar tanpa menjalankan server")
+	flag.Parse()
+
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
 
+	if *migrateOnly {
+		if err := app.RunMigration(); err != nil {
+			log.Fatal("Migrasi database gagal: ", err)
+		}
+		return
+	}
+
 	app.RunApp()
 }
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/apikey"
+)
+
+const (
+	apiKeyHeader = "X-Api-Key"
+)
+
+// ApiKeyValidator diimplementasikan oleh service.ApiKeyServiceAssumer
+type ApiKeyValidator interface {
+	ValidateApiKey(ctx context.Context, key string, scope string, ip string) (*dto.ApiKeyClaim, rest_err.APIError)
+}
+
+// ApiKeyAuth digunakan untuk endpoint machine to machine (pinger).
+// Key harus memiliki scope yang sesuai agar diloloskan ke proses berikutnya
+func ApiKeyAuth(validator ApiKeyValidator, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(apiKeyHeader)
+		claims, err := validator.ValidateApiKey(c.Context(), key, scope, c.IP())
+		if err != nil {
+			return c.Status(err.Status()).JSON(fiber.Map{"error": err, "data": nil})
+		}
+		c.Locals(apikey.KEYCLAIMS, claims)
+		return c.Next()
+	}
+}
@@ -0,0 +1,281 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// backfillHistoryVersion mengisi version = 1 pada history lama yang dibuat sebelum field version ada.
+// nilai null juga cocok untuk dokumen yang tidak memiliki field version
+func backfillHistoryVersion(ctx context.Context, database *mongo.Database) error {
+	filter := bson.M{"version": bson.M{"$in": bson.A{nil, 0}}}
+	update := bson.M{"$set": bson.M{"version": 1}}
+
+	result, err := database.Collection("history").UpdateMany(ctx, filter, update)
+	if err != nil {
+		logger.Error("Gagal backfill version history (backfillHistoryVersion)", err)
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("backfill version history: %d dokumen diperbarui", result.ModifiedCount))
+	return nil
+}
+
+// branchColl collection yang menyimpan field branch dan difilter secara exact match
+var branchColl = []string{
+	"user", "cctv", "computer", "other", "stock", "genUnit", "history",
+	"check", "checkItem", "vendorCheck", "venPhyCheck", "altaiCheck", "altaiPhyCheck", "configCheck",
+	"improve", "pendingReport", "pdf", "apiKey", "job", "jobRun", "alertRule", "alertLog", "audit",
+}
+
+// normalizeBranchCase menyeragamkan field branch menjadi huruf kapital.
+// hanya dokumen yang mengandung huruf kecil yang disentuh
+func normalizeBranchCase(ctx context.Context, database *mongo.Database) error {
+	filter := bson.M{"branch": bson.M{"$regex": "[a-z]"}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "branch", Value: bson.D{{Key: "$toUpper", Value: "$branch"}}}}}},
+	}
+
+	for _, collName := range branchColl {
+		result, err := database.Collection(collName).UpdateMany(ctx, filter, update)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Gagal normalisasi branch pada %s (normalizeBranchCase)", collName), err)
+			return err
+		}
+		if result.ModifiedCount > 0 {
+			logger.Info(fmt.Sprintf("normalisasi branch %s: %d dokumen diperbarui", collName, result.ModifiedCount))
+		}
+	}
+	return nil
+}
+
+// geoColl collection inventaris yang memiliki location_lat dan location_lon
+var geoColl = []string{"cctv", "computer", "other"}
+
+// backfillGeoPoint mengisi field geo (GeoJSON Point) dari location_lat dan location_lon yang berupa string
+// lalu membuat index 2dsphere. koordinat yang tidak valid dibiarkan tanpa geo dan dicatat jumlahnya
+func backfillGeoPoint(ctx context.Context, database *mongo.Database) error {
+	filter := bson.M{
+		"geo":          bson.M{"$exists": false},
+		"location_lat": bson.M{"$nin": bson.A{nil, ""}},
+		"location_lon": bson.M{"$nin": bson.A{nil, ""}},
+	}
+
+	for _, collName := range geoColl {
+		coll := database.Collection(collName)
+		cursor, err := coll.Find(ctx, filter)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Gagal membaca koordinat %s (backfillGeoPoint)", collName), err)
+			return err
+		}
+
+		var models []mongo.WriteModel
+		var invalid int
+		for cursor.Next(ctx) {
+			var doc struct {
+				ID          primitive.ObjectID `bson:"_id"`
+				LocationLat string             `bson:"location_lat"`
+				LocationLon string             `bson:"location_lon"`
+			}
+			if err := cursor.Decode(&doc); err != nil {
+				invalid++
+				continue
+			}
+			point := dto.NewGeoPoint(doc.LocationLat, doc.LocationLon)
+			if point == nil {
+				invalid++
+				continue
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": doc.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"geo": point}}))
+		}
+		err = cursor.Err()
+		_ = cursor.Close(ctx)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Gagal membaca koordinat %s (backfillGeoPoint)", collName), err)
+			return err
+		}
+
+		if len(models) > 0 {
+			result, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+			if err != nil {
+				logger.Error(fmt.Sprintf("Gagal backfill geo %s (backfillGeoPoint)", collName), err)
+				return err
+			}
+			logger.Info(fmt.Sprintf("backfill geo %s: %d dokumen diperbarui", collName, result.ModifiedCount))
+		}
+		if invalid > 0 {
+			logger.Info(fmt.Sprintf("backfill geo %s: %d dokumen memiliki koordinat tidak valid", collName, invalid))
+		}
+
+		if err := ensureIndexes(ctx, coll, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "geo", Value: "2dsphere"}}},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedMasterData mengisi collection masterData dari nilai konstanta sebelumnya.
+// seed hanya dijalankan jika collection masih kosong agar perubahan admin tidak tertimpa
+func seedMasterData(ctx context.Context, database *mongo.Database) error {
+	coll := database.Collection("masterData")
+	if err := ensureIndexes(ctx, coll, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "value", Value: 1}, {Key: "branch", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}); err != nil {
+		return err
+	}
+
+	count, err := coll.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		logger.Error("Gagal menghitung master data (seedMasterData)", err)
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	result, err := coll.InsertMany(ctx, masterSeedDocuments(masterdata.Defaults(), time.Now().Unix()))
+	if err != nil {
+		logger.Error("Gagal seed master data (seedMasterData)", err)
+		return err
+	}
+	logger.Info(fmt.Sprintf("seed master data: %d dokumen ditambahkan", len(result.InsertedIDs)))
+	return nil
+}
+
+// masterSeedDocuments order mengikuti urutan nilai pada setiap kind
+func masterSeedDocuments(entries []masterdata.Entry, timeNow int64) []interface{} {
+	orders := make(map[string]int)
+	docs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		docs[i] = dto.MasterData{
+			ID:          primitive.NewObjectID(),
+			CreatedAt:   timeNow,
+			CreatedBy:   "SYSTEM",
+			CreatedByID: "SYSTEM",
+			UpdatedAt:   timeNow,
+			UpdatedBy:   "SYSTEM",
+			UpdatedByID: "SYSTEM",
+			Kind:        e.Kind,
+			Value:       e.Value,
+			Branch:      e.Branch,
+			Lang:        e.Lang,
+			Order:       orders[e.Kind],
+		}
+		orders[e.Kind]++
+	}
+	return docs
+}
+
+// backfillAlertAutoCase mengisi auto_case_after pada rule alert yang dibuat sebelum field tersebut ada,
+// tanpa backfill rule lama terbaca 0 yang berarti auto case nonaktif. nilai 0 yang disimpan user tidak disentuh
+func backfillAlertAutoCase(ctx context.Context, database *mongo.Database) error {
+	filter := bson.M{"auto_case_after": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"auto_case_after": dto.DefaultAutoCaseAfter}}
+
+	result, err := database.Collection("alertRule").UpdateMany(ctx, filter, update)
+	if err != nil {
+		logger.Error("Gagal backfill auto_case_after alert rule (backfillAlertAutoCase)", err)
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("backfill auto_case_after alert rule: %d dokumen diperbarui", result.ModifiedCount))
+	return nil
+}
+
+// backfillBranchLang mengisi bahasa header export pada master data BRANCH yang di-seed sebelum field lang ada,
+// nilainya mengikuti masterdata.Defaults. cabang tambahan atau yang sudah diatur admin tidak disentuh
+func backfillBranchLang(ctx context.Context, database *mongo.Database) error {
+	coll := database.Collection("masterData")
+	var modified int64
+	for _, e := range masterdata.Defaults() {
+		if e.Kind != masterkind.Branch || e.Lang == "" {
+			continue
+		}
+		filter := bson.M{"kind": e.Kind, "value": e.Value, "lang": bson.M{"$exists": false}}
+		result, err := coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lang": e.Lang}})
+		if err != nil {
+			logger.Error("Gagal backfill lang master data branch (backfillBranchLang)", err)
+			return err
+		}
+		modified += result.ModifiedCount
+	}
+
+	logger.Info(fmt.Sprintf("backfill lang master data branch: %d dokumen diperbarui", modified))
+	return nil
+}
+
+// seedDefaultJob mengisi jadwal bawaan untuk setiap pasangan tipe dan branch yang belum ada.
+// dijalankan sekali agar job yang dihapus admin tidak dibuat ulang, branch baru mendapatkan jadwal bawaan
+// saat master data branch ditambahkan
+func seedDefaultJob(ctx context.Context, database *mongo.Database) error {
+	branchList, err := database.Collection("masterData").Distinct(ctx, "value", bson.M{"kind": masterkind.Branch})
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar branch (seedDefaultJob)", err)
+		return err
+	}
+
+	coll := database.Collection("job")
+	timeNow := time.Now().Unix()
+	var inserted int
+	for _, b := range branchList {
+		branch, ok := b.(string)
+		if !ok {
+			continue
+		}
+
+		existType, err := coll.Distinct(ctx, "type", bson.M{"branch": branch})
+		if err != nil {
+			logger.Error("Gagal mendapatkan tipe job branch (seedDefaultJob)", err)
+			return err
+		}
+
+		docs := defaultJobDocuments(branch, existType, timeNow)
+		if len(docs) == 0 {
+			continue
+		}
+		result, err := coll.InsertMany(ctx, docs)
+		if err != nil {
+			logger.Error("Gagal seed job bawaan (seedDefaultJob)", err)
+			return err
+		}
+		inserted += len(result.InsertedIDs)
+	}
+
+	logger.Info(fmt.Sprintf("seed job bawaan: %d dokumen ditambahkan", inserted))
+	return nil
+}
+
+// defaultJobDocuments jadwal bawaan branch yang tipenya belum ada pada existType
+func defaultJobDocuments(branch string, existType []interface{}, timeNow int64) []interface{} {
+	exist := make(map[string]bool)
+	for _, t := range existType {
+		if jobType, ok := t.(string); ok {
+			exist[jobType] = true
+		}
+	}
+
+	var docs []interface{}
+	for _, job := range dto.DefaultJobs(branch, timeNow) {
+		if exist[job.Type] {
+			continue
+		}
+		job.Name = strings.ToUpper(job.Name)
+		docs = append(docs, job)
+	}
+	return docs
+}
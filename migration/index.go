@@ -0,0 +1,206 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// codeNamespaceExists kode error mongodb apabila collection sudah dibuat
+	codeNamespaceExists = 48
+	// codeIndexOptionsConflict dan codeIndexKeySpecsConflict muncul apabila index serupa sudah ada
+	// dengan nama atau opsi berbeda, misalnya text index yang dibuat manual sebelum migrasi ada
+	codeIndexOptionsConflict  = 85
+	codeIndexKeySpecsConflict = 86
+)
+
+// createPingHistoryCollection membuat time-series collection pingHistory (mongodb 5.0 keatas).
+// Jika server tidak mendukung time-series, collection biasa akan dibuat otomatis saat insert pertama
+func createPingHistoryCollection(ctx context.Context, database *mongo.Database) error {
+	cmd := bson.D{
+		{Key: "create", Value: "pingHistory"},
+		{Key: "timeseries", Value: bson.D{
+			{Key: "timeField", Value: "time"},
+			{Key: "metaField", Value: "meta"},
+			{Key: "granularity", Value: "minutes"},
+		}},
+	}
+	err := database.RunCommand(ctx, cmd).Err()
+	if err != nil && !isCommandCode(err, codeNamespaceExists) {
+		logger.Error("Gagal membuat time-series collection pingHistory (createPingHistoryCollection)", err)
+	}
+
+	return ensureIndexes(ctx, database.Collection("pingHistory"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "meta.unit_id", Value: 1}, {Key: "time", Value: 1}}},
+	})
+}
+
+// branchIndexedColl collection inventaris yang daftarnya selalu difilter per cabang dan status disable
+var branchIndexedColl = []string{"cctv", "computer", "other", "stock"}
+
+// createInitialIndexes membuat index compound dan text index yang dibutuhkan query dao
+func createInitialIndexes(ctx context.Context, database *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"history": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "updated_at", Value: -1}}},
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "complete_status", Value: 1}, {Key: "updated_at", Value: -1}}},
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "category", Value: 1}, {Key: "updated_at", Value: -1}}},
+			{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "created_by_id", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "tag", Value: 1}}},
+			{
+				Keys: bson.D{
+					{Key: "problem", Value: "text"},
+					{Key: "problem_resolve", Value: "text"},
+					{Key: "parent_name", Value: "text"},
+					{Key: "status", Value: "text"},
+				},
+				Options: options.Index().SetName("history_text"),
+			},
+		},
+		"genUnit": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "category", Value: 1}, {Key: "name", Value: 1}}},
+			{Keys: bson.D{{Key: "ip", Value: 1}}},
+			{Keys: bson.D{{Key: "deleted", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		},
+		"check": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "is_finish", Value: 1}}},
+		},
+		"checkItem": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "name", Value: 1}}},
+		},
+		"vendorCheck": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"venPhyCheck": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"altaiCheck": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"altaiPhyCheck": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"configCheck": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"audit": {
+			{Keys: bson.D{{Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "time", Value: -1}}},
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "time", Value: -1}}},
+			{Keys: bson.D{{Key: "time", Value: -1}}},
+		},
+		"apiKey": {
+			{Keys: bson.D{{Key: "hash_key", Value: 1}}},
+		},
+		"job": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "type", Value: 1}}},
+		},
+		"jobRun": {
+			{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "started_at", Value: -1}}},
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "started_at", Value: -1}}},
+		},
+		"alertRule": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "category", Value: 1}}},
+		},
+		"alertLog": {
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "time", Value: -1}}},
+			{Keys: bson.D{{Key: "unit_id", Value: 1}, {Key: "time", Value: -1}}},
+		},
+	}
+
+	for _, collName := range branchIndexedColl {
+		indexes[collName] = []mongo.IndexModel{
+			{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "disable", Value: 1}, {Key: "name", Value: 1}}},
+			{Keys: bson.D{{Key: "deleted", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		}
+	}
+
+	for collName, models := range indexes {
+		if err := ensureIndexes(ctx, database.Collection(collName), models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTransferIndexes index untuk daftar transfer per cabang asal / tujuan dan pengecekan unit yang sedang ditransfer
+func createTransferIndexes(ctx context.Context, database *mongo.Database) error {
+	return ensureIndexes(ctx, database.Collection("transfer"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "from_branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "to_branch", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "units.id", Value: 1}, {Key: "state", Value: 1}}},
+	})
+}
+
+// createCustomFieldIndexes schema custom field bersifat unik per category
+func createCustomFieldIndexes(ctx context.Context, database *mongo.Database) error {
+	return ensureIndexes(ctx, database.Collection("customField"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "category", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+}
+
+// createDataQualityIndexes index untuk pengecekan ip dan nomor inventaris saat insert / edit
+// serta laporan data quality yang unik per branch
+func createDataQualityIndexes(ctx context.Context, database *mongo.Database) error {
+	for _, coll := range []string{"cctv", "computer", "other"} {
+		if err := ensureIndexes(ctx, database.Collection(coll), []mongo.IndexModel{
+			{Keys: bson.D{{Key: "inventory_number", Value: 1}}},
+		}); err != nil {
+			return err
+		}
+	}
+	if err := ensureIndexes(ctx, database.Collection("genUnit"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "ip", Value: 1}}},
+	}); err != nil {
+		return err
+	}
+	return ensureIndexes(ctx, database.Collection("dataQuality"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "branch", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+}
+
+// createSLAIndexes policy SLA unik per category dan priority
+func createSLAIndexes(ctx context.Context, database *mongo.Database) error {
+	return ensureIndexes(ctx, database.Collection("slaPolicy"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "priority", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+}
+
+// createAssigneeIndexes digunakan oleh daftar insiden milik user dan ringkasan workload
+func createAssigneeIndexes(ctx context.Context, database *mongo.Database) error {
+	return ensureIndexes(ctx, database.Collection("history"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "branch", Value: 1}, {Key: "assignee_id", Value: 1}, {Key: "complete_status", Value: 1}}},
+	})
+}
+
+// createCommentIndexes digunakan oleh daftar komentar history dan timeline unwind
+func createCommentIndexes(ctx context.Context, database *mongo.Database) error {
+	return ensureIndexes(ctx, database.Collection("historyComment"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "history_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+	})
+}
+
+// ensureIndexes membuat index satu per satu agar konflik pada satu index tidak menggagalkan index lain.
+// Index yang sudah ada dengan nama atau opsi berbeda dianggap sudah terpenuhi
+func ensureIndexes(ctx context.Context, coll *mongo.Collection, models []mongo.IndexModel) error {
+	for _, model := range models {
+		_, err := coll.Indexes().CreateOne(ctx, model)
+		if err == nil {
+			continue
+		}
+		if isCommandCode(err, codeIndexOptionsConflict, codeIndexKeySpecsConflict) {
+			logger.Info(fmt.Sprintf("index %v pada %s sudah ada dengan opsi berbeda, dilewati", model.Keys, coll.Name()))
+			continue
+		}
+		logger.Error(fmt.Sprintf("Gagal membuat index pada %s (ensureIndexes)", coll.Name()), err)
+		return err
+	}
+	return nil
+}
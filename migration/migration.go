@@ -0,0 +1,158 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// migrationTimeout batas waktu satu migrasi, migrasi data bisa menyentuh seluruh dokumen collection
+	migrationTimeout = 300
+	connectTimeout   = 10
+
+	keyMigrationColl = "_migrations"
+
+	keyMigID        = "_id"
+	keyMigName      = "name"
+	keyMigAppliedAt = "applied_at"
+)
+
+// Migration adalah satu langkah perubahan skema atau data yang diberi nomor versi.
+// Version harus unik dan tidak boleh diubah setelah migrasi dirilis
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, database *mongo.Database) error
+}
+
+// registry berisi seluruh migrasi yang dikenal aplikasi, urutan eksekusi ditentukan oleh Version
+var registry = []Migration{
+	{Version: 1, Name: "ping_history_timeseries", Up: createPingHistoryCollection},
+	{Version: 2, Name: "initial_indexes", Up: createInitialIndexes},
+	{Version: 3, Name: "history_backfill_version", Up: backfillHistoryVersion},
+	{Version: 4, Name: "normalize_branch_case", Up: normalizeBranchCase},
+	{Version: 5, Name: "transfer_indexes", Up: createTransferIndexes},
+	{Version: 6, Name: "geo_point_backfill", Up: backfillGeoPoint},
+	{Version: 7, Name: "custom_field_indexes", Up: createCustomFieldIndexes},
+	{Version: 8, Name: "data_quality_indexes", Up: createDataQualityIndexes},
+	{Version: 9, Name: "master_data_seed", Up: seedMasterData},
+	{Version: 10, Name: "sla_policy_indexes", Up: createSLAIndexes},
+	{Version: 11, Name: "history_assignee_indexes", Up: createAssigneeIndexes},
+	{Version: 12, Name: "history_comment_indexes", Up: createCommentIndexes},
+	{Version: 13, Name: "alert_rule_auto_case_backfill", Up: backfillAlertAutoCase},
+	{Version: 14, Name: "branch_lang_backfill", Up: backfillBranchLang},
+	{Version: 15, Name: "job_default_seed", Up: seedDefaultJob},
+}
+
+// Run menjalankan seluruh migrasi yang belum tercatat di collection _migrations secara berurutan.
+// Eksekusi berhenti pada migrasi pertama yang gagal sehingga urutan versi tetap terjaga
+func Run(ctx context.Context, database *mongo.Database) error {
+	migrations, err := sortMigrations(registry)
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("menjalankan migrasi %d %s", m.Version, m.Name))
+		if err := runOne(ctx, database, m); err != nil {
+			logger.Error(fmt.Sprintf("Gagal menjalankan migrasi %d %s (Run)", m.Version, m.Name), err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runOne(ctx context.Context, database *mongo.Database, m Migration) error {
+	ctxt, cancel := context.WithTimeout(ctx, migrationTimeout*time.Second)
+	defer cancel()
+
+	if err := m.Up(ctxt, database); err != nil {
+		return err
+	}
+
+	_, err := database.Collection(keyMigrationColl).InsertOne(ctxt, bson.D{
+		{Key: keyMigID, Value: m.Version},
+		{Key: keyMigName, Value: m.Name},
+		{Key: keyMigAppliedAt, Value: time.Now().Unix()},
+	})
+	return err
+}
+
+// appliedVersions mengembalikan daftar versi migrasi yang sudah pernah dijalankan
+func appliedVersions(ctx context.Context, database *mongo.Database) (map[int]bool, error) {
+	ctxt, cancel := context.WithTimeout(ctx, connectTimeout*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(keyMigrationColl).Find(ctxt, bson.M{})
+	if err != nil {
+		logger.Error("Gagal mendapatkan daftar migrasi dari database (appliedVersions)", err)
+		return nil, err
+	}
+
+	var records []struct {
+		Version int `bson:"_id"`
+	}
+	if err := cursor.All(ctxt, &records); err != nil {
+		logger.Error("Gagal decode daftar migrasi (appliedVersions)", err)
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, r := range records {
+		applied[r.Version] = true
+	}
+	return applied, nil
+}
+
+// sortMigrations mengurutkan migrasi berdasarkan versi dan memastikan versi valid serta tidak ganda
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("versi migrasi %s harus lebih dari 0", m.Name)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("migrasi %d %s tidak memiliki fungsi Up", m.Version, m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("versi migrasi %d ganda", m.Version)
+		}
+	}
+	return sorted, nil
+}
+
+// isCommandCode memeriksa apakah error berasal dari perintah mongodb dengan kode tertentu
+func isCommandCode(err error, codes ...int32) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	for _, code := range codes {
+		if cmdErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func noop(ctx context.Context, database *mongo.Database) error {
+	return nil
+}
+
+func TestSortMigrations(t *testing.T) {
+	sorted, err := sortMigrations([]Migration{
+		{Version: 3, Name: "c", Up: noop},
+		{Version: 1, Name: "a", Up: noop},
+		{Version: 2, Name: "b", Up: noop},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{sorted[0].Name, sorted[1].Name, sorted[2].Name})
+}
+
+func TestSortMigrationsDuplicateVersion(t *testing.T) {
+	_, err := sortMigrations([]Migration{
+		{Version: 1, Name: "a", Up: noop},
+		{Version: 1, Name: "b", Up: noop},
+	})
+
+	assert.NotNil(t, err)
+}
+
+func TestSortMigrationsInvalid(t *testing.T) {
+	_, err := sortMigrations([]Migration{{Version: 0, Name: "a", Up: noop}})
+	assert.NotNil(t, err)
+
+	_, err = sortMigrations([]Migration{{Version: 1, Name: "a"}})
+	assert.NotNil(t, err)
+}
+
+func TestRegistryValid(t *testing.T) {
+	_, err := sortMigrations(registry)
+	assert.Nil(t, err)
+}
+
+func TestMasterSeedDocuments(t *testing.T) {
+	docs := masterSeedDocuments([]masterdata.Entry{
+		{Kind: masterkind.Branch, Value: "BANJARMASIN"},
+		{Kind: masterkind.Location, Value: "Trisakti", Branch: "BANJARMASIN"},
+		{Kind: masterkind.Branch, Value: "SAMPIT"},
+		{Kind: masterkind.Location, Value: "Lainnya"},
+	}, 100)
+
+	assert.Len(t, docs, 4)
+	assert.Equal(t, 1, docs[2].(dto.MasterData).Order)
+	assert.Equal(t, 1, docs[3].(dto.MasterData).Order)
+	assert.Equal(t, "BANJARMASIN", docs[1].(dto.MasterData).Branch)
+	assert.Equal(t, int64(100), docs[3].(dto.MasterData).CreatedAt)
+}
+
+func TestDefaultJobDocuments(t *testing.T) {
+	docs := defaultJobDocuments("SAMPIT", []interface{}{jobtype.PingAlert, jobtype.TrashPurge}, 100)
+
+	assert.Len(t, docs, len(dto.DefaultJobs("SAMPIT", 100))-2)
+	for _, d := range docs {
+		job := d.(dto.Job)
+		assert.NotEqual(t, jobtype.PingAlert, job.Type)
+		assert.NotEqual(t, jobtype.TrashPurge, job.Type)
+		assert.Equal(t, "SAMPIT", job.Branch)
+		assert.Equal(t, strings.ToUpper(job.Name), job.Name)
+	}
+}
@@ -3,92 +3,108 @@ package scheduller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-co-op/gocron"
 	"github.com/muchlist/erru_utils_go/logger"
-	"github.com/muchlist/risa_restfull/constants/category"
-	"github.com/muchlist/risa_restfull/constants/pdftype"
+	"github.com/muchlist/risa_restfull/constants/jobtype"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/service"
-	"github.com/muchlist/risa_restfull/utils/timegen"
 )
 
-func RunScheduler(
-	genUnitService service.GenUnitServiceAssumer,
-	reportService service.ReportServiceAssumer,
-) {
+// RunScheduler menjalankan job yang terdaftar pada registry job di database.
+// perubahan registry dimuat ulang tanpa restart, baik dari sinyal jobService maupun polling tiap menit
+func RunScheduler(jobService service.JobServiceAssumer) {
 	witaTimeZone, err := time.LoadLocation("Asia/Makassar")
 	if err != nil {
 		logger.Error("gagal menggunakan timezone wita", err)
+		witaTimeZone = time.Local
 	}
 	s := gocron.NewScheduler(witaTimeZone)
 
-	// run check cctv
-	_, _ = s.Every(2).Hours().StartAt(time.Now().Add(time.Second * 30)).Do(func() {
-		runCctvCheckBanjarmasin(genUnitService)
-	})
+	registry := &jobRegistry{
+		scheduler:  s,
+		jobService: jobService,
+		entries:    make(map[string]registeredJob),
+	}
+	registry.sync()
 
-	// run report vendor berjalan setiap jam 1 malam tanggal 1 bulan berjalan
-	_, _ = s.Every(1).Month(1).At("01:00").Do(func() {
-		runReportGeneratorVendormonthlyBanjarmasin(reportService)
-	})
+	// polling registry, menangkap perubahan dari instance lain
+	_, _ = s.Every(1).Minute().WaitForSchedule().Do(registry.sync)
+
+	go func() {
+		for range jobService.Changed() {
+			registry.sync()
+		}
+	}()
 
 	s.StartAsync()
 }
 
-func runCctvCheckBanjarmasin(genUnitService service.GenUnitServiceAssumer) {
-	_ = genUnitService.CheckHardwareDownAndSendNotif(context.Background(), "BANJARMASIN", category.Cctv)
+type registeredJob struct {
+	signature string
+	ref       *gocron.Job
 }
 
-func runReportGeneratorVendormonthlyBanjarmasin(reportService service.ReportServiceAssumer) {
+type jobRegistry struct {
+	mu         sync.Mutex
+	scheduler  *gocron.Scheduler
+	jobService service.JobServiceAssumer
+	entries    map[string]registeredJob
+}
 
-	// berjalan setiap tanggal 1 bulan sekarang jam 00.01
-	// cari tanggal terakhir bulan sebelumnya jam 24.00 --- >  time end
-	// cari tanggal awal bulan sebelumnya jam 00.00   --- >  time start
+// sync menyamakan jadwal gocron dengan job aktif di database,
+// hanya job yang berubah (cron atau updated_at) yang dijadwalkan ulang
+func (r *jobRegistry) sync() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	loc, _ := time.LoadLocation("Asia/Makassar")
-	timeNow := time.Now().In(loc)
-	month := timeNow.Month()
-	beforeMonth := int(month) - 1
-	if beforeMonth == 0 {
-		beforeMonth = 12
+	jobList, _, apiErr := r.jobService.FindJob(context.Background(), dto.FilterJob{EnabledOnly: true}, dto.PageRequest{All: true})
+	if apiErr != nil {
+		logger.Error("gagal memuat registry job", apiErr)
+		return
 	}
 
-	// tanggal awal
-	timeStart := time.Date(timeNow.Year(), time.Month(beforeMonth), 1, 00, 00, 00, 0, loc)
-	timeEnd := timeStart.AddDate(0, 1, 0).Add(time.Second * -1)
-
-	timeStartUnix := timeStart.Unix()
-	timeEndUnix := timeEnd.Unix()
-
-	pdfName, err := timegen.GetTimeAsName(timeEndUnix)
-	if err != nil {
-		logger.Error("gagal membuat nama pdf", err)
+	active := make(map[string]dto.Job, len(jobList))
+	for _, job := range jobList {
+		active[job.ID.Hex()] = job
 	}
-	pdfName = fmt.Sprintf("vendor-monthly%s", pdfName)
 
-	_, apiErr := reportService.GenerateReportPDFVendorMonthly(context.Background(), pdfName, "BANJARMASIN", timeStartUnix, timeEndUnix, false)
-	if apiErr != nil {
-		logger.Error(apiErr.Message(), apiErr)
+	// hapus job yang dihapus, dinonaktifkan atau berubah
+	for id, entry := range r.entries {
+		job, ok := active[id]
+		if ok && entry.signature == jobSignature(job) {
+			continue
+		}
+		r.scheduler.RemoveByReference(entry.ref)
+		delete(r.entries, id)
 	}
 
-	// simpan pdf ke database
-	_, apiErr = reportService.InsertPdf(context.Background(), dto.PdfFile{
-		CreatedAt:     time.Now().Unix(),
-		CreatedBy:     "SYSTEM",
-		Branch:        "BANJARMASIN",
-		Name:          pdfName,
-		Type:          pdftype.VendorMonthly,
-		FileName:      fmt.Sprintf("pdf-v-month/%s.pdf", pdfName),
-		EndReportTime: timeEndUnix,
-	})
-
-	if apiErr != nil {
-		logger.Error(apiErr.Message(), apiErr)
+	// tambahkan job baru atau yang berubah
+	for id, job := range active {
+		if _, ok := r.entries[id]; ok {
+			continue
+		}
+		jobToRun := job
+		ref, err := r.scheduler.Cron(job.Cron).WaitForSchedule().Do(func() {
+			r.jobService.ExecuteJob(context.Background(), jobToRun, jobtype.TriggerSchedule, "SYSTEM")
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("gagal menjadwalkan job %s dengan cron %s", job.Name, job.Cron), err)
+			continue
+		}
+		r.entries[id] = registeredJob{
+			signature: jobSignature(job),
+			ref:       ref,
+		}
 	}
 }
 
+func jobSignature(job dto.Job) string {
+	return fmt.Sprintf("%s|%d", job.Cron, job.UpdatedAt)
+}
+
 /*
 func runReportGeneratorBanjarmasin(reportService service.ReportServiceAssumer) {
 	timeNow := time.Now().Unix()
@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/alertstate"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/histtag"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dao/alertdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultAlertRule digunakan jika belum ada rule untuk branch dan category unit
+// rule lama tanpa auto_case_after diisi nilai yang sama oleh migrasi alert_rule_auto_case_backfill
+var defaultAlertRule = dto.AlertRule{
+	DegradedThreshold: 2,
+	DownThreshold:     3,
+	RecoverThreshold:  2,
+	NotifyDegraded:    false,
+	AutoCaseAfter:     dto.DefaultAutoCaseAfter,
+}
+
+// pingStaleAfter batas umur ping terbaru (detik). unit yang tidak lagi menerima ping
+// melewati batas ini dianggap DOWN, atau UNREACHABLE jika induknya DOWN
+const pingStaleAfter int64 = 30 * 60
+
+// systemClaim user yang digunakan saat sistem membuat history secara otomatis
+func systemClaim(branch string) mjwt.CustomClaim {
+	return mjwt.CustomClaim{
+		Identity: "SYSTEM",
+		Name:     "SYSTEM",
+		Branch:   branch,
+	}
+}
+
+func NewAlertService(alertDao alertdao.AlertDaoAssumer,
+	genDao genunitdao.GenUnitDaoAssumer,
+	userDao userdao.UserLoader,
+	histService HistoryServiceAssumer,
+	fcmClient fcm.ClientAssumer) AlertServiceAssumer {
+	return &alertService{
+		daoA:      alertDao,
+		daoG:      genDao,
+		daoU:      userDao,
+		servH:     histService,
+		fcmClient: fcmClient,
+	}
+}
+
+type alertService struct {
+	daoA      alertdao.AlertDaoAssumer
+	daoG      genunitdao.GenUnitDaoAssumer
+	daoU      userdao.UserLoader
+	servH     HistoryServiceAssumer
+	fcmClient fcm.ClientAssumer
+}
+type AlertServiceAssumer interface {
+	EvaluateBranch(ctx context.Context, branch string, category string) rest_err.APIError
+	EvaluateAll(ctx context.Context) rest_err.APIError
+	AcknowledgeAlert(ctx context.Context, user mjwt.CustomClaim, alertID string, note string) (*dto.AlertLog, rest_err.APIError)
+	FindAlertLog(ctx context.Context, filter dto.FilterAlertLog, page dto.PageRequest) ([]dto.AlertLog, dto.PageInfo, rest_err.APIError)
+
+	UpsertRule(ctx context.Context, user mjwt.CustomClaim, input dto.AlertRuleRequest) (*dto.AlertRule, rest_err.APIError)
+	DeleteRule(ctx context.Context, ruleID string) rest_err.APIError
+	FindRule(ctx context.Context) ([]dto.AlertRule, rest_err.APIError)
+}
+
+// EvaluateAll menjalankan EvaluateBranch untuk semua branch dan category
+func (a *alertService) EvaluateAll(ctx context.Context) rest_err.APIError {
+	var lastErr rest_err.APIError
+	for _, branch := range masterdata.Values(masterkind.Branch) {
+		if err := a.EvaluateBranch(ctx, branch, ""); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// EvaluateBranch menghitung state alert terbaru setiap unit berdasarkan pings_state dan umur ping terbaru,
+// mencatat perpindahan state ke alert log dan mengirim notifikasi hanya saat terjadi perpindahan.
+// aman dijalankan berulang kali karena perpindahan state bersifat atomic
+func (a *alertService) EvaluateBranch(ctx context.Context, branch string, category string) rest_err.APIError {
+	rules, err := a.daoA.FindRule(ctx)
+	if err != nil {
+		return err
+	}
+
+	// seluruh category dimuat supaya state unit induk (uplink) dapat ditelusuri
+	units, _, err := a.daoG.FindUnit(ctx, dto.GenUnitFilter{
+		Branch: branch,
+		Pings:  true,
+	}, dto.PageRequest{All: true})
+	if err != nil {
+		logger.Error("mendapatkan unit gagal saat evaluasi alert (EvaluateBranch)", err)
+		return err
+	}
+
+	category = strings.ToUpper(category)
+	timeNow := time.Now().Unix()
+	states := resolveAlertStates(units, rules, category, timeNow)
+
+	transitions := make(map[string][]dto.GenUnitResponse) // state tujuan -> unit yang perlu dinotifikasi
+	affected := make(map[string]int)                      // id unit DOWN -> jumlah turunan yang menjadi UNREACHABLE
+	for _, unit := range units {
+		if category != "" && unit.Category != category {
+			continue
+		}
+		rule := pickAlertRule(rules, unit.Branch, unit.Category)
+		if rule.Disable {
+			continue
+		}
+
+		current := unit.AlertState
+		if current == "" {
+			current = alertstate.OK
+		}
+		next := states[unit.ID]
+		if next == current {
+			if current == alertstate.Down && shouldOpenAutoCase(unit, rule, timeNow) {
+				a.openAutoCase(ctx, unit)
+			}
+			continue
+		}
+
+		unitChanged, err := a.daoG.ChangeAlertState(ctx, dto.GenUnitAlertStateRequest{
+			UnitID:    unit.ID,
+			FromState: current,
+			ToState:   next,
+			Since:     timeNow,
+		})
+		if err != nil || unitChanged == nil {
+			// sudah diubah oleh evaluasi lain
+			continue
+		}
+
+		_, _ = a.daoA.InsertLog(ctx, dto.AlertLog{
+			Time:       timeNow,
+			UnitID:     unit.ID,
+			UnitName:   unit.Name,
+			Branch:     unit.Branch,
+			Category:   unit.Category,
+			FromState:  current,
+			ToState:    next,
+			PingsState: unit.PingsState,
+		})
+
+		if next == alertstate.Unreachable {
+			if root := unreachableRoot(units, states, unit.ID); root != "" {
+				affected[root]++
+			}
+		}
+
+		if shouldNotifyAlert(next, rule) {
+			transitions[next] = append(transitions[next], unit)
+		}
+
+		// unit UNREACHABLE pulih langsung ke OK
+		if (next == alertstate.Recovered || next == alertstate.OK) && unit.AutoCaseID != "" {
+			a.resolveAutoCase(ctx, unit, timeNow)
+		}
+	}
+
+	if len(transitions) != 0 {
+		go a.sendAlertNotification(context.Background(), branch, alertNotificationNames(transitions, affected))
+	}
+
+	return nil
+}
+
+// openAutoCase membuat history progress atas nama SYSTEM untuk unit yang DOWN terlalu lama.
+// auto_case_id diklaim terlebih dahulu supaya evaluasi yang berjalan bersamaan tidak membuat history ganda
+func (a *alertService) openAutoCase(ctx context.Context, unit dto.GenUnitResponse) {
+	historyID := primitive.NewObjectID().Hex()
+	claimed, err := a.daoG.ChangeAutoCase(ctx, dto.GenUnitAutoCaseRequest{
+		UnitID:     unit.ID,
+		FromCaseID: "",
+		ToCaseID:   historyID,
+	})
+	if err != nil || claimed == nil {
+		return
+	}
+
+	_, err = a.servH.InsertHistory(ctx, systemClaim(unit.Branch), dto.HistoryRequest{
+		ID:             historyID,
+		ParentID:       unit.ID,
+		Status:         "Down",
+		Problem:        fmt.Sprintf("Unit tidak dapat di ping sejak %s", time.Unix(unit.AlertSince, 0).Format("02 Jan 2006 15:04")),
+		CompleteStatus: enum.HProgress,
+		Tag:            []string{histtag.SystemAuto},
+	})
+	if err != nil {
+		logger.Error("membuat history otomatis gagal (openAutoCase)", err)
+		// lepas klaim supaya dicoba lagi pada evaluasi berikutnya
+		_, _ = a.daoG.ChangeAutoCase(ctx, dto.GenUnitAutoCaseRequest{
+			UnitID:     unit.ID,
+			FromCaseID: historyID,
+			ToCaseID:   "",
+		})
+	}
+}
+
+// resolveAutoCase menambahkan update pada history otomatis bahwa unit sudah kembali normal.
+// history tidak diselesaikan otomatis, penyelesaian tetap dilakukan oleh user
+func (a *alertService) resolveAutoCase(ctx context.Context, unit dto.GenUnitResponse, timeNow int64) {
+	if unit.AutoCaseID != "" {
+		_, err := a.servH.AppendUpdate(ctx, unit.AutoCaseID, dto.HistoryUpdate{
+			Time:           timeNow,
+			UpdatedBy:      "SYSTEM",
+			UpdatedByID:    "SYSTEM",
+			Problem:        "Unit sudah dapat di ping kembali",
+			ProblemResolve: "Disarankan untuk mengecek dan menyelesaikan insiden ini",
+			CompleteStatus: enum.HProgress,
+		})
+		if err != nil {
+			// history mungkin sudah diselesaikan user lebih dulu
+			logger.Info(fmt.Sprintf("update history otomatis %s tidak ditambahkan : %s", unit.AutoCaseID, err.Message()))
+		}
+	}
+
+	_, _ = a.daoG.ChangeAutoCase(ctx, dto.GenUnitAutoCaseRequest{
+		UnitID:     unit.ID,
+		FromCaseID: unit.AutoCaseID,
+		ToCaseID:   "",
+	})
+}
+
+// sendAlertNotification mengirim satu notifikasi per state tujuan
+func (a *alertService) sendAlertNotification(ctx context.Context, branch string, transitions map[string][]string) {
+	users, err := a.daoU.FindUser(ctx, branch)
+	if err != nil {
+		logger.Error("mendapatkan user gagal saat menambahkan fcm (sendAlertNotification)", err)
+		return
+	}
+	var tokens []string
+	for _, u := range users {
+		if u.FcmToken != "" {
+			tokens = append(tokens, u.FcmToken)
+		}
+	}
+
+	titles := map[string]string{
+		alertstate.Degraded:  "%d unit tidak stabil",
+		alertstate.Down:      "%d unit down",
+		alertstate.Recovered: "%d unit kembali normal",
+	}
+	for state, names := range transitions {
+		a.fcmClient.SendMessage(fcm.Payload{
+			Title:          fmt.Sprintf(titles[state], len(names)),
+			Message:        strings.Join(names, ", "),
+			ReceiverTokens: tokens,
+		})
+	}
+}
+
+func (a *alertService) AcknowledgeAlert(ctx context.Context, user mjwt.CustomClaim, alertID string, note string) (*dto.AlertLog, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(alertID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	alertLog, err := a.daoA.AckLog(ctx, dto.AlertLogAck{
+		FilterID:     oid,
+		FilterBranch: user.Branch,
+		AckAt:        time.Now().Unix(),
+		AckBy:        user.Name,
+		AckByID:      user.Identity,
+		AckNote:      note,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alertLog, nil
+}
+
+func (a *alertService) FindAlertLog(ctx context.Context, filter dto.FilterAlertLog, page dto.PageRequest) ([]dto.AlertLog, dto.PageInfo, rest_err.APIError) {
+	logs, pageInfo, err := a.daoA.FindLog(ctx, filter, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	return logs, pageInfo, nil
+}
+
+func (a *alertService) UpsertRule(ctx context.Context, user mjwt.CustomClaim, input dto.AlertRuleRequest) (*dto.AlertRule, rest_err.APIError) {
+	rule, err := a.daoA.UpsertRule(ctx, dto.AlertRule{
+		UpdatedAt:         time.Now().Unix(),
+		UpdatedBy:         user.Name,
+		UpdatedByID:       user.Identity,
+		Branch:            input.Branch,
+		Category:          input.Category,
+		DegradedThreshold: input.DegradedThreshold,
+		DownThreshold:     input.DownThreshold,
+		RecoverThreshold:  input.RecoverThreshold,
+		NotifyDegraded:    input.NotifyDegraded,
+		AutoCaseAfter:     input.AutoCaseAfter,
+		Disable:           input.Disable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (a *alertService) DeleteRule(ctx context.Context, ruleID string) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(ruleID)
+	if errT != nil {
+		return rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	return a.daoA.DeleteRule(ctx, oid)
+}
+
+func (a *alertService) FindRule(ctx context.Context) ([]dto.AlertRule, rest_err.APIError) {
+	rules, err := a.daoA.FindRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// pickAlertRule memilih rule yang paling spesifik : branch+category, category saja, lalu default
+func pickAlertRule(rules []dto.AlertRule, branch string, category string) dto.AlertRule {
+	var categoryRule *dto.AlertRule
+	for i := range rules {
+		if rules[i].Category != category {
+			continue
+		}
+		if rules[i].Branch == branch {
+			return rules[i]
+		}
+		if rules[i].Branch == "" {
+			categoryRule = &rules[i]
+		}
+	}
+	if categoryRule != nil {
+		return *categoryRule
+	}
+	return defaultAlertRule
+}
+
+// nextAlertState menentukan state berikutnya dari state saat ini dan ping terbaru.
+// pings diurutkan dari yang terbaru (index 0), sesuai pings_state pada gen_unit
+func nextAlertState(current string, pings []dto.PingState, rule dto.AlertRule) string {
+	downStreak := pingStreak(pings, func(code int) bool { return code == enum.PingDown })
+	badStreak := pingStreak(pings, func(code int) bool { return code != enum.PingUp })
+	upStreak := pingStreak(pings, func(code int) bool { return code == enum.PingUp })
+
+	switch current {
+	case alertstate.Down:
+		if upStreak >= rule.RecoverThreshold {
+			return alertstate.Recovered
+		}
+		return alertstate.Down
+	case alertstate.Degraded:
+		if downStreak >= rule.DownThreshold {
+			return alertstate.Down
+		}
+		if upStreak >= rule.RecoverThreshold {
+			return alertstate.OK
+		}
+		return alertstate.Degraded
+	case alertstate.Unreachable:
+		// tidak pernah dinotifikasi sehingga pulih langsung ke OK.
+		// DOWN dikembalikan menjadi UNREACHABLE oleh resolveAlertStates selama induknya masih DOWN
+		if upStreak >= rule.RecoverThreshold {
+			return alertstate.OK
+		}
+		if downStreak >= rule.DownThreshold {
+			return alertstate.Down
+		}
+		if badStreak >= rule.DegradedThreshold {
+			return alertstate.Degraded
+		}
+		return alertstate.Unreachable
+	default: // OK dan RECOVERED
+		if downStreak >= rule.DownThreshold {
+			return alertstate.Down
+		}
+		if badStreak >= rule.DegradedThreshold {
+			return alertstate.Degraded
+		}
+		if current == alertstate.Recovered && upStreak >= rule.RecoverThreshold {
+			return alertstate.OK
+		}
+		return current
+	}
+}
+
+// resolveAlertStates menghitung state berikutnya seluruh unit berdasarkan ping dan uplink.
+// unit yang seharusnya DOWN menjadi UNREACHABLE jika induknya DOWN / UNREACHABLE,
+// unit di luar category (kosong berarti semua) atau dengan rule disable tetap memakai state tersimpan.
+// unit yang ping terbarunya lebih tua dari pingStaleAfter diperlakukan sebagai DOWN
+func resolveAlertStates(units dto.GenUnitResponseList, rules []dto.AlertRule, category string, now int64) map[string]string {
+	byID := make(map[string]dto.GenUnitResponse, len(units))
+	for _, unit := range units {
+		byID[unit.ID] = unit
+	}
+
+	states := make(map[string]string, len(units))
+	var resolve func(unitID string) string
+	resolve = func(unitID string) string {
+		if state, ok := states[unitID]; ok {
+			return state
+		}
+		unit, ok := byID[unitID]
+		if !ok {
+			return ""
+		}
+
+		current := unit.AlertState
+		if current == "" {
+			current = alertstate.OK
+		}
+		// diisi lebih dulu sebagai pengaman jika uplink membentuk siklus
+		states[unitID] = current
+
+		rule := pickAlertRule(rules, unit.Branch, unit.Category)
+		if rule.Disable || (category != "" && unit.Category != category) {
+			return current
+		}
+
+		next := nextAlertState(current, unit.PingsState, rule)
+		if pingStale(unit.PingsState, now) {
+			next = alertstate.Down
+		}
+		if next == alertstate.Down && unit.Uplink != "" {
+			switch resolve(unit.Uplink) {
+			case alertstate.Down, alertstate.Unreachable:
+				next = alertstate.Unreachable
+			}
+		}
+		states[unitID] = next
+		return next
+	}
+
+	for _, unit := range units {
+		resolve(unit.ID)
+	}
+	return states
+}
+
+// pingStale true jika unit pernah menerima ping tetapi ping terbarunya sudah melewati pingStaleAfter.
+// unit yang belum pernah diping tidak dianggap stale
+func pingStale(pings []dto.PingState, now int64) bool {
+	if len(pings) == 0 {
+		return false
+	}
+	return now-pings[0].Time > pingStaleAfter
+}
+
+// unreachableRoot mencari induk DOWN terdekat yang menyebabkan unit menjadi UNREACHABLE
+func unreachableRoot(units dto.GenUnitResponseList, states map[string]string, unitID string) string {
+	uplinks := make(map[string]string, len(units))
+	for _, unit := range units {
+		uplinks[unit.ID] = unit.Uplink
+	}
+
+	current := uplinks[unitID]
+	for depth := 0; current != "" && depth <= maxUplinkDepth; depth++ {
+		switch states[current] {
+		case alertstate.Down:
+			return current
+		case alertstate.Unreachable:
+			current = uplinks[current]
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// alertNotificationNames nama unit per state tujuan, unit DOWN disertai jumlah turunan yang tidak terjangkau
+func alertNotificationNames(transitions map[string][]dto.GenUnitResponse, affected map[string]int) map[string][]string {
+	names := make(map[string][]string, len(transitions))
+	for state, units := range transitions {
+		for _, unit := range units {
+			name := unit.Name
+			if state == alertstate.Down && affected[unit.ID] != 0 {
+				name = fmt.Sprintf("%s (%d unit tidak terjangkau)", unit.Name, affected[unit.ID])
+			}
+			names[state] = append(names[state], name)
+		}
+	}
+	return names
+}
+
+// pingStreak menghitung jumlah ping terbaru berurutan yang memenuhi kondisi
+func pingStreak(pings []dto.PingState, match func(code int) bool) int {
+	count := 0
+	for _, ping := range pings {
+		if !match(ping.Code) {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// shouldOpenAutoCase unit harus DOWN selama AutoCaseAfter detik, tidak memiliki case terbuka
+// dan belum pernah dibuatkan history otomatis
+func shouldOpenAutoCase(unit dto.GenUnitResponse, rule dto.AlertRule, timeNow int64) bool {
+	if rule.AutoCaseAfter <= 0 || unit.AlertSince == 0 {
+		return false
+	}
+	if unit.CasesSize != 0 || unit.AutoCaseID != "" {
+		return false
+	}
+	return timeNow-unit.AlertSince >= rule.AutoCaseAfter
+}
+
+func shouldNotifyAlert(toState string, rule dto.AlertRule) bool {
+	switch toState {
+	case alertstate.Down, alertstate.Recovered:
+		return true
+	case alertstate.Degraded:
+		return rule.NotifyDegraded
+	default:
+		return false
+	}
+}
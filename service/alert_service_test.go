@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/alertstate"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// pingsLatestFirst membuat pings_state dengan index 0 sebagai ping terbaru
+func pingsLatestFirst(codes ...int) []dto.PingState {
+	pings := make([]dto.PingState, len(codes))
+	for i, code := range codes {
+		pings[i] = dto.PingState{Code: code}
+	}
+	return pings
+}
+
+func TestNextAlertStateDebounce(t *testing.T) {
+	rule := dto.AlertRule{DegradedThreshold: 2, DownThreshold: 3, RecoverThreshold: 2}
+
+	// satu ping down tidak merubah state
+	assert.Equal(t, alertstate.OK, nextAlertState(alertstate.OK, pingsLatestFirst(enum.PingDown, enum.PingUp), rule))
+	// dua ping buruk menjadi degraded
+	assert.Equal(t, alertstate.Degraded, nextAlertState(alertstate.OK, pingsLatestFirst(enum.PingHalf, enum.PingDown, enum.PingUp), rule))
+	// tiga ping down menjadi down
+	assert.Equal(t, alertstate.Down, nextAlertState(alertstate.Degraded, pingsLatestFirst(enum.PingDown, enum.PingDown, enum.PingDown), rule))
+	// satu ping up tidak cukup untuk recover
+	assert.Equal(t, alertstate.Down, nextAlertState(alertstate.Down, pingsLatestFirst(enum.PingUp, enum.PingDown, enum.PingDown), rule))
+	// dua ping up menjadi recovered lalu ok
+	assert.Equal(t, alertstate.Recovered, nextAlertState(alertstate.Down, pingsLatestFirst(enum.PingUp, enum.PingUp, enum.PingDown), rule))
+	assert.Equal(t, alertstate.OK, nextAlertState(alertstate.Recovered, pingsLatestFirst(enum.PingUp, enum.PingUp, enum.PingDown), rule))
+	// degraded kembali ok
+	assert.Equal(t, alertstate.OK, nextAlertState(alertstate.Degraded, pingsLatestFirst(enum.PingUp, enum.PingUp), rule))
+	// tanpa ping state tidak berubah
+	assert.Equal(t, alertstate.Down, nextAlertState(alertstate.Down, nil, rule))
+}
+
+func TestPickAlertRule(t *testing.T) {
+	rules := []dto.AlertRule{
+		{Branch: "", Category: "CCTV", DownThreshold: 5},
+		{Branch: "BANJARMASIN", Category: "CCTV", DownThreshold: 4},
+	}
+
+	assert.Equal(t, 4, pickAlertRule(rules, "BANJARMASIN", "CCTV").DownThreshold)
+	assert.Equal(t, 5, pickAlertRule(rules, "SAMPIT", "CCTV").DownThreshold)
+	assert.Equal(t, defaultAlertRule.DownThreshold, pickAlertRule(rules, "BANJARMASIN", "PC").DownThreshold)
+}
+
+func TestShouldOpenAutoCase(t *testing.T) {
+	rule := dto.AlertRule{AutoCaseAfter: 1800}
+	unit := dto.GenUnitResponse{AlertState: alertstate.Down, AlertSince: 1000}
+
+	assert.False(t, shouldOpenAutoCase(unit, rule, 2000))
+	assert.True(t, shouldOpenAutoCase(unit, rule, 2800))
+
+	// sudah ada case terbuka
+	withCase := unit
+	withCase.CasesSize = 1
+	assert.False(t, shouldOpenAutoCase(withCase, rule, 2800))
+
+	// sudah pernah dibuatkan history otomatis
+	withAuto := unit
+	withAuto.AutoCaseID = "6050a1f3d2b8a1b4c8e0f000"
+	assert.False(t, shouldOpenAutoCase(withAuto, rule, 2800))
+
+	// auto case nonaktif
+	assert.False(t, shouldOpenAutoCase(unit, dto.AlertRule{}, 999999))
+}
+
+func TestResolveAlertStatesUplink(t *testing.T) {
+	down := pingsLatestFirst(enum.PingDown, enum.PingDown, enum.PingDown)
+	units := dto.GenUnitResponseList{
+		// turunan dimuat lebih dulu dari induknya
+		{ID: "cctv1", Category: "CCTV", Uplink: "switch2", PingsState: down},
+		{ID: "switch2", Category: "SWITCH", Uplink: "switch1", PingsState: down},
+		{ID: "switch1", Category: "SWITCH", PingsState: down},
+		{ID: "cctv2", Category: "CCTV", PingsState: down},
+	}
+
+	states := resolveAlertStates(units, nil, "", 0)
+	assert.Equal(t, alertstate.Down, states["switch1"])
+	assert.Equal(t, alertstate.Unreachable, states["switch2"])
+	assert.Equal(t, alertstate.Unreachable, states["cctv1"])
+	assert.Equal(t, alertstate.Down, states["cctv2"])
+	assert.Equal(t, "switch1", unreachableRoot(units, states, "cctv1"))
+
+	// induk di luar category memakai state tersimpan
+	units[1].AlertState = alertstate.OK
+	units[2].AlertState = alertstate.OK
+	states = resolveAlertStates(units, nil, "CCTV", 0)
+	assert.Equal(t, alertstate.OK, states["switch2"])
+	assert.Equal(t, alertstate.Down, states["cctv1"])
+	assert.Equal(t, "", unreachableRoot(units, states, "cctv1"))
+}
+
+func TestResolveAlertStatesCycle(t *testing.T) {
+	down := pingsLatestFirst(enum.PingDown, enum.PingDown, enum.PingDown)
+	units := dto.GenUnitResponseList{
+		{ID: "a", Category: "CCTV", Uplink: "b", PingsState: down},
+		{ID: "b", Category: "CCTV", Uplink: "a", PingsState: down},
+	}
+
+	// tidak berulang tanpa batas
+	states := resolveAlertStates(units, nil, "", 0)
+	assert.Len(t, states, 2)
+}
+
+func TestNextAlertStateUnreachable(t *testing.T) {
+	rule := dto.AlertRule{DegradedThreshold: 2, DownThreshold: 3, RecoverThreshold: 2}
+
+	assert.Equal(t, alertstate.OK, nextAlertState(alertstate.Unreachable, pingsLatestFirst(enum.PingUp, enum.PingUp), rule))
+	assert.Equal(t, alertstate.Down, nextAlertState(alertstate.Unreachable, pingsLatestFirst(enum.PingDown, enum.PingDown, enum.PingDown), rule))
+	assert.Equal(t, alertstate.Unreachable, nextAlertState(alertstate.Unreachable, pingsLatestFirst(enum.PingUp, enum.PingDown), rule))
+}
+
+func TestResolveAlertStatesStalePing(t *testing.T) {
+	fresh := []dto.PingState{{Code: enum.PingUp, Time: 1000}, {Code: enum.PingUp, Time: 400}}
+	units := dto.GenUnitResponseList{
+		{ID: "switch1", Category: "SWITCH", PingsState: fresh},
+		{ID: "cctv1", Category: "CCTV", Uplink: "switch1", PingsState: fresh},
+		{ID: "cctv2", Category: "CCTV"},
+	}
+
+	// ping masih baru
+	states := resolveAlertStates(units, nil, "", 1000+pingStaleAfter)
+	assert.Equal(t, alertstate.OK, states["switch1"])
+	assert.Equal(t, alertstate.OK, states["cctv1"])
+
+	// ping berhenti masuk, turunan dari induk yang DOWN menjadi UNREACHABLE
+	states = resolveAlertStates(units, nil, "", 1001+pingStaleAfter)
+	assert.Equal(t, alertstate.Down, states["switch1"])
+	assert.Equal(t, alertstate.Unreachable, states["cctv1"])
+	// belum pernah diping
+	assert.Equal(t, alertstate.OK, states["cctv2"])
+}
@@ -41,7 +41,7 @@ type AltaiCheckServiceAssumer interface {
 	InsertAltaiCheck(ctx context.Context, user mjwt.CustomClaim) (*string, rest_err.APIError)
 	DeleteAltaiCheck(ctx context.Context, user mjwt.CustomClaim, id string) rest_err.APIError
 	GetAltaiCheckByID(ctx context.Context, altaiCheckID string, branchIfSpecific string) (*dto.AltaiCheck, rest_err.APIError)
-	FindAltaiCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.AltaiCheck, rest_err.APIError)
+	FindAltaiCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.AltaiCheck, dto.PageInfo, rest_err.APIError)
 	UpdateAltaiCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.AltaiCheckItemUpdateRequest) (*dto.AltaiCheck, rest_err.APIError)
 	BulkUpdateAltaiItem(ctx context.Context, user mjwt.CustomClaim, inputs []dto.AltaiCheckItemUpdateRequest) (string, rest_err.APIError)
 	FinishCheck(ctx context.Context, user mjwt.CustomClaim, detailID string) (*dto.AltaiCheck, rest_err.APIError)
@@ -52,21 +52,21 @@ func (c *altaiCheckService) InsertAltaiCheck(ctx context.Context, user mjwt.Cust
 
 	// ambil altai genUnit item berdasarkan cabang yang di input
 	// mendapatkan data cases
-	genItems, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Altai,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	// ambil altai untuk mendapatkan data lokasi
 	// altaiItems sudah sorted berdasarkan lokasi sedangkan genItems tidak
-	altaiItems, err := c.daoAltai.FindOther(ctx, dto.FilterOther{
+	altaiItems, _, err := c.daoAltai.FindOther(ctx, dto.FilterOther{
 		FilterBranch:      user.Branch,
 		FilterSubCategory: category.Altai,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -212,11 +212,11 @@ func (c *altaiCheckService) FinishCheck(ctx context.Context, user mjwt.CustomCla
 	timeNow := time.Now().Unix()
 
 	// 1. cek altai existing, untuk mendapatkan keterangan apakah ada case
-	genItems, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Altai,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -297,10 +297,10 @@ func (c *altaiCheckService) GetAltaiCheckByID(ctx context.Context, altaiCheckID
 	return altaiCheck, nil
 }
 
-func (c *altaiCheckService) FindAltaiCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.AltaiCheck, rest_err.APIError) {
-	altaiCheckList, err := c.daoC.FindCheck(ctx, branch, filter, false)
+func (c *altaiCheckService) FindAltaiCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.AltaiCheck, dto.PageInfo, rest_err.APIError) {
+	altaiCheckList, pageInfo, err := c.daoC.FindCheck(ctx, branch, filter, page, false)
 	if err != nil {
-		return []dto.AltaiCheck{}, err
+		return nil, dto.PageInfo{}, err
 	}
-	return altaiCheckList, nil
+	return altaiCheckList, pageInfo, nil
 }
@@ -40,7 +40,7 @@ type AltaiPhyCheckServiceAssumer interface {
 	InsertAltaiPhyCheck(ctx context.Context, user mjwt.CustomClaim, name string, isQuarterMode bool) (*string, rest_err.APIError)
 	DeleteAltaiPhyCheck(ctx context.Context, user mjwt.CustomClaim, id string) rest_err.APIError
 	GetAltaiPhyCheckByID(ctx context.Context, altaiCheckID string, branchIfSpecific string) (*dto.AltaiPhyCheck, rest_err.APIError)
-	FindAltaiPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.AltaiPhyCheck, rest_err.APIError)
+	FindAltaiPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.AltaiPhyCheck, dto.PageInfo, rest_err.APIError)
 	UpdateAltaiPhyCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.AltaiPhyCheckItemUpdateRequest) (*dto.AltaiPhyCheck, rest_err.APIError)
 	BulkUpdateAltaiPhyItem(ctx context.Context, user mjwt.CustomClaim, inputs []dto.AltaiPhyCheckItemUpdateRequest) (string, rest_err.APIError)
 	FinishCheck(ctx context.Context, user mjwt.CustomClaim, detailID string) (*dto.AltaiPhyCheck, rest_err.APIError)
@@ -51,21 +51,21 @@ func (vc *altaiPhyCheckService) InsertAltaiPhyCheck(ctx context.Context, user mj
 
 	// ambil altai genUnit item berdasarkan cabang yang di input
 	// mendapatkan data cases
-	genItems, err := vc.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := vc.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Altai,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	// ambil altai untuk mendapatkan data lokasi
 	// altaiItems sudah sorted berdasarkan lokasi sedangkan genItems tidak
-	altaiItems, err := vc.daoAltai.FindOther(ctx, dto.FilterOther{
+	altaiItems, _, err := vc.daoAltai.FindOther(ctx, dto.FilterOther{
 		FilterBranch:      user.Branch,
 		FilterSubCategory: category.Altai,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -293,10 +293,10 @@ func (vc *altaiPhyCheckService) GetAltaiPhyCheckByID(ctx context.Context, altaiC
 	return altaiCheck, nil
 }
 
-func (vc *altaiPhyCheckService) FindAltaiPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.AltaiPhyCheck, rest_err.APIError) {
-	altaiCheckList, err := vc.daoC.FindCheck(ctx, branch, filter, false)
+func (vc *altaiPhyCheckService) FindAltaiPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.AltaiPhyCheck, dto.PageInfo, rest_err.APIError) {
+	altaiCheckList, pageInfo, err := vc.daoC.FindCheck(ctx, branch, filter, page, false)
 	if err != nil {
-		return []dto.AltaiPhyCheck{}, err
+		return nil, dto.PageInfo{}, err
 	}
-	return altaiCheckList, nil
+	return altaiCheckList, pageInfo, nil
 }
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/apikeydao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/apikey"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func NewApiKeyService(apiKeyDao apikeydao.ApiKeyDaoAssumer) ApiKeyServiceAssumer {
+	return &apiKeyService{
+		daoA: apiKeyDao,
+	}
+}
+
+type apiKeyService struct {
+	daoA apikeydao.ApiKeyDaoAssumer
+}
+type ApiKeyServiceAssumer interface {
+	InsertApiKey(ctx context.Context, user mjwt.CustomClaim, input dto.ApiKeyRequest) (*dto.ApiKeyCreated, rest_err.APIError)
+	RotateApiKey(ctx context.Context, user mjwt.CustomClaim, keyID string) (*dto.ApiKeyCreated, rest_err.APIError)
+	RevokeApiKey(ctx context.Context, user mjwt.CustomClaim, keyID string) (*dto.ApiKey, rest_err.APIError)
+	FindApiKey(ctx context.Context, branchIfSpecific string) ([]dto.ApiKey, rest_err.APIError)
+	ValidateApiKey(ctx context.Context, key string, scope string, ip string) (*dto.ApiKeyClaim, rest_err.APIError)
+}
+
+// InsertApiKey membuat service account baru, key plaintext hanya dikembalikan sekali pada response ini
+func (a *apiKeyService) InsertApiKey(ctx context.Context, user mjwt.CustomClaim, input dto.ApiKeyRequest) (*dto.ApiKeyCreated, rest_err.APIError) {
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	timeNow := time.Now().Unix()
+	data := dto.ApiKey{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   timeNow,
+		CreatedBy:   user.Name,
+		CreatedByID: user.Identity,
+		UpdatedAt:   timeNow,
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Name:        input.Name,
+		Branch:      input.Branch,
+		Scopes:      input.Scopes,
+		Prefix:      prefix,
+		HashKey:     hash,
+	}
+
+	// DB
+	_, err = a.daoA.InsertKey(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApiKeyCreated{
+		Key:    key,
+		ApiKey: data,
+	}, nil
+}
+
+// RotateApiKey membuat key baru untuk service account yang sama, key lama langsung tidak berlaku
+func (a *apiKeyService) RotateApiKey(ctx context.Context, user mjwt.CustomClaim, keyID string) (*dto.ApiKeyCreated, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(keyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	// DB
+	keyRotated, err := a.daoA.RotateKey(ctx, dto.ApiKeyRotate{
+		ID:          oid,
+		UpdatedAt:   time.Now().Unix(),
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Prefix:      prefix,
+		HashKey:     hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApiKeyCreated{
+		Key:    key,
+		ApiKey: *keyRotated,
+	}, nil
+}
+
+func (a *apiKeyService) RevokeApiKey(ctx context.Context, user mjwt.CustomClaim, keyID string) (*dto.ApiKey, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(keyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	// DB
+	keyRevoked, err := a.daoA.RevokeKey(ctx, oid, user)
+	if err != nil {
+		return nil, err
+	}
+	return keyRevoked, nil
+}
+
+func (a *apiKeyService) FindApiKey(ctx context.Context, branchIfSpecific string) ([]dto.ApiKey, rest_err.APIError) {
+	// DB
+	keyList, err := a.daoA.FindKey(ctx, branchIfSpecific)
+	if err != nil {
+		return nil, err
+	}
+	return keyList, nil
+}
+
+// ValidateApiKey memastikan key terdaftar, belum direvoke dan memiliki scope yang diminta.
+// last used diupdate tanpa menahan request
+func (a *apiKeyService) ValidateApiKey(ctx context.Context, key string, scope string, ip string) (*dto.ApiKeyClaim, rest_err.APIError) {
+	if key == "" {
+		return nil, rest_err.NewUnauthorizedError("Unauthorized, memerlukan api key")
+	}
+
+	// DB
+	keyData, err := a.daoA.GetKeyByHash(ctx, apikey.Hash(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if keyData.Revoked {
+		return nil, rest_err.NewUnauthorizedError("Api key sudah direvoke")
+	}
+
+	if !sfunc.InSlice(scope, keyData.Scopes) {
+		return nil, rest_err.NewUnauthorizedError("Unauthorized, api key tidak memiliki scope " + scope)
+	}
+
+	go func() {
+		_ = a.daoA.UpdateLastUsed(context.Background(), keyData.ID, ip)
+	}()
+
+	return &dto.ApiKeyClaim{
+		ID:     keyData.ID.Hex(),
+		Name:   keyData.Name,
+		Branch: keyData.Branch,
+		Scopes: keyData.Scopes,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/auditdao"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+func NewAuditService(auditDao auditdao.AuditDaoAssumer) AuditServiceAssumer {
+	return &auditService{
+		daoA: auditDao,
+	}
+}
+
+type auditService struct {
+	daoA auditdao.AuditDaoAssumer
+}
+type AuditServiceAssumer interface {
+	FindAudit(ctx context.Context, filter dto.FilterAudit, page dto.PageRequest) ([]dto.Audit, dto.PageInfo, rest_err.APIError)
+}
+
+func (a *auditService) FindAudit(ctx context.Context, filter dto.FilterAudit, page dto.PageRequest) ([]dto.Audit, dto.PageInfo, rest_err.APIError) {
+	auditList, pageInfo, err := a.daoA.FindAudit(ctx, filter, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	return auditList, pageInfo, nil
+}
@@ -2,9 +2,7 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/constants/category"
 	"github.com/muchlist/risa_restfull/constants/enum"
@@ -12,6 +10,7 @@ import (
 	"github.com/muchlist/risa_restfull/dao/cctvdao"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
@@ -23,18 +22,30 @@ import (
 
 func NewCctvService(cctvDao cctvdao.CctvDaoAssumer,
 	histDao historydao.HistoryDaoAssumer,
-	genDao genunitdao.GenUnitDaoAssumer) CctvServiceAssumer {
+	genDao genunitdao.GenUnitDaoAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	customFieldService CustomFieldServiceAssumer,
+	dataQualityService DataQualityServiceAssumer,
+	searchIndexer SearchIndexer) CctvServiceAssumer {
 	return &cctvService{
-		daoC: cctvDao,
-		daoH: histDao,
-		daoG: genDao,
+		daoC:   cctvDao,
+		daoH:   histDao,
+		daoG:   genDao,
+		daoT:   txDao,
+		servCf: customFieldService,
+		servDq: dataQualityService,
+		servSi: searchIndexer,
 	}
 }
 
 type cctvService struct {
-	daoC cctvdao.CctvDaoAssumer
-	daoH historydao.HistoryDaoAssumer
-	daoG genunitdao.GenUnitDaoAssumer
+	daoC   cctvdao.CctvDaoAssumer
+	daoH   historydao.HistoryDaoAssumer
+	daoG   genunitdao.GenUnitDaoAssumer
+	daoT   transactiondao.TransactionDaoAssumer
+	servCf CustomFieldServiceAssumer
+	servDq DataQualityServiceAssumer
+	servSi SearchIndexer
 }
 type CctvServiceAssumer interface {
 	InsertCctv(ctx context.Context, user mjwt.CustomClaim, input dto.CctvRequest) (*string, rest_err.APIError)
@@ -44,8 +55,8 @@ type CctvServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.Cctv, rest_err.APIError)
 
 	GetCctvByID(ctx context.Context, cctvID string, branchIfSpecific string) (*dto.Cctv, rest_err.APIError)
-	FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable) (dto.CctvResponseMinList, dto.GenUnitResponseList, rest_err.APIError)
-	MergeCctv(ctx context.Context, cctvID1, cctvID2 string) (*string, rest_err.APIError)
+	FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, page dto.PageRequest) (dto.CctvResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError)
+	MergeCctv(ctx context.Context, user mjwt.CustomClaim, cctvID1, cctvID2 string) (*string, rest_err.APIError)
 }
 
 func (c *cctvService) InsertCctv(ctx context.Context, user mjwt.CustomClaim, input dto.CctvRequest) (*string, rest_err.APIError) {
@@ -62,18 +73,22 @@ func (c *cctvService) InsertCctv(ctx context.Context, user mjwt.CustomClaim, inp
 		input.IP = "0.0.0.0"
 	}
 
-	// kembalian dari golang channel
-	type result struct {
-		id  *string
-		err rest_err.APIError
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
 	}
 
-	resultChan := make(chan result, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
+	customFields, err := c.servCf.ValidateValues(ctx, category.Cctv, input.CustomFields)
+	if err != nil {
+		return nil, err
+	}
 
-	insertToCctv := func() {
-		defer wg.Done()
+	var insertedID *string
+	err = c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
 		// Filling data
 		timeNow := time.Now().Unix()
 		data := dto.Cctv{
@@ -98,22 +113,22 @@ func (c *cctvService) InsertCctv(ctx context.Context, user mjwt.CustomClaim, inp
 			Type:            input.Type,
 			Note:            input.Note,
 			DisVendor:       input.DisVendor,
+
+			Lifecycle: input.Lifecycle,
+			Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+			CustomFields: customFields,
 		}
 
 		// DB
-		insertedID, err := c.daoC.InsertCctv(ctx, data)
-
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
+		resultID, err := c.daoC.InsertCctv(txCtx, data)
+		if err != nil {
+			return err
 		}
-	}
 
-	insertToGenUnit := func() {
-		defer wg.Done()
 		// Menambahkan juga General Unit dengan ID yang sama
 		// DB
-		insertedID, err := c.daoG.InsertUnit(ctx,
+		_, err = c.daoG.InsertUnit(txCtx,
 			dto.GenUnit{
 				ID:       idGenerated.Hex(),
 				Category: category.Cctv,
@@ -121,36 +136,19 @@ func (c *cctvService) InsertCctv(ctx context.Context, user mjwt.CustomClaim, inp
 				IP:       input.IP,
 				Branch:   user.Branch,
 			})
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
-		}
-	}
-
-	go insertToCctv()
-	go insertToGenUnit()
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	var resultID *string
-	var errString string
-	for v := range resultChan {
-		if v.err != nil {
-			errString = errString + v.err.Message() + ". "
-		}
-		if resultID == nil {
-			resultID = v.id
+		if err != nil {
+			return err
 		}
-	}
 
-	if errString != "" {
-		return nil, rest_err.NewBadRequestError(errString)
+		insertedID = resultID
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return resultID, nil
+	c.servSi.IndexUnit(ctx, idGenerated.Hex())
+	return insertedID, nil
 }
 
 func (c *cctvService) EditCctv(ctx context.Context, user mjwt.CustomClaim, cctvID string, input dto.CctvEditRequest) (*dto.Cctv, rest_err.APIError) {
@@ -168,6 +166,26 @@ func (c *cctvService) EditCctv(ctx context.Context, user mjwt.CustomClaim, cctvI
 		input.IP = "0.0.0.0"
 	}
 
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		ID:              cctvID,
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
+	}
+
+	// custom_fields yang tidak dikirim (null) tidak merubah nilai tersimpan
+	var customFields dto.CustomFields
+	if input.CustomFields != nil {
+		validated, err := c.servCf.ValidateValues(ctx, category.Cctv, input.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		customFields = validated
+	}
+
 	// Filling data
 	timeNow := time.Now().Unix()
 	data := dto.CctvEdit{ // Prefer filter dan datanya dipisah dengan dua struct
@@ -189,34 +207,35 @@ func (c *cctvService) EditCctv(ctx context.Context, user mjwt.CustomClaim, cctvI
 		Type:            input.Type,
 		Note:            input.Note,
 		DisVendor:       input.DisVendor,
-	}
 
-	// DB
-	cctvEdited, err := c.daoC.EditCctv(ctx, data)
-	if err != nil {
-		return nil, err
+		Lifecycle: input.Lifecycle,
+		Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+		CustomFields: customFields,
 	}
 
-	errChan := make(chan rest_err.APIError, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
-	editUnit := func() {
-		defer wg.Done()
+	var edited *dto.Cctv
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		cctvEdited, err := c.daoC.EditCctv(txCtx, data)
+		if err != nil {
+			return err
+		}
+
 		// DB
-		_, err = c.daoG.EditUnit(ctx, cctvID, dto.GenUnitEditRequest{
+		_, err = c.daoG.EditUnit(txCtx, cctvID, dto.GenUnitEditRequest{
 			Category: category.Cctv,
 			Name:     cctvEdited.Name,
 			IP:       cctvEdited.IP,
 			Branch:   cctvEdited.Branch,
 		})
-		errChan <- err
-	}
+		if err != nil {
+			return err
+		}
 
-	insertHistory := func() {
-		defer wg.Done()
 		isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
 		// DB
-		_, err = c.daoH.InsertHistory(ctx,
+		_, err = c.daoH.InsertHistory(txCtx,
 			dto.History{
 				ID:             primitive.NewObjectID(),
 				CreatedAt:      timeNow,
@@ -238,32 +257,19 @@ func (c *cctvService) EditCctv(ctx context.Context, user mjwt.CustomClaim, cctvI
 				Tag:            []string{},
 				Image:          "",
 			}, isVendor)
-		errChan <- err
-	}
-
-	go editUnit()
-	go insertHistory()
-
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-
-	var errMessage string
-	for err := range errChan {
 		if err != nil {
-			errMessage += ". "
+			return err
 		}
-	}
 
-	if errMessage != "" {
-		errorPartial := errors.New("partial error")
-		restErr := rest_err.NewInternalServerError(fmt.Sprintf("Mengedit cctv berhasil namun : %s", errMessage), errorPartial)
-		logger.Error(restErr.Message(), errorPartial)
-		return nil, restErr
+		edited = cctvEdited
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return cctvEdited, nil
+	c.servSi.IndexUnit(ctx, cctvID)
+	return edited, nil
 }
 
 func (c *cctvService) DeleteCctv(ctx context.Context, user mjwt.CustomClaim, id string, force bool) rest_err.APIError {
@@ -278,23 +284,26 @@ func (c *cctvService) DeleteCctv(ctx context.Context, user mjwt.CustomClaim, id
 		timeMinusOneDay = 0
 	}
 
-	// DB
-	_, err := c.daoC.DeleteCctv(ctx, dto.FilterIDBranchCreateGte{
-		FilterID:        oid,
-		FilterBranch:    user.Branch,
-		FilterCreateGTE: timeMinusOneDay,
-	})
-	if err != nil {
-		return err
-	}
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		_, err := c.daoC.DeleteCctv(txCtx, dto.FilterIDBranchCreateGte{
+			FilterID:        oid,
+			FilterBranch:    user.Branch,
+			FilterCreateGTE: timeMinusOneDay,
+		}, user)
+		if err != nil {
+			return err
+		}
 
-	// Delete unit_gen
-	// DB
-	err = c.daoG.DeleteUnit(ctx, id)
+		// Delete unit_gen
+		// DB
+		return c.daoG.DeleteUnit(txCtx, id, user)
+	})
 	if err != nil {
 		return err
 	}
 
+	c.servSi.IndexUnit(ctx, id)
 	return nil
 }
 
@@ -317,6 +326,7 @@ func (c *cctvService) DisableCctv(ctx context.Context, cctvID string, user mjwt.
 		return nil, err
 	}
 
+	c.servSi.IndexUnit(ctx, oid.Hex())
 	return cctv, nil
 }
 
@@ -401,18 +411,25 @@ func (c *cctvService) GetCctvByID(ctx context.Context, cctvID string, branchIfSp
 	return cctvData, nil
 }
 
-func (c *cctvService) FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable) (dto.CctvResponseMinList, dto.GenUnitResponseList, rest_err.APIError) {
+func (c *cctvService) FindCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, page dto.PageRequest) (dto.CctvResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
 	// cek apakah ip address valid, jika valid maka set filter.FilterName ke kosong supaya pencarian berdasarkan IP
 	if filter.FilterIP != "" {
 		if net.ParseIP(filter.FilterIP) == nil {
-			return nil, nil, rest_err.NewBadRequestError("IP Address tidak valid")
+			return nil, nil, dto.PageInfo{}, rest_err.NewBadRequestError("IP Address tidak valid")
 		}
 		filter.FilterName = ""
 	}
 
+	customFilter, err := c.servCf.ParseFilter(ctx, category.Cctv, filter.FilterCustomFields)
+	if err != nil {
+		return nil, nil, dto.PageInfo{}, err
+	}
+	filter.FilterCustomFields = customFilter
+
 	// wrap golang channel
 	type resultCctv struct {
 		data dto.CctvResponseMinList
+		page dto.PageInfo
 		err  rest_err.APIError
 	}
 
@@ -429,9 +446,10 @@ func (c *cctvService) FindCctv(ctx context.Context, filter dto.FilterBranchLocIP
 
 	go func() {
 		defer wg.Done()
-		cctvList, err := c.daoC.FindCctv(ctx, filter)
+		cctvList, pageInfo, err := c.daoC.FindCctv(ctx, filter, page)
 		cctvListChan <- resultCctv{
 			data: cctvList,
+			page: pageInfo,
 			err:  err,
 		}
 	}()
@@ -448,12 +466,12 @@ func (c *cctvService) FindCctv(ctx context.Context, filter dto.FilterBranchLocIP
 			return
 		}
 
-		generalList, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+		generalList, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 			Branch:   filter.FilterBranch,
 			Category: category.Cctv,
 			Pings:    true,
 			Name:     "",
-		})
+		}, dto.PageRequest{All: true})
 		generalListChan <- resultGeneral{
 			data: generalList,
 			err:  err,
@@ -462,21 +480,21 @@ func (c *cctvService) FindCctv(ctx context.Context, filter dto.FilterBranchLocIP
 
 	cctvList := <-cctvListChan
 	if cctvList.err != nil {
-		return nil, nil, cctvList.err
+		return nil, nil, dto.PageInfo{}, cctvList.err
 	}
 
 	generalList := <-generalListChan
 	if generalList.err != nil {
-		return nil, nil, generalList.err
+		return nil, nil, dto.PageInfo{}, generalList.err
 	}
 
 	filterGeneralList(&generalList.data)
-	return cctvList.data, generalList.data, nil
+	return cctvList.data, generalList.data, cctvList.page, nil
 }
 
 // MergeCctv akan menggabungkan cctv 1 ke cctv 2 dan menghapus cctv 1
 // mungkin memerlukan bantuan developer untuk menghapus keberadaan cctv 1 pada checklist bulanan dan triwulanan
-func (c *cctvService) MergeCctv(ctx context.Context, cctvID1, cctvID2 string) (*string, rest_err.APIError) {
+func (c *cctvService) MergeCctv(ctx context.Context, user mjwt.CustomClaim, cctvID1, cctvID2 string) (*string, rest_err.APIError) {
 	oid1, errT := primitive.ObjectIDFromHex(cctvID1)
 	if errT != nil {
 		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
@@ -504,71 +522,76 @@ func (c *cctvService) MergeCctv(ctx context.Context, cctvID1, cctvID2 string) (*
 		return nil, apiErr
 	}
 
-	if len(history1) > 0 {
-		for _, history := range history1 {
-			generateHistID := primitive.NewObjectID()
-			_, apiErr = c.daoH.InsertHistory(ctx, dto.History{
-				Version:        history.Version,
-				ID:             generateHistID,
-				CreatedAt:      history.CreatedAt,
-				CreatedBy:      history.CreatedBy,
-				UpdatedAt:      history.UpdatedAt,
-				UpdatedBy:      history.UpdatedBy,
-				Category:       history.Category,
-				Branch:         history.Branch,
-				ParentID:       cctvID2,
-				ParentName:     cctv2Detail.Name,
-				Status:         history.Status,
-				Problem:        history.Problem,
-				ProblemResolve: history.ProblemResolve,
-				CompleteStatus: history.CompleteStatus,
-				DateStart:      history.DateStart,
-				DateEnd:        history.DateEnd,
-				Tag:            history.Tag,
-				Image:          history.Image,
-				Updates:        history.Updates,
-			}, false)
-
-			if apiErr != nil {
-				return nil, apiErr
-			}
+	// pemindahan history dan penghapusan cctv 1 dalam satu transaction
+	apiErr = c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		if len(history1) > 0 {
+			for _, history := range history1 {
+				generateHistID := primitive.NewObjectID()
+				_, err := c.daoH.InsertHistory(txCtx, dto.History{
+					Version:        history.Version,
+					ID:             generateHistID,
+					CreatedAt:      history.CreatedAt,
+					CreatedBy:      history.CreatedBy,
+					UpdatedAt:      history.UpdatedAt,
+					UpdatedBy:      history.UpdatedBy,
+					Category:       history.Category,
+					Branch:         history.Branch,
+					ParentID:       cctvID2,
+					ParentName:     cctv2Detail.Name,
+					Status:         history.Status,
+					Problem:        history.Problem,
+					ProblemResolve: history.ProblemResolve,
+					CompleteStatus: history.CompleteStatus,
+					DateStart:      history.DateStart,
+					DateEnd:        history.DateEnd,
+					Tag:            history.Tag,
+					Image:          history.Image,
+					Updates:        history.Updates,
+				}, false)
+
+				if err != nil {
+					return err
+				}
 
-			historyIsComplete := history.CompleteStatus == enum.HComplete
-			historyIsInfo := history.CompleteStatus == enum.HInfo
-			historyIsDataInfo := history.CompleteStatus == enum.HDataInfo
-			if !(historyIsComplete || historyIsInfo || historyIsDataInfo) {
-				// DB
-				_, apiErr = c.daoG.InsertCase(ctx, dto.GenUnitCaseRequest{
-					UnitID:       cctvID2,
-					FilterBranch: cctv2Detail.Branch,
-					CaseID:       generateHistID.Hex(), // gunakan History id sebagai caseID
-					CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(history.CompleteStatus), history.Status, history.Problem),
-				})
-				if apiErr != nil {
-					return nil, apiErr
+				historyIsComplete := history.CompleteStatus == enum.HComplete
+				historyIsInfo := history.CompleteStatus == enum.HInfo
+				historyIsDataInfo := history.CompleteStatus == enum.HDataInfo
+				if !(historyIsComplete || historyIsInfo || historyIsDataInfo) {
+					// DB
+					_, err = c.daoG.InsertCase(txCtx, dto.GenUnitCaseRequest{
+						UnitID:       cctvID2,
+						FilterBranch: cctv2Detail.Branch,
+						CaseID:       generateHistID.Hex(), // gunakan History id sebagai caseID
+						CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(history.CompleteStatus), history.Status, history.Problem),
+					})
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
-	}
 
-	// hapus cctvID 1
-	// hapus gen Unit cctvID 1
-	_, apiErr = c.daoC.DeleteCctv(ctx, dto.FilterIDBranchCreateGte{
-		FilterID:        oid1,
-		FilterBranch:    cctv1Detail.Branch,
-		FilterCreateGTE: 0,
-	})
-	if apiErr != nil {
-		return nil, apiErr
-	}
+		// hapus cctvID 1
+		// hapus gen Unit cctvID 1
+		_, err := c.daoC.DeleteCctv(txCtx, dto.FilterIDBranchCreateGte{
+			FilterID:        oid1,
+			FilterBranch:    cctv1Detail.Branch,
+			FilterCreateGTE: 0,
+		}, user)
+		if err != nil {
+			return err
+		}
 
-	apiErr = c.daoG.DeleteUnit(ctx, cctvID1)
+		return c.daoG.DeleteUnit(txCtx, cctvID1, user)
+	})
 	if apiErr != nil {
 		return nil, apiErr
 	}
+	c.servSi.IndexUnit(ctx, cctvID1)
+	c.servSi.IndexUnit(ctx, cctvID2)
 
 	msg := fmt.Sprintf(
-		"Cctv dengan id %s berhasil digabungkan dengan id %s, cctv %s telah dihapus dari database, mohon melakukan pengecekan ulang pada checklist bulanan dan triwulanan",
+		"Cctv dengan id %s berhasil digabungkan dengan id %s, cctv %s telah dipindahkan ke tempat sampah, mohon melakukan pengecekan ulang pada checklist bulanan dan triwulanan",
 		cctvID1, cctvID2, cctvID1)
 	return &msg, nil
 }
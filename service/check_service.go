@@ -45,7 +45,7 @@ type CheckServiceAssumer interface {
 	PutChildImage(ctx context.Context, user mjwt.CustomClaim, parentID string, childID string, imagePath string) (*dto.Check, rest_err.APIError)
 
 	GetCheckByID(ctx context.Context, checkID string, branchIfSpecific string) (*dto.Check, rest_err.APIError)
-	FindCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) (dto.CheckResponseMinList, rest_err.APIError)
+	FindCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.CheckResponseMinList, dto.PageInfo, rest_err.APIError)
 }
 
 func (c *checkService) InsertCheck(ctx context.Context, user mjwt.CustomClaim, input dto.CheckRequest) (*string, rest_err.APIError) {
@@ -86,13 +86,13 @@ func (c *checkService) InsertCheck(ctx context.Context, user mjwt.CustomClaim, i
 
 	//go func() {
 	//	// ambil data cctv yang harus di cek
-	//	cctvList, err := c.daoG.FindUnit(dto.GenUnitFilter{
+	//	cctvList, _, err := c.daoG.FindUnit(dto.GenUnitFilter{
 	//		Branch:   user.Branch,
 	//		Category: category.Cctv,
 	//		Disable:  false,
 	//		Pings:    true,
 	//		LastPing: enum.GetPingString(enum.PingDown),
-	//	})
+	//	}, dto.PageRequest{All: true})
 	//	if err != nil {
 	//		// if error, send result 0 slice
 	//		itemResultCctvChan <- []dto.CheckItemEmbed{}
@@ -340,10 +340,10 @@ func (c *checkService) GetCheckByID(ctx context.Context, checkID string, branchI
 	return check, nil
 }
 
-func (c *checkService) FindCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) (dto.CheckResponseMinList, rest_err.APIError) {
-	checkList, err := c.daoC.FindCheck(ctx, branch, filter)
+func (c *checkService) FindCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.CheckResponseMinList, dto.PageInfo, rest_err.APIError) {
+	checkList, pageInfo, err := c.daoC.FindCheck(ctx, branch, filter, page)
 	if err != nil {
-		return nil, err
+		return nil, dto.PageInfo{}, err
 	}
-	return checkList, nil
+	return checkList, pageInfo, nil
 }
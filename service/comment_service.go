@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/attachtype"
+	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/dao/commentdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// commentEditWindow batas waktu (detik) komentar masih dapat diedit atau dihapus oleh pembuatnya
+const commentEditWindow = 15 * 60
+
+func NewCommentService(commentDao commentdao.CommentDaoAssumer,
+	histDao historydao.HistoryLoader,
+	userDao userdao.UserLoader,
+	fcmClient fcm.ClientAssumer) CommentServiceAssumer {
+	return &commentService{
+		daoC:      commentDao,
+		daoH:      histDao,
+		daoU:      userDao,
+		fcmClient: fcmClient,
+	}
+}
+
+type commentService struct {
+	daoC      commentdao.CommentDaoAssumer
+	daoH      historydao.HistoryLoader
+	daoU      userdao.UserLoader
+	fcmClient fcm.ClientAssumer
+}
+type CommentServiceAssumer interface {
+	InsertComment(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.CommentRequest) (*dto.HistoryComment, rest_err.APIError)
+	EditComment(ctx context.Context, user mjwt.CustomClaim, commentID string, input dto.CommentEditRequest) (*dto.HistoryComment, rest_err.APIError)
+	DeleteComment(ctx context.Context, user mjwt.CustomClaim, commentID string) rest_err.APIError
+	FindComment(ctx context.Context, user mjwt.CustomClaim, historyID string) ([]dto.HistoryCommentThread, rest_err.APIError)
+}
+
+func (cm *commentService) InsertComment(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.CommentRequest) (*dto.HistoryComment, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	history, err := cm.daoH.GetHistoryByID(ctx, oid, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := cm.daoC.FindComment(ctx, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// balasan dari balasan tetap menginduk pada komentar utama
+	parentID := ""
+	if input.ParentID != "" {
+		parent := findComment(comments, input.ParentID)
+		if parent == nil {
+			return nil, rest_err.NewBadRequestError("komentar yang dibalas tidak ditemukan pada history ini")
+		}
+		parentID = parent.ID.Hex()
+		if parent.ParentID != "" {
+			parentID = parent.ParentID
+		}
+	}
+
+	users, err := cm.daoU.FindUser(ctx, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := commentMentions(users, input.MentionIDs, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	timeNow := time.Now().Unix()
+	comment, err := cm.daoC.InsertComment(ctx, dto.HistoryComment{
+		HistoryID:   historyID,
+		ParentID:    parentID,
+		Branch:      user.Branch,
+		CreatedAt:   timeNow,
+		CreatedBy:   user.Name,
+		CreatedByID: user.Identity,
+		UpdatedAt:   timeNow,
+		Body:        strings.TrimSpace(input.Body),
+		Mentions:    mentions,
+		Attachments: commentAttachments(input.Attachments),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go cm.notifyParticipants(user, history, users, comments, *comment)
+
+	return comment, nil
+}
+
+// EditComment hanya dapat dilakukan pembuat komentar sebelum commentEditWindow berakhir
+func (cm *commentService) EditComment(ctx context.Context, user mjwt.CustomClaim, commentID string, input dto.CommentEditRequest) (*dto.HistoryComment, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(commentID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	users, err := cm.daoU.FindUser(ctx, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := commentMentions(users, input.MentionIDs, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	timeNow := time.Now().Unix()
+	comment, err := cm.daoC.EditComment(ctx, dto.CommentEdit{
+		FilterID:        oid,
+		FilterAuthorID:  user.Identity,
+		FilterCreateGTE: timeNow - commentEditWindow,
+		UpdatedAt:       timeNow,
+		Body:            strings.TrimSpace(input.Body),
+		Mentions:        mentions,
+		Attachments:     commentAttachments(input.Attachments),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// DeleteComment pembuat komentar dapat menghapus komentar tanpa balasan selama commentEditWindow,
+// admin dapat menghapus kapan saja beserta balasannya
+func (cm *commentService) DeleteComment(ctx context.Context, user mjwt.CustomClaim, commentID string) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(commentID)
+	if errT != nil {
+		return rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	comment, err := cm.daoC.GetCommentByID(ctx, oid, user.Branch)
+	if err != nil {
+		return err
+	}
+
+	if !sfunc.InSlice(roles.RoleAdmin, user.Roles) {
+		if !canModifyComment(*comment, user.Identity, time.Now().Unix()) {
+			return rest_err.NewUnauthorizedError("komentar hanya dapat dihapus oleh pembuatnya sebelum batas waktu edit berakhir")
+		}
+		replies, err := cm.daoC.CountReply(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		if replies != 0 {
+			return rest_err.NewBadRequestError("komentar yang sudah dibalas tidak dapat dihapus")
+		}
+	}
+
+	return cm.daoC.DeleteComment(ctx, oid)
+}
+
+func (cm *commentService) FindComment(ctx context.Context, user mjwt.CustomClaim, historyID string) ([]dto.HistoryCommentThread, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	// memastikan history berada pada cabang user
+	if _, err := cm.daoH.GetHistoryByID(ctx, oid, user.Branch); err != nil {
+		return nil, err
+	}
+
+	comments, err := cm.daoC.FindComment(ctx, historyID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentThreads(comments), nil
+}
+
+// notifyParticipants mengirim notifikasi ke pembuat history, assignee, watcher dan user yang pernah berkomentar.
+// user yang disebut mendapat notifikasi tersendiri, pembuat komentar tidak diberi notifikasi
+func (cm *commentService) notifyParticipants(user mjwt.CustomClaim, history *dto.HistoryResponse, users dto.UserResponseList, previous []dto.HistoryComment, comment dto.HistoryComment) {
+	mentioned := make(map[string]bool)
+	for _, m := range comment.Mentions {
+		mentioned[m.ID] = true
+	}
+
+	participants := []string{history.CreatedByID, history.AssigneeID}
+	for _, w := range history.Watchers {
+		participants = append(participants, w.ID)
+	}
+	for _, c := range previous {
+		participants = append(participants, c.CreatedByID)
+	}
+
+	var participantTokens []string
+	var mentionTokens []string
+	notified := make(map[string]bool)
+	for _, id := range append(participants, commentUserIDs(comment.Mentions)...) {
+		if id == "" || id == user.Identity || notified[id] {
+			continue
+		}
+		notified[id] = true
+		target := findBranchUser(users, id)
+		if target == nil || target.FcmToken == "" {
+			continue
+		}
+		if mentioned[id] {
+			mentionTokens = append(mentionTokens, target.FcmToken)
+		} else {
+			participantTokens = append(participantTokens, target.FcmToken)
+		}
+	}
+
+	message := fmt.Sprintf("%s :: %s", strings.ToLower(user.Name), commentSummary(comment))
+	if len(mentionTokens) != 0 {
+		cm.fcmClient.SendMessage(fcm.Payload{
+			Title:          fmt.Sprintf("Anda disebut pada insiden %s", strings.ToLower(history.ParentName)),
+			Message:        message,
+			ReceiverTokens: mentionTokens,
+		})
+	}
+	if len(participantTokens) != 0 {
+		cm.fcmClient.SendMessage(fcm.Payload{
+			Title:          fmt.Sprintf("Komentar baru pada insiden %s", strings.ToLower(history.ParentName)),
+			Message:        message,
+			ReceiverTokens: participantTokens,
+		})
+	}
+}
+
+func findComment(comments []dto.HistoryComment, commentID string) *dto.HistoryComment {
+	for i := range comments {
+		if comments[i].ID.Hex() == commentID {
+			return &comments[i]
+		}
+	}
+	return nil
+}
+
+func commentUserIDs(users []dto.HistoryUser) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+// commentMentions user yang disebut harus berada pada cabang yang sama
+func commentMentions(users dto.UserResponseList, mentionIDs []string, branch string) ([]dto.HistoryUser, rest_err.APIError) {
+	mentions := make([]dto.HistoryUser, 0, len(mentionIDs))
+	for _, id := range mentionIDs {
+		found := findBranchUser(users, id)
+		if found == nil {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("user %s tidak ditemukan pada cabang %s", id, branch))
+		}
+		if sfunc.InSlice(found.ID, commentUserIDs(mentions)) {
+			continue
+		}
+		mentions = append(mentions, dto.HistoryUser{ID: found.ID, Name: found.Name})
+	}
+	return mentions, nil
+}
+
+// commentAttachments menentukan jenis lampiran dari ekstensi, thumbnail mengikuti penamaan saveImage
+func commentAttachments(paths []string) []dto.CommentAttachment {
+	attachments := make([]dto.CommentAttachment, len(paths))
+	for i, p := range paths {
+		if strings.ToLower(path.Ext(p)) == ".pdf" {
+			attachments[i] = dto.CommentAttachment{Type: attachtype.PDF, Path: p}
+			continue
+		}
+		dir, file := path.Split(p)
+		attachments[i] = dto.CommentAttachment{Type: attachtype.Image, Path: p, Thumbnail: dir + "thumb_" + file}
+	}
+	return attachments
+}
+
+func canModifyComment(comment dto.HistoryComment, userID string, now int64) bool {
+	return comment.CreatedByID == userID && now-comment.CreatedAt <= commentEditWindow
+}
+
+// commentSummary isi komentar untuk notifikasi dan timeline, komentar tanpa teks diganti jumlah lampiran
+func commentSummary(comment dto.HistoryComment) string {
+	summary := comment.Body
+	if len(comment.Attachments) != 0 {
+		summary = strings.TrimSpace(fmt.Sprintf("%s [%d lampiran]", summary, len(comment.Attachments)))
+	}
+	return summary
+}
+
+// buildCommentThreads mengelompokkan balasan ke komentar utama, balasan yang induknya
+// sudah tidak ada ditampilkan sebagai komentar utama
+func buildCommentThreads(comments []dto.HistoryComment) []dto.HistoryCommentThread {
+	threads := make([]dto.HistoryCommentThread, 0)
+	index := make(map[string]int)
+	for _, c := range comments {
+		if c.ParentID == "" {
+			index[c.ID.Hex()] = len(threads)
+			threads = append(threads, dto.HistoryCommentThread{HistoryComment: c, Replies: []dto.HistoryComment{}})
+		}
+	}
+	for _, c := range comments {
+		if c.ParentID == "" {
+			continue
+		}
+		if i, ok := index[c.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, c)
+			continue
+		}
+		threads = append(threads, dto.HistoryCommentThread{HistoryComment: c, Replies: []dto.HistoryComment{}})
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].CreatedAt < threads[j].CreatedAt
+	})
+	return threads
+}
+
+// unwindHistoryWithComment unwind history yang disisipi komentar, digunakan endpoint unwind dan laporan
+func unwindHistoryWithComment(ctx context.Context,
+	histDao historydao.HistoryLoader,
+	commentDao commentdao.CommentLoader,
+	filterA dto.FilterBranchCatInCompleteIn,
+	filterB dto.FilterTimeRangeLimit) (dto.HistoryUnwindResponseList, rest_err.APIError) {
+	historyList, err := histDao.UnwindHistory(ctx, filterA, filterB)
+	if err != nil {
+		return nil, err
+	}
+	if commentDao == nil || len(historyList) == 0 {
+		return historyList, nil
+	}
+
+	var historyIDs []string
+	seen := make(map[string]bool)
+	for _, h := range historyList {
+		if id := h.ID.Hex(); !seen[id] {
+			seen[id] = true
+			historyIDs = append(historyIDs, id)
+		}
+	}
+
+	comments, err := commentDao.FindCommentForHistories(ctx, historyIDs)
+	if err != nil {
+		// laporan tetap dibuat walaupun komentar gagal didapatkan
+		logger.Error("gagal mendapatkan komentar untuk timeline unwind (unwindHistoryWithComment)", err)
+		return historyList, nil
+	}
+	return mergeCommentTimeline(historyList, comments), nil
+}
+
+// mergeCommentTimeline menyisipkan komentar sebagai baris timeline pada setiap kelompok history sesuai waktu.
+// baris komentar membawa problem, solusi dan status dari update sebelumnya sehingga
+// perhitungan durasi dan status pada laporan tidak berubah
+func mergeCommentTimeline(histories dto.HistoryUnwindResponseList, comments []dto.HistoryComment) dto.HistoryUnwindResponseList {
+	if len(comments) == 0 {
+		return histories
+	}
+
+	commentMap := make(map[string][]dto.HistoryComment)
+	for _, c := range comments {
+		commentMap[c.HistoryID] = append(commentMap[c.HistoryID], c)
+	}
+	for id := range commentMap {
+		list := commentMap[id]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].CreatedAt < list[j].CreatedAt
+		})
+	}
+
+	merged := make(dto.HistoryUnwindResponseList, 0, len(histories)+len(comments))
+	for start := 0; start < len(histories); {
+		end := start + 1
+		for end < len(histories) && histories[end].ID == histories[start].ID {
+			end++
+		}
+
+		pending := commentMap[histories[start].ID.Hex()]
+		for i := start; i < end; i++ {
+			// komentar sebelum update pertama tetap ditaruh setelah update pertama
+			for i > start && len(pending) != 0 && pending[0].CreatedAt < histories[i].Updates.Time {
+				merged = append(merged, commentTimelineRow(merged[len(merged)-1], pending[0]))
+				pending = pending[1:]
+			}
+			merged = append(merged, histories[i])
+		}
+		for _, c := range pending {
+			merged = append(merged, commentTimelineRow(merged[len(merged)-1], c))
+		}
+		start = end
+	}
+	return merged
+}
+
+func commentTimelineRow(previous dto.HistoryUnwindResponse, comment dto.HistoryComment) dto.HistoryUnwindResponse {
+	row := previous
+	row.Updates = dto.HistoryUpdate{
+		Time:           comment.CreatedAt,
+		UpdatedBy:      comment.CreatedBy,
+		UpdatedByID:    comment.CreatedByID,
+		Problem:        previous.Updates.Problem,
+		ProblemResolve: previous.Updates.ProblemResolve,
+		CompleteStatus: previous.Updates.CompleteStatus,
+		Vendor:         previous.Updates.Vendor,
+		CommentID:      comment.ID.Hex(),
+		Comment:        commentSummary(comment),
+	}
+	return row
+}
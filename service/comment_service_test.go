@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/attachtype"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergeCommentTimeline(t *testing.T) {
+	histA := primitive.NewObjectID()
+	histB := primitive.NewObjectID()
+	histories := dto.HistoryUnwindResponseList{
+		{ID: histA, Updates: dto.HistoryUpdate{Time: 100, Problem: "mati", CompleteStatus: enum.HProgress}},
+		{ID: histA, Updates: dto.HistoryUpdate{Time: 300, Problem: "mati", ProblemResolve: "restart", CompleteStatus: enum.HComplete}},
+		{ID: histB, Updates: dto.HistoryUpdate{Time: 150, Problem: "lambat", CompleteStatus: enum.HPending}},
+	}
+	comments := []dto.HistoryComment{
+		{HistoryID: histA.Hex(), CreatedAt: 400, CreatedBy: "budi", Body: "sudah dicek"},
+		{HistoryID: histA.Hex(), CreatedAt: 200, CreatedBy: "andi", Body: "menunggu kabel"},
+		{HistoryID: histB.Hex(), CreatedAt: 160, CreatedBy: "andi", Attachments: []dto.CommentAttachment{{Type: attachtype.PDF}}},
+	}
+
+	merged := mergeCommentTimeline(histories, comments)
+	assert.Len(t, merged, 6)
+
+	// komentar disisipkan sesuai waktu dan membawa status update sebelumnya
+	assert.Equal(t, int64(200), merged[1].Updates.Time)
+	assert.Equal(t, "menunggu kabel", merged[1].Updates.Comment)
+	assert.Equal(t, enum.HProgress, merged[1].Updates.CompleteStatus)
+	assert.Equal(t, "mati", merged[1].Updates.Problem)
+	assert.Equal(t, int64(300), merged[2].Updates.Time)
+	assert.Equal(t, "budi", merged[3].Updates.UpdatedBy)
+	assert.Equal(t, enum.HComplete, merged[3].Updates.CompleteStatus)
+	assert.Equal(t, "restart", merged[3].Updates.ProblemResolve)
+
+	assert.Equal(t, histB, merged[5].ID)
+	assert.Equal(t, enum.HPending, merged[5].Updates.CompleteStatus)
+	assert.Equal(t, "[1 lampiran]", merged[5].Updates.Comment)
+
+	// tanpa komentar timeline tidak berubah
+	assert.Equal(t, histories, mergeCommentTimeline(histories, nil))
+}
+
+func TestBuildCommentThreads(t *testing.T) {
+	root := dto.HistoryComment{ID: primitive.NewObjectID(), CreatedAt: 100}
+	other := dto.HistoryComment{ID: primitive.NewObjectID(), CreatedAt: 300}
+	reply := dto.HistoryComment{ID: primitive.NewObjectID(), ParentID: root.ID.Hex(), CreatedAt: 200}
+	orphan := dto.HistoryComment{ID: primitive.NewObjectID(), ParentID: primitive.NewObjectID().Hex(), CreatedAt: 250}
+
+	threads := buildCommentThreads([]dto.HistoryComment{root, reply, orphan, other})
+	assert.Len(t, threads, 3)
+	assert.Equal(t, root.ID, threads[0].ID)
+	assert.Len(t, threads[0].Replies, 1)
+	assert.Equal(t, orphan.ID, threads[1].ID)
+	assert.Equal(t, other.ID, threads[2].ID)
+	assert.Len(t, threads[2].Replies, 0)
+}
+
+func TestCommentAttachmentsAndEditWindow(t *testing.T) {
+	attachments := commentAttachments([]string{"image/comment/a-0.jpg", "image/comment/a-1.PDF"})
+	assert.Equal(t, dto.CommentAttachment{Type: attachtype.Image, Path: "image/comment/a-0.jpg", Thumbnail: "image/comment/thumb_a-0.jpg"}, attachments[0])
+	assert.Equal(t, dto.CommentAttachment{Type: attachtype.PDF, Path: "image/comment/a-1.PDF"}, attachments[1])
+
+	comment := dto.HistoryComment{CreatedByID: "user1", CreatedAt: 1000}
+	assert.True(t, canModifyComment(comment, "user1", 1000+commentEditWindow))
+	assert.False(t, canModifyComment(comment, "user1", 1001+commentEditWindow))
+	assert.False(t, canModifyComment(comment, "user2", 1000))
+}
@@ -2,9 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/constants/category"
 	"github.com/muchlist/risa_restfull/constants/enum"
@@ -12,6 +9,7 @@ import (
 	"github.com/muchlist/risa_restfull/dao/computerdao"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
@@ -23,18 +21,30 @@ import (
 
 func NewComputerService(computerDao computerdao.ComputerDaoAssumer,
 	histDao historydao.HistorySaver,
-	genDao genunitdao.GenUnitDaoAssumer) ComputerServiceAssumer {
+	genDao genunitdao.GenUnitDaoAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	customFieldService CustomFieldServiceAssumer,
+	dataQualityService DataQualityServiceAssumer,
+	searchIndexer SearchIndexer) ComputerServiceAssumer {
 	return &computerService{
-		daoC: computerDao,
-		daoH: histDao,
-		daoG: genDao,
+		daoC:   computerDao,
+		daoH:   histDao,
+		daoG:   genDao,
+		daoT:   txDao,
+		servCf: customFieldService,
+		servDq: dataQualityService,
+		servSi: searchIndexer,
 	}
 }
 
 type computerService struct {
-	daoC computerdao.ComputerDaoAssumer
-	daoH historydao.HistorySaver
-	daoG genunitdao.GenUnitDaoAssumer
+	daoC   computerdao.ComputerDaoAssumer
+	daoH   historydao.HistorySaver
+	daoG   genunitdao.GenUnitDaoAssumer
+	daoT   transactiondao.TransactionDaoAssumer
+	servCf CustomFieldServiceAssumer
+	servDq DataQualityServiceAssumer
+	servSi SearchIndexer
 }
 type ComputerServiceAssumer interface {
 	InsertComputer(ctx context.Context, user mjwt.CustomClaim, input dto.ComputerRequest) (*string, rest_err.APIError)
@@ -44,7 +54,7 @@ type ComputerServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.Computer, rest_err.APIError)
 
 	GetComputerByID(ctx context.Context, computerID string, branchIfSpecific string) (*dto.Computer, rest_err.APIError)
-	FindComputer(ctx context.Context, filter dto.FilterComputer) (dto.ComputerResponseMinList, dto.GenUnitResponseList, rest_err.APIError)
+	FindComputer(ctx context.Context, filter dto.FilterComputer, page dto.PageRequest) (dto.ComputerResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError)
 }
 
 func (c *computerService) InsertComputer(ctx context.Context, user mjwt.CustomClaim, input dto.ComputerRequest) (*string, rest_err.APIError) {
@@ -61,18 +71,22 @@ func (c *computerService) InsertComputer(ctx context.Context, user mjwt.CustomCl
 		input.IP = "0.0.0.0"
 	}
 
-	// kembalian dari golang channel
-	type result struct {
-		id  *string
-		err rest_err.APIError
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
 	}
 
-	resultChan := make(chan result, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
+	customFields, err := c.servCf.ValidateValues(ctx, category.PC, input.CustomFields)
+	if err != nil {
+		return nil, err
+	}
 
-	insertToComputer := func() {
-		defer wg.Done()
+	var insertedID *string
+	err = c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
 		// Filling data
 		timeNow := time.Now().Unix()
 		data := dto.Computer{
@@ -105,22 +119,22 @@ func (c *computerService) InsertComputer(ctx context.Context, user mjwt.CustomCl
 			Brand: input.Brand,
 			Type:  input.Type,
 			Note:  input.Note,
+
+			Lifecycle: input.Lifecycle,
+			Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+			CustomFields: customFields,
 		}
 
 		// DB
-		insertedID, err := c.daoC.InsertPc(ctx, data)
-
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
+		resultID, err := c.daoC.InsertPc(txCtx, data)
+		if err != nil {
+			return err
 		}
-	}
 
-	insertToGenUnit := func() {
-		defer wg.Done()
 		// Menambahkan juga General Unit dengan ID yang sama
 		// DB
-		insertedID, err := c.daoG.InsertUnit(ctx,
+		_, err = c.daoG.InsertUnit(txCtx,
 			dto.GenUnit{
 				ID:       idGenerated.Hex(),
 				Category: category.PC,
@@ -128,36 +142,19 @@ func (c *computerService) InsertComputer(ctx context.Context, user mjwt.CustomCl
 				IP:       input.IP,
 				Branch:   user.Branch,
 			})
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
-		}
-	}
-
-	go insertToComputer()
-	go insertToGenUnit()
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	var resultID *string
-	var errString string
-	for v := range resultChan {
-		if v.err != nil {
-			errString = errString + v.err.Message() + ". "
-		}
-		if resultID == nil {
-			resultID = v.id
+		if err != nil {
+			return err
 		}
-	}
 
-	if errString != "" {
-		return nil, rest_err.NewBadRequestError(errString)
+		insertedID = resultID
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return resultID, nil
+	c.servSi.IndexUnit(ctx, idGenerated.Hex())
+	return insertedID, nil
 }
 
 func (c *computerService) EditComputer(ctx context.Context, user mjwt.CustomClaim, computerID string, input dto.ComputerEditRequest) (*dto.Computer, rest_err.APIError) {
@@ -175,6 +172,26 @@ func (c *computerService) EditComputer(ctx context.Context, user mjwt.CustomClai
 		input.IP = "0.0.0.0"
 	}
 
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		ID:              computerID,
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
+	}
+
+	// custom_fields yang tidak dikirim (null) tidak merubah nilai tersimpan
+	var customFields dto.CustomFields
+	if input.CustomFields != nil {
+		validated, err := c.servCf.ValidateValues(ctx, category.PC, input.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		customFields = validated
+	}
+
 	// Filling data
 	timeNow := time.Now().Unix()
 	data := dto.ComputerEdit{ // Prefer filter dan datanya dipisah dengan dua struct
@@ -202,35 +219,35 @@ func (c *computerService) EditComputer(ctx context.Context, user mjwt.CustomClai
 		Brand:           input.Brand,
 		Type:            input.Type,
 		Note:            input.Note,
-	}
 
-	// DB
-	computerEdited, err := c.daoC.EditPc(ctx, data)
-	if err != nil {
-		return nil, err
+		Lifecycle: input.Lifecycle,
+		Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+		CustomFields: customFields,
 	}
 
-	errChan := make(chan rest_err.APIError, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
+	var edited *dto.Computer
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		computerEdited, err := c.daoC.EditPc(txCtx, data)
+		if err != nil {
+			return err
+		}
 
-	editUnit := func() {
-		defer wg.Done()
 		// DB
-		_, err = c.daoG.EditUnit(ctx, computerID, dto.GenUnitEditRequest{
+		_, err = c.daoG.EditUnit(txCtx, computerID, dto.GenUnitEditRequest{
 			Category: category.PC,
 			Name:     computerEdited.Name,
 			IP:       computerEdited.IP,
 			Branch:   computerEdited.Branch,
 		})
-		errChan <- err
-	}
+		if err != nil {
+			return err
+		}
 
-	insertHistory := func() {
-		defer wg.Done()
 		isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
 		// DB
-		_, err = c.daoH.InsertHistory(ctx,
+		_, err = c.daoH.InsertHistory(txCtx,
 			dto.History{
 				ID:             primitive.NewObjectID(),
 				CreatedAt:      timeNow,
@@ -252,32 +269,19 @@ func (c *computerService) EditComputer(ctx context.Context, user mjwt.CustomClai
 				Tag:            []string{},
 				Image:          "",
 			}, isVendor)
-		errChan <- err
-	}
-
-	go editUnit()
-	go insertHistory()
-
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-
-	var errMessage string
-	for err := range errChan {
 		if err != nil {
-			errMessage += ". "
+			return err
 		}
-	}
 
-	if errMessage != "" {
-		errorPartial := errors.New("partial error")
-		restErr := rest_err.NewInternalServerError(fmt.Sprintf("Mengedit computer berhasil namun : %s", errMessage), errorPartial)
-		logger.Error(restErr.Message(), errorPartial)
-		return nil, restErr
+		edited = computerEdited
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return computerEdited, nil
+	c.servSi.IndexUnit(ctx, computerID)
+	return edited, nil
 }
 
 func (c *computerService) DeleteComputer(ctx context.Context, user mjwt.CustomClaim, id string, force bool) rest_err.APIError {
@@ -291,23 +295,26 @@ func (c *computerService) DeleteComputer(ctx context.Context, user mjwt.CustomCl
 	if force {
 		timeMinusOneDay = 0
 	}
-	// DB
-	_, err := c.daoC.DeletePc(ctx, dto.FilterIDBranchCreateGte{
-		FilterID:        oid,
-		FilterBranch:    user.Branch,
-		FilterCreateGTE: timeMinusOneDay,
-	})
-	if err != nil {
-		return err
-	}
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		_, err := c.daoC.DeletePc(txCtx, dto.FilterIDBranchCreateGte{
+			FilterID:        oid,
+			FilterBranch:    user.Branch,
+			FilterCreateGTE: timeMinusOneDay,
+		}, user)
+		if err != nil {
+			return err
+		}
 
-	// Delete unit_gen
-	// DB
-	err = c.daoG.DeleteUnit(ctx, id)
+		// Delete unit_gen
+		// DB
+		return c.daoG.DeleteUnit(txCtx, id, user)
+	})
 	if err != nil {
 		return err
 	}
 
+	c.servSi.IndexUnit(ctx, id)
 	return nil
 }
 
@@ -330,6 +337,7 @@ func (c *computerService) DisableComputer(ctx context.Context, computerID string
 		return nil, err
 	}
 
+	c.servSi.IndexUnit(ctx, oid.Hex())
 	return computer, nil
 }
 
@@ -414,18 +422,25 @@ func (c *computerService) GetComputerByID(ctx context.Context, computerID string
 	return computerData, nil
 }
 
-func (c *computerService) FindComputer(ctx context.Context, filter dto.FilterComputer) (dto.ComputerResponseMinList, dto.GenUnitResponseList, rest_err.APIError) {
+func (c *computerService) FindComputer(ctx context.Context, filter dto.FilterComputer, page dto.PageRequest) (dto.ComputerResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
 	// cek apakah ip address valid, jika valid maka set filter.FilterName ke kosong supaya pencarian berdasarkan IP
 	if filter.FilterIP != "" {
 		if net.ParseIP(filter.FilterIP) == nil {
-			return nil, nil, rest_err.NewBadRequestError("IP Address tidak valid")
+			return nil, nil, dto.PageInfo{}, rest_err.NewBadRequestError("IP Address tidak valid")
 		}
 		filter.FilterName = ""
 	}
 
+	customFilter, err := c.servCf.ParseFilter(ctx, category.PC, filter.FilterCustomFields)
+	if err != nil {
+		return nil, nil, dto.PageInfo{}, err
+	}
+	filter.FilterCustomFields = customFilter
+
 	// wrap golang channel
 	type resultComputer struct {
 		data dto.ComputerResponseMinList
+		page dto.PageInfo
 		err  rest_err.APIError
 	}
 
@@ -442,9 +457,10 @@ func (c *computerService) FindComputer(ctx context.Context, filter dto.FilterCom
 
 	go func() {
 		defer wg.Done()
-		computerList, err := c.daoC.FindPc(ctx, filter)
+		computerList, pageInfo, err := c.daoC.FindPc(ctx, filter, page)
 		computerListChan <- resultComputer{
 			data: computerList,
+			page: pageInfo,
 			err:  err,
 		}
 	}()
@@ -461,12 +477,12 @@ func (c *computerService) FindComputer(ctx context.Context, filter dto.FilterCom
 			return
 		}
 
-		generalList, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+		generalList, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 			Branch:   filter.FilterBranch,
 			Category: category.PC,
 			Pings:    false,
 			Name:     "",
-		})
+		}, dto.PageRequest{All: true})
 		generalListChan <- resultGeneral{
 			data: generalList,
 			err:  err,
@@ -475,14 +491,14 @@ func (c *computerService) FindComputer(ctx context.Context, filter dto.FilterCom
 
 	computerList := <-computerListChan
 	if computerList.err != nil {
-		return nil, nil, computerList.err
+		return nil, nil, dto.PageInfo{}, computerList.err
 	}
 
 	generalList := <-generalListChan
 	if generalList.err != nil {
-		return nil, nil, generalList.err
+		return nil, nil, dto.PageInfo{}, generalList.err
 	}
 
 	filterGeneral(&generalList.data)
-	return computerList.data, generalList.data, nil
+	return computerList.data, generalList.data, computerList.page, nil
 }
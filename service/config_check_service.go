@@ -42,7 +42,7 @@ type ConfigCheckServiceAssumer interface {
 	DeleteConfigCheck(ctx context.Context, user mjwt.CustomClaim, id string) rest_err.APIError
 	GetConfigCheckByID(ctx context.Context, configCheckID string, branchIfSpecific string) (*dto.ConfigCheck, rest_err.APIError)
 	UpdateManyConfigCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.ConfigCheckUpdateManyRequest) (*dto.ConfigCheck, rest_err.APIError)
-	FindConfigCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.ConfigCheck, rest_err.APIError)
+	FindConfigCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.ConfigCheck, dto.PageInfo, rest_err.APIError)
 	UpdateConfigCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.ConfigCheckItemUpdateRequest) (*dto.ConfigCheck, rest_err.APIError)
 	FinishCheck(ctx context.Context, user mjwt.CustomClaim, detailID string) (*dto.ConfigCheck, rest_err.APIError)
 }
@@ -50,10 +50,10 @@ type ConfigCheckServiceAssumer interface {
 func (c *configCheckService) InsertConfigCheck(ctx context.Context, user mjwt.CustomClaim) (*string, rest_err.APIError) {
 	timeNow := time.Now().Unix()
 
-	networkItems, err := c.daoNetwork.FindOther(ctx, dto.FilterOther{
+	networkItems, _, err := c.daoNetwork.FindOther(ctx, dto.FilterOther{
 		FilterBranch:      user.Branch,
 		FilterSubCategory: fmt.Sprintf("%s,%s", category.Network, category.Altai),
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -261,10 +261,10 @@ func (c *configCheckService) GetConfigCheckByID(ctx context.Context, configCheck
 	return configCheck, nil
 }
 
-func (c *configCheckService) FindConfigCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.ConfigCheck, rest_err.APIError) {
-	configCheckList, err := c.daoC.FindCheck(ctx, branch, filter, false)
+func (c *configCheckService) FindConfigCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.ConfigCheck, dto.PageInfo, rest_err.APIError) {
+	configCheckList, pageInfo, err := c.daoC.FindCheck(ctx, branch, filter, page, false)
 	if err != nil {
-		return []dto.ConfigCheck{}, err
+		return nil, dto.PageInfo{}, err
 	}
-	return configCheckList, nil
+	return configCheckList, pageInfo, nil
 }
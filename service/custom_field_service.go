@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/dao/customfielddao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func NewCustomFieldService(customFieldDao customfielddao.CustomFieldDaoAssumer) CustomFieldServiceAssumer {
+	return &customFieldService{
+		daoCf: customFieldDao,
+	}
+}
+
+type customFieldService struct {
+	daoCf customfielddao.CustomFieldDaoAssumer
+}
+
+type CustomFieldServiceAssumer interface {
+	UpsertSchema(ctx context.Context, user mjwt.CustomClaim, input dto.CustomFieldSchemaRequest) (*dto.CustomFieldSchema, rest_err.APIError)
+	DeleteSchema(ctx context.Context, category string) rest_err.APIError
+	FindSchema(ctx context.Context) ([]dto.CustomFieldSchema, rest_err.APIError)
+	GetFields(ctx context.Context, category string) ([]dto.CustomField, rest_err.APIError)
+	ValidateValues(ctx context.Context, category string, values dto.CustomFields) (dto.CustomFields, rest_err.APIError)
+	ParseFilter(ctx context.Context, category string, raw map[string]interface{}) (map[string]interface{}, rest_err.APIError)
+}
+
+func (c *customFieldService) UpsertSchema(ctx context.Context, user mjwt.CustomClaim, input dto.CustomFieldSchemaRequest) (*dto.CustomFieldSchema, rest_err.APIError) {
+	for i := range input.Fields {
+		input.Fields[i].Label = strings.TrimSpace(input.Fields[i].Label)
+		if input.Fields[i].Type != fieldtype.Enum {
+			input.Fields[i].Options = []string{}
+		}
+	}
+
+	schema, err := c.daoCf.UpsertSchema(ctx, dto.CustomFieldSchema{
+		UpdatedAt:   time.Now().Unix(),
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Category:    strings.ToUpper(input.Category),
+		Fields:      input.Fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (c *customFieldService) DeleteSchema(ctx context.Context, category string) rest_err.APIError {
+	return c.daoCf.DeleteSchema(ctx, category)
+}
+
+func (c *customFieldService) FindSchema(ctx context.Context) ([]dto.CustomFieldSchema, rest_err.APIError) {
+	schemas, err := c.daoCf.FindSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// GetFields mengembalikan definisi custom field kategori, kategori tanpa schema mengembalikan slice kosong
+func (c *customFieldService) GetFields(ctx context.Context, category string) ([]dto.CustomField, rest_err.APIError) {
+	schemas, err := c.daoCf.FindSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	category = strings.ToUpper(category)
+	for _, schema := range schemas {
+		if schema.Category == category {
+			return schema.Fields, nil
+		}
+	}
+	return []dto.CustomField{}, nil
+}
+
+// ValidateValues memeriksa nilai custom field terhadap schema kategori dan mengembalikan nilai yang sudah
+// dikonversi ke tipe penyimpanannya. nil dikembalikan jika tidak ada nilai yang disimpan
+func (c *customFieldService) ValidateValues(ctx context.Context, category string, values dto.CustomFields) (dto.CustomFields, rest_err.APIError) {
+	fields, err := c.GetFields(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return validateCustomFields(fields, values)
+}
+
+// ParseFilter mengubah query custom field (string) menjadi filter sesuai tipe field pada schema kategori
+func (c *customFieldService) ParseFilter(ctx context.Context, category string, raw map[string]interface{}) (map[string]interface{}, rest_err.APIError) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	fields, err := c.GetFields(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return parseCustomFieldFilter(fields, raw)
+}
+
+// validateCustomFields nilai dari json berupa string, float64 atau bool.
+// DATE disimpan sebagai int64 unix detik, key yang tidak terdaftar ditolak
+func validateCustomFields(fields []dto.CustomField, values dto.CustomFields) (dto.CustomFields, rest_err.APIError) {
+	fieldMap := make(map[string]dto.CustomField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Key] = field
+	}
+	for key := range values {
+		if _, ok := fieldMap[key]; !ok {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s tidak terdaftar", key))
+		}
+	}
+
+	cleaned := make(dto.CustomFields)
+	for _, field := range fields {
+		value, ok := values[field.Key]
+		if str, isStr := value.(string); ok && isStr && strings.TrimSpace(str) == "" {
+			ok = false
+		}
+		if !ok || value == nil {
+			if field.Required {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s wajib diisi", field.Label))
+			}
+			continue
+		}
+
+		switch field.Type {
+		case fieldtype.Text:
+			str, isStr := value.(string)
+			if !isStr {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s harus berupa teks", field.Label))
+			}
+			cleaned[field.Key] = strings.TrimSpace(str)
+		case fieldtype.Number:
+			number, isNumber := value.(float64)
+			if !isNumber {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s harus berupa angka", field.Label))
+			}
+			cleaned[field.Key] = number
+		case fieldtype.Bool:
+			boolean, isBool := value.(bool)
+			if !isBool {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s harus berupa boolean", field.Label))
+			}
+			cleaned[field.Key] = boolean
+		case fieldtype.Date:
+			number, isNumber := value.(float64)
+			if !isNumber || number != math.Trunc(number) {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s harus berupa tanggal unix", field.Label))
+			}
+			cleaned[field.Key] = int64(number)
+		case fieldtype.Enum:
+			str, isStr := value.(string)
+			if !isStr || !sfunc.InSlice(str, field.Options) {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s harus salah satu dari %s", field.Label, field.Options))
+			}
+			cleaned[field.Key] = str
+		}
+	}
+
+	if len(cleaned) == 0 {
+		return nil, nil
+	}
+	return cleaned, nil
+}
+
+// parseCustomFieldFilter TEXT dicari dengan regex tanpa membedakan huruf besar kecil,
+// tipe lain harus sama persis dengan nilai tersimpan
+func parseCustomFieldFilter(fields []dto.CustomField, raw map[string]interface{}) (map[string]interface{}, rest_err.APIError) {
+	fieldMap := make(map[string]dto.CustomField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Key] = field
+	}
+
+	filter := make(map[string]interface{}, len(raw))
+	for key, rawValue := range raw {
+		field, ok := fieldMap[key]
+		if !ok {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("custom field %s tidak terdaftar", key))
+		}
+		value := strings.TrimSpace(fmt.Sprint(rawValue))
+
+		switch field.Type {
+		case fieldtype.Text:
+			filter[key] = primitive.Regex{Pattern: regexp.QuoteMeta(value), Options: "i"}
+		case fieldtype.Number:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("filter %s harus berupa angka", key))
+			}
+			filter[key] = number
+		case fieldtype.Bool:
+			boolean, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("filter %s harus berupa boolean", key))
+			}
+			filter[key] = boolean
+		case fieldtype.Date:
+			date, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("filter %s harus berupa tanggal unix", key))
+			}
+			filter[key] = date
+		default:
+			filter[key] = value
+		}
+	}
+	return filter, nil
+}
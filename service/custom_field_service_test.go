@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testCustomFields = []dto.CustomField{
+	{Key: "vendor", Label: "Vendor", Type: fieldtype.Text, Required: true},
+	{Key: "port", Label: "Port", Type: fieldtype.Number},
+	{Key: "poe", Label: "PoE", Type: fieldtype.Bool},
+	{Key: "installed", Label: "Terpasang", Type: fieldtype.Date},
+	{Key: "zone", Label: "Zona", Type: fieldtype.Enum, Options: []string{"A", "B"}},
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	cleaned, err := validateCustomFields(testCustomFields, dto.CustomFields{
+		"vendor":    " Hikvision ",
+		"port":      float64(8080),
+		"poe":       true,
+		"installed": float64(1600000000),
+		"zone":      "B",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, dto.CustomFields{
+		"vendor":    "Hikvision",
+		"port":      float64(8080),
+		"poe":       true,
+		"installed": int64(1600000000),
+		"zone":      "B",
+	}, cleaned)
+
+	// field tidak wajib boleh kosong
+	cleaned, err = validateCustomFields(testCustomFields, dto.CustomFields{"vendor": "Axis"})
+	assert.Nil(t, err)
+	assert.Equal(t, dto.CustomFields{"vendor": "Axis"}, cleaned)
+
+	// tanpa schema dan tanpa nilai
+	cleaned, err = validateCustomFields(nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, cleaned)
+
+	invalid := []dto.CustomFields{
+		{"vendor": ""},
+		{"vendor": "Axis", "unknown": "x"},
+		{"vendor": "Axis", "port": "8080"},
+		{"vendor": "Axis", "poe": "true"},
+		{"vendor": "Axis", "installed": 1.5},
+		{"vendor": "Axis", "zone": "C"},
+	}
+	for _, values := range invalid {
+		_, err = validateCustomFields(testCustomFields, values)
+		assert.NotNil(t, err, values)
+	}
+}
+
+func TestParseCustomFieldFilter(t *testing.T) {
+	filter, err := parseCustomFieldFilter(testCustomFields, map[string]interface{}{
+		"vendor":    "hik.",
+		"port":      "8080",
+		"poe":       "true",
+		"installed": "1600000000",
+		"zone":      "A",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"vendor":    primitive.Regex{Pattern: `hik\.`, Options: "i"},
+		"port":      float64(8080),
+		"poe":       true,
+		"installed": int64(1600000000),
+		"zone":      "A",
+	}, filter)
+
+	_, err = parseCustomFieldFilter(testCustomFields, map[string]interface{}{"port": "abc"})
+	assert.NotNil(t, err)
+	_, err = parseCustomFieldFilter(testCustomFields, map[string]interface{}{"unknown": "abc"})
+	assert.NotNil(t, err)
+}
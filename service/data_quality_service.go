@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/dataquality"
+	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/dataqualitydao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+)
+
+const (
+	// minSimilarNameLen nama yang lebih pendek dari ini hanya dibandingkan secara utuh
+	minSimilarNameLen = 6
+	defaultIP         = "0.0.0.0"
+)
+
+func NewDataQualityService(
+	dataQualityDao dataqualitydao.DataQualityDaoAssumer,
+	genDao genunitdao.GenUnitLoader,
+	cctvDao cctvdao.CctvLoader,
+	computerDao computerdao.ComputerLoader,
+	otherDao otherdao.OtherLoader,
+) DataQualityServiceAssumer {
+	return &dataQualityService{
+		daoDq: dataQualityDao,
+		daoG:  genDao,
+		daoC:  cctvDao,
+		daoPC: computerDao,
+		daoO:  otherDao,
+	}
+}
+
+type dataQualityService struct {
+	daoDq dataqualitydao.DataQualityDaoAssumer
+	daoG  genunitdao.GenUnitLoader
+	daoC  cctvdao.CctvLoader
+	daoPC computerdao.ComputerLoader
+	daoO  otherdao.OtherLoader
+}
+
+type DataQualityServiceAssumer interface {
+	ValidateUnique(ctx context.Context, user mjwt.CustomClaim, input dto.UnitUniqueCheck) rest_err.APIError
+	Scan(ctx context.Context, branch string, scannedBy string) (*dto.DataQualityReport, rest_err.APIError)
+	GetReport(ctx context.Context, branch string) (*dto.DataQualityReport, rest_err.APIError)
+}
+
+// ValidateUnique menolak ip yang sudah dipakai unit aktif lain pada branch yang sama
+// dan nomor inventaris yang sudah dipakai cctv, computer atau other lain di seluruh branch.
+// admin dapat melewati pengecekan dengan force_duplicate
+func (d *dataQualityService) ValidateUnique(ctx context.Context, user mjwt.CustomClaim, input dto.UnitUniqueCheck) rest_err.APIError {
+	if input.Force && sfunc.InSlice(roles.RoleAdmin, user.Roles) {
+		return nil
+	}
+
+	var conflicts []string
+	if input.IP != "" && input.IP != defaultIP {
+		units, _, err := d.daoG.FindUnit(ctx, dto.GenUnitFilter{
+			Branch: input.Branch,
+			IP:     input.IP,
+		}, dto.PageRequest{All: true})
+		if err != nil {
+			return err
+		}
+		for _, unit := range units {
+			if unit.ID != input.ID {
+				conflicts = append(conflicts, fmt.Sprintf("ip %s dipakai %s (%s)", input.IP, unit.Name, unit.Category))
+			}
+		}
+	}
+
+	if !blankInventory(input.InventoryNumber) {
+		assets, err := d.inventoryAssets(ctx, "", strings.TrimSpace(input.InventoryNumber))
+		if err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			if asset.ID != input.ID {
+				conflicts = append(conflicts, fmt.Sprintf("nomor inventaris %s dipakai %s (%s %s)", asset.InventoryNumber, asset.Name, asset.Category, asset.Branch))
+			}
+		}
+	}
+
+	if len(conflicts) != 0 {
+		return rest_err.NewBadRequestError(fmt.Sprintf("%s. admin dapat menggunakan force_duplicate untuk tetap menyimpan", strings.Join(conflicts, ", ")))
+	}
+	return nil
+}
+
+// Scan memindai konflik ip, nomor inventaris ganda dan nama yang mirip pada branch lalu menyimpan hasilnya.
+// nomor inventaris dibandingkan dengan seluruh branch, temuan disimpan jika melibatkan unit branch ini
+func (d *dataQualityService) Scan(ctx context.Context, branch string, scannedBy string) (*dto.DataQualityReport, rest_err.APIError) {
+	branch = strings.ToUpper(branch)
+
+	units, _, err := d.daoG.FindUnit(ctx, dto.GenUnitFilter{Branch: branch}, dto.PageRequest{All: true})
+	if err != nil {
+		return nil, err
+	}
+	assets, err := d.inventoryAssets(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []dto.DataQualityFinding
+	findings = append(findings, ipConflicts(units)...)
+	findings = append(findings, inventoryDuplicates(assets, branch)...)
+	findings = append(findings, similarNames(units)...)
+
+	summary := make(map[string]int)
+	for _, findingType := range dataquality.GetFindingTypeAvailable() {
+		summary[findingType] = 0
+	}
+	for _, finding := range findings {
+		summary[finding.Type]++
+	}
+
+	return d.daoDq.UpsertReport(ctx, dto.DataQualityReport{
+		Branch:      branch,
+		GeneratedAt: time.Now().Unix(),
+		GeneratedBy: scannedBy,
+		Summary:     summary,
+		Findings:    findings,
+	})
+}
+
+func (d *dataQualityService) GetReport(ctx context.Context, branch string) (*dto.DataQualityReport, rest_err.APIError) {
+	return d.daoDq.GetReport(ctx, branch)
+}
+
+// inventoryAssets membaca cctv, computer dan other aktif yang memiliki nomor inventaris
+func (d *dataQualityService) inventoryAssets(ctx context.Context, branch string, inventoryNumber string) ([]dto.DataQualityUnit, rest_err.APIError) {
+	var assets []dto.DataQualityUnit
+	add := func(id, cat, name, unitBranch, inventory string) {
+		if blankInventory(inventory) {
+			return
+		}
+		assets = append(assets, dto.DataQualityUnit{
+			ID:              id,
+			Category:        cat,
+			Name:            name,
+			Branch:          unitBranch,
+			InventoryNumber: inventory,
+		})
+	}
+
+	if err := d.daoC.IterateCctv(ctx, dto.FilterBranchLocIPNameDisable{FilterBranch: branch, FilterInventoryNumber: inventoryNumber}, func(cctv dto.Cctv) error {
+		add(cctv.ID.Hex(), category.Cctv, cctv.Name, cctv.Branch, cctv.InventoryNumber)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := d.daoPC.IteratePc(ctx, dto.FilterComputer{FilterBranch: branch, FilterInventoryNumber: inventoryNumber, FilterSeatManagement: -1}, func(pc dto.Computer) error {
+		add(pc.ID.Hex(), category.PC, pc.Name, pc.Branch, pc.InventoryNumber)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := d.daoO.IterateOther(ctx, dto.FilterOther{FilterBranch: branch, FilterInventoryNumber: inventoryNumber}, func(other dto.Other) error {
+		add(other.ID.Hex(), other.SubCategory, other.Name, other.Branch, other.InventoryNumber)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// blankInventory nomor inventaris kosong atau placeholder seperti "-" dan "000" tidak dianggap duplikat
+func blankInventory(inventory string) bool {
+	return strings.Trim(inventory, " -0") == ""
+}
+
+// ipConflicts mengelompokkan unit aktif yang memakai ip yang sama, ip default diabaikan
+func ipConflicts(units dto.GenUnitResponseList) []dto.DataQualityFinding {
+	groups := make(map[string][]dto.DataQualityUnit)
+	var keys []string
+	for _, unit := range units {
+		if unit.IP == "" || unit.IP == defaultIP {
+			continue
+		}
+		if _, ok := groups[unit.IP]; !ok {
+			keys = append(keys, unit.IP)
+		}
+		groups[unit.IP] = append(groups[unit.IP], dto.DataQualityUnit{
+			ID:       unit.ID,
+			Category: unit.Category,
+			Name:     unit.Name,
+			Branch:   unit.Branch,
+			IP:       unit.IP,
+		})
+	}
+	return groupFindings(dataquality.IPConflict, keys, groups)
+}
+
+// inventoryDuplicates mengelompokkan nomor inventaris yang sama tanpa membedakan huruf besar kecil.
+// jika branch diisi hanya kelompok yang memiliki unit pada branch tersebut yang dikembalikan
+func inventoryDuplicates(assets []dto.DataQualityUnit, branch string) []dto.DataQualityFinding {
+	groups := make(map[string][]dto.DataQualityUnit)
+	var keys []string
+	for _, asset := range assets {
+		key := strings.ToUpper(strings.TrimSpace(asset.InventoryNumber))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], asset)
+	}
+
+	var findings []dto.DataQualityFinding
+	for _, finding := range groupFindings(dataquality.DuplicateInventory, keys, groups) {
+		if branch == "" {
+			findings = append(findings, finding)
+			continue
+		}
+		for _, unit := range finding.Units {
+			if unit.Branch == branch {
+				findings = append(findings, finding)
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// similarNames mencari nama unit yang sama setelah spasi, tanda baca dan huruf besar kecil diabaikan,
+// atau berbeda satu huruf dengan angka yang sama sehingga CCTV-01 dan CCTV-02 tidak dianggap mirip
+func similarNames(units dto.GenUnitResponseList) []dto.DataQualityFinding {
+	groups := make(map[string][]dto.DataQualityUnit)
+	var keys []string
+	for _, unit := range units {
+		key := normalizeName(unit.Name)
+		if key == "" {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], dto.DataQualityUnit{
+			ID:       unit.ID,
+			Category: unit.Category,
+			Name:     unit.Name,
+			Branch:   unit.Branch,
+		})
+	}
+
+	findings := groupFindings(dataquality.SimilarName, keys, groups)
+
+	sort.Strings(keys)
+	for i := 0; i < len(keys); i++ {
+		for j := i + 1; j < len(keys); j++ {
+			a, b := keys[i], keys[j]
+			if len(a) < minSimilarNameLen || len(b) < minSimilarNameLen {
+				continue
+			}
+			if digitsOf(a) != digitsOf(b) || !withinOneEdit(a, b) {
+				continue
+			}
+			var pair []dto.DataQualityUnit
+			pair = append(pair, groups[a]...)
+			pair = append(pair, groups[b]...)
+			findings = append(findings, dto.DataQualityFinding{
+				Type:  dataquality.SimilarName,
+				Value: fmt.Sprintf("%s ~ %s", groups[a][0].Name, groups[b][0].Name),
+				Units: pair,
+			})
+		}
+	}
+	return findings
+}
+
+// groupFindings mengubah kelompok dengan lebih dari satu unit menjadi temuan sesuai urutan keys
+func groupFindings(findingType string, keys []string, groups map[string][]dto.DataQualityUnit) []dto.DataQualityFinding {
+	var findings []dto.DataQualityFinding
+	for _, key := range keys {
+		if len(groups[key]) < 2 {
+			continue
+		}
+		findings = append(findings, dto.DataQualityFinding{
+			Type:  findingType,
+			Value: key,
+			Units: groups[key],
+		})
+	}
+	return findings
+}
+
+// normalizeName huruf besar tanpa spasi dan tanda baca
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func digitsOf(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// withinOneEdit true jika a dan b berbeda tepat satu sisipan, hapusan atau penggantian huruf
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	i, j, edits := 0, 0, 0
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(ra) == len(rb) {
+			i++
+		}
+		j++
+	}
+	edits += len(rb) - j
+	return edits == 1
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/dataquality"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPConflicts(t *testing.T) {
+	findings := ipConflicts(dto.GenUnitResponseList{
+		{ID: "1", Category: "CCTV", Name: "CCTV GATE", IP: "10.0.0.1"},
+		{ID: "2", Category: "PC", Name: "PC ADMIN", IP: "10.0.0.1"},
+		{ID: "3", Category: "PC", Name: "PC GUDANG", IP: "10.0.0.2"},
+		{ID: "4", Category: "PC", Name: "PC BARU", IP: "0.0.0.0"},
+		{ID: "5", Category: "CCTV", Name: "CCTV BARU", IP: "0.0.0.0"},
+	})
+	assert.Len(t, findings, 1)
+	assert.Equal(t, dataquality.IPConflict, findings[0].Type)
+	assert.Equal(t, "10.0.0.1", findings[0].Value)
+	assert.Len(t, findings[0].Units, 2)
+}
+
+func TestInventoryDuplicates(t *testing.T) {
+	assets := []dto.DataQualityUnit{
+		{ID: "1", Branch: "BANJARMASIN", InventoryNumber: "inv-001"},
+		{ID: "2", Branch: "SAMPIT", InventoryNumber: "INV-001 "},
+		{ID: "3", Branch: "SAMPIT", InventoryNumber: "INV-002"},
+		{ID: "4", Branch: "SAMPIT", InventoryNumber: "INV-002"},
+	}
+
+	findings := inventoryDuplicates(assets, "")
+	assert.Len(t, findings, 2)
+
+	// hanya temuan yang melibatkan branch
+	findings = inventoryDuplicates(assets, "BANJARMASIN")
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "INV-001", findings[0].Value)
+
+	assert.True(t, blankInventory(" - "))
+	assert.True(t, blankInventory("000"))
+	assert.False(t, blankInventory("INV-001"))
+}
+
+func TestSimilarNames(t *testing.T) {
+	findings := similarNames(dto.GenUnitResponseList{
+		{ID: "1", Name: "CCTV Gate-1"},
+		{ID: "2", Name: "CCTV GATE 1"},
+		{ID: "3", Name: "CCTV DERMAGA 01"},
+		{ID: "4", Name: "CCTV DERMAGA 02"},
+		{ID: "5", Name: "PC GUDANG UTAMA"},
+		{ID: "6", Name: "PC GUDANG UTMA"},
+	})
+	assert.Len(t, findings, 2)
+	assert.Equal(t, "CCTVGATE1", findings[0].Value)
+	assert.Len(t, findings[0].Units, 2)
+	assert.Equal(t, []string{"5", "6"}, []string{findings[1].Units[0].ID, findings[1].Units[1].ID})
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	assert.True(t, withinOneEdit("GUDANG", "GUDANX"))
+	assert.True(t, withinOneEdit("GUDANG", "GUDAN"))
+	assert.True(t, withinOneEdit("GUDAN", "GUDANG"))
+	assert.False(t, withinOneEdit("GUDANG", "GUDANG"))
+	assert.False(t, withinOneEdit("GUDANG", "GUDXNX"))
+	assert.False(t, withinOneEdit("GUDANG", "GUD"))
+}
+
+func TestDuplicateValues(t *testing.T) {
+	assert.Equal(t, []string{"10.0.0.1"}, duplicateValues([]string{"10.0.0.1", "10.0.0.2", "10.0.0.1", "10.0.0.1"}))
+	assert.Nil(t, duplicateValues([]string{"10.0.0.1"}))
+}
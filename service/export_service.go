@@ -0,0 +1,548 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/constants/headerlang"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/stockdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/tabular"
+	"github.com/muchlist/risa_restfull/utils/timegen"
+	"github.com/spf13/cast"
+)
+
+const (
+	ExportLangID = headerlang.ID
+	ExportLangEN = headerlang.EN
+)
+
+// ExportStreamer menulis seluruh baris export ke writer.
+// dijalankan setelah header response terkirim sehingga semua validasi sudah dilakukan sebelumnya
+type ExportStreamer func(ctx context.Context, w tabular.Writer) rest_err.APIError
+
+func NewExportService(
+	cctvDao cctvdao.CctvLoader,
+	computerDao computerdao.ComputerLoader,
+	otherDao otherdao.OtherLoader,
+	stockDao stockdao.StockLoader,
+	genDao genunitdao.GenUnitLoader,
+	histDao historydao.HistoryLoader,
+	customFieldServ CustomFieldServiceAssumer,
+) ExportServiceAssumer {
+	return &exportService{
+		daoC:   cctvDao,
+		daoPC:  computerDao,
+		daoO:   otherDao,
+		daoS:   stockDao,
+		daoG:   genDao,
+		daoH:   histDao,
+		servCf: customFieldServ,
+	}
+}
+
+type exportService struct {
+	daoC   cctvdao.CctvLoader
+	daoPC  computerdao.ComputerLoader
+	daoO   otherdao.OtherLoader
+	daoS   stockdao.StockLoader
+	daoG   genunitdao.GenUnitLoader
+	daoH   historydao.HistoryLoader
+	servCf CustomFieldServiceAssumer
+}
+
+type ExportServiceAssumer interface {
+	ExportCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, lang string) (ExportStreamer, rest_err.APIError)
+	ExportComputer(ctx context.Context, filter dto.FilterComputer, lang string) (ExportStreamer, rest_err.APIError)
+	ExportOther(ctx context.Context, filter dto.FilterOther, lang string) (ExportStreamer, rest_err.APIError)
+	ExportStock(ctx context.Context, filter dto.FilterBranchNameCatDisable, lang string) (ExportStreamer, rest_err.APIError)
+}
+
+// exportColumn label header kolom untuk setiap bahasa
+type exportColumn struct {
+	id string
+	en string
+}
+
+// label bahasa inggris sama dengan nama kolom import sehingga hasil export bisa di import kembali
+var (
+	exportCctvColumns = []exportColumn{
+		{id: "Nama", en: "Name"},
+		{id: "IP", en: "IP"},
+		{id: "Nomor Inventaris", en: "Inventory Number"},
+		{id: "Lokasi", en: "Location"},
+		{id: "Lokasi Lat", en: "Location Lat"},
+		{id: "Lokasi Lon", en: "Location Lon"},
+		{id: "Merk", en: "Brand"},
+		{id: "Tipe", en: "Type"},
+		{id: "Tanggal Pengadaan", en: "Date"},
+		{id: "Tag", en: "Tag"},
+		{id: "Non Vendor", en: "Dis Vendor"},
+		{id: "Nonaktif", en: "Disable"},
+		{id: "Catatan", en: "Note"},
+	}
+	exportComputerColumns = []exportColumn{
+		{id: "Nama", en: "Name"},
+		{id: "Hostname", en: "Hostname"},
+		{id: "Divisi", en: "Division"},
+		{id: "Seat Management", en: "Seat Management"},
+		{id: "Sistem Operasi", en: "OS"},
+		{id: "Prosesor", en: "Processor"},
+		{id: "RAM", en: "RAM"},
+		{id: "Hardisk", en: "Hardisk"},
+		{id: "IP", en: "IP"},
+		{id: "Nomor Inventaris", en: "Inventory Number"},
+		{id: "Lokasi", en: "Location"},
+		{id: "Lokasi Lat", en: "Location Lat"},
+		{id: "Lokasi Lon", en: "Location Lon"},
+		{id: "Merk", en: "Brand"},
+		{id: "Tipe", en: "Type"},
+		{id: "Tanggal Pengadaan", en: "Date"},
+		{id: "Tag", en: "Tag"},
+		{id: "Nonaktif", en: "Disable"},
+		{id: "Catatan", en: "Note"},
+	}
+	exportOtherColumns = []exportColumn{
+		{id: "Nama", en: "Name"},
+		{id: "Sub Kategori", en: "Sub Category"},
+		{id: "Detail", en: "Detail"},
+		{id: "Divisi", en: "Division"},
+		{id: "IP", en: "IP"},
+		{id: "Nomor Inventaris", en: "Inventory Number"},
+		{id: "Lokasi", en: "Location"},
+		{id: "Lokasi Lat", en: "Location Lat"},
+		{id: "Lokasi Lon", en: "Location Lon"},
+		{id: "Merk", en: "Brand"},
+		{id: "Tipe", en: "Type"},
+		{id: "Tanggal Pengadaan", en: "Date"},
+		{id: "Tag", en: "Tag"},
+		{id: "Non Vendor", en: "Dis Vendor"},
+		{id: "Nonaktif", en: "Disable"},
+		{id: "Catatan", en: "Note"},
+	}
+	exportStockColumns = []exportColumn{
+		{id: "Nama", en: "Name"},
+		{id: "Kategori", en: "Stock Category"},
+		{id: "Satuan", en: "Unit"},
+		{id: "Jumlah", en: "Qty"},
+		{id: "Batas Minimum", en: "Threshold"},
+		{id: "Lokasi", en: "Location"},
+		{id: "Tag", en: "Tag"},
+		{id: "Nonaktif", en: "Disable"},
+		{id: "Catatan", en: "Note"},
+	}
+	// exportLifecycleColumns data garansi dan umur aset cctv, computer dan other
+	exportLifecycleColumns = []exportColumn{
+		{id: "Tanggal Pembelian", en: "Purchase Date"},
+		{id: "Akhir Garansi", en: "Warranty Expiry"},
+		{id: "End Of Life", en: "End Of Life"},
+		{id: "Vendor", en: "Vendor"},
+		{id: "Harga", en: "Cost"},
+	}
+	// exportExtraColumns berasal dari gen_unit
+	exportExtraColumns = []exportColumn{
+		{id: "Jumlah Kasus", en: "Cases Size"},
+		{id: "Ping Terakhir", en: "Last Ping"},
+	}
+	// exportHistoryColumns berasal dari history terakhir unit
+	exportHistoryColumns = []exportColumn{
+		{id: "Tanggal History Terakhir", en: "Last History Date"},
+		{id: "Status History Terakhir", en: "Last History Status"},
+		{id: "Masalah Terakhir", en: "Last Problem"},
+		{id: "Penyelesaian Terakhir", en: "Last Problem Resolve"},
+		{id: "Progress Terakhir", en: "Last Progress"},
+	}
+)
+
+func (e *exportService) ExportCctv(ctx context.Context, filter dto.FilterBranchLocIPNameDisable, lang string) (ExportStreamer, rest_err.APIError) {
+	if filter.FilterIP != "" {
+		if net.ParseIP(filter.FilterIP) == nil {
+			return nil, rest_err.NewBadRequestError("IP Address tidak valid")
+		}
+		filter.FilterName = ""
+	}
+
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+	lang = exportLanguage(filter.FilterBranch, lang)
+
+	extras, err := e.unitExtras(ctx, filter.FilterBranch, []string{category.Cctv}, filter.FilterDisable)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := e.lastHistories(ctx, filter.FilterBranch, category.Cctv)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := e.customFields(ctx, []string{category.Cctv})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w tabular.Writer) rest_err.APIError {
+		if err := w.Write(exportHeader(lang, exportCctvColumns, exportLifecycleColumns, exportCustomColumns(customFields), exportExtraColumns, exportHistoryColumns)); err != nil {
+			return rest_err.NewInternalServerError("Gagal menulis header export", err)
+		}
+		return e.daoC.IterateCctv(ctx, filter, func(cctv dto.Cctv) error {
+			id := cctv.ID.Hex()
+			record := []string{
+				cctv.Name,
+				cctv.IP,
+				cctv.InventoryNumber,
+				cctv.Location,
+				cctv.LocationLat,
+				cctv.LocationLon,
+				cctv.Brand,
+				cctv.Type,
+				exportDate(cctv.Date),
+				strings.Join(cctv.Tag, ","),
+				exportBool(lang, cctv.DisVendor),
+				exportBool(lang, cctv.Disable),
+				cctv.Note,
+			}
+			record = append(record, exportLifecycleRecord(cctv.Lifecycle)...)
+			record = append(record, exportCustomRecord(lang, customFields, cctv.CustomFields)...)
+			record = append(record, exportExtraRecord(extras, id)...)
+			record = append(record, exportHistoryRecord(histories, id)...)
+			return w.Write(record)
+		})
+	}, nil
+}
+
+func (e *exportService) ExportComputer(ctx context.Context, filter dto.FilterComputer, lang string) (ExportStreamer, rest_err.APIError) {
+	if filter.FilterIP != "" {
+		if net.ParseIP(filter.FilterIP) == nil {
+			return nil, rest_err.NewBadRequestError("IP Address tidak valid")
+		}
+		filter.FilterName = ""
+	}
+
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+	lang = exportLanguage(filter.FilterBranch, lang)
+
+	extras, err := e.unitExtras(ctx, filter.FilterBranch, []string{category.PC}, filter.FilterDisable)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := e.lastHistories(ctx, filter.FilterBranch, category.PC)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := e.customFields(ctx, []string{category.PC})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w tabular.Writer) rest_err.APIError {
+		if err := w.Write(exportHeader(lang, exportComputerColumns, exportLifecycleColumns, exportCustomColumns(customFields), exportExtraColumns, exportHistoryColumns)); err != nil {
+			return rest_err.NewInternalServerError("Gagal menulis header export", err)
+		}
+		return e.daoPC.IteratePc(ctx, filter, func(pc dto.Computer) error {
+			id := pc.ID.Hex()
+			record := []string{
+				pc.Name,
+				pc.Hostname,
+				pc.Division,
+				exportBool(lang, pc.SeatManagement),
+				pc.OS,
+				pc.Processor,
+				strconv.Itoa(pc.Ram),
+				strconv.Itoa(pc.Hardisk),
+				pc.IP,
+				pc.InventoryNumber,
+				pc.Location,
+				pc.LocationLat,
+				pc.LocationLon,
+				pc.Brand,
+				pc.Type,
+				exportDate(pc.Date),
+				strings.Join(pc.Tag, ","),
+				exportBool(lang, pc.Disable),
+				pc.Note,
+			}
+			record = append(record, exportLifecycleRecord(pc.Lifecycle)...)
+			record = append(record, exportCustomRecord(lang, customFields, pc.CustomFields)...)
+			record = append(record, exportExtraRecord(extras, id)...)
+			record = append(record, exportHistoryRecord(histories, id)...)
+			return w.Write(record)
+		})
+	}, nil
+}
+
+func (e *exportService) ExportOther(ctx context.Context, filter dto.FilterOther, lang string) (ExportStreamer, rest_err.APIError) {
+	if filter.FilterIP != "" {
+		if net.ParseIP(filter.FilterIP) == nil {
+			return nil, rest_err.NewBadRequestError("IP Address tidak valid")
+		}
+		filter.FilterName = ""
+	}
+
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+	filter.FilterSubCategory = strings.ToUpper(filter.FilterSubCategory)
+	lang = exportLanguage(filter.FilterBranch, lang)
+
+	subCategories := masterdata.Values(masterkind.SubCategory)
+	if filter.FilterSubCategory != "" {
+		subCategories = strings.Split(filter.FilterSubCategory, ",")
+	}
+
+	extras, err := e.unitExtras(ctx, filter.FilterBranch, subCategories, filter.FilterDisable)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := e.lastHistories(ctx, filter.FilterBranch, strings.Join(subCategories, ","))
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := e.customFields(ctx, subCategories)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w tabular.Writer) rest_err.APIError {
+		if err := w.Write(exportHeader(lang, exportOtherColumns, exportLifecycleColumns, exportCustomColumns(customFields), exportExtraColumns, exportHistoryColumns)); err != nil {
+			return rest_err.NewInternalServerError("Gagal menulis header export", err)
+		}
+		return e.daoO.IterateOther(ctx, filter, func(other dto.Other) error {
+			id := other.ID.Hex()
+			record := []string{
+				other.Name,
+				other.SubCategory,
+				other.Detail,
+				other.Division,
+				other.IP,
+				other.InventoryNumber,
+				other.Location,
+				other.LocationLat,
+				other.LocationLon,
+				other.Brand,
+				other.Type,
+				exportDate(other.Date),
+				strings.Join(other.Tag, ","),
+				exportBool(lang, other.DisVendor),
+				exportBool(lang, other.Disable),
+				other.Note,
+			}
+			record = append(record, exportLifecycleRecord(other.Lifecycle)...)
+			record = append(record, exportCustomRecord(lang, customFields, other.CustomFields)...)
+			record = append(record, exportExtraRecord(extras, id)...)
+			record = append(record, exportHistoryRecord(histories, id)...)
+			return w.Write(record)
+		})
+	}, nil
+}
+
+// ExportStock stock tidak terdaftar di gen_unit sehingga hanya history terakhir yang digabungkan
+func (e *exportService) ExportStock(ctx context.Context, filter dto.FilterBranchNameCatDisable, lang string) (ExportStreamer, rest_err.APIError) {
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+	lang = exportLanguage(filter.FilterBranch, lang)
+
+	histories, err := e.lastHistories(ctx, filter.FilterBranch, category.Stock)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w tabular.Writer) rest_err.APIError {
+		if err := w.Write(exportHeader(lang, exportStockColumns, exportHistoryColumns)); err != nil {
+			return rest_err.NewInternalServerError("Gagal menulis header export", err)
+		}
+		return e.daoS.IterateStock(ctx, filter, func(stock dto.Stock) error {
+			record := []string{
+				stock.Name,
+				stock.StockCategory,
+				stock.Unit,
+				strconv.Itoa(stock.Qty),
+				strconv.Itoa(stock.Threshold),
+				stock.Location,
+				strings.Join(stock.Tag, ","),
+				exportBool(lang, stock.Disable),
+				stock.Note,
+			}
+			record = append(record, exportHistoryRecord(histories, stock.ID.Hex())...)
+			return w.Write(record)
+		})
+	}, nil
+}
+
+// unitExtras memuat gen_unit untuk setiap kategori dengan key ID unit
+func (e *exportService) unitExtras(ctx context.Context, branch string, categories []string, disable bool) (map[string]dto.GenUnitResponse, rest_err.APIError) {
+	extras := make(map[string]dto.GenUnitResponse)
+	for _, cat := range categories {
+		units, _, err := e.daoG.FindUnit(ctx, dto.GenUnitFilter{
+			Branch:   branch,
+			Category: cat,
+			Disable:  disable,
+		}, dto.PageRequest{All: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range units {
+			extras[unit.ID] = unit
+		}
+	}
+	return extras, nil
+}
+
+// lastHistories memuat history terakhir setiap unit dengan key parent_id
+func (e *exportService) lastHistories(ctx context.Context, branch string, categories string) (map[string]dto.HistoryResponseMin, rest_err.APIError) {
+	histories, err := e.daoH.FindLastHistoryPerParent(ctx, dto.FilterBranchCategory{
+		FilterBranch:   branch,
+		FilterCategory: categories,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]dto.HistoryResponseMin, len(histories))
+	for _, history := range histories {
+		result[history.ParentID] = history
+	}
+	return result, nil
+}
+
+// customFields menggabungkan custom field seluruh kategori, key yang sama pada beberapa sub category
+// hanya menjadi satu kolom
+func (e *exportService) customFields(ctx context.Context, categories []string) ([]dto.CustomField, rest_err.APIError) {
+	var fields []dto.CustomField
+	seen := make(map[string]bool)
+	for _, cat := range categories {
+		catFields, err := e.servCf.GetFields(ctx, cat)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range catFields {
+			if seen[field.Key] {
+				continue
+			}
+			seen[field.Key] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// exportLanguage memakai lang jika valid, jika tidak mengikuti lang cabang pada master data BRANCH
+func exportLanguage(branch string, lang string) string {
+	lang = strings.ToLower(lang)
+	if lang == ExportLangID || lang == ExportLangEN {
+		return lang
+	}
+	if branchLang := masterdata.BranchLang(strings.ToUpper(branch)); branchLang != "" {
+		return branchLang
+	}
+	return ExportLangEN
+}
+
+func exportHeader(lang string, groups ...[]exportColumn) []string {
+	var header []string
+	for _, columns := range groups {
+		for _, column := range columns {
+			if lang == ExportLangID {
+				header = append(header, column.id)
+			} else {
+				header = append(header, column.en)
+			}
+		}
+	}
+	return header
+}
+
+func exportExtraRecord(extras map[string]dto.GenUnitResponse, id string) []string {
+	unit, ok := extras[id]
+	if !ok {
+		return make([]string, len(exportExtraColumns))
+	}
+	return []string{strconv.Itoa(unit.CasesSize), unit.LastPing}
+}
+
+func exportHistoryRecord(histories map[string]dto.HistoryResponseMin, id string) []string {
+	history, ok := histories[id]
+	if !ok {
+		return make([]string, len(exportHistoryColumns))
+	}
+	updatedAt, _ := timegen.GetTimeWithYearWITA(history.UpdatedAt)
+	return []string{
+		updatedAt,
+		history.Status,
+		history.Problem,
+		history.ProblemResolve,
+		enum.GetProgressString(history.CompleteStatus),
+	}
+}
+
+// exportCustomColumns header custom_<key> untuk semua bahasa agar dapat dibaca kembali oleh import
+func exportCustomColumns(fields []dto.CustomField) []exportColumn {
+	columns := make([]exportColumn, len(fields))
+	for i, field := range fields {
+		columns[i] = exportColumn{id: importCustomPrefix + field.Key, en: importCustomPrefix + field.Key}
+	}
+	return columns
+}
+
+func exportCustomRecord(lang string, fields []dto.CustomField, values dto.CustomFields) []string {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		value, ok := values[field.Key]
+		if !ok || value == nil {
+			continue
+		}
+		switch field.Type {
+		case fieldtype.Number:
+			record[i] = strconv.FormatFloat(cast.ToFloat64(value), 'f', -1, 64)
+		case fieldtype.Bool:
+			record[i] = exportBool(lang, cast.ToBool(value))
+		case fieldtype.Date:
+			record[i] = exportDate(cast.ToInt64(value))
+		default:
+			record[i] = cast.ToString(value)
+		}
+	}
+	return record
+}
+
+func exportLifecycleRecord(lifecycle dto.Lifecycle) []string {
+	var cost string
+	if lifecycle.Cost != 0 {
+		cost = strconv.FormatInt(lifecycle.Cost, 10)
+	}
+	return []string{
+		exportDate(lifecycle.PurchaseDate),
+		exportDate(lifecycle.WarrantyExpiry),
+		exportDate(lifecycle.EndOfLife),
+		lifecycle.Vendor,
+		cost,
+	}
+}
+
+// exportDate format YYYY-MM-DD agar dapat dibaca kembali oleh import
+func exportDate(timestamp int64) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+}
+
+func exportBool(lang string, value bool) string {
+	switch {
+	case lang == ExportLangID && value:
+		return "ya"
+	case lang == ExportLangID:
+		return "tidak"
+	case value:
+		return "yes"
+	default:
+		return "no"
+	}
+}
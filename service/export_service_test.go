@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportLanguage(t *testing.T) {
+	assert.Equal(t, ExportLangID, exportLanguage("banjarmasin", ""))
+	assert.Equal(t, ExportLangEN, exportLanguage("BANJARMASIN", "EN"))
+	assert.Equal(t, ExportLangEN, exportLanguage("", ""))
+	assert.Equal(t, ExportLangEN, exportLanguage("OTHER", "fr"))
+}
+
+func TestExportHeaderMatchesRecord(t *testing.T) {
+	header := exportHeader(ExportLangEN, exportStockColumns, exportHistoryColumns)
+	assert.Equal(t, "Name", header[0])
+	assert.Len(t, header, len(exportStockColumns)+len(exportHistoryColumns))
+
+	histories := map[string]dto.HistoryResponseMin{
+		"a1": {ParentID: "a1", Status: "Progress", Problem: "kabel putus", CompleteStatus: 1},
+	}
+	assert.Equal(t, "kabel putus", exportHistoryRecord(histories, "a1")[2])
+	assert.Len(t, exportHistoryRecord(histories, "b2"), len(exportHistoryColumns))
+	assert.Len(t, exportExtraRecord(map[string]dto.GenUnitResponse{}, "a1"), len(exportExtraColumns))
+}
+
+func TestExportDateImportable(t *testing.T) {
+	date := exportDate(1609459200)
+	assert.Equal(t, "2021-01-01", date)
+	parsed, err := importDate(date)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1609459200), parsed)
+}
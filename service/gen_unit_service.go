@@ -5,54 +5,61 @@ import (
 	"fmt"
 	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
-	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/enum"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
-	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dao/pinghistorydao"
 	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
 	"net"
+	"sort"
 	"strings"
+	"time"
 )
 
+// maxUplinkDepth batas kedalaman rantai uplink yang ditelusuri
+const maxUplinkDepth = 16
+
 func NewGenUnitService(
 	dao genunitdao.GenUnitDaoAssumer,
-	daoU userdao.UserDaoAssumer,
-	fcmClient fcm.ClientAssumer) GenUnitServiceAssumer {
+	daoP pinghistorydao.PingHistorySaver,
+	alertServ AlertServiceAssumer) GenUnitServiceAssumer {
 	return &genUnitService{
 		dao:       dao,
-		daoU:      daoU,
-		fcmClient: fcmClient,
+		daoP:      daoP,
+		alertServ: alertServ,
 	}
 }
 
 type genUnitService struct {
 	dao       genunitdao.GenUnitDaoAssumer
-	daoU      userdao.UserDaoAssumer
-	fcmClient fcm.ClientAssumer
+	daoP      pinghistorydao.PingHistorySaver
+	alertServ AlertServiceAssumer
 }
 
 type GenUnitServiceAssumer interface {
-	FindUnit(ctx context.Context, filter dto.GenUnitFilter) (dto.GenUnitResponseList, rest_err.APIError)
+	FindUnit(ctx context.Context, filter dto.GenUnitFilter, page dto.PageRequest) (dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError)
 	GetIPList(ctx context.Context, branchIfSpecific string, category string) ([]string, rest_err.APIError)
 	AppendPingState(ctx context.Context, input dto.GenUnitPingStateRequest) (int64, rest_err.APIError)
-	CheckHardwareDownAndSendNotif(ctx context.Context, branchIfSpecific string, category string) rest_err.APIError
+	SetUplink(ctx context.Context, user mjwt.CustomClaim, unitID string, uplinkID string) (*dto.GenUnitResponse, rest_err.APIError)
+	Topology(ctx context.Context, branch string) ([]dto.TopologyNode, rest_err.APIError)
 }
 
-func (g *genUnitService) FindUnit(ctx context.Context, filter dto.GenUnitFilter) (dto.GenUnitResponseList, rest_err.APIError) {
+func (g *genUnitService) FindUnit(ctx context.Context, filter dto.GenUnitFilter, page dto.PageRequest) (dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
 	// cek apakah ip address valid, jika valid maka set filter.FilterName ke kosong supaya pencarian berdasarkan FilterIP
 	if filter.IP != "" {
 		if net.ParseIP(filter.IP) == nil {
-			return nil, rest_err.NewBadRequestError("IP Address tidak valid")
+			return nil, dto.PageInfo{}, rest_err.NewBadRequestError("IP Address tidak valid")
 		}
 		filter.Name = ""
 	}
 
 	// DB
-	unitList, err := g.dao.FindUnit(ctx, filter)
+	unitList, pageInfo, err := g.dao.FindUnit(ctx, filter, page)
 	if err != nil {
-		return nil, err
+		return nil, dto.PageInfo{}, err
 	}
-	return unitList, nil
+	return unitList, pageInfo, nil
 }
 
 func (g *genUnitService) GetIPList(ctx context.Context, branchIfSpecific string, category string) ([]string, rest_err.APIError) {
@@ -62,88 +69,171 @@ func (g *genUnitService) GetIPList(ctx context.Context, branchIfSpecific string,
 		return nil, err
 	}
 
+	// ip ganda hanya di ping sekali, konflik dicatat agar tidak tersembunyi. detail ada pada laporan data quality
+	if duplicates := duplicateValues(ipAddressList); len(duplicates) != 0 {
+		logger.Info(fmt.Sprintf("ip ganda pada %s %s : %s", branchIfSpecific, category, strings.Join(duplicates, ", ")))
+	}
+
 	uniqueIPList := sfunc.Unique(ipAddressList)
 	return uniqueIPList, nil
 }
 
-// CheckHardwareDownAndSendNotif mengecek perangkat yang mati dan belum pernah dilakukan pengeckean
-// lalu mengirimkan notif ke semua user terkait
-// sudah dilengkapi dengan logger
-func (g *genUnitService) CheckHardwareDownAndSendNotif(ctx context.Context, branchIfSpecific string, category string) rest_err.APIError {
-	// DB
-	unitList, err := g.dao.FindUnit(ctx, dto.GenUnitFilter{
-		Branch:   branchIfSpecific,
-		Category: category,
-		Pings:    true,
-		LastPing: "DOWN",
-	})
-	if err != nil {
-		logger.Error("mendapatkan unit gagal saat menambahkan fcm (CheckHardwareDownAndSendNotif)", err)
-		return err
+// duplicateValues mengembalikan nilai yang muncul lebih dari sekali sesuai urutan kemunculan pertama
+func duplicateValues(values []string) []string {
+	count := make(map[string]int, len(values))
+	var duplicates []string
+	for _, value := range values {
+		count[value]++
+		if count[value] == 2 {
+			duplicates = append(duplicates, value)
+		}
 	}
+	return duplicates
+}
 
-	// filtering
-	filterUncheckedUnitList(&unitList)
-
-	// jika tidak ada unitnya, continue
-	if len(unitList) == 0 {
-		return nil
+// AppendPingState menambahkan ping ke rolling pings_state gen_unit
+// dan menyimpan setiap sample ke ping history untuk perhitungan uptime.
+// setelah itu state alert unit pada branch dan category tersebut dievaluasi ulang
+func (g *genUnitService) AppendPingState(ctx context.Context, input dto.GenUnitPingStateRequest) (int64, rest_err.APIError) {
+	// DB
+	unitUpdatedCount, err := g.dao.AppendPingState(ctx, input)
+	if err != nil {
+		return 0, err
 	}
 
-	users, err := g.daoU.FindUser(ctx, branchIfSpecific)
+	// kegagalan menyimpan history tidak menggagalkan update ping state
+	units, err := g.dao.FindUnitByIP(ctx, input.Branch, input.Category, input.IPAddresses)
 	if err != nil {
-		logger.Error("mendapatkan user gagal saat menambahkan fcm (CheckHardwareDownAndSendNotif)", err)
-		return err
+		logger.Error("mendapatkan unit gagal saat menyimpan ping history (AppendPingState)", err)
+		return unitUpdatedCount, nil
 	}
-	var tokens []string
-	for _, u := range users {
-		tokens = append(tokens, u.FcmToken)
+
+	timeNow := time.Now()
+	samples := make([]dto.PingSample, len(units))
+	for i, unit := range units {
+		samples[i] = dto.PingSample{
+			Time: timeNow,
+			Meta: dto.PingMeta{
+				UnitID:   unit.ID,
+				Branch:   unit.Branch,
+				Category: unit.Category,
+			},
+			Code:   input.PingCode,
+			Status: enum.GetPingString(input.PingCode),
+		}
 	}
+	_ = g.daoP.InsertSamples(ctx, samples)
 
-	var allUnitBuilder strings.Builder
-	for _, unit := range unitList {
-		allUnitBuilder.WriteString(unit.Name + ", ")
+	go func() {
+		_ = g.alertServ.EvaluateBranch(context.Background(), input.Branch, input.Category)
+	}()
+
+	return unitUpdatedCount, nil
+}
+
+// SetUplink menghubungkan unit ke unit induknya pada branch yang sama, uplinkID kosong melepas hubungan.
+// hubungan yang membuat siklus ditolak
+func (g *genUnitService) SetUplink(ctx context.Context, user mjwt.CustomClaim, unitID string, uplinkID string) (*dto.GenUnitResponse, rest_err.APIError) {
+	if uplinkID != "" {
+		if unitID == uplinkID {
+			return nil, rest_err.NewBadRequestError("Unit tidak dapat menjadi uplink bagi dirinya sendiri")
+		}
+
+		units, _, err := g.dao.FindUnit(ctx, dto.GenUnitFilter{Branch: user.Branch}, dto.PageRequest{All: true})
+		if err != nil {
+			return nil, err
+		}
+		uplinks := make(map[string]string, len(units))
+		for _, unit := range units {
+			uplinks[unit.ID] = unit.Uplink
+		}
+		if _, ok := uplinks[uplinkID]; !ok {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("Uplink %s tidak ditemukan pada branch %s", uplinkID, user.Branch))
+		}
+		if err := validateUplinkChain(uplinks, unitID, uplinkID); err != nil {
+			return nil, err
+		}
 	}
-	allUnitString := strings.TrimRight(allUnitBuilder.String(), ", ")
 
-	// firebase
-	g.fcmClient.SendMessage(fcm.Payload{
-		Title:          fmt.Sprintf("%d unit perlu dicek", len(unitList)),
-		Message:        fmt.Sprint(allUnitString),
-		ReceiverTokens: tokens,
+	// DB
+	unit, err := g.dao.ChangeUplink(ctx, dto.GenUnitUplinkEdit{
+		UnitID:       unitID,
+		FilterBranch: user.Branch,
+		Uplink:       uplinkID,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
 
-	return err
+// Topology menyusun unit aktif branch menjadi pohon berdasarkan uplink,
+// unit tanpa uplink atau dengan uplink yang tidak ditemukan menjadi akar
+func (g *genUnitService) Topology(ctx context.Context, branch string) ([]dto.TopologyNode, rest_err.APIError) {
+	units, _, err := g.dao.FindUnit(ctx, dto.GenUnitFilter{Branch: strings.ToUpper(branch)}, dto.PageRequest{All: true})
+	if err != nil {
+		return nil, err
+	}
+	return buildTopology(units), nil
 }
 
-// hanya sisakan unit yang tidak memiliki case dan sedang down total.
-func filterUncheckedUnitList(data *dto.GenUnitResponseList) {
-	temp := dto.GenUnitResponseList{}
-	for _, gen := range *data {
-		if len(gen.PingsState) == 0 {
-			continue
+// validateUplinkChain menelusuri rantai uplink mulai dari uplinkID, ditolak jika unitID ditemukan (siklus)
+// atau jika rantai lebih dalam dari maxUplinkDepth
+func validateUplinkChain(uplinks map[string]string, unitID string, uplinkID string) rest_err.APIError {
+	current := uplinkID
+	for depth := 0; current != ""; depth++ {
+		if current == unitID {
+			return rest_err.NewBadRequestError("Uplink tidak valid karena membentuk siklus")
 		}
-		var totalPing int
-		for _, ping := range gen.PingsState {
-			totalPing = +ping.Code
+		if depth > maxUplinkDepth {
+			return rest_err.NewBadRequestError(fmt.Sprintf("Uplink tidak valid karena rantai uplink terlalu dalam, maksimal %d tingkat", maxUplinkDepth))
 		}
-		// cek casenya harus 0
-		isCaseZero := gen.CasesSize == 0
-		isTotalPingZero := totalPing == 0
+		current = uplinks[current]
+	}
+	return nil
+}
 
-		if isCaseZero && isTotalPingZero {
-			temp = append(temp, gen)
+func buildTopology(units dto.GenUnitResponseList) []dto.TopologyNode {
+	exist := make(map[string]bool, len(units))
+	for _, unit := range units {
+		exist[unit.ID] = true
+	}
+	children := make(map[string][]dto.GenUnitResponse)
+	var roots []dto.GenUnitResponse
+	for _, unit := range units {
+		if unit.Uplink == "" || !exist[unit.Uplink] {
+			roots = append(roots, unit)
+			continue
 		}
+		children[unit.Uplink] = append(children[unit.Uplink], unit)
 	}
-	*data = temp
-}
 
-func (g *genUnitService) AppendPingState(ctx context.Context, input dto.GenUnitPingStateRequest) (int64, rest_err.APIError) {
-	// DB
-	unitUpdatedCount, err := g.dao.AppendPingState(ctx, input)
-	if err != nil {
-		return 0, err
+	var build func(unit dto.GenUnitResponse, depth int) dto.TopologyNode
+	build = func(unit dto.GenUnitResponse, depth int) dto.TopologyNode {
+		node := dto.TopologyNode{
+			ID:         unit.ID,
+			Category:   unit.Category,
+			Name:       unit.Name,
+			IP:         unit.IP,
+			LastPing:   unit.LastPing,
+			AlertState: unit.AlertState,
+			Children:   []dto.TopologyNode{},
+		}
+		if depth < maxUplinkDepth {
+			for _, child := range children[unit.ID] {
+				node.Children = append(node.Children, build(child, depth+1))
+			}
+		}
+		return node
 	}
 
-	return unitUpdatedCount, nil
+	nodes := []dto.TopologyNode{}
+	for _, root := range roots {
+		nodes = append(nodes, build(root, 0))
+	}
+	// unit dengan banyak turunan ditampilkan terlebih dahulu
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return len(nodes[i].Children) > len(nodes[j].Children)
+	})
+	return nodes
 }
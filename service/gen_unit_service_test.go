@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUplinkChain(t *testing.T) {
+	uplinks := map[string]string{
+		"switch1": "",
+		"switch2": "switch1",
+		"cctv1":   "switch2",
+	}
+
+	assert.Nil(t, validateUplinkChain(uplinks, "cctv2", "switch2"))
+
+	err := validateUplinkChain(uplinks, "switch1", "cctv1")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Message(), "siklus")
+
+	err = validateUplinkChain(uplinks, "switch2", "cctv1")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Message(), "siklus")
+}
+
+func TestValidateUplinkChainTooDeep(t *testing.T) {
+	uplinks := make(map[string]string)
+	for i := 1; i <= maxUplinkDepth+1; i++ {
+		uplinks[fmt.Sprintf("switch%d", i)] = fmt.Sprintf("switch%d", i+1)
+	}
+
+	err := validateUplinkChain(uplinks, "cctv1", "switch1")
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+	assert.Contains(t, err.Message(), "terlalu dalam")
+	assert.NotContains(t, err.Message(), "siklus")
+
+	// rantai tepat pada batas masih diterima
+	assert.Nil(t, validateUplinkChain(uplinks, "cctv1", "switch2"))
+}
+
+func TestBuildTopology(t *testing.T) {
+	units := dto.GenUnitResponseList{
+		{ID: "cctv1", Uplink: "switch1"},
+		{ID: "cctv2", Uplink: "switch1"},
+		{ID: "pc1"},
+		{ID: "switch1"},
+		// induk sudah dihapus
+		{ID: "cctv3", Uplink: "deleted"},
+	}
+
+	nodes := buildTopology(units)
+	assert.Len(t, nodes, 3)
+	assert.Equal(t, "switch1", nodes[0].ID)
+	assert.Len(t, nodes[0].Children, 2)
+	assert.Equal(t, "cctv1", nodes[0].Children[0].ID)
+	assert.NotNil(t, nodes[1].Children)
+}
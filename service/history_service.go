@@ -14,9 +14,13 @@ import (
 	"github.com/muchlist/risa_restfull/clients/fcm"
 	"github.com/muchlist/risa_restfull/constants/category"
 	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/priority"
 	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/dao/commentdao"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/sladao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
 	"github.com/muchlist/risa_restfull/dao/userdao"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
@@ -28,12 +32,20 @@ func NewHistoryService(
 	histDao historydao.HistoryDaoAssumer,
 	genDao genunitdao.GenUnitDaoAssumer,
 	userDao userdao.UserDaoAssumer,
-	fcmClient fcm.ClientAssumer) HistoryServiceAssumer {
+	fcmClient fcm.ClientAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	slaDao sladao.SLALoader,
+	commentDao commentdao.CommentLoader,
+	searchIndexer SearchIndexer) HistoryServiceAssumer {
 	return &historyService{
 		daoH:      histDao,
 		daoG:      genDao,
 		daoU:      userDao,
+		daoT:      txDao,
+		daoS:      slaDao,
+		daoC:      commentDao,
 		fcmClient: fcmClient,
+		servSi:    searchIndexer,
 	}
 }
 
@@ -41,7 +53,11 @@ type historyService struct {
 	daoH      historydao.HistoryDaoAssumer
 	daoG      genunitdao.GenUnitDaoAssumer
 	daoU      userdao.UserDaoAssumer
+	daoT      transactiondao.TransactionDaoAssumer
+	daoS      sladao.SLALoader
+	daoC      commentdao.CommentLoader
 	fcmClient fcm.ClientAssumer
+	servSi    SearchIndexer
 }
 type HistoryServiceAssumer interface {
 	InsertHistory(ctx context.Context, user mjwt.CustomClaim, input dto.HistoryRequest) (*string, rest_err.APIError)
@@ -50,12 +66,18 @@ type HistoryServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.HistoryResponse, rest_err.APIError)
 
 	GetHistory(ctx context.Context, parentID string, branchIfSpecific string) (*dto.HistoryResponse, rest_err.APIError)
-	FindHistory(ctx context.Context, search string, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit) (dto.HistoryResponseMinList, rest_err.APIError)
+	FindHistory(ctx context.Context, search string, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.HistoryResponseMinList, dto.PageInfo, rest_err.APIError)
 	FindHistoryForHome(ctx context.Context, filterA dto.FilterBranchCatComplete) (dto.HistoryResponseMinList, rest_err.APIError)
 	UnwindHistory(ctx context.Context, filterA dto.FilterBranchCatInCompleteIn, filterB dto.FilterTimeRangeLimit) (dto.HistoryUnwindResponseList, rest_err.APIError)
 	FindHistoryForParent(ctx context.Context, parentID string) (dto.HistoryResponseMinList, rest_err.APIError)
 	FindHistoryForUser(ctx context.Context, userID string, filter dto.FilterTimeRangeLimit) (dto.HistoryResponseMinList, rest_err.APIError)
 	GetHistoryCount(ctx context.Context, branchIfSpecific string, statusComplete int) (dto.HistoryCountList, rest_err.APIError)
+
+	AppendUpdate(ctx context.Context, historyID string, input dto.HistoryUpdate) (*dto.HistoryResponse, rest_err.APIError)
+	AssignHistory(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.HistoryAssignRequest) (*dto.HistoryResponse, rest_err.APIError)
+	SetWatchers(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.HistoryWatcherRequest) (*dto.HistoryResponse, rest_err.APIError)
+	FindMyOpenHistory(ctx context.Context, user mjwt.CustomClaim) (dto.HistoryResponseMinList, rest_err.APIError)
+	GetWorkload(ctx context.Context, branch string) (*dto.WorkloadSummary, rest_err.APIError)
 }
 
 func (h *historyService) InsertHistory(ctx context.Context, user mjwt.CustomClaim, input dto.HistoryRequest) (*string, rest_err.APIError) {
@@ -64,6 +86,9 @@ func (h *historyService) InsertHistory(ctx context.Context, user mjwt.CustomClai
 	if input.DateStart == 0 {
 		input.DateStart = timeNow
 	}
+	if input.Priority == "" {
+		input.Priority = priority.Default
+	}
 
 	// jika ID tersedia, gunakan ID , jika tidak buatkan objectID
 	// memastikan ID yang diinputkan bisa diubah ke ObjectID
@@ -90,62 +115,81 @@ func (h *historyService) InsertHistory(ctx context.Context, user mjwt.CustomClai
 	// Mengambil gen_unit
 	// Tambahkan Case jika History status bukan Complete atau bukan info, akan gagal jika ID dan Cabang tidak sesuai
 	// jika complete gunakan GetUnitByID untuk memastikan ID dan Cabang sesuai
-	var parent *dto.GenUnitResponse
-	var err rest_err.APIError
 	historyIsComplete := input.CompleteStatus == enum.HComplete
 	historyIsInfo := input.CompleteStatus == enum.HInfo
 	historyIsDataInfo := input.CompleteStatus == enum.HDataInfo
-	if !(historyIsComplete || historyIsInfo || historyIsDataInfo) {
-		// DB
-		parent, err = h.daoG.InsertCase(ctx, dto.GenUnitCaseRequest{
-			UnitID:       input.ParentID,
-			FilterBranch: user.Branch,
-			CaseID:       generatedID.Hex(), // gunakan History id sebagai caseID
-			CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(input.CompleteStatus), input.Status, input.Problem),
-		})
-	} else {
-		// DB
-		parent, err = h.daoG.GetUnitByID(ctx, input.ParentID, user.Branch)
-	}
-	if err != nil {
-		// menggabungkan err dari case insert dengan diawali pesan error tambahan
-		combineErr := rest_err.NewBadRequestError(
-			fmt.Sprintf("-> %s -> %s", "id unit atau cabang tidak sesuai", err.Message()),
-		)
-		return nil, combineErr
-	}
 
-	// Filling data
-	data := dto.History{
-		ID:             generatedID,
-		CreatedAt:      timeNow,
-		CreatedBy:      user.Name,
-		CreatedByID:    user.Identity,
-		UpdatedAt:      timeNow,
-		UpdatedBy:      user.Name,
-		UpdatedByID:    user.Identity,
-		Category:       parent.Category,
-		Branch:         user.Branch,
-		ParentID:       input.ParentID,
-		ParentName:     parent.Name,
-		Status:         input.Status,
-		Problem:        input.Problem,
-		ProblemResolve: input.ProblemResolve,
-		CompleteStatus: input.CompleteStatus,
-		DateStart:      input.DateStart,
-		DateEnd:        input.DateEnd,
-		Tag:            input.Tag,
-		Image:          input.Image,
-	}
+	slaPolicies := h.slaPolicies(ctx)
 
-	isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
+	// case pada gen_unit dan history ditulis dalam satu transaction agar tidak saling tertinggal
+	var parent *dto.GenUnitResponse
+	var data dto.History
+	var insertedID *string
+	err := h.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		var err rest_err.APIError
+		if !(historyIsComplete || historyIsInfo || historyIsDataInfo) {
+			// DB
+			parent, err = h.daoG.InsertCase(txCtx, dto.GenUnitCaseRequest{
+				UnitID:       input.ParentID,
+				FilterBranch: user.Branch,
+				CaseID:       generatedID.Hex(), // gunakan History id sebagai caseID
+				CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(input.CompleteStatus), input.Status, input.Problem),
+			})
+		} else {
+			// DB
+			parent, err = h.daoG.GetUnitByID(txCtx, input.ParentID, user.Branch)
+		}
+		if err != nil {
+			// menggabungkan err dari case insert dengan diawali pesan error tambahan
+			combineErr := rest_err.NewBadRequestError(
+				fmt.Sprintf("-> %s -> %s", "id unit atau cabang tidak sesuai", err.Message()),
+			)
+			return combineErr
+		}
+
+		// Filling data
+		data = dto.History{
+			ID:             generatedID,
+			CreatedAt:      timeNow,
+			CreatedBy:      user.Name,
+			CreatedByID:    user.Identity,
+			UpdatedAt:      timeNow,
+			UpdatedBy:      user.Name,
+			UpdatedByID:    user.Identity,
+			Category:       parent.Category,
+			Branch:         user.Branch,
+			ParentID:       input.ParentID,
+			ParentName:     parent.Name,
+			Status:         input.Status,
+			Problem:        input.Problem,
+			ProblemResolve: input.ProblemResolve,
+			CompleteStatus: input.CompleteStatus,
+			Priority:       input.Priority,
+			DateStart:      input.DateStart,
+			DateEnd:        input.DateEnd,
+			Tag:            input.Tag,
+			Image:          input.Image,
+		}
+		data.SLA = evaluateSLA(slaSubject{
+			DateStart:      data.DateStart,
+			DateEnd:        data.DateEnd,
+			CompleteStatus: data.CompleteStatus,
+			Updates:        []dto.HistoryUpdate{{Time: timeNow, CompleteStatus: data.CompleteStatus}},
+		}, pickSLAPolicy(slaPolicies, data.Category, data.Priority), timeNow)
+
+		isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
 
-	// DB
-	insertedID, err := h.daoH.InsertHistory(ctx, data, isVendor)
+		// DB
+		insertedID, err = h.daoH.InsertHistory(txCtx, data, isVendor)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	h.servSi.IndexHistory(ctx, generatedID.Hex())
+	h.servSi.IndexUnit(ctx, data.ParentID)
+
 	// goroutine notifikasi
 	go func() {
 
@@ -206,6 +250,7 @@ func (h *historyService) EditHistory(ctx context.Context, user mjwt.CustomClaim,
 		Problem:         input.Problem,
 		ProblemResolve:  input.ProblemResolve,
 		CompleteStatus:  input.CompleteStatus,
+		Priority:        input.Priority,
 		DateEnd:         input.DateEnd,
 		Tag:             input.Tag,
 		UpdatedAt:       timeNow,
@@ -215,40 +260,54 @@ func (h *historyService) EditHistory(ctx context.Context, user mjwt.CustomClaim,
 
 	isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
 
-	// DB
-	historyEdited, err := h.daoH.EditHistory(ctx, oid, data, isVendor)
-	if err != nil {
-		return nil, err
-	}
-
-	// Hapus Case pada parent
-	// DB
-	_, err = h.daoG.DeleteCase(ctx, dto.GenUnitCaseRequest{
-		UnitID:       historyEdited.ParentID,
-		FilterBranch: user.Branch,
-		CaseID:       historyID,
-		CaseNote:     "",
-	})
-	if err != nil {
-		return nil, err
-	}
+	var historyEdited *dto.HistoryResponse
+	err := h.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		var err rest_err.APIError
+		// DB
+		historyEdited, err = h.daoH.EditHistory(txCtx, oid, data, isVendor)
+		if err != nil {
+			return err
+		}
 
-	// jika complete_status tidak complete atau tidak info maka perlu ditambahkan lagi case baru
-	historyIsComplete := input.CompleteStatus == enum.HComplete
-	historyIsInfo := input.CompleteStatus == enum.HInfo
-	if !(historyIsComplete || historyIsInfo) {
+		// Hapus Case pada parent
 		// DB
-		_, err = h.daoG.InsertCase(ctx, dto.GenUnitCaseRequest{
+		_, err = h.daoG.DeleteCase(txCtx, dto.GenUnitCaseRequest{
 			UnitID:       historyEdited.ParentID,
 			FilterBranch: user.Branch,
-			CaseID:       historyID, // gunakan History id sebagai caseID
-			CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(input.CompleteStatus), input.Status, input.Problem),
+			CaseID:       historyID,
+			CaseNote:     "",
 		})
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		// jika complete_status tidak complete atau tidak info maka perlu ditambahkan lagi case baru
+		historyIsComplete := input.CompleteStatus == enum.HComplete
+		historyIsInfo := input.CompleteStatus == enum.HInfo
+		if !(historyIsComplete || historyIsInfo) {
+			// DB
+			_, err = h.daoG.InsertCase(txCtx, dto.GenUnitCaseRequest{
+				UnitID:       historyEdited.ParentID,
+				FilterBranch: user.Branch,
+				CaseID:       historyID, // gunakan History id sebagai caseID
+				CaseNote:     fmt.Sprintf("#%s# %s : %s", enum.GetProgressString(input.CompleteStatus), input.Status, input.Problem),
+			})
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	// status SLA dihitung ulang karena respon, status dan prioritas bisa berubah
+	h.storeSLA(ctx, historyEdited, timeNow)
+
+	h.servSi.IndexHistory(ctx, historyID)
+	h.servSi.IndexUnit(ctx, historyEdited.ParentID)
+
 	go func() {
 		users, err := h.daoU.FindUser(ctx, user.Branch)
 		if err != nil {
@@ -296,30 +355,40 @@ func (h *historyService) DeleteHistory(ctx context.Context, user mjwt.CustomClai
 		timeMinusOneDay = 0
 	}
 
-	// DB
-	history, err := h.daoH.DeleteHistory(ctx, dto.FilterIDBranchCreateGte{
-		FilterID:        oid,
-		FilterBranch:    user.Branch,
-		FilterCreateGTE: timeMinusOneDay,
-	})
-	if err != nil {
-		return err
-	}
-
-	// Jika History yang dihapus tidak complete, berarti harus dihapus di parentnya karena masih ada sebagai case
-	if history.CompleteStatus != enum.HComplete {
+	var parentID string
+	err := h.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
 		// DB
-		_, err = h.daoG.DeleteCase(ctx, dto.GenUnitCaseRequest{
-			UnitID:       history.ParentID,
-			FilterBranch: user.Branch,
-			CaseID:       id,
-			CaseNote:     "",
+		history, err := h.daoH.DeleteHistory(txCtx, dto.FilterIDBranchCreateGte{
+			FilterID:        oid,
+			FilterBranch:    user.Branch,
+			FilterCreateGTE: timeMinusOneDay,
 		})
 		if err != nil {
 			return err
 		}
+		parentID = history.ParentID
+
+		// Jika History yang dihapus tidak complete, berarti harus dihapus di parentnya karena masih ada sebagai case
+		if history.CompleteStatus != enum.HComplete {
+			// DB
+			_, err = h.daoG.DeleteCase(txCtx, dto.GenUnitCaseRequest{
+				UnitID:       history.ParentID,
+				FilterBranch: user.Branch,
+				CaseID:       id,
+				CaseNote:     "",
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	h.servSi.IndexHistory(ctx, id)
+	h.servSi.IndexUnit(ctx, parentID)
 	return nil
 }
 
@@ -333,25 +402,80 @@ func (h *historyService) GetHistory(ctx context.Context, parentID string, branch
 	if err != nil {
 		return nil, err
 	}
+
+	history.SLA = evaluateSLA(slaSubject{
+		DateStart:      history.DateStart,
+		DateEnd:        history.DateEnd,
+		CompleteStatus: history.CompleteStatus,
+		Updates:        history.Updates,
+		Current:        history.SLA,
+	}, pickSLAPolicy(h.slaPolicies(ctx), history.Category, history.Priority), time.Now().Unix())
 	return history, nil
 }
 
-func (h *historyService) FindHistory(ctx context.Context, search string, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit) (dto.HistoryResponseMinList, rest_err.APIError) {
-	historyList := dto.HistoryResponseMinList{}
-	var err rest_err.APIError
+// FindHistory mengembalikan daftar history per halaman.
+// pencarian text diurutkan berdasarkan skor relevansi sehingga tidak dapat memakai cursor :
+// hasilnya satu halaman dibatasi limit (default 200) tanpa next_cursor, cursor bersama search ditolak
+func (h *historyService) FindHistory(ctx context.Context, search string, filterA dto.FilterBranchCatComplete, filterB dto.FilterTimeRangeLimit, page dto.PageRequest) (dto.HistoryResponseMinList, dto.PageInfo, rest_err.APIError) {
 	if len(search) != 0 {
-		historyList, err = h.daoH.SearchHistory(ctx, search, filterA, filterB)
-		if err != nil {
-			return nil, err
+		if page.Cursor != "" {
+			return nil, dto.PageInfo{}, rest_err.NewBadRequestError("cursor tidak dapat digunakan bersama search, hasil pencarian hanya satu halaman")
 		}
-	} else {
-		historyList, err = h.daoH.FindHistory(ctx, filterA, filterB)
+		historyList, err := h.daoH.SearchHistory(ctx, search, filterA, filterB)
 		if err != nil {
-			return nil, err
+			return nil, dto.PageInfo{}, err
 		}
+		h.refreshSLA(ctx, historyList)
+		return historyList, dto.PageInfo{Size: int64(len(historyList)), Total: int64(len(historyList))}, nil
 	}
 
-	return historyList, nil
+	historyList, pageInfo, err := h.daoH.FindHistory(ctx, filterA, filterB, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	h.refreshSLA(ctx, historyList)
+	return historyList, pageInfo, nil
+}
+
+// refreshSLA menghitung SLA pada waktu sekarang, nilai tersimpan bisa tertinggal sampai job SLA-ESCALATION berikutnya
+func (h *historyService) refreshSLA(ctx context.Context, historyList dto.HistoryResponseMinList) {
+	if len(historyList) == 0 {
+		return
+	}
+	policies := h.slaPolicies(ctx)
+	timeNow := time.Now().Unix()
+	for i := range historyList {
+		historyList[i].SLA = evaluateSLA(slaSubjectFromMin(historyList[i]), pickSLAPolicy(policies, historyList[i].Category, historyList[i].Priority), timeNow)
+	}
+}
+
+// storeSLA menghitung ulang SLA history yang baru berubah dan menyimpannya jika berbeda,
+// kegagalan menyimpan sla diperbaiki oleh job SLA-ESCALATION
+func (h *historyService) storeSLA(ctx context.Context, history *dto.HistoryResponse, now int64) {
+	sla := evaluateSLA(slaSubject{
+		DateStart:      history.DateStart,
+		DateEnd:        history.DateEnd,
+		CompleteStatus: history.CompleteStatus,
+		Updates:        history.Updates,
+		Current:        history.SLA,
+	}, pickSLAPolicy(h.slaPolicies(ctx), history.Category, history.Priority), now)
+	if sla == history.SLA {
+		return
+	}
+	if err := h.daoH.SetSLA(ctx, history.ID, sla); err != nil {
+		logger.Error(fmt.Sprintf("Gagal menyimpan sla history %s (storeSLA)", history.ID.Hex()), err)
+	}
+	history.SLA = sla
+}
+
+// slaPolicies jika gagal membaca policy, SLA dihitung memakai target default
+func (h *historyService) slaPolicies(ctx context.Context) []dto.SLAPolicy {
+	policies, err := h.daoS.FindPolicy(ctx)
+	if err != nil {
+		logger.Error("Gagal mendapatkan sla policy (slaPolicies)", err)
+		return nil
+	}
+	return policies
 }
 
 // FindHistoryForHome get history complete + progress + pending + reqPending
@@ -370,15 +494,14 @@ func (h *historyService) FindHistoryForHome(ctx context.Context, filterA dto.Fil
 	getCompleteHistory := func() {
 		defer wg.Done()
 		// DB
-		historyListCompleteInfo, err := h.daoH.FindHistory(ctx,
+		historyListCompleteInfo, _, err := h.daoH.FindHistory(ctx,
 			dto.FilterBranchCatComplete{
 				FilterBranch:         filterA.FilterBranch,
 				FilterCategory:       filterA.FilterCategory,
 				FilterCompleteStatus: []int{enum.HComplete, enum.HInfo},
 			},
-			dto.FilterTimeRangeLimit{
-				Limit: 100,
-			},
+			dto.FilterTimeRangeLimit{},
+			dto.PageRequest{Size: 100},
 		)
 		resultChan <- result{
 			res: historyListCompleteInfo,
@@ -389,15 +512,14 @@ func (h *historyService) FindHistoryForHome(ctx context.Context, filterA dto.Fil
 	getProgressHistory := func() {
 		defer wg.Done()
 		// DB
-		historyListProgressPending, err := h.daoH.FindHistory(ctx,
+		historyListProgressPending, _, err := h.daoH.FindHistory(ctx,
 			dto.FilterBranchCatComplete{
 				FilterBranch:         filterA.FilterBranch,
 				FilterCategory:       filterA.FilterCategory,
 				FilterCompleteStatus: []int{enum.HProgress, enum.HRequestPending, enum.HPending, enum.HRequestComplete, enum.HCompleteWithBA},
 			},
-			dto.FilterTimeRangeLimit{
-				Limit: 200,
-			},
+			dto.FilterTimeRangeLimit{},
+			dto.PageRequest{Size: 200},
 		)
 		resultChan <- result{
 			res: historyListProgressPending,
@@ -436,8 +558,9 @@ func (h *historyService) FindHistoryForHome(ctx context.Context, filterA dto.Fil
 	return resultTemp, nil
 }
 
+// UnwindHistory komentar history ikut ditampilkan sebagai baris timeline
 func (h *historyService) UnwindHistory(ctx context.Context, filterA dto.FilterBranchCatInCompleteIn, filterB dto.FilterTimeRangeLimit) (dto.HistoryUnwindResponseList, rest_err.APIError) {
-	historyList, err := h.daoH.UnwindHistory(ctx, filterA, filterB)
+	historyList, err := unwindHistoryWithComment(ctx, h.daoH, h.daoC, filterA, filterB)
 	if err != nil {
 		return nil, err
 	}
@@ -481,3 +604,267 @@ func (h *historyService) PutImage(ctx context.Context, user mjwt.CustomClaim, id
 	}
 	return history, nil
 }
+
+// AssignHistory menugaskan history kepada user pada cabang yang sama, assignee_id kosong melepas penugasan.
+// user biasa hanya dapat mengambil insiden untuk dirinya sendiri atau melepas penugasannya,
+// menugaskan user lain membutuhkan role approve
+// AppendUpdate menambahkan riwayat update dari sistem tanpa merubah field utama history.
+// SLA disimpan ulang karena update tersebut bisa menjadi respon pertama
+func (h *historyService) AppendUpdate(ctx context.Context, historyID string, input dto.HistoryUpdate) (*dto.HistoryResponse, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	history, err := h.daoH.AppendUpdate(ctx, oid, input)
+	if err != nil {
+		return nil, err
+	}
+
+	h.storeSLA(ctx, history, input.Time)
+	h.servSi.IndexHistory(ctx, historyID)
+
+	return history, nil
+}
+
+func (h *historyService) AssignHistory(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.HistoryAssignRequest) (*dto.HistoryResponse, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	current, err := h.daoH.GetHistoryByID(ctx, oid, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+	if current.AssigneeID == input.AssigneeID {
+		return current, nil
+	}
+
+	isSelfAssign := input.AssigneeID == user.Identity
+	isSelfRelease := input.AssigneeID == "" && current.AssigneeID == user.Identity
+	if !(isSelfAssign || isSelfRelease || sfunc.InSlice(roles.RoleApprove, user.Roles)) {
+		return nil, rest_err.NewUnauthorizedError("menugaskan insiden ke user lain membutuhkan role approve")
+	}
+
+	users, err := h.daoU.FindUser(ctx, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignee dto.UserResponse
+	if input.AssigneeID != "" {
+		found := findBranchUser(users, input.AssigneeID)
+		if found == nil {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("user %s tidak ditemukan pada cabang %s", input.AssigneeID, user.Branch))
+		}
+		// vendor hanya menangani insiden cctv
+		if current.Category != category.Cctv && sfunc.InSlice(roles.RoleVendor, found.Roles) {
+			return nil, rest_err.NewBadRequestError("vendor hanya dapat ditugaskan pada insiden cctv")
+		}
+		assignee = *found
+	}
+
+	historyAssigned, err := h.daoH.AssignHistory(ctx, dto.HistoryAssign{
+		FilterID:     oid,
+		FilterBranch: user.Branch,
+		AssigneeID:   assignee.ID,
+		AssigneeName: assignee.Name,
+		AssignedAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		notifications := []struct {
+			userID string
+			title  string
+		}{
+			{assignee.ID, fmt.Sprintf("Insiden %s ditugaskan kepada anda", strings.ToLower(historyAssigned.ParentName))},
+			{current.AssigneeID, fmt.Sprintf("Insiden %s dialihkan dari anda", strings.ToLower(historyAssigned.ParentName))},
+		}
+		for _, n := range notifications {
+			// tidak perlu memberi tahu user yang melakukan penugasan
+			if n.userID == "" || n.userID == user.Identity {
+				continue
+			}
+			target := findBranchUser(users, n.userID)
+			if target == nil || target.FcmToken == "" {
+				continue
+			}
+			h.fcmClient.SendMessage(fcm.Payload{
+				Title:          n.title,
+				Message:        fmt.Sprintf("%s :: %s :: oleh %s", enum.GetProgressString(historyAssigned.CompleteStatus), historyAssigned.Problem, strings.ToLower(user.Name)),
+				ReceiverTokens: []string{target.FcmToken},
+			})
+		}
+	}()
+
+	return historyAssigned, nil
+}
+
+// SetWatchers mengganti daftar watcher history, watcher harus user pada cabang yang sama.
+// hanya assignee, pembuat history atau role approve yang dapat mengganti watcher
+func (h *historyService) SetWatchers(ctx context.Context, user mjwt.CustomClaim, historyID string, input dto.HistoryWatcherRequest) (*dto.HistoryResponse, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	current, err := h.daoH.GetHistoryByID(ctx, oid, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+	isAssignee := current.AssigneeID != "" && current.AssigneeID == user.Identity
+	isCreator := current.CreatedByID == user.Identity
+	if !(isAssignee || isCreator || sfunc.InSlice(roles.RoleApprove, user.Roles)) {
+		return nil, rest_err.NewUnauthorizedError("mengganti watcher hanya dapat dilakukan oleh assignee, pembuat insiden atau role approve")
+	}
+
+	users, err := h.daoU.FindUser(ctx, user.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	watchers := make([]dto.HistoryUser, 0, len(input.WatcherIDs))
+	seen := make(map[string]bool, len(input.WatcherIDs))
+	for _, id := range input.WatcherIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		found := findBranchUser(users, id)
+		if found == nil {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("user %s tidak ditemukan pada cabang %s", id, user.Branch))
+		}
+		watchers = append(watchers, dto.HistoryUser{ID: found.ID, Name: found.Name})
+	}
+
+	return h.daoH.SetWatchers(ctx, dto.HistoryWatcherEdit{
+		FilterID:     oid,
+		FilterBranch: user.Branch,
+		Watchers:     watchers,
+	})
+}
+
+// FindMyOpenHistory history yang belum complete dan ditugaskan kepada user
+func (h *historyService) FindMyOpenHistory(ctx context.Context, user mjwt.CustomClaim) (dto.HistoryResponseMinList, rest_err.APIError) {
+	historyList, err := h.daoH.FindOpenHistory(ctx, user.Branch, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	h.refreshSLA(ctx, historyList)
+	return historyList, nil
+}
+
+// GetWorkload ringkasan insiden terbuka per teknisi pada branch
+func (h *historyService) GetWorkload(ctx context.Context, branch string) (*dto.WorkloadSummary, rest_err.APIError) {
+	branch = strings.ToUpper(branch)
+
+	users, err := h.daoU.FindUser(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	historyList, err := h.daoH.FindOpenHistory(ctx, branch, "")
+	if err != nil {
+		return nil, err
+	}
+	h.refreshSLA(ctx, historyList)
+
+	summary := buildWorkload(branch, users, historyList, time.Now().Unix())
+	return &summary, nil
+}
+
+func findBranchUser(users dto.UserResponseList, userID string) *dto.UserResponse {
+	for i := range users {
+		if users[i].ID == userID {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// workloadAgeBucket pengelompokan umur insiden terbuka
+func workloadAgeBucket(dateStart int64, now int64) string {
+	const day = 24 * 60 * 60
+	age := now - dateStart
+	switch {
+	case age < day:
+		return "<1d"
+	case age < 3*day:
+		return "1-3d"
+	case age < 7*day:
+		return "3-7d"
+	default:
+		return ">7d"
+	}
+}
+
+func newTechnicianWorkload(userID string, name string) dto.TechnicianWorkload {
+	return dto.TechnicianWorkload{
+		UserID:   userID,
+		Name:     name,
+		ByStatus: make(map[string]int),
+		ByAge:    make(map[string]int),
+	}
+}
+
+func addWorkload(workload *dto.TechnicianWorkload, history dto.HistoryResponseMin, now int64) {
+	workload.Total++
+	workload.ByStatus[enum.GetProgressString(history.CompleteStatus)]++
+	workload.ByAge[workloadAgeBucket(history.DateStart, now)]++
+	if history.SLA.ResponseBreached || history.SLA.ResolveBreached {
+		workload.SLABreached++
+	}
+	if workload.OldestStart == 0 || history.DateStart < workload.OldestStart {
+		workload.OldestStart = history.DateStart
+	}
+}
+
+// buildWorkload seluruh user cabang tampil walaupun tidak memiliki insiden,
+// assignee yang sudah tidak berada di cabang tetap ditampilkan menggunakan nama yang tersimpan pada history
+func buildWorkload(branch string, users dto.UserResponseList, historyList dto.HistoryResponseMinList, now int64) dto.WorkloadSummary {
+	perUser := make(map[string]*dto.TechnicianWorkload, len(users))
+	order := make([]string, 0, len(users))
+	for _, u := range users {
+		workload := newTechnicianWorkload(u.ID, u.Name)
+		perUser[u.ID] = &workload
+		order = append(order, u.ID)
+	}
+
+	unassigned := newTechnicianWorkload("", "")
+	for _, history := range historyList {
+		if history.AssigneeID == "" {
+			addWorkload(&unassigned, history, now)
+			continue
+		}
+		workload, ok := perUser[history.AssigneeID]
+		if !ok {
+			created := newTechnicianWorkload(history.AssigneeID, history.AssigneeName)
+			workload = &created
+			perUser[history.AssigneeID] = workload
+			order = append(order, history.AssigneeID)
+		}
+		addWorkload(workload, history, now)
+	}
+
+	technicians := make([]dto.TechnicianWorkload, 0, len(order))
+	for _, id := range order {
+		technicians = append(technicians, *perUser[id])
+	}
+	sort.SliceStable(technicians, func(i, j int) bool {
+		if technicians[i].Total != technicians[j].Total {
+			return technicians[i].Total > technicians[j].Total
+		}
+		return technicians[i].Name < technicians[j].Name
+	})
+
+	return dto.WorkloadSummary{
+		Branch:      branch,
+		GeneratedAt: now,
+		Technicians: technicians,
+		Unassigned:  unassigned,
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadAgeBucket(t *testing.T) {
+	const day = int64(24 * 60 * 60)
+	now := 100 * day
+
+	assert.Equal(t, "<1d", workloadAgeBucket(now-60, now))
+	assert.Equal(t, "1-3d", workloadAgeBucket(now-2*day, now))
+	assert.Equal(t, "3-7d", workloadAgeBucket(now-3*day, now))
+	assert.Equal(t, ">7d", workloadAgeBucket(now-30*day, now))
+}
+
+func TestBuildWorkload(t *testing.T) {
+	const day = int64(24 * 60 * 60)
+	now := 100 * day
+	users := dto.UserResponseList{
+		{ID: "budi", Name: "Budi"},
+		{ID: "andi", Name: "Andi"},
+	}
+	histories := dto.HistoryResponseMinList{
+		{AssigneeID: "andi", AssigneeName: "Andi", CompleteStatus: enum.HProgress, DateStart: now - 4*day},
+		{AssigneeID: "andi", AssigneeName: "Andi", CompleteStatus: enum.HPending, DateStart: now - day/2, SLA: dto.HistorySLA{ResolveBreached: true}},
+		{AssigneeID: "", CompleteStatus: enum.HProgress, DateStart: now - day},
+		// assignee sudah pindah cabang
+		{AssigneeID: "citra", AssigneeName: "Citra", CompleteStatus: enum.HProgress, DateStart: now - 10*day},
+	}
+
+	summary := buildWorkload("BANJARMASIN", users, histories, now)
+
+	assert.Equal(t, "BANJARMASIN", summary.Branch)
+	assert.Len(t, summary.Technicians, 3)
+
+	andi := summary.Technicians[0]
+	assert.Equal(t, "andi", andi.UserID)
+	assert.Equal(t, 2, andi.Total)
+	assert.Equal(t, 1, andi.ByStatus[enum.GetProgressString(enum.HProgress)])
+	assert.Equal(t, 1, andi.ByStatus[enum.GetProgressString(enum.HPending)])
+	assert.Equal(t, 1, andi.ByAge["3-7d"])
+	assert.Equal(t, 1, andi.ByAge["<1d"])
+	assert.Equal(t, 1, andi.SLABreached)
+	assert.Equal(t, now-4*day, andi.OldestStart)
+
+	// citra tetap tampil walaupun tidak terdaftar pada cabang, budi tanpa insiden di urutan terakhir
+	assert.Equal(t, "citra", summary.Technicians[1].UserID)
+	assert.Equal(t, "budi", summary.Technicians[2].UserID)
+	assert.Equal(t, 0, summary.Technicians[2].Total)
+
+	assert.Equal(t, 1, summary.Unassigned.Total)
+}
@@ -0,0 +1,667 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/tabular"
+	"github.com/spf13/cast"
+)
+
+const (
+	ImportCctv     = "CCTV"
+	ImportComputer = "COMPUTER"
+	ImportOther    = "OTHER"
+
+	importActionCreate = "CREATE"
+	importActionUpdate = "UPDATE"
+
+	// importCustomPrefix kolom custom field ditulis sebagai custom_<key>
+	importCustomPrefix = "custom_"
+
+	// excelSerialLimit angka dibawah batas ini dianggap nomor seri tanggal excel (sekitar tahun 2173), bukan unix timestamp
+	excelSerialLimit = 100000
+)
+
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func NewImportService(
+	cctvServ CctvServiceAssumer,
+	computerServ ComputerServiceAssumer,
+	otherServ OtherServiceAssumer,
+	customFieldServ CustomFieldServiceAssumer,
+	cctvDao cctvdao.CctvDaoAssumer,
+	computerDao computerdao.ComputerDaoAssumer,
+	otherDao otherdao.OtherDaoAssumer,
+	dataQualityServ DataQualityServiceAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+) ImportServiceAssumer {
+	return &importService{
+		servCctv:     cctvServ,
+		servComputer: computerServ,
+		servOther:    otherServ,
+		servCf:       customFieldServ,
+		servDq:       dataQualityServ,
+		daoC:         cctvDao,
+		daoPC:        computerDao,
+		daoO:         otherDao,
+		daoT:         txDao,
+	}
+}
+
+type importService struct {
+	servCctv     CctvServiceAssumer
+	servComputer ComputerServiceAssumer
+	servOther    OtherServiceAssumer
+	servCf       CustomFieldServiceAssumer
+	servDq       DataQualityServiceAssumer
+	daoC         cctvdao.CctvDaoAssumer
+	daoPC        computerdao.ComputerDaoAssumer
+	daoO         otherdao.OtherDaoAssumer
+	daoT         transactiondao.TransactionDaoAssumer
+}
+
+type ImportServiceAssumer interface {
+	Import(ctx context.Context, user mjwt.CustomClaim, category string, rows []tabular.Row, dryRun bool) (*dto.ImportResult, rest_err.APIError)
+}
+
+// importTarget dokumen yang sudah ada dengan nomor inventaris yang sama
+type importTarget struct {
+	ID          string
+	UpdatedAt   int64
+	SubCategory string
+	Lifecycle   dto.Lifecycle
+}
+
+// importSchemas definisi custom field dengan key kategori
+type importSchemas map[string][]dto.CustomField
+
+// importer menyatukan proses per kategori agar alur validasi dan penulisan sama
+type importer struct {
+	parse  func(row tabular.Row, schemas importSchemas) (req interface{}, inventoryNumber string, name string, err error)
+	lookup func(ctx context.Context, branch string, inventoryNumber string) (*importTarget, rest_err.APIError)
+	unique func(req interface{}) dto.UnitUniqueCheck
+	create func(ctx context.Context, user mjwt.CustomClaim, req interface{}) (*string, rest_err.APIError)
+	update func(ctx context.Context, user mjwt.CustomClaim, target importTarget, req interface{}) rest_err.APIError
+}
+
+// Import memvalidasi seluruh baris lalu menulis data melalui service Insert/Edit masing-masing kategori.
+// Data hanya ditulis jika bukan dry-run dan seluruh baris valid, baris dengan nomor inventaris
+// yang sudah ada akan diupdate sehingga upload ulang file yang sama tidak menggandakan data.
+// Penulisan berjalan dalam satu transaction, kegagalan pada satu baris membatalkan seluruh import
+func (s *importService) Import(ctx context.Context, user mjwt.CustomClaim, category string, rows []tabular.Row, dryRun bool) (*dto.ImportResult, rest_err.APIError) {
+	imp, apiErr := s.importerFor(category)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if len(rows) == 0 {
+		return nil, rest_err.NewBadRequestError("file import tidak memiliki data")
+	}
+	schemas, apiErr := s.importSchemas(ctx)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := dto.ImportResult{
+		Category: strings.ToUpper(category),
+		Branch:   user.Branch,
+		DryRun:   dryRun,
+		Total:    len(rows),
+		Rows:     make([]dto.ImportRowResult, len(rows)),
+	}
+
+	requests := make([]interface{}, len(rows))
+	targets := make([]*importTarget, len(rows))
+	seen := make(map[string]int)
+	invalid := false
+
+	for i, row := range rows {
+		req, inventoryNumber, name, err := imp.parse(row, schemas)
+		rowResult := dto.ImportRowResult{
+			Line:            row.Line,
+			InventoryNumber: inventoryNumber,
+			Name:            name,
+		}
+
+		if err == nil && inventoryNumber == "" {
+			err = errors.New("inventory_number wajib diisi untuk import")
+		}
+		if err == nil {
+			if line, exist := seen[inventoryNumber]; exist {
+				err = fmt.Errorf("inventory_number sama dengan baris %d", line)
+			}
+		}
+		if err != nil {
+			rowResult.Error = err.Error()
+			result.Rows[i] = rowResult
+			invalid = true
+			continue
+		}
+		seen[inventoryNumber] = row.Line
+
+		target, apiErr := imp.lookup(ctx, user.Branch, inventoryNumber)
+		if apiErr != nil && apiErr.Status() != 404 {
+			return nil, apiErr
+		}
+		rowResult.Action = importActionCreate
+		check := imp.unique(req)
+		check.Branch = user.Branch
+		if target != nil {
+			rowResult.Action = importActionUpdate
+			rowResult.ID = target.ID
+			check.ID = target.ID
+		}
+
+		// konflik ip / nomor inventaris dengan unit lain sudah terlihat saat dry-run
+		if apiErr := s.servDq.ValidateUnique(ctx, user, check); apiErr != nil {
+			if apiErr.Status() != 400 {
+				return nil, apiErr
+			}
+			rowResult.Error = apiErr.Message()
+			invalid = true
+		}
+
+		requests[i] = req
+		targets[i] = target
+		result.Rows[i] = rowResult
+	}
+
+	if dryRun || invalid {
+		tallyImport(&result)
+		return &result, nil
+	}
+
+	apiErr = s.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		for i := range rows {
+			rowResult := &result.Rows[i]
+			if targets[i] == nil {
+				insertedID, apiErr := imp.create(txCtx, user, requests[i])
+				if apiErr != nil {
+					rowResult.Error = apiErr.Message()
+					return apiErr
+				}
+				rowResult.ID = *insertedID
+				continue
+			}
+			if apiErr := imp.update(txCtx, user, *targets[i], requests[i]); apiErr != nil {
+				rowResult.Error = apiErr.Message()
+				return apiErr
+			}
+		}
+		return nil
+	})
+	if apiErr != nil {
+		// penulisan dibatalkan, id baris baru yang sempat dibuat tidak lagi berlaku
+		for i := range result.Rows {
+			if targets[i] == nil && result.Rows[i].Error == "" {
+				result.Rows[i].ID = ""
+			}
+		}
+		tallyImport(&result)
+		return &result, nil
+	}
+
+	result.Committed = true
+	tallyImport(&result)
+	return &result, nil
+}
+
+func (s *importService) importerFor(category string) (*importer, rest_err.APIError) {
+	switch strings.ToUpper(category) {
+	case ImportCctv:
+		return s.cctvImporter(), nil
+	case ImportComputer:
+		return s.computerImporter(), nil
+	case ImportOther:
+		return s.otherImporter(), nil
+	default:
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("kategori import harus salah satu dari %s, %s, %s",
+			strings.ToLower(ImportCctv), strings.ToLower(ImportComputer), strings.ToLower(ImportOther)))
+	}
+}
+
+// importSchemas memuat seluruh schema custom field sekali untuk semua baris
+func (s *importService) importSchemas(ctx context.Context) (importSchemas, rest_err.APIError) {
+	schemaList, apiErr := s.servCf.FindSchema(ctx)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	schemas := make(importSchemas, len(schemaList))
+	for _, schema := range schemaList {
+		schemas[schema.Category] = schema.Fields
+	}
+	return schemas, nil
+}
+
+func (s *importService) cctvImporter() *importer {
+	return &importer{
+		parse: func(row tabular.Row, schemas importSchemas) (interface{}, string, string, error) {
+			req := dto.CctvRequest{
+				Name:            row.Get("name"),
+				IP:              row.Get("ip"),
+				InventoryNumber: row.Get("inventory_number"),
+				Location:        row.Get("location"),
+				LocationLat:     row.Get("location_lat"),
+				LocationLon:     row.Get("location_lon"),
+				Tag:             importTags(row.Get("tag")),
+				Brand:           row.Get("brand"),
+				Type:            row.Get("type"),
+				Note:            row.Get("note"),
+			}
+			var err error
+			if req.Date, err = importDate(row.Get("date")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.Lifecycle, err = importLifecycle(row); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.DisVendor, err = importBool(row.Get("dis_vendor")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.CustomFields, err = importCustomFields(row, schemas[category.Cctv]); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if err := importValidate(req, req.IP); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			return req, req.InventoryNumber, req.Name, nil
+		},
+		lookup: func(ctx context.Context, branch string, inventoryNumber string) (*importTarget, rest_err.APIError) {
+			cctv, apiErr := s.daoC.GetCctvByInventoryNumber(ctx, branch, inventoryNumber)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return &importTarget{ID: cctv.ID.Hex(), UpdatedAt: cctv.UpdatedAt, Lifecycle: cctv.Lifecycle}, nil
+		},
+		unique: func(req interface{}) dto.UnitUniqueCheck {
+			in := req.(dto.CctvRequest)
+			return dto.UnitUniqueCheck{IP: in.IP, InventoryNumber: in.InventoryNumber}
+		},
+		create: func(ctx context.Context, user mjwt.CustomClaim, req interface{}) (*string, rest_err.APIError) {
+			return s.servCctv.InsertCctv(ctx, user, req.(dto.CctvRequest))
+		},
+		update: func(ctx context.Context, user mjwt.CustomClaim, target importTarget, req interface{}) rest_err.APIError {
+			in := req.(dto.CctvRequest)
+			_, apiErr := s.servCctv.EditCctv(ctx, user, target.ID, dto.CctvEditRequest{
+				FilterTimestamp: target.UpdatedAt,
+				Name:            in.Name,
+				IP:              in.IP,
+				InventoryNumber: in.InventoryNumber,
+				Location:        in.Location,
+				LocationLat:     in.LocationLat,
+				LocationLon:     in.LocationLon,
+				Date:            in.Date,
+				Tag:             in.Tag,
+				DisVendor:       in.DisVendor,
+				Brand:           in.Brand,
+				Type:            in.Type,
+				Note:            in.Note,
+
+				Lifecycle:    importKeepLifecycle(target.Lifecycle, in.Lifecycle),
+				CustomFields: in.CustomFields,
+			})
+			return apiErr
+		},
+	}
+}
+
+func (s *importService) computerImporter() *importer {
+	return &importer{
+		parse: func(row tabular.Row, schemas importSchemas) (interface{}, string, string, error) {
+			req := dto.ComputerRequest{
+				Name:            row.Get("name"),
+				Hostname:        row.Get("hostname"),
+				Division:        row.Get("division"),
+				OS:              row.Get("os"),
+				Processor:       row.Get("processor"),
+				IP:              row.Get("ip"),
+				InventoryNumber: row.Get("inventory_number"),
+				Location:        row.Get("location"),
+				LocationLat:     row.Get("location_lat"),
+				LocationLon:     row.Get("location_lon"),
+				Tag:             importTags(row.Get("tag")),
+				Brand:           row.Get("brand"),
+				Type:            row.Get("type"),
+				Note:            row.Get("note"),
+			}
+			var err error
+			if req.Date, err = importDate(row.Get("date")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.Lifecycle, err = importLifecycle(row); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.SeatManagement, err = importBool(row.Get("seat_management")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.Ram, err = importInt("ram", row.Get("ram")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.Hardisk, err = importInt("hardisk", row.Get("hardisk")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.CustomFields, err = importCustomFields(row, schemas[category.PC]); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if err := importValidate(req, req.IP); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			return req, req.InventoryNumber, req.Name, nil
+		},
+		lookup: func(ctx context.Context, branch string, inventoryNumber string) (*importTarget, rest_err.APIError) {
+			pc, apiErr := s.daoPC.GetPcByInventoryNumber(ctx, branch, inventoryNumber)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return &importTarget{ID: pc.ID.Hex(), UpdatedAt: pc.UpdatedAt, Lifecycle: pc.Lifecycle}, nil
+		},
+		unique: func(req interface{}) dto.UnitUniqueCheck {
+			in := req.(dto.ComputerRequest)
+			return dto.UnitUniqueCheck{IP: in.IP, InventoryNumber: in.InventoryNumber}
+		},
+		create: func(ctx context.Context, user mjwt.CustomClaim, req interface{}) (*string, rest_err.APIError) {
+			return s.servComputer.InsertComputer(ctx, user, req.(dto.ComputerRequest))
+		},
+		update: func(ctx context.Context, user mjwt.CustomClaim, target importTarget, req interface{}) rest_err.APIError {
+			in := req.(dto.ComputerRequest)
+			_, apiErr := s.servComputer.EditComputer(ctx, user, target.ID, dto.ComputerEditRequest{
+				FilterTimestamp: target.UpdatedAt,
+				Name:            in.Name,
+				Hostname:        in.Hostname,
+				Division:        in.Division,
+				SeatManagement:  in.SeatManagement,
+				OS:              in.OS,
+				Processor:       in.Processor,
+				Ram:             in.Ram,
+				Hardisk:         in.Hardisk,
+				IP:              in.IP,
+				InventoryNumber: in.InventoryNumber,
+				Location:        in.Location,
+				LocationLat:     in.LocationLat,
+				LocationLon:     in.LocationLon,
+				Date:            in.Date,
+				Tag:             in.Tag,
+				Brand:           in.Brand,
+				Type:            in.Type,
+				Note:            in.Note,
+
+				Lifecycle:    importKeepLifecycle(target.Lifecycle, in.Lifecycle),
+				CustomFields: in.CustomFields,
+			})
+			return apiErr
+		},
+	}
+}
+
+func (s *importService) otherImporter() *importer {
+	return &importer{
+		parse: func(row tabular.Row, schemas importSchemas) (interface{}, string, string, error) {
+			req := dto.OtherRequest{
+				Name:            row.Get("name"),
+				Detail:          row.Get("detail"),
+				Division:        row.Get("division"),
+				SubCategory:     strings.ToUpper(row.Get("sub_category")),
+				IP:              row.Get("ip"),
+				InventoryNumber: row.Get("inventory_number"),
+				Location:        row.Get("location"),
+				LocationLat:     row.Get("location_lat"),
+				LocationLon:     row.Get("location_lon"),
+				Tag:             importTags(row.Get("tag")),
+				Brand:           row.Get("brand"),
+				Type:            row.Get("type"),
+				Note:            row.Get("note"),
+			}
+			var err error
+			if req.Date, err = importDate(row.Get("date")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.Lifecycle, err = importLifecycle(row); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.DisVendor, err = importBool(row.Get("dis_vendor")); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if req.CustomFields, err = importCustomFields(row, schemas[req.SubCategory]); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			if err := importValidate(req, req.IP); err != nil {
+				return nil, req.InventoryNumber, req.Name, err
+			}
+			return req, req.InventoryNumber, req.Name, nil
+		},
+		lookup: func(ctx context.Context, branch string, inventoryNumber string) (*importTarget, rest_err.APIError) {
+			other, apiErr := s.daoO.GetOtherByInventoryNumber(ctx, branch, inventoryNumber)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return &importTarget{ID: other.ID.Hex(), UpdatedAt: other.UpdatedAt, SubCategory: other.SubCategory, Lifecycle: other.Lifecycle}, nil
+		},
+		unique: func(req interface{}) dto.UnitUniqueCheck {
+			in := req.(dto.OtherRequest)
+			return dto.UnitUniqueCheck{IP: in.IP, InventoryNumber: in.InventoryNumber}
+		},
+		create: func(ctx context.Context, user mjwt.CustomClaim, req interface{}) (*string, rest_err.APIError) {
+			return s.servOther.InsertOther(ctx, user, req.(dto.OtherRequest))
+		},
+		update: func(ctx context.Context, user mjwt.CustomClaim, target importTarget, req interface{}) rest_err.APIError {
+			in := req.(dto.OtherRequest)
+			_, apiErr := s.servOther.EditOther(ctx, user, target.ID, dto.OtherEditRequest{
+				FilterTimestamp:   target.UpdatedAt,
+				FilterSubCategory: target.SubCategory,
+				Name:              in.Name,
+				Detail:            in.Detail,
+				Division:          in.Division,
+				IP:                in.IP,
+				InventoryNumber:   in.InventoryNumber,
+				Location:          in.Location,
+				LocationLat:       in.LocationLat,
+				LocationLon:       in.LocationLon,
+				Date:              in.Date,
+				Tag:               in.Tag,
+				Brand:             in.Brand,
+				Type:              in.Type,
+				Note:              in.Note,
+				DisVendor:         in.DisVendor,
+
+				Lifecycle:    importKeepLifecycle(target.Lifecycle, in.Lifecycle),
+				CustomFields: in.CustomFields,
+			})
+			return apiErr
+		},
+	}
+}
+
+// importValidate menjalankan validator dto yang sama dengan endpoint POST serta cek ip address
+func importValidate(req interface{ Validate() error }, ip string) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if ip != "" && net.ParseIP(ip) == nil {
+		return errors.New("IP Address tidak valid")
+	}
+	return nil
+}
+
+// importTags memecah kolom tag yang dipisah koma
+func importTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// importBool menerima true/false, 1/0, ya/tidak. kolom kosong bernilai false
+func importBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "":
+		return false, nil
+	case "ya", "y", "yes":
+		return true, nil
+	case "tidak", "n", "no":
+		return false, nil
+	}
+	b, err := cast.ToBoolE(value)
+	if err != nil {
+		return false, fmt.Errorf("nilai %s bukan boolean", value)
+	}
+	return b, nil
+}
+
+// importInt menerima angka bulat, termasuk angka dari excel yang ditulis "8.0"
+func importInt(field string, value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s harus berupa angka", field)
+	}
+	return int(number), nil
+}
+
+// importDate menerima unix timestamp, nomor seri tanggal excel atau tanggal format 2006-01-02 / 02/01/2006
+func importDate(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		// sel bertipe tanggal pada xlsx disimpan sebagai jumlah hari sejak 1899-12-30
+		if number < excelSerialLimit {
+			return excelEpoch.Add(time.Duration(number*24) * time.Hour).Unix(), nil
+		}
+		return int64(number), nil
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("format tanggal %s tidak dikenali, gunakan YYYY-MM-DD", value)
+}
+
+// importCustomFields membaca kolom custom_<key> dan mengubah nilainya sesuai tipe field pada schema.
+// nil dikembalikan jika file tidak memiliki kolom custom sehingga update tidak merubah nilai tersimpan,
+// kolom kosong yang tidak terdaftar pada schema kategori baris diabaikan
+func importCustomFields(row tabular.Row, fields []dto.CustomField) (dto.CustomFields, error) {
+	fieldMap := make(map[string]dto.CustomField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Key] = field
+	}
+
+	var values dto.CustomFields
+	for column := range row.Values {
+		if !strings.HasPrefix(column, importCustomPrefix) {
+			continue
+		}
+		if values == nil {
+			values = make(dto.CustomFields)
+		}
+		key := strings.TrimPrefix(column, importCustomPrefix)
+		value := row.Get(column)
+		field, ok := fieldMap[key]
+		if !ok {
+			if value != "" {
+				return nil, fmt.Errorf("custom field %s tidak terdaftar", key)
+			}
+			continue
+		}
+		if value == "" {
+			continue
+		}
+
+		switch field.Type {
+		case fieldtype.Number:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("custom field %s harus berupa angka", field.Label)
+			}
+			values[key] = number
+		case fieldtype.Bool:
+			boolean, err := importBool(value)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = boolean
+		case fieldtype.Date:
+			date, err := importDate(value)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = float64(date)
+		default:
+			values[key] = value
+		}
+	}
+	if values == nil {
+		return nil, nil
+	}
+
+	// validasi yang sama dengan insert/edit agar kesalahan sudah terlihat saat dry-run
+	if _, apiErr := validateCustomFields(fields, values); apiErr != nil {
+		return nil, errors.New(apiErr.Message())
+	}
+	return values, nil
+}
+
+// importLifecycle membaca kolom purchase_date, warranty_expiry, end_of_life, vendor dan cost
+func importLifecycle(row tabular.Row) (dto.Lifecycle, error) {
+	lifecycle := dto.Lifecycle{
+		Vendor: row.Get("vendor"),
+	}
+	var err error
+	if lifecycle.PurchaseDate, err = importDate(row.Get("purchase_date")); err != nil {
+		return lifecycle, err
+	}
+	if lifecycle.WarrantyExpiry, err = importDate(row.Get("warranty_expiry")); err != nil {
+		return lifecycle, err
+	}
+	if lifecycle.EndOfLife, err = importDate(row.Get("end_of_life")); err != nil {
+		return lifecycle, err
+	}
+	cost, err := importInt("cost", row.Get("cost"))
+	if err != nil {
+		return lifecycle, err
+	}
+	lifecycle.Cost = int64(cost)
+	return lifecycle, nil
+}
+
+// importKeepLifecycle mempertahankan data lifecycle lama jika file import tidak memiliki kolom lifecycle
+func importKeepLifecycle(existing dto.Lifecycle, imported dto.Lifecycle) dto.Lifecycle {
+	if imported == (dto.Lifecycle{}) {
+		return existing
+	}
+	return imported
+}
+
+// tallyImport menghitung ringkasan berdasarkan hasil per baris
+func tallyImport(result *dto.ImportResult) {
+	result.Created, result.Updated, result.Failed = 0, 0, 0
+	for _, row := range result.Rows {
+		switch {
+		case row.Error != "":
+			result.Failed++
+		case row.Action == importActionCreate:
+			result.Created++
+		case row.Action == importActionUpdate:
+			result.Updated++
+		}
+	}
+}
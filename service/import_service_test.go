@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muchlist/risa_restfull/constants/fieldtype"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/tabular"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportDate(t *testing.T) {
+	unix, err := importDate("2021-03-01")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), unix)
+
+	serial, err := importDate("44256")
+	assert.Nil(t, err)
+	assert.Equal(t, unix, serial)
+
+	raw, err := importDate("1614556800")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1614556800), raw)
+
+	_, err = importDate("maret")
+	assert.NotNil(t, err)
+}
+
+func TestImportBoolIntTags(t *testing.T) {
+	b, err := importBool("Ya")
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	_, err = importBool("mungkin")
+	assert.NotNil(t, err)
+
+	n, err := importInt("ram", "8.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 8, n)
+
+	assert.Equal(t, []string{"GATE", "POS"}, importTags(" GATE, ,POS "))
+}
+
+func TestImportCctvParse(t *testing.T) {
+	imp := (&importService{}).cctvImporter()
+
+	_, inv, _, err := imp.parse(tabular.Row{Line: 2, Values: map[string]string{
+		"name": "CCTV GATE", "inventory_number": "INV-01", "ip": "10.0.0.300",
+	}}, nil)
+	assert.Equal(t, "INV-01", inv)
+	assert.NotNil(t, err)
+}
+
+func TestImportCustomFieldsRoundTrip(t *testing.T) {
+	fields := []dto.CustomField{
+		{Key: "vlan", Label: "VLAN", Type: fieldtype.Number},
+		{Key: "outdoor", Label: "Outdoor", Type: fieldtype.Bool},
+		{Key: "installed", Label: "Installed", Type: fieldtype.Date},
+		{Key: "zone", Label: "Zone", Type: fieldtype.Enum, Options: []string{"A", "B"}},
+	}
+	stored := dto.CustomFields{"vlan": float64(20), "outdoor": true, "installed": int64(1609459200), "zone": "B"}
+
+	header := exportHeader(ExportLangID, exportCustomColumns(fields))
+	record := exportCustomRecord(ExportLangID, fields, stored)
+	values := map[string]string{"name": "CCTV GATE"}
+	for i, column := range header {
+		values[column] = record[i]
+	}
+
+	imported, err := importCustomFields(tabular.Row{Line: 2, Values: values}, fields)
+	assert.Nil(t, err)
+	assert.Equal(t, dto.CustomFields{"vlan": float64(20), "outdoor": true, "installed": float64(1609459200), "zone": "B"}, imported)
+
+	validated, apiErr := validateCustomFields(fields, imported)
+	assert.Nil(t, apiErr)
+	assert.Equal(t, int64(1609459200), validated["installed"])
+}
+
+func TestImportCustomFieldsColumns(t *testing.T) {
+	fields := []dto.CustomField{{Key: "vlan", Label: "VLAN", Type: fieldtype.Number, Required: true}}
+
+	none, err := importCustomFields(tabular.Row{Values: map[string]string{"name": "PC"}}, fields)
+	assert.Nil(t, err)
+	assert.Nil(t, none)
+
+	_, err = importCustomFields(tabular.Row{Values: map[string]string{"custom_vlan": ""}}, fields)
+	assert.NotNil(t, err)
+
+	_, err = importCustomFields(tabular.Row{Values: map[string]string{"custom_vlan": "dua"}}, fields)
+	assert.NotNil(t, err)
+
+	_, err = importCustomFields(tabular.Row{Values: map[string]string{"custom_vlan": "2", "custom_rack": "R1"}}, fields)
+	assert.NotNil(t, err)
+
+	// kolom milik sub category lain yang kosong diabaikan
+	ignored, err := importCustomFields(tabular.Row{Values: map[string]string{"custom_vlan": "2", "custom_rack": ""}}, fields)
+	assert.Nil(t, err)
+	assert.Equal(t, dto.CustomFields{"vlan": float64(2)}, ignored)
+}
+
+func TestTallyImport(t *testing.T) {
+	result := dto.ImportResult{Rows: []dto.ImportRowResult{
+		{Action: importActionCreate},
+		{Action: importActionUpdate},
+		{Action: importActionCreate, Error: "gagal"},
+	}}
+	tallyImport(&result)
+
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Failed)
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+	"github.com/muchlist/risa_restfull/constants/pdftype"
+	"github.com/muchlist/risa_restfull/dao/jobdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/timegen"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func NewJobService(jobDao jobdao.JobDaoAssumer,
+	alertServ AlertServiceAssumer,
+	reportServ ReportServiceAssumer,
+	trashServ TrashServiceAssumer,
+	lifecycleServ LifecycleServiceAssumer,
+	dataQualityServ DataQualityServiceAssumer,
+	searchServ SearchServiceAssumer,
+	slaServ SLAServiceAssumer) JobServiceAssumer {
+	return &jobService{
+		daoJ:       jobDao,
+		alertServ:  alertServ,
+		reportServ: reportServ,
+		trashServ:  trashServ,
+		lifeServ:   lifecycleServ,
+		dqServ:     dataQualityServ,
+		searchServ: searchServ,
+		slaServ:    slaServ,
+		changed:    make(chan struct{}, 1),
+		running:    make(map[string]bool),
+	}
+}
+
+type jobService struct {
+	daoJ       jobdao.JobDaoAssumer
+	alertServ  AlertServiceAssumer
+	reportServ ReportServiceAssumer
+	trashServ  TrashServiceAssumer
+	lifeServ   LifecycleServiceAssumer
+	dqServ     DataQualityServiceAssumer
+	searchServ SearchServiceAssumer
+	slaServ    SLAServiceAssumer
+
+	// changed memberi tanda ke scheduler untuk memuat ulang jadwal
+	changed chan struct{}
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+type JobServiceAssumer interface {
+	InsertJob(ctx context.Context, user mjwt.CustomClaim, input dto.JobRequest) (*string, rest_err.APIError)
+	EditJob(ctx context.Context, user mjwt.CustomClaim, jobID string, input dto.JobRequest) (*dto.Job, rest_err.APIError)
+	DeleteJob(ctx context.Context, jobID string) rest_err.APIError
+	RunJobNow(ctx context.Context, user mjwt.CustomClaim, jobID string) (*dto.Job, rest_err.APIError)
+	ExecuteJob(ctx context.Context, job dto.Job, trigger string, triggeredBy string) dto.JobRun
+	Changed() <-chan struct{}
+
+	GetJob(ctx context.Context, jobID string) (*dto.Job, rest_err.APIError)
+	FindJob(ctx context.Context, filter dto.FilterJob, page dto.PageRequest) ([]dto.Job, dto.PageInfo, rest_err.APIError)
+	FindJobRun(ctx context.Context, filter dto.FilterJobRun, page dto.PageRequest) ([]dto.JobRun, dto.PageInfo, rest_err.APIError)
+}
+
+func (j *jobService) InsertJob(ctx context.Context, user mjwt.CustomClaim, input dto.JobRequest) (*string, rest_err.APIError) {
+	timeNow := time.Now().Unix()
+	insertedID, err := j.daoJ.InsertJob(ctx, dto.Job{
+		CreatedAt:   timeNow,
+		CreatedBy:   user.Name,
+		CreatedByID: user.Identity,
+		UpdatedAt:   timeNow,
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Name:        input.Name,
+		Type:        input.Type,
+		Branch:      input.Branch,
+		Category:    input.Category,
+		Cron:        input.Cron,
+		Enabled:     input.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	j.notifyChanged()
+	return insertedID, nil
+}
+
+func (j *jobService) EditJob(ctx context.Context, user mjwt.CustomClaim, jobID string, input dto.JobRequest) (*dto.Job, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(jobID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	jobEdited, err := j.daoJ.EditJob(ctx, dto.JobEdit{
+		FilterID:    oid,
+		UpdatedAt:   time.Now().Unix(),
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Name:        input.Name,
+		Type:        input.Type,
+		Branch:      input.Branch,
+		Category:    input.Category,
+		Cron:        input.Cron,
+		Enabled:     input.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	j.notifyChanged()
+	return jobEdited, nil
+}
+
+func (j *jobService) DeleteJob(ctx context.Context, jobID string) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(jobID)
+	if errT != nil {
+		return rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	if err := j.daoJ.DeleteJob(ctx, oid); err != nil {
+		return err
+	}
+
+	j.notifyChanged()
+	return nil
+}
+
+// RunJobNow menjalankan job di background tanpa menunggu jadwal, hasil dapat dilihat pada run log
+func (j *jobService) RunJobNow(ctx context.Context, user mjwt.CustomClaim, jobID string) (*dto.Job, rest_err.APIError) {
+	job, err := j.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.isRunning(job.ID.Hex()) {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("job %s masih berjalan", job.Name))
+	}
+
+	go j.ExecuteJob(context.Background(), *job, jobtype.TriggerManual, user.Name)
+
+	return job, nil
+}
+
+// ExecuteJob menjalankan job sesuai tipenya lalu mencatat durasi dan error ke run log.
+// job yang sama tidak dijalankan bersamaan
+func (j *jobService) ExecuteJob(ctx context.Context, job dto.Job, trigger string, triggeredBy string) dto.JobRun {
+	run := dto.JobRun{
+		JobID:       job.ID.Hex(),
+		JobName:     job.Name,
+		Type:        job.Type,
+		Branch:      job.Branch,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+	}
+
+	if !j.markRunning(run.JobID) {
+		run.Error = "job masih berjalan, eksekusi dilewati"
+		return run
+	}
+	defer j.unmarkRunning(run.JobID)
+
+	started := time.Now()
+	run.StartedAt = started.Unix()
+
+	result, err := j.execute(ctx, job)
+
+	finished := time.Now()
+	run.FinishedAt = finished.Unix()
+	run.DurationMs = finished.Sub(started).Milliseconds()
+	run.Result = result
+	run.Success = err == nil
+	if err != nil {
+		run.Error = err.Message()
+		logger.Error(fmt.Sprintf("job %s gagal dijalankan (ExecuteJob)", job.Name), err)
+	}
+
+	_, _ = j.daoJ.InsertRun(ctx, run)
+	_ = j.daoJ.UpdateLastRun(ctx, job.ID, run.StartedAt, run.Error)
+
+	return run
+}
+
+func (j *jobService) execute(ctx context.Context, job dto.Job) (string, rest_err.APIError) {
+	switch job.Type {
+	case jobtype.PingAlert:
+		if err := j.alertServ.EvaluateBranch(ctx, job.Branch, job.Category); err != nil {
+			return "", err
+		}
+		return "evaluasi alert selesai", nil
+	case jobtype.VendorMonthly:
+		return j.runVendorMonthly(ctx, job)
+	case jobtype.VendorDaily:
+		return j.runVendorDaily(ctx, job)
+	case jobtype.StockRestock:
+		return j.runStockRestock(ctx, job)
+	case jobtype.TrashPurge:
+		purged, err := j.trashServ.PurgeTrash(ctx, job.Branch)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d dokumen dihapus permanen dari tempat sampah", purged), nil
+	case jobtype.LifecycleAlert:
+		flagged, err := j.lifeServ.NotifyBranch(ctx, job.Branch)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d aset ditandai garansi / EOL", flagged), nil
+	case jobtype.DataQuality:
+		report, err := j.dqServ.Scan(ctx, job.Branch, "SYSTEM")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d temuan data quality", len(report.Findings)), nil
+	case jobtype.SearchReindex:
+		count, err := j.searchServ.Reindex(ctx, job.Branch)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d dokumen diindex ulang untuk pencarian", count), nil
+	case jobtype.SLAEscalation:
+		escalated, err := j.slaServ.EvaluateBranch(ctx, job.Branch)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d insiden dieskalasi karena melewati SLA", escalated), nil
+	default:
+		return "", rest_err.NewBadRequestError(fmt.Sprintf("tipe job %s tidak dikenali", job.Type))
+	}
+}
+
+// runVendorMonthly membuat laporan vendor bulanan untuk bulan sebelumnya
+func (j *jobService) runVendorMonthly(ctx context.Context, job dto.Job) (string, rest_err.APIError) {
+	start, end := previousMonthRange(time.Now().In(witaLocation()))
+
+	pdfName, errT := timegen.GetTimeAsName(end)
+	if errT != nil {
+		return "", rest_err.NewInternalServerError("gagal membuat nama pdf", errT)
+	}
+	pdfName = fmt.Sprintf("vendor-monthly%s", pdfName)
+
+	if _, err := j.reportServ.GenerateReportPDFVendorMonthly(ctx, pdfName, job.Branch, start, end, false); err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("pdf-v-month/%s.pdf", pdfName)
+	return fileName, j.savePdf(ctx, job.Branch, pdfName, pdftype.VendorMonthly, fileName, end)
+}
+
+// runVendorDaily membuat laporan harian vendor mulai dari laporan terakhir
+func (j *jobService) runVendorDaily(ctx context.Context, job dto.Job) (string, rest_err.APIError) {
+	currentTime := time.Now().Unix()
+
+	pdfName, errT := timegen.GetTimeAsName(currentTime)
+	if errT != nil {
+		return "", rest_err.NewInternalServerError("gagal membuat nama pdf", errT)
+	}
+	pdfName = fmt.Sprintf("daily-vendor-%s", pdfName)
+
+	if _, err := j.reportServ.GenerateReportVendorDailyStartFromLast(ctx, pdfName, job.Branch, false); err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("pdf-vendor/%s.pdf", pdfName)
+	return fileName, j.savePdf(ctx, job.Branch, pdfName, pdftype.Vendor, fileName, currentTime)
+}
+
+// runStockRestock membuat laporan stock yang perlu restock untuk bulan sebelumnya
+func (j *jobService) runStockRestock(ctx context.Context, job dto.Job) (string, rest_err.APIError) {
+	start, end := previousMonthRange(time.Now().In(witaLocation()))
+
+	pdfName, errT := timegen.GetTimeAsName(end)
+	if errT != nil {
+		return "", rest_err.NewInternalServerError("gagal membuat nama pdf", errT)
+	}
+	pdfName = fmt.Sprintf("stock-%s", pdfName)
+
+	if _, err := j.reportServ.GenerateStockReportRestock(ctx, pdfName, job.Branch, job.Category, start, end); err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("pdf-stock/%s.pdf", pdfName)
+	return fileName, j.savePdf(ctx, job.Branch, pdfName, pdftype.Stock, fileName, end)
+}
+
+func (j *jobService) savePdf(ctx context.Context, branch, pdfName, pdfType, fileName string, endReport int64) rest_err.APIError {
+	_, err := j.reportServ.InsertPdf(ctx, dto.PdfFile{
+		CreatedAt:     time.Now().Unix(),
+		CreatedBy:     "SYSTEM",
+		Branch:        branch,
+		Name:          pdfName,
+		Type:          pdfType,
+		FileName:      fileName,
+		EndReportTime: endReport,
+	})
+	return err
+}
+
+// seedBranchJob mengisi jadwal bawaan untuk branch baru, pasangan tipe dan branch yang sudah ada tidak disentuh.
+// seed awal seluruh branch dijalankan sekali oleh migrasi sehingga job yang dihapus admin tidak dibuat ulang,
+// untuk menonaktifkan job gunakan enabled = false
+func seedBranchJob(ctx context.Context, jobDao jobdao.JobDaoAssumer, branch string) rest_err.APIError {
+	jobList, _, err := jobDao.FindJob(ctx, dto.FilterJob{FilterBranch: branch}, dto.PageRequest{All: true})
+	if err != nil {
+		return err
+	}
+
+	defaultJobs := missingDefaultJobs(jobList, branch, time.Now().Unix())
+	if len(defaultJobs) == 0 {
+		return nil
+	}
+
+	_, err = jobDao.InsertManyJob(ctx, defaultJobs)
+	return err
+}
+
+// missingDefaultJobs jadwal bawaan branch yang tipenya belum ada pada jobList
+func missingDefaultJobs(jobList []dto.Job, branch string, timeNow int64) []dto.Job {
+	existType := make(map[string]bool)
+	for _, job := range jobList {
+		if strings.EqualFold(job.Branch, branch) {
+			existType[job.Type] = true
+		}
+	}
+
+	var missing []dto.Job
+	for _, job := range dto.DefaultJobs(branch, timeNow) {
+		if !existType[job.Type] {
+			missing = append(missing, job)
+		}
+	}
+	return missing
+}
+
+func (j *jobService) Changed() <-chan struct{} {
+	return j.changed
+}
+
+func (j *jobService) GetJob(ctx context.Context, jobID string) (*dto.Job, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(jobID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	job, err := j.daoJ.GetJobByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (j *jobService) FindJob(ctx context.Context, filter dto.FilterJob, page dto.PageRequest) ([]dto.Job, dto.PageInfo, rest_err.APIError) {
+	jobList, pageInfo, err := j.daoJ.FindJob(ctx, filter, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	return jobList, pageInfo, nil
+}
+
+func (j *jobService) FindJobRun(ctx context.Context, filter dto.FilterJobRun, page dto.PageRequest) ([]dto.JobRun, dto.PageInfo, rest_err.APIError) {
+	runList, pageInfo, err := j.daoJ.FindRun(ctx, filter, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	return runList, pageInfo, nil
+}
+
+// notifyChanged tidak memblokir, cukup satu tanda yang tertunda untuk memicu reload
+func (j *jobService) notifyChanged() {
+	select {
+	case j.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (j *jobService) markRunning(jobID string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running[jobID] {
+		return false
+	}
+	j.running[jobID] = true
+	return true
+}
+
+func (j *jobService) unmarkRunning(jobID string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.running, jobID)
+}
+
+func (j *jobService) isRunning(jobID string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.running[jobID]
+}
+
+func witaLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Makassar")
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// previousMonthRange mengembalikan awal bulan sebelumnya jam 00.00 sampai akhir bulan sebelumnya jam 23.59.59
+func previousMonthRange(now time.Time) (int64, int64) {
+	timeStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	timeEnd := timeStart.AddDate(0, 1, 0).Add(time.Second * -1)
+	return timeStart.Unix(), timeEnd.Unix()
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muchlist/risa_restfull/constants/jobtype"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviousMonthRange(t *testing.T) {
+	loc := time.FixedZone("WITA", 8*60*60)
+
+	start, end := previousMonthRange(time.Date(2021, time.March, 1, 1, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2021, time.February, 1, 0, 0, 0, 0, loc).Unix(), start)
+	assert.Equal(t, time.Date(2021, time.February, 28, 23, 59, 59, 0, loc).Unix(), end)
+
+	// pergantian tahun
+	start, end = previousMonthRange(time.Date(2021, time.January, 1, 1, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2020, time.December, 1, 0, 0, 0, 0, loc).Unix(), start)
+	assert.Equal(t, time.Date(2020, time.December, 31, 23, 59, 59, 0, loc).Unix(), end)
+}
+
+func TestMissingDefaultJobs(t *testing.T) {
+	// branch lain tidak mempengaruhi seed branch baru
+	jobList := []dto.Job{
+		{Type: jobtype.PingAlert, Branch: "BANJARMASIN"},
+		{Type: jobtype.SLAEscalation, Branch: "BATULICIN"},
+	}
+
+	missing := missingDefaultJobs(jobList, "BATULICIN", 100)
+	assert.Len(t, missing, len(dto.DefaultJobs("BATULICIN", 100))-1)
+	for _, job := range missing {
+		assert.NotEqual(t, jobtype.SLAEscalation, job.Type)
+		assert.Equal(t, "BATULICIN", job.Branch)
+	}
+
+	assert.Empty(t, missingDefaultJobs(dto.DefaultJobs("SAMPIT", 100), "SAMPIT", 200))
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/labelcode"
+	"github.com/muchlist/risa_restfull/utils/pdfgen"
+)
+
+const (
+	LabelCctv     = "CCTV"
+	LabelComputer = "COMPUTER"
+	LabelOther    = "OTHER"
+
+	// maxLabel batas jumlah label sekali cetak, filter perlu dipersempit jika melebihi
+	maxLabel = 500
+	// scanHistoryLimit jumlah history terbaru yang ditampilkan saat scan
+	scanHistoryLimit = 10
+)
+
+var errTooManyLabel = fmt.Errorf("jumlah label melebihi %d, persempit filter", maxLabel)
+
+func NewLabelService(
+	cctvDao cctvdao.CctvLoader,
+	computerDao computerdao.ComputerLoader,
+	otherDao otherdao.OtherLoader,
+	genDao genunitdao.GenUnitLoader,
+	histDao historydao.HistoryLoader,
+) LabelServiceAssumer {
+	return &labelService{
+		daoC:  cctvDao,
+		daoPC: computerDao,
+		daoO:  otherDao,
+		daoG:  genDao,
+		daoH:  histDao,
+	}
+}
+
+type labelService struct {
+	daoC  cctvdao.CctvLoader
+	daoPC computerdao.ComputerLoader
+	daoO  otherdao.OtherLoader
+	daoG  genunitdao.GenUnitLoader
+	daoH  historydao.HistoryLoader
+}
+
+type LabelServiceAssumer interface {
+	GenerateLabel(ctx context.Context, kind string, filter dto.FilterLabel) (*bytes.Buffer, rest_err.APIError)
+	Scan(ctx context.Context, code string) (*dto.ScanResponse, rest_err.APIError)
+}
+
+// GenerateLabel membuat pdf label QR untuk unit yang sesuai filter (unit disable tidak dicetak)
+func (l *labelService) GenerateLabel(ctx context.Context, kind string, filter dto.FilterLabel) (*bytes.Buffer, rest_err.APIError) {
+	kind = strings.ToUpper(kind)
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+
+	selected := make(map[string]bool, len(filter.FilterIDs))
+	for _, id := range filter.FilterIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			selected[id] = true
+		}
+	}
+
+	var labels []pdfgen.LabelData
+	var tooMany bool
+	add := func(id string, label pdfgen.LabelData) error {
+		if len(selected) != 0 && !selected[id] {
+			return nil
+		}
+		if len(labels) >= maxLabel {
+			tooMany = true
+			return errTooManyLabel
+		}
+		label.Code = labelcode.Sign(kind, id)
+		labels = append(labels, label)
+		return nil
+	}
+
+	var apiErr rest_err.APIError
+	switch kind {
+	case LabelCctv:
+		apiErr = l.daoC.IterateCctv(ctx, dto.FilterBranchLocIPNameDisable{
+			FilterBranch:   filter.FilterBranch,
+			FilterLocation: filter.FilterLocation,
+			FilterName:     filter.FilterName,
+		}, func(cctv dto.Cctv) error {
+			return add(cctv.ID.Hex(), pdfgen.LabelData{
+				Name:            cctv.Name,
+				InventoryNumber: cctv.InventoryNumber,
+				Location:        cctv.Location,
+				Branch:          cctv.Branch,
+			})
+		})
+	case LabelComputer:
+		apiErr = l.daoPC.IteratePc(ctx, dto.FilterComputer{
+			FilterBranch:         filter.FilterBranch,
+			FilterLocation:       filter.FilterLocation,
+			FilterDivision:       filter.FilterDivision,
+			FilterName:           filter.FilterName,
+			FilterSeatManagement: -1,
+		}, func(pc dto.Computer) error {
+			return add(pc.ID.Hex(), pdfgen.LabelData{
+				Name:            pc.Name,
+				InventoryNumber: pc.InventoryNumber,
+				Location:        pc.Location,
+				Branch:          pc.Branch,
+			})
+		})
+	case LabelOther:
+		apiErr = l.daoO.IterateOther(ctx, dto.FilterOther{
+			FilterBranch:      filter.FilterBranch,
+			FilterSubCategory: filter.FilterSubCategory,
+			FilterLocation:    filter.FilterLocation,
+			FilterDivision:    filter.FilterDivision,
+			FilterName:        filter.FilterName,
+		}, func(other dto.Other) error {
+			return add(other.ID.Hex(), pdfgen.LabelData{
+				Name:            other.Name,
+				InventoryNumber: other.InventoryNumber,
+				Location:        other.Location,
+				Branch:          other.Branch,
+			})
+		})
+	default:
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("kategori label tidak tersedia. gunakan %s", []string{LabelCctv, LabelComputer, LabelOther}))
+	}
+
+	if tooMany {
+		return nil, rest_err.NewBadRequestError(errTooManyLabel.Error())
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if len(labels) == 0 {
+		return nil, rest_err.NewNotFoundError("tidak ada unit yang sesuai filter")
+	}
+
+	title := fmt.Sprintf("Label %s %s", kind, filter.FilterBranch)
+	pdfBuffer, err := pdfgen.GenerateLabelPDF(title, labels)
+	if err != nil {
+		logger.Error("Gagal membuat pdf label (GenerateLabel)", err)
+		return nil, rest_err.NewInternalServerError("gagal membuat pdf label", err)
+	}
+	return &pdfBuffer, nil
+}
+
+// Scan memverifikasi kode label lalu mengembalikan unit, kasus terbuka dan history terbarunya
+func (l *labelService) Scan(ctx context.Context, code string) (*dto.ScanResponse, rest_err.APIError) {
+	kind, oid, apiErr := labelcode.Verify(code)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := dto.ScanResponse{
+		Kind: kind,
+	}
+
+	switch kind {
+	case LabelCctv:
+		cctv, apiErr := l.daoC.GetCctvByID(ctx, oid, "")
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		result.Category = category.Cctv
+		result.Unit = cctv
+	case LabelComputer:
+		pc, apiErr := l.daoPC.GetPcByID(ctx, oid, "")
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		result.Category = category.PC
+		result.Unit = pc
+	case LabelOther:
+		other, apiErr := l.daoO.GetOtherByID(ctx, oid, "")
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		result.Category = other.SubCategory
+		result.Unit = other
+	default:
+		return nil, rest_err.NewBadRequestError("Kode label tidak valid")
+	}
+
+	// unit lama bisa saja belum memiliki gen_unit, cukup tampilkan tanpa kasus
+	result.Cases = []dto.Case{}
+	unit, apiErr := l.daoG.GetUnitByID(ctx, oid.Hex(), "")
+	if apiErr != nil && apiErr.Status() != http.StatusNotFound {
+		return nil, apiErr
+	}
+	if unit != nil && unit.Cases != nil {
+		result.Cases = unit.Cases
+	}
+
+	histories, apiErr := l.daoH.FindHistoryForParent(ctx, oid.Hex())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if len(histories) > scanHistoryLimit {
+		histories = histories[:scanHistoryLimit]
+	}
+	result.Histories = histories
+
+	return &result, nil
+}
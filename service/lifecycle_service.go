@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/hwlist"
+	"github.com/muchlist/risa_restfull/constants/lifecycle"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+const (
+	lifecycleWindowKey = "LIFECYCLE_WARRANTY_DAYS"
+	// defaultLifecycleWindow jumlah hari sebelum garansi / EOL habis aset mulai ditandai
+	defaultLifecycleWindow = 60
+	maxLifecycleWindow     = 365
+	// lifecycleNotifyNames jumlah nama aset yang ditulis pada pesan notifikasi
+	lifecycleNotifyNames = 5
+
+	secondsPerDay  = 24 * 60 * 60
+	secondsPerYear = 365 * secondsPerDay
+)
+
+func NewLifecycleService(
+	cctvDao cctvdao.CctvLoader,
+	computerDao computerdao.ComputerLoader,
+	otherDao otherdao.OtherLoader,
+	userDao userdao.UserLoader,
+	fcmClient fcm.ClientAssumer,
+) LifecycleServiceAssumer {
+	return &lifecycleService{
+		daoC:      cctvDao,
+		daoPC:     computerDao,
+		daoO:      otherDao,
+		daoU:      userDao,
+		fcmClient: fcmClient,
+	}
+}
+
+type lifecycleService struct {
+	daoC      cctvdao.CctvLoader
+	daoPC     computerdao.ComputerLoader
+	daoO      otherdao.OtherLoader
+	daoU      userdao.UserLoader
+	fcmClient fcm.ClientAssumer
+}
+
+type LifecycleServiceAssumer interface {
+	FindFlagged(ctx context.Context, branch string, withinDays int) ([]dto.LifecycleFlag, rest_err.APIError)
+	NotifyBranch(ctx context.Context, branch string) (int, rest_err.APIError)
+	Report(ctx context.Context, branch string) (*dto.LifecycleReport, rest_err.APIError)
+}
+
+// lifecycleAsset bentuk umum cctv, computer dan other untuk dievaluasi
+type lifecycleAsset struct {
+	ID        string
+	Category  string
+	Name      string
+	Branch    string
+	Location  string
+	OS        string
+	Date      int64
+	Lifecycle dto.Lifecycle
+}
+
+// FindFlagged mengembalikan aset aktif yang garansinya / EOL nya habis dalam withinDays hari,
+// sudah habis, atau menjalankan OS yang sudah tidak didukung
+func (l *lifecycleService) FindFlagged(ctx context.Context, branch string, withinDays int) ([]dto.LifecycleFlag, rest_err.APIError) {
+	if withinDays <= 0 {
+		withinDays = defaultLifecycleWindow
+	}
+	if withinDays > maxLifecycleWindow {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("rentang hari maksimal %d", maxLifecycleWindow))
+	}
+
+	now := time.Now().Unix()
+	within := int64(withinDays) * secondsPerDay
+	osEOL := hwlist.GetPCOSEndOfLife()
+
+	flags := []dto.LifecycleFlag{}
+	err := l.iterateAsset(ctx, branch, func(asset lifecycleAsset) {
+		reasons := lifecycleReasons(now, within, asset.Lifecycle, osEOL[asset.OS])
+		if len(reasons) == 0 {
+			return
+		}
+		flags = append(flags, dto.LifecycleFlag{
+			ID:             asset.ID,
+			Category:       asset.Category,
+			Name:           asset.Name,
+			Branch:         asset.Branch,
+			Location:       asset.Location,
+			OS:             asset.OS,
+			WarrantyExpiry: asset.Lifecycle.WarrantyExpiry,
+			OSEndOfLife:    osEOL[asset.OS],
+			Reasons:        reasons,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// NotifyBranch mengirim ringkasan aset yang ditandai ke seluruh user cabang, dijalankan oleh job LIFECYCLE-ALERT
+func (l *lifecycleService) NotifyBranch(ctx context.Context, branch string) (int, rest_err.APIError) {
+	flags, err := l.FindFlagged(ctx, branch, lifecycleWindowDays(os.Getenv(lifecycleWindowKey)))
+	if err != nil {
+		return 0, err
+	}
+	if len(flags) == 0 {
+		return 0, nil
+	}
+
+	users, err := l.daoU.FindUser(ctx, branch)
+	if err != nil {
+		logger.Error("mendapatkan user gagal saat menambahkan fcm (NotifyBranch lifecycle)", err)
+		return 0, err
+	}
+	var tokens []string
+	for _, u := range users {
+		if u.FcmToken != "" {
+			tokens = append(tokens, u.FcmToken)
+		}
+	}
+
+	countReason := make(map[string]int)
+	var names []string
+	for _, flag := range flags {
+		for _, reason := range flag.Reasons {
+			countReason[reason]++
+		}
+		if len(names) < lifecycleNotifyNames {
+			names = append(names, flag.Name)
+		}
+	}
+	var summary []string
+	for _, reason := range []string{lifecycle.OSEndOfLife, lifecycle.EndOfLife, lifecycle.WarrantyExpired, lifecycle.OSEndOfLifeSoon, lifecycle.WarrantyExpiring} {
+		if countReason[reason] != 0 {
+			summary = append(summary, fmt.Sprintf("%s %d", reason, countReason[reason]))
+		}
+	}
+	if len(flags) > len(names) {
+		names = append(names, fmt.Sprintf("dan %d lainnya", len(flags)-len(names)))
+	}
+
+	l.fcmClient.SendMessage(fcm.Payload{
+		Title:          fmt.Sprintf("%d aset perlu peremajaan", len(flags)),
+		Message:        fmt.Sprintf("%s : %s", strings.Join(summary, ", "), strings.Join(names, ", ")),
+		ReceiverTokens: tokens,
+	})
+
+	return len(flags), nil
+}
+
+// Report mengelompokkan aset aktif per kategori berdasarkan umur dan status lifecycle untuk perencanaan anggaran
+func (l *lifecycleService) Report(ctx context.Context, branch string) (*dto.LifecycleReport, rest_err.APIError) {
+	now := time.Now().Unix()
+	osEOL := hwlist.GetPCOSEndOfLife()
+
+	groups := make(map[string]*dto.LifecycleReportGroup)
+	err := l.iterateAsset(ctx, branch, func(asset lifecycleAsset) {
+		group, ok := groups[asset.Category]
+		if !ok {
+			group = &dto.LifecycleReportGroup{
+				Category:  asset.Category,
+				ByAge:     make(map[string]int),
+				ByStatus:  make(map[string]int),
+				CostByAge: make(map[string]int64),
+			}
+			groups[asset.Category] = group
+		}
+		age := lifecycleAge(now, asset.Lifecycle.PurchaseDate, asset.Date)
+		group.Total++
+		group.TotalCost += asset.Lifecycle.Cost
+		group.ByAge[age]++
+		group.CostByAge[age] += asset.Lifecycle.Cost
+		group.ByStatus[lifecycleStatus(now, asset.Lifecycle, osEOL[asset.OS])]++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := dto.LifecycleReport{
+		Branch:      strings.ToUpper(branch),
+		GeneratedAt: now,
+		Groups:      []dto.LifecycleReportGroup{},
+	}
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Category < report.Groups[j].Category
+	})
+	return &report, nil
+}
+
+// iterateAsset membaca cctv, computer dan other aktif pada cabang secara berurutan
+func (l *lifecycleService) iterateAsset(ctx context.Context, branch string, fn func(asset lifecycleAsset)) rest_err.APIError {
+	branch = strings.ToUpper(branch)
+
+	if err := l.daoC.IterateCctv(ctx, dto.FilterBranchLocIPNameDisable{FilterBranch: branch}, func(cctv dto.Cctv) error {
+		fn(lifecycleAsset{
+			ID:        cctv.ID.Hex(),
+			Category:  category.Cctv,
+			Name:      cctv.Name,
+			Branch:    cctv.Branch,
+			Location:  cctv.Location,
+			Date:      cctv.Date,
+			Lifecycle: cctv.Lifecycle,
+		})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := l.daoPC.IteratePc(ctx, dto.FilterComputer{FilterBranch: branch, FilterSeatManagement: -1}, func(pc dto.Computer) error {
+		fn(lifecycleAsset{
+			ID:        pc.ID.Hex(),
+			Category:  category.PC,
+			Name:      pc.Name,
+			Branch:    pc.Branch,
+			Location:  pc.Location,
+			OS:        pc.OS,
+			Date:      pc.Date,
+			Lifecycle: pc.Lifecycle,
+		})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return l.daoO.IterateOther(ctx, dto.FilterOther{FilterBranch: branch}, func(other dto.Other) error {
+		fn(lifecycleAsset{
+			ID:        other.ID.Hex(),
+			Category:  other.SubCategory,
+			Name:      other.Name,
+			Branch:    other.Branch,
+			Location:  other.Location,
+			Date:      other.Date,
+			Lifecycle: other.Lifecycle,
+		})
+		return nil
+	})
+}
+
+// lifecycleWindowDays membaca rentang hari notifikasi, nilai kosong atau tidak valid memakai default
+func lifecycleWindowDays(value string) int {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > maxLifecycleWindow {
+		return defaultLifecycleWindow
+	}
+	return days
+}
+
+// lifecycleReasons alasan aset ditandai, osEOL 0 berarti OS tidak diketahui masa dukungannya
+func lifecycleReasons(now int64, within int64, lc dto.Lifecycle, osEOL int64) []string {
+	var reasons []string
+	switch {
+	case osEOL != 0 && osEOL <= now:
+		reasons = append(reasons, lifecycle.OSEndOfLife)
+	case osEOL != 0 && osEOL <= now+within:
+		reasons = append(reasons, lifecycle.OSEndOfLifeSoon)
+	}
+	switch {
+	case lc.WarrantyExpiry != 0 && lc.WarrantyExpiry <= now:
+		reasons = append(reasons, lifecycle.WarrantyExpired)
+	case lc.WarrantyExpiry != 0 && lc.WarrantyExpiry <= now+within:
+		reasons = append(reasons, lifecycle.WarrantyExpiring)
+	}
+	if lc.EndOfLife != 0 && lc.EndOfLife <= now+within {
+		reasons = append(reasons, lifecycle.EndOfLife)
+	}
+	return reasons
+}
+
+// lifecycleStatus satu status per aset sesuai prioritas pada constants lifecycle
+func lifecycleStatus(now int64, lc dto.Lifecycle, osEOL int64) string {
+	switch {
+	case osEOL != 0 && osEOL <= now, lc.EndOfLife != 0 && lc.EndOfLife <= now:
+		return lifecycle.StatusEOL
+	case lc.WarrantyExpiry != 0 && lc.WarrantyExpiry <= now:
+		return lifecycle.StatusWarrantyExpired
+	case lc.WarrantyExpiry != 0:
+		return lifecycle.StatusInWarranty
+	default:
+		return lifecycle.StatusUnknown
+	}
+}
+
+// lifecycleAge umur aset dari purchase_date, jika kosong memakai field date lama
+func lifecycleAge(now int64, purchaseDate int64, date int64) string {
+	since := purchaseDate
+	if since == 0 {
+		since = date
+	}
+	if since == 0 || since > now {
+		return lifecycle.AgeUnknown
+	}
+	years := (now - since) / secondsPerYear
+	switch {
+	case years < 1:
+		return lifecycle.AgeUnder1
+	case years < 3:
+		return lifecycle.Age1To3
+	case years < 5:
+		return lifecycle.Age3To5
+	default:
+		return lifecycle.AgeOver5
+	}
+}
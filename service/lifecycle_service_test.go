@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/lifecycle"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleWindowDays(t *testing.T) {
+	assert.Equal(t, defaultLifecycleWindow, lifecycleWindowDays(""))
+	assert.Equal(t, defaultLifecycleWindow, lifecycleWindowDays("abc"))
+	assert.Equal(t, defaultLifecycleWindow, lifecycleWindowDays("0"))
+	assert.Equal(t, defaultLifecycleWindow, lifecycleWindowDays("1000"))
+	assert.Equal(t, 30, lifecycleWindowDays("30"))
+}
+
+func TestLifecycleReasons(t *testing.T) {
+	now := int64(1700000000)
+	within := int64(60 * secondsPerDay)
+
+	assert.Empty(t, lifecycleReasons(now, within, dto.Lifecycle{}, 0))
+	assert.Empty(t, lifecycleReasons(now, within, dto.Lifecycle{WarrantyExpiry: now + within + 1}, 0))
+	assert.Equal(t, []string{lifecycle.WarrantyExpiring}, lifecycleReasons(now, within, dto.Lifecycle{WarrantyExpiry: now + secondsPerDay}, 0))
+	assert.Equal(t, []string{lifecycle.OSEndOfLife, lifecycle.WarrantyExpired},
+		lifecycleReasons(now, within, dto.Lifecycle{WarrantyExpiry: now - 1}, now-secondsPerYear))
+	assert.Equal(t, []string{lifecycle.OSEndOfLifeSoon, lifecycle.EndOfLife},
+		lifecycleReasons(now, within, dto.Lifecycle{EndOfLife: now + secondsPerDay}, now+secondsPerDay))
+}
+
+func TestLifecycleStatus(t *testing.T) {
+	now := int64(1700000000)
+
+	assert.Equal(t, lifecycle.StatusUnknown, lifecycleStatus(now, dto.Lifecycle{}, 0))
+	assert.Equal(t, lifecycle.StatusInWarranty, lifecycleStatus(now, dto.Lifecycle{WarrantyExpiry: now + 1}, 0))
+	assert.Equal(t, lifecycle.StatusWarrantyExpired, lifecycleStatus(now, dto.Lifecycle{WarrantyExpiry: now - 1}, 0))
+	assert.Equal(t, lifecycle.StatusEOL, lifecycleStatus(now, dto.Lifecycle{WarrantyExpiry: now + 1}, now-1))
+	assert.Equal(t, lifecycle.StatusEOL, lifecycleStatus(now, dto.Lifecycle{EndOfLife: now - 1}, 0))
+}
+
+func TestLifecycleAge(t *testing.T) {
+	now := int64(1700000000)
+
+	assert.Equal(t, lifecycle.AgeUnknown, lifecycleAge(now, 0, 0))
+	assert.Equal(t, lifecycle.AgeUnknown, lifecycleAge(now, now+1, 0))
+	assert.Equal(t, lifecycle.AgeUnder1, lifecycleAge(now, now-secondsPerDay, 0))
+	assert.Equal(t, lifecycle.Age1To3, lifecycleAge(now, 0, now-2*secondsPerYear))
+	assert.Equal(t, lifecycle.Age3To5, lifecycleAge(now, now-4*secondsPerYear, now))
+	assert.Equal(t, lifecycle.AgeOver5, lifecycleAge(now, now-6*secondsPerYear, 0))
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+)
+
+const (
+	geoFeatureCollection = "FeatureCollection"
+	geoFeature           = "Feature"
+
+	// jarak dalam meter untuk pencarian unit terdekat
+	defaultNearDistance = 500
+	maxNearDistance     = 5000
+	defaultNearLimit    = 20
+	maxNearLimit        = 100
+
+	earthRadius = 6371000.0
+)
+
+func NewMapService(
+	cctvDao cctvdao.CctvLoader,
+	computerDao computerdao.ComputerLoader,
+	otherDao otherdao.OtherLoader,
+	genDao genunitdao.GenUnitLoader,
+) MapServiceAssumer {
+	return &mapService{
+		daoC:  cctvDao,
+		daoPC: computerDao,
+		daoO:  otherDao,
+		daoG:  genDao,
+	}
+}
+
+type mapService struct {
+	daoC  cctvdao.CctvLoader
+	daoPC computerdao.ComputerLoader
+	daoO  otherdao.OtherLoader
+	daoG  genunitdao.GenUnitLoader
+}
+
+type MapServiceAssumer interface {
+	FindMap(ctx context.Context, filter dto.FilterMap) (*dto.GeoFeatureCollection, rest_err.APIError)
+	FindNear(ctx context.Context, filter dto.FilterGeoNear) (*dto.GeoFeatureCollection, rest_err.APIError)
+}
+
+// FindMap mengembalikan seluruh unit aktif cabang yang memiliki koordinat sebagai GeoJSON FeatureCollection
+// lengkap dengan status ping dan jumlah kasus terbuka dari gen_unit
+func (m *mapService) FindMap(ctx context.Context, filter dto.FilterMap) (*dto.GeoFeatureCollection, rest_err.APIError) {
+	filter.FilterBranch = strings.ToUpper(filter.FilterBranch)
+	filter.FilterCategory = strings.ToUpper(filter.FilterCategory)
+
+	withCctv, withPC, subCategories, apiErr := mapCategories(filter.FilterCategory)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var categories []string
+	if withCctv {
+		categories = append(categories, category.Cctv)
+	}
+	if withPC {
+		categories = append(categories, category.PC)
+	}
+	categories = append(categories, subCategories...)
+	units, apiErr := m.genUnits(ctx, filter.FilterBranch, categories)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	collection := newFeatureCollection()
+	add := func(geo *dto.GeoPoint, props dto.GeoProperties) {
+		if geo == nil {
+			return
+		}
+		collection.Features = append(collection.Features, newFeature(*geo, props, units))
+	}
+
+	if withCctv {
+		if err := m.daoC.IterateCctv(ctx, dto.FilterBranchLocIPNameDisable{FilterBranch: filter.FilterBranch}, func(cctv dto.Cctv) error {
+			add(cctv.Geo, dto.GeoProperties{ID: cctv.ID.Hex(), Category: category.Cctv, Name: cctv.Name, Branch: cctv.Branch, Location: cctv.Location, IP: cctv.IP})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if withPC {
+		if err := m.daoPC.IteratePc(ctx, dto.FilterComputer{FilterBranch: filter.FilterBranch, FilterSeatManagement: -1}, func(pc dto.Computer) error {
+			add(pc.Geo, dto.GeoProperties{ID: pc.ID.Hex(), Category: category.PC, Name: pc.Name, Branch: pc.Branch, Location: pc.Location, IP: pc.IP})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if len(subCategories) != 0 {
+		if err := m.daoO.IterateOther(ctx, dto.FilterOther{FilterBranch: filter.FilterBranch, FilterSubCategory: strings.Join(subCategories, ",")}, func(other dto.Other) error {
+			add(other.Geo, dto.GeoProperties{ID: other.ID.Hex(), Category: other.SubCategory, Name: other.Name, Branch: other.Branch, Location: other.Location, IP: other.IP})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &collection, nil
+}
+
+// FindNear mencari unit cctv, computer dan other terdekat dari posisi teknisi, diurutkan berdasarkan jarak
+func (m *mapService) FindNear(ctx context.Context, filter dto.FilterGeoNear) (*dto.GeoFeatureCollection, rest_err.APIError) {
+	if filter.Lat < -90 || filter.Lat > 90 || filter.Lon < -180 || filter.Lon > 180 {
+		return nil, rest_err.NewBadRequestError("lat dan lon harus berupa koordinat yang valid")
+	}
+	if filter.MaxDistance <= 0 {
+		filter.MaxDistance = defaultNearDistance
+	}
+	if filter.MaxDistance > maxNearDistance {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("radius maksimal %d meter", maxNearDistance))
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultNearLimit
+	}
+	if filter.Limit > maxNearLimit {
+		filter.Limit = maxNearLimit
+	}
+
+	type nearUnit struct {
+		geo   dto.GeoPoint
+		props dto.GeoProperties
+	}
+	var nearUnits []nearUnit
+	add := func(geo *dto.GeoPoint, props dto.GeoProperties) {
+		if geo == nil {
+			return
+		}
+		props.Distance = math.Round(geoDistance(filter.Lat, filter.Lon, geo.Lat(), geo.Lon()))
+		nearUnits = append(nearUnits, nearUnit{geo: *geo, props: props})
+	}
+
+	cctvList, apiErr := m.daoC.FindCctvNear(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	for _, cctv := range cctvList {
+		add(cctv.Geo, dto.GeoProperties{ID: cctv.ID.Hex(), Category: category.Cctv, Name: cctv.Name, Branch: cctv.Branch, Location: cctv.Location, IP: cctv.IP})
+	}
+
+	pcList, apiErr := m.daoPC.FindPcNear(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	for _, pc := range pcList {
+		add(pc.Geo, dto.GeoProperties{ID: pc.ID.Hex(), Category: category.PC, Name: pc.Name, Branch: pc.Branch, Location: pc.Location, IP: pc.IP})
+	}
+
+	otherList, apiErr := m.daoO.FindOtherNear(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	for _, other := range otherList {
+		add(other.Geo, dto.GeoProperties{ID: other.ID.Hex(), Category: other.SubCategory, Name: other.Name, Branch: other.Branch, Location: other.Location, IP: other.IP})
+	}
+
+	// setiap collection sudah terurut, gabungan ketiganya diurutkan ulang lalu dipotong sesuai limit
+	sort.SliceStable(nearUnits, func(i, j int) bool {
+		return nearUnits[i].props.Distance < nearUnits[j].props.Distance
+	})
+	if int64(len(nearUnits)) > filter.Limit {
+		nearUnits = nearUnits[:filter.Limit]
+	}
+
+	var categories []string
+	for _, unit := range nearUnits {
+		if !sfunc.InSlice(unit.props.Category, categories) {
+			categories = append(categories, unit.props.Category)
+		}
+	}
+	units, apiErr := m.genUnits(ctx, filter.FilterBranch, categories)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	collection := newFeatureCollection()
+	for _, unit := range nearUnits {
+		collection.Features = append(collection.Features, newFeature(unit.geo, unit.props, units))
+	}
+	return &collection, nil
+}
+
+// genUnits memuat gen_unit aktif dengan key id unit untuk status ping dan jumlah kasus
+func (m *mapService) genUnits(ctx context.Context, branch string, categories []string) (map[string]dto.GenUnitResponse, rest_err.APIError) {
+	units := make(map[string]dto.GenUnitResponse)
+	for _, cat := range categories {
+		list, _, err := m.daoG.FindUnit(ctx, dto.GenUnitFilter{
+			Branch:   branch,
+			Category: cat,
+		}, dto.PageRequest{All: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range list {
+			units[unit.ID] = unit
+		}
+	}
+	return units, nil
+}
+
+// mapCategories menerjemahkan filter category menjadi sumber data, kosong berarti semua kategori
+func mapCategories(cat string) (bool, bool, []string, rest_err.APIError) {
+	switch {
+	case cat == "":
+		return true, true, masterdata.Values(masterkind.SubCategory), nil
+	case cat == category.Cctv:
+		return true, false, nil, nil
+	case cat == category.PC:
+		return false, true, nil, nil
+	case masterdata.IsAvailable(masterkind.SubCategory, cat):
+		return false, false, []string{cat}, nil
+	default:
+		available := append([]string{category.Cctv, category.PC}, masterdata.Values(masterkind.SubCategory)...)
+		return false, false, nil, rest_err.NewBadRequestError(fmt.Sprintf("category tidak tersedia. gunakan %s", available))
+	}
+}
+
+func newFeatureCollection() dto.GeoFeatureCollection {
+	return dto.GeoFeatureCollection{
+		Type:     geoFeatureCollection,
+		Features: []dto.GeoFeature{},
+	}
+}
+
+func newFeature(geo dto.GeoPoint, props dto.GeoProperties, units map[string]dto.GenUnitResponse) dto.GeoFeature {
+	if unit, ok := units[props.ID]; ok {
+		props.LastPing = unit.LastPing
+		props.AlertState = unit.AlertState
+		props.CasesSize = unit.CasesSize
+	}
+	return dto.GeoFeature{
+		Type:       geoFeature,
+		Geometry:   geo,
+		Properties: props,
+	}
+}
+
+// geoDistance jarak haversine dalam meter antara dua koordinat
+func geoDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoDistance(t *testing.T) {
+	assert.Equal(t, float64(0), geoDistance(-3.3186, 114.5944, -3.3186, 114.5944))
+	// satu derajat lintang sekitar 111,2 km
+	assert.InDelta(t, 111195, geoDistance(0, 114, 1, 114), 1)
+	assert.InDelta(t, geoDistance(-3.3, 114.5, -3.31, 114.51), geoDistance(-3.31, 114.51, -3.3, 114.5), 0.001)
+}
+
+func TestMapCategories(t *testing.T) {
+	withCctv, withPC, subCategories, err := mapCategories("")
+	assert.Nil(t, err)
+	assert.True(t, withCctv)
+	assert.True(t, withPC)
+	assert.Equal(t, category.GetSubCategoryAvailable(), subCategories)
+
+	withCctv, withPC, subCategories, err = mapCategories(category.Cctv)
+	assert.Nil(t, err)
+	assert.True(t, withCctv)
+	assert.False(t, withPC)
+	assert.Empty(t, subCategories)
+
+	sub := category.GetSubCategoryAvailable()[0]
+	withCctv, withPC, subCategories, err = mapCategories(sub)
+	assert.Nil(t, err)
+	assert.False(t, withCctv)
+	assert.False(t, withPC)
+	assert.Equal(t, []string{sub}, subCategories)
+
+	_, _, _, err = mapCategories("TIDAKADA")
+	assert.NotNil(t, err)
+}
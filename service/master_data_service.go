@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/dao/jobdao"
+	"github.com/muchlist/risa_restfull/dao/masterdatadao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMasterDataService juga mendaftarkan database sebagai sumber cache masterdata
+func NewMasterDataService(masterDao masterdatadao.MasterDataDaoAssumer, jobDao jobdao.JobDaoAssumer, txDao transactiondao.TransactionDaoAssumer) MasterDataServiceAssumer {
+	s := &masterDataService{
+		daoM: masterDao,
+		daoJ: jobDao,
+		daoT: txDao,
+	}
+	masterdata.SetLoader(s.loadEntries)
+	return s
+}
+
+type masterDataService struct {
+	daoM masterdatadao.MasterDataDaoAssumer
+	daoJ jobdao.JobDaoAssumer
+	daoT transactiondao.TransactionDaoAssumer
+}
+
+type MasterDataServiceAssumer interface {
+	InsertMaster(ctx context.Context, user mjwt.CustomClaim, input dto.MasterDataRequest) (*string, rest_err.APIError)
+	EditMaster(ctx context.Context, user mjwt.CustomClaim, id string, input dto.MasterDataEditRequest) (*dto.MasterData, rest_err.APIError)
+	DeleteMaster(ctx context.Context, id string) rest_err.APIError
+	FindMaster(ctx context.Context, filter dto.FilterMasterData) ([]dto.MasterData, rest_err.APIError)
+	WarmCache(ctx context.Context) rest_err.APIError
+}
+
+func (m *masterDataService) InsertMaster(ctx context.Context, user mjwt.CustomClaim, input dto.MasterDataRequest) (*string, rest_err.APIError) {
+	input.Kind = strings.ToUpper(input.Kind)
+	input.Branch = strings.ToUpper(input.Branch)
+	input.Lang = strings.ToLower(input.Lang)
+	input.Value = normalizeMasterValue(input.Kind, input.Value)
+
+	if err := m.checkDuplicate(ctx, primitive.NilObjectID, input.Kind, input.Value, input.Branch); err != nil {
+		return nil, err
+	}
+
+	timeNow := time.Now().Unix()
+	var insertedID *string
+	// branch baru langsung mendapatkan jadwal bawaan agar alert, purge dan sla ikut berjalan
+	err := m.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		var err rest_err.APIError
+		insertedID, err = m.daoM.InsertMaster(txCtx, dto.MasterData{
+			CreatedAt:   timeNow,
+			CreatedBy:   user.Name,
+			CreatedByID: user.Identity,
+			UpdatedAt:   timeNow,
+			UpdatedBy:   user.Name,
+			UpdatedByID: user.Identity,
+			Kind:        input.Kind,
+			Value:       input.Value,
+			Branch:      input.Branch,
+			Lang:        input.Lang,
+			Order:       input.Order,
+		})
+		if err != nil {
+			return err
+		}
+		if input.Kind == masterkind.Branch {
+			return seedBranchJob(txCtx, m.daoJ, input.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.refreshCache(ctx)
+	return insertedID, nil
+}
+
+// EditMaster nilai lama yang sudah tersimpan pada unit, stock, dll tidak ikut diganti
+func (m *masterDataService) EditMaster(ctx context.Context, user mjwt.CustomClaim, id string, input dto.MasterDataEditRequest) (*dto.MasterData, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(id)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	current, err := m.daoM.GetMasterByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+
+	// validasi branch dan angka bergantung pada kind yang tersimpan
+	request := dto.MasterDataRequest{
+		Kind:   current.Kind,
+		Value:  normalizeMasterValue(current.Kind, input.Value),
+		Branch: strings.ToUpper(input.Branch),
+		Lang:   strings.ToLower(input.Lang),
+		Order:  input.Order,
+	}
+	if errV := request.Validate(); errV != nil {
+		return nil, rest_err.NewBadRequestError(errV.Error())
+	}
+
+	if err := m.checkDuplicate(ctx, oid, request.Kind, request.Value, request.Branch); err != nil {
+		return nil, err
+	}
+
+	edited, err := m.daoM.EditMaster(ctx, dto.MasterDataEdit{
+		FilterID:    oid,
+		UpdatedAt:   time.Now().Unix(),
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		Value:       request.Value,
+		Branch:      request.Branch,
+		Lang:        request.Lang,
+		Order:       request.Order,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.refreshCache(ctx)
+	return edited, nil
+}
+
+func (m *masterDataService) DeleteMaster(ctx context.Context, id string) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(id)
+	if errT != nil {
+		return rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	current, err := m.daoM.GetMasterByID(ctx, oid)
+	if err != nil {
+		return err
+	}
+
+	// kind tanpa nilai sama sekali membuat seluruh validasi kind tersebut gagal
+	siblings, err := m.daoM.FindMaster(ctx, dto.FilterMasterData{FilterKind: current.Kind})
+	if err != nil {
+		return err
+	}
+	if len(siblings) <= 1 {
+		return rest_err.NewBadRequestError(fmt.Sprintf("%s setidaknya harus memiliki satu nilai", current.Kind))
+	}
+
+	if err := m.daoM.DeleteMaster(ctx, oid); err != nil {
+		return err
+	}
+
+	m.refreshCache(ctx)
+	return nil
+}
+
+func (m *masterDataService) FindMaster(ctx context.Context, filter dto.FilterMasterData) ([]dto.MasterData, rest_err.APIError) {
+	return m.daoM.FindMaster(ctx, filter)
+}
+
+// WarmCache memuat master data ke cache saat aplikasi mulai
+func (m *masterDataService) WarmCache(ctx context.Context) rest_err.APIError {
+	if err := masterdata.Refresh(ctx); err != nil {
+		return rest_err.NewInternalServerError("Gagal memuat master data ke cache", err)
+	}
+	return nil
+}
+
+// refreshCache dipanggil setelah perubahan agar instance ini langsung membaca nilai baru,
+// jika gagal cache ditandai kadaluarsa dan dimuat ulang pada pembacaan berikutnya
+func (m *masterDataService) refreshCache(ctx context.Context) {
+	if err := masterdata.Refresh(ctx); err != nil {
+		logger.Error("Gagal memuat ulang cache master data (refreshCache)", err)
+		masterdata.Invalidate()
+	}
+}
+
+func (m *masterDataService) checkDuplicate(ctx context.Context, id primitive.ObjectID, kind, value, branch string) rest_err.APIError {
+	existing, err := m.daoM.FindMaster(ctx, dto.FilterMasterData{FilterKind: kind})
+	if err != nil {
+		return err
+	}
+	for _, master := range existing {
+		if master.ID != id && strings.EqualFold(master.Value, value) && master.Branch == branch {
+			return rest_err.NewBadRequestError(fmt.Sprintf("%s %s sudah tersedia", kind, value))
+		}
+	}
+	return nil
+}
+
+func (m *masterDataService) loadEntries(ctx context.Context) ([]masterdata.Entry, error) {
+	masters, err := m.daoM.FindMaster(ctx, dto.FilterMasterData{})
+	if err != nil {
+		return nil, err
+	}
+	// collection kosong berarti seed belum berjalan, cache tetap memakai isi sebelumnya
+	if len(masters) == 0 {
+		return nil, errors.New("master data kosong")
+	}
+	return masterEntries(masters), nil
+}
+
+func masterEntries(masters []dto.MasterData) []masterdata.Entry {
+	entries := make([]masterdata.Entry, len(masters))
+	for i, master := range masters {
+		entries[i] = masterdata.Entry{
+			Kind:   master.Kind,
+			Value:  master.Value,
+			Branch: master.Branch,
+			Lang:   master.Lang,
+		}
+	}
+	return entries
+}
+
+// normalizeMasterValue nilai yang dicocokkan exact dengan collection lain disimpan kapital
+func normalizeMasterValue(kind string, value string) string {
+	value = strings.TrimSpace(value)
+	if sfunc.InSlice(kind, masterkind.GetUpperCaseKind()) {
+		return strings.ToUpper(value)
+	}
+	return value
+}
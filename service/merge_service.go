@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/dao/altaicheckdao"
+	"github.com/muchlist/risa_restfull/dao/altaiphycheckdao"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/pendingreportdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dao/vendorcheckdao"
+	"github.com/muchlist/risa_restfull/dao/venphycheckdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MergeParams struct {
+	GenUnit       genunitdao.GenUnitDaoAssumer
+	Cctv          cctvdao.CctvDaoAssumer
+	Computer      computerdao.ComputerDaoAssumer
+	Other         otherdao.OtherDaoAssumer
+	History       historydao.HistoryDaoAssumer
+	CheckCCTV     vendorcheckdao.CheckVendorDaoAssumer
+	CheckCCTVPhy  venphycheckdao.CheckVenPhyDaoAssumer
+	CheckAltai    altaicheckdao.CheckAltaiDaoAssumer
+	CheckAltaiPhy altaiphycheckdao.CheckAltaiPhyDaoAssumer
+	PendingReport pendingreportdao.PRAssumer
+	Tx            transactiondao.TransactionDaoAssumer
+	Search        SearchIndexer
+}
+
+func NewMergeService(params MergeParams) MergeServiceAssumer {
+	return &mergeService{
+		daoG:   params.GenUnit,
+		daoC:   params.Cctv,
+		daoPC:  params.Computer,
+		daoO:   params.Other,
+		daoH:   params.History,
+		daoT:   params.Tx,
+		servSi: params.Search,
+		refs: []unitRef{
+			{collection: "vendor_check", dao: params.CheckCCTV},
+			{collection: "ven_phy_check", dao: params.CheckCCTVPhy},
+			{collection: "altai_check", dao: params.CheckAltai},
+			{collection: "altai_phy_check", dao: params.CheckAltaiPhy},
+			{collection: "pending_report", dao: params.PendingReport},
+		},
+	}
+}
+
+type mergeService struct {
+	daoG   genunitdao.GenUnitDaoAssumer
+	daoC   cctvdao.CctvDaoAssumer
+	daoPC  computerdao.ComputerDaoAssumer
+	daoO   otherdao.OtherDaoAssumer
+	daoH   historydao.HistoryDaoAssumer
+	daoT   transactiondao.TransactionDaoAssumer
+	servSi SearchIndexer
+	refs   []unitRef
+}
+
+type MergeServiceAssumer interface {
+	Preview(ctx context.Context, loserID string, winnerID string) (*dto.MergePreview, rest_err.APIError)
+	Merge(ctx context.Context, user mjwt.CustomClaim, loserID string, winnerID string) (*dto.MergePreview, rest_err.APIError)
+}
+
+// unitRefReplacer dao dokumen yang menyimpan id unit di dalam item / equipment
+type unitRefReplacer interface {
+	CountUnitRef(ctx context.Context, branch string, unitID string) (int64, rest_err.APIError)
+	ReplaceUnitRef(ctx context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError)
+}
+
+type unitRef struct {
+	collection string
+	dao        unitRefReplacer
+}
+
+// mergePlan hasil pengecekan sebelum penggabungan, dipakai bersama oleh Preview dan Merge
+type mergePlan struct {
+	preview   dto.MergePreview
+	loserOID  primitive.ObjectID
+	winnerOID primitive.ObjectID
+	tag       []string
+	image     string
+	// winnerUnderLoser winner terhubung ke loser sebagai uplink, uplink winner dikosongkan
+	// karena loser dihapus dan winner tidak boleh menjadi uplink dirinya sendiri
+	winnerUnderLoser bool
+}
+
+// Preview menampilkan perubahan yang akan terjadi jika unit loser digabungkan ke unit winner tanpa menyimpan apapun
+func (m *mergeService) Preview(ctx context.Context, loserID string, winnerID string) (*dto.MergePreview, rest_err.APIError) {
+	plan, err := m.plan(ctx, loserID, winnerID)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.preview, nil
+}
+
+// Merge menggabungkan unit loser ke unit winner dengan kategori dan branch yang sama dalam satu transaction :
+// history dipindahkan, case terbuka ikut pindah, tag dan image digabungkan, referensi pada check dan
+// pending report diganti, unit turunan uplink dipindahkan, lalu unit loser dipindahkan ke tempat sampah
+func (m *mergeService) Merge(ctx context.Context, user mjwt.CustomClaim, loserID string, winnerID string) (*dto.MergePreview, rest_err.APIError) {
+	plan, err := m.plan(ctx, loserID, winnerID)
+	if err != nil {
+		return nil, err
+	}
+	preview := plan.preview
+
+	apiErr := m.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		if _, err := m.daoH.ReparentHistory(txCtx, loserID, winnerID, preview.Winner.Name); err != nil {
+			return err
+		}
+
+		for _, unitCase := range preview.OpenCases {
+			if _, err := m.daoG.InsertCase(txCtx, dto.GenUnitCaseRequest{
+				UnitID:       winnerID,
+				FilterBranch: preview.Branch,
+				CaseID:       unitCase.CaseID,
+				CaseNote:     unitCase.CaseNote,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(preview.TagsAdded) != 0 || preview.ImageMoved {
+			if err := m.editTagImage(txCtx, user, plan); err != nil {
+				return err
+			}
+		}
+
+		if plan.winnerUnderLoser {
+			if _, err := m.daoG.ChangeUplink(txCtx, dto.GenUnitUplinkEdit{
+				UnitID:       winnerID,
+				FilterBranch: preview.Branch,
+				Uplink:       "",
+			}); err != nil {
+				return err
+			}
+		}
+		if _, err := m.daoG.ReplaceUplink(txCtx, loserID, winnerID); err != nil {
+			return err
+		}
+
+		for _, ref := range m.refs {
+			if _, err := ref.dao.ReplaceUnitRef(txCtx, dto.UnitRefReplace{
+				Branch: preview.Branch,
+				FromID: loserID,
+				ToID:   winnerID,
+				ToName: preview.Winner.Name,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := m.deleteDetail(txCtx, user, plan); err != nil {
+			return err
+		}
+		return m.daoG.DeleteUnit(txCtx, loserID, user)
+	})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// loser sudah di tempat sampah sehingga terhapus dari index, history nya kini milik winner
+	m.servSi.IndexUnit(ctx, loserID)
+	m.servSi.IndexUnitWithHistory(ctx, winnerID)
+
+	preview.Executed = true
+	return &preview, nil
+}
+
+func (m *mergeService) plan(ctx context.Context, loserID string, winnerID string) (*mergePlan, rest_err.APIError) {
+	if loserID == winnerID {
+		return nil, rest_err.NewBadRequestError("Unit tidak dapat digabungkan dengan dirinya sendiri")
+	}
+	loserOID, errT := primitive.ObjectIDFromHex(loserID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	winnerOID, errT := primitive.ObjectIDFromHex(winnerID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	loserGen, err := m.daoG.GetUnitByID(ctx, loserID, "")
+	if err != nil {
+		return nil, err
+	}
+	winnerGen, err := m.daoG.GetUnitByID(ctx, winnerID, "")
+	if err != nil {
+		return nil, err
+	}
+	if loserGen.Category != winnerGen.Category {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("Kategori unit berbeda : %s dan %s", loserGen.Category, winnerGen.Category))
+	}
+	if loserGen.Branch != winnerGen.Branch {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("Branch unit berbeda : %s dan %s, lakukan transfer terlebih dahulu", loserGen.Branch, winnerGen.Branch))
+	}
+
+	loser, err := m.detail(ctx, loserGen.Category, loserOID)
+	if err != nil {
+		return nil, err
+	}
+	winner, err := m.detail(ctx, winnerGen.Category, winnerOID)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := m.daoH.FindHistoryForParent(ctx, loserID)
+	if err != nil {
+		return nil, err
+	}
+
+	units, _, err := m.daoG.FindUnit(ctx, dto.GenUnitFilter{Branch: loserGen.Branch}, dto.PageRequest{All: true})
+	if err != nil {
+		return nil, err
+	}
+	downlinks := 0
+	for _, unit := range units {
+		if unit.Uplink == loserID && unit.ID != winnerID {
+			downlinks++
+		}
+	}
+
+	references := make([]dto.MergeReference, len(m.refs))
+	for i, ref := range m.refs {
+		count, err := ref.dao.CountUnitRef(ctx, loserGen.Branch, loserID)
+		if err != nil {
+			return nil, err
+		}
+		references[i] = dto.MergeReference{Collection: ref.collection, Count: count}
+	}
+
+	tag, tagsAdded := mergeTags(winner.Tag, loser.Tag)
+	image := winner.Image
+	imageMoved := image == "" && loser.Image != ""
+	if imageMoved {
+		image = loser.Image
+	}
+
+	openCases := loserGen.Cases
+	if openCases == nil {
+		openCases = []dto.Case{}
+	}
+
+	return &mergePlan{
+		preview: dto.MergePreview{
+			Category:   loserGen.Category,
+			Branch:     loserGen.Branch,
+			Loser:      *loser,
+			Winner:     *winner,
+			Histories:  len(histories),
+			OpenCases:  openCases,
+			TagsAdded:  tagsAdded,
+			ImageMoved: imageMoved,
+			Downlinks:  downlinks,
+			References: references,
+		},
+		loserOID:  loserOID,
+		winnerOID: winnerOID,
+		tag:       tag,
+		image:     image,
+
+		winnerUnderLoser: winnerGen.Uplink == loserID,
+	}, nil
+}
+
+// detail mengambil ringkasan unit dari collection sesuai kategori gen_unit
+func (m *mergeService) detail(ctx context.Context, unitCategory string, oid primitive.ObjectID) (*dto.MergeUnit, rest_err.APIError) {
+	switch unitCategory {
+	case category.Cctv:
+		cctv, err := m.daoC.GetCctvByID(ctx, oid, "")
+		if err != nil {
+			return nil, err
+		}
+		return &dto.MergeUnit{ID: cctv.ID.Hex(), Name: cctv.Name, IP: cctv.IP, InventoryNumber: cctv.InventoryNumber, Tag: cctv.Tag, Image: cctv.Image}, nil
+	case category.PC:
+		pc, err := m.daoPC.GetPcByID(ctx, oid, "")
+		if err != nil {
+			return nil, err
+		}
+		return &dto.MergeUnit{ID: pc.ID.Hex(), Name: pc.Name, IP: pc.IP, InventoryNumber: pc.InventoryNumber, Tag: pc.Tag, Image: pc.Image}, nil
+	default:
+		other, err := m.daoO.GetOtherByID(ctx, oid, "")
+		if err != nil {
+			return nil, err
+		}
+		return &dto.MergeUnit{ID: other.ID.Hex(), Name: other.Name, IP: other.IP, InventoryNumber: other.InventoryNumber, Tag: other.Tag, Image: other.Image}, nil
+	}
+}
+
+func (m *mergeService) editTagImage(ctx context.Context, user mjwt.CustomClaim, plan *mergePlan) rest_err.APIError {
+	input := dto.UnitTagImageEdit{
+		ID:           plan.winnerOID,
+		FilterBranch: plan.preview.Branch,
+		Tag:          plan.tag,
+		Image:        plan.image,
+		UpdatedAt:    time.Now().Unix(),
+		UpdatedBy:    user.Name,
+		UpdatedByID:  user.Identity,
+	}
+
+	var err rest_err.APIError
+	switch plan.preview.Category {
+	case category.Cctv:
+		_, err = m.daoC.EditCctvTagImage(ctx, input)
+	case category.PC:
+		_, err = m.daoPC.EditPcTagImage(ctx, input)
+	default:
+		_, err = m.daoO.EditOtherTagImage(ctx, input)
+	}
+	return err
+}
+
+func (m *mergeService) deleteDetail(ctx context.Context, user mjwt.CustomClaim, plan *mergePlan) rest_err.APIError {
+	var err rest_err.APIError
+	switch plan.preview.Category {
+	case category.Cctv:
+		_, err = m.daoC.DeleteCctv(ctx, dto.FilterIDBranchCreateGte{
+			FilterID:     plan.loserOID,
+			FilterBranch: plan.preview.Branch,
+		}, user)
+	case category.PC:
+		_, err = m.daoPC.DeletePc(ctx, dto.FilterIDBranchCreateGte{
+			FilterID:     plan.loserOID,
+			FilterBranch: plan.preview.Branch,
+		}, user)
+	default:
+		_, err = m.daoO.DeleteOther(ctx, dto.FilterIDBranchCategoryCreateGte{
+			FilterID:          plan.loserOID,
+			FilterBranch:      plan.preview.Branch,
+			FilterSubCategory: plan.preview.Category,
+		}, user)
+	}
+	return err
+}
+
+// mergeTags menggabungkan tag tanpa duplikat dengan urutan tag winner terlebih dahulu,
+// mengembalikan tag gabungan dan tag yang baru ditambahkan dari loser
+func mergeTags(winner []string, loser []string) ([]string, []string) {
+	merged := make([]string, 0, len(winner)+len(loser))
+	added := []string{}
+	for _, tag := range winner {
+		if !sfunc.InSlice(tag, merged) {
+			merged = append(merged, tag)
+		}
+	}
+	for _, tag := range loser {
+		if tag == "" || sfunc.InSlice(tag, merged) {
+			continue
+		}
+		merged = append(merged, tag)
+		added = append(added, tag)
+	}
+	return merged, added
+}
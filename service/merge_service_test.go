@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fake dao merge memakai pola yang sama dengan fake transfer : interface di-embed,
+// fakeTx, fakeIndexer dan inTx berasal dari transfer_service_test.go
+
+type mergeGenUnitDao struct {
+	genunitdao.GenUnitDaoAssumer
+	units     dto.GenUnitResponseList
+	outsideTx bool
+	cases     []dto.GenUnitCaseRequest
+	uplinks   [][2]string
+	changed   []dto.GenUnitUplinkEdit
+	deleted   []string
+}
+
+func (f *mergeGenUnitDao) GetUnitByID(_ context.Context, unitID string, _ string) (*dto.GenUnitResponse, rest_err.APIError) {
+	for _, unit := range f.units {
+		if unit.ID == unitID {
+			return &unit, nil
+		}
+	}
+	return nil, rest_err.NewNotFoundError("unit tidak ditemukan")
+}
+
+func (f *mergeGenUnitDao) FindUnit(context.Context, dto.GenUnitFilter, dto.PageRequest) (dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
+	return f.units, dto.PageInfo{}, nil
+}
+
+func (f *mergeGenUnitDao) InsertCase(ctx context.Context, payload dto.GenUnitCaseRequest) (*dto.GenUnitResponse, rest_err.APIError) {
+	f.outsideTx = f.outsideTx || !inTx(ctx)
+	f.cases = append(f.cases, payload)
+	return &dto.GenUnitResponse{ID: payload.UnitID}, nil
+}
+
+func (f *mergeGenUnitDao) ReplaceUplink(ctx context.Context, fromUplinkID string, toUplinkID string) (int64, rest_err.APIError) {
+	f.outsideTx = f.outsideTx || !inTx(ctx)
+	f.uplinks = append(f.uplinks, [2]string{fromUplinkID, toUplinkID})
+	return 1, nil
+}
+
+func (f *mergeGenUnitDao) ChangeUplink(ctx context.Context, input dto.GenUnitUplinkEdit) (*dto.GenUnitResponse, rest_err.APIError) {
+	f.outsideTx = f.outsideTx || !inTx(ctx)
+	f.changed = append(f.changed, input)
+	return &dto.GenUnitResponse{ID: input.UnitID, Uplink: input.Uplink}, nil
+}
+
+func (f *mergeGenUnitDao) DeleteUnit(ctx context.Context, unitID string, _ mjwt.CustomClaim) rest_err.APIError {
+	f.outsideTx = f.outsideTx || !inTx(ctx)
+	f.deleted = append(f.deleted, unitID)
+	return nil
+}
+
+type mergeCctvDao struct {
+	cctvdao.CctvDaoAssumer
+	cctvs    []dto.Cctv
+	tagImage []dto.UnitTagImageEdit
+	deleted  []dto.FilterIDBranchCreateGte
+}
+
+func (f *mergeCctvDao) GetCctvByID(_ context.Context, cctvID primitive.ObjectID, _ string) (*dto.Cctv, rest_err.APIError) {
+	for _, cctv := range f.cctvs {
+		if cctv.ID == cctvID {
+			return &cctv, nil
+		}
+	}
+	return nil, rest_err.NewNotFoundError("cctv tidak ditemukan")
+}
+
+func (f *mergeCctvDao) EditCctvTagImage(_ context.Context, input dto.UnitTagImageEdit) (*dto.Cctv, rest_err.APIError) {
+	f.tagImage = append(f.tagImage, input)
+	return &dto.Cctv{ID: input.ID}, nil
+}
+
+func (f *mergeCctvDao) DeleteCctv(_ context.Context, input dto.FilterIDBranchCreateGte, _ mjwt.CustomClaim) (*dto.Cctv, rest_err.APIError) {
+	f.deleted = append(f.deleted, input)
+	return &dto.Cctv{ID: input.FilterID}, nil
+}
+
+type mergeHistoryDao struct {
+	historydao.HistoryDaoAssumer
+	histories dto.HistoryResponseMinList
+	reparent  [][3]string
+}
+
+func (f *mergeHistoryDao) FindHistoryForParent(context.Context, string) (dto.HistoryResponseMinList, rest_err.APIError) {
+	return f.histories, nil
+}
+
+func (f *mergeHistoryDao) ReparentHistory(_ context.Context, fromParentID string, toParentID string, toParentName string) (int64, rest_err.APIError) {
+	f.reparent = append(f.reparent, [3]string{fromParentID, toParentID, toParentName})
+	return int64(len(f.histories)), nil
+}
+
+type fakeUnitRef struct {
+	count    int64
+	replaced []dto.UnitRefReplace
+}
+
+func (f *fakeUnitRef) CountUnitRef(context.Context, string, string) (int64, rest_err.APIError) {
+	return f.count, nil
+}
+
+func (f *fakeUnitRef) ReplaceUnitRef(_ context.Context, input dto.UnitRefReplace) (int64, rest_err.APIError) {
+	f.replaced = append(f.replaced, input)
+	return f.count, nil
+}
+
+type mergeFixture struct {
+	service  *mergeService
+	genUnit  *mergeGenUnitDao
+	cctv     *mergeCctvDao
+	history  *mergeHistoryDao
+	ref      *fakeUnitRef
+	tx       *fakeTx
+	indexer  *fakeIndexer
+	loserID  string
+	winnerID string
+}
+
+// newMergeFixture dua cctv pada branch yang sama, loser memiliki dua case terbuka dan satu turunan uplink
+func newMergeFixture() mergeFixture {
+	loserOID := primitive.NewObjectID()
+	winnerOID := primitive.NewObjectID()
+	f := mergeFixture{
+		genUnit: &mergeGenUnitDao{units: dto.GenUnitResponseList{
+			{ID: loserOID.Hex(), Category: category.Cctv, Branch: "BANJARMASIN", Cases: []dto.Case{
+				{CaseID: "case1", CaseNote: "kamera mati"},
+				{CaseID: "case2", CaseNote: "kabel putus"},
+			}},
+			{ID: winnerOID.Hex(), Category: category.Cctv, Branch: "BANJARMASIN"},
+			{ID: "switch1", Category: category.Network, Branch: "BANJARMASIN", Uplink: loserOID.Hex()},
+		}},
+		cctv: &mergeCctvDao{cctvs: []dto.Cctv{
+			{ID: loserOID, Name: "CCTV GATE LAMA", Tag: []string{"GATE", "PTZ"}, Image: "image/cctv/lama.jpg"},
+			{ID: winnerOID, Name: "CCTV GATE", Tag: []string{"GATE"}},
+		}},
+		history: &mergeHistoryDao{histories: dto.HistoryResponseMinList{{}, {}, {}}},
+		ref:     &fakeUnitRef{count: 4},
+		tx:      &fakeTx{},
+		indexer: &fakeIndexer{},
+
+		loserID:  loserOID.Hex(),
+		winnerID: winnerOID.Hex(),
+	}
+	f.service = &mergeService{
+		daoG:   f.genUnit,
+		daoC:   f.cctv,
+		daoH:   f.history,
+		daoT:   f.tx,
+		servSi: f.indexer,
+		refs:   []unitRef{{collection: "vendor_check", dao: f.ref}},
+	}
+	return f
+}
+
+func TestMergePreview(t *testing.T) {
+	f := newMergeFixture()
+
+	preview, err := f.service.Preview(context.Background(), f.loserID, f.winnerID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, preview.Histories)
+	assert.Len(t, preview.OpenCases, 2)
+	assert.Equal(t, []string{"PTZ"}, preview.TagsAdded)
+	assert.True(t, preview.ImageMoved)
+	assert.Equal(t, 1, preview.Downlinks)
+	assert.Equal(t, []dto.MergeReference{{Collection: "vendor_check", Count: 4}}, preview.References)
+	assert.False(t, preview.Executed)
+	// preview tidak menulis apapun
+	assert.Equal(t, 0, f.tx.calls)
+	assert.Empty(t, f.history.reparent)
+}
+
+func TestMergeMovesCasesAndReferences(t *testing.T) {
+	f := newMergeFixture()
+	user := mjwt.CustomClaim{Identity: "user1", Name: "User Satu", Branch: "BANJARMASIN"}
+
+	preview, err := f.service.Merge(context.Background(), user, f.loserID, f.winnerID)
+
+	assert.Nil(t, err)
+	assert.True(t, preview.Executed)
+	assert.Equal(t, 1, f.tx.calls)
+	assert.False(t, f.genUnit.outsideTx)
+
+	// history pindah ke winner dengan nama winner
+	assert.Equal(t, [][3]string{{f.loserID, f.winnerID, "CCTV GATE"}}, f.history.reparent)
+
+	// case terbuka loser ikut pindah ke winner
+	assert.Len(t, f.genUnit.cases, 2)
+	for _, c := range f.genUnit.cases {
+		assert.Equal(t, f.winnerID, c.UnitID)
+		assert.Equal(t, "BANJARMASIN", c.FilterBranch)
+	}
+	assert.Equal(t, "case1", f.genUnit.cases[0].CaseID)
+
+	// tag digabung dan image loser dipakai karena winner belum memiliki image
+	assert.Len(t, f.cctv.tagImage, 1)
+	assert.Equal(t, []string{"GATE", "PTZ"}, f.cctv.tagImage[0].Tag)
+	assert.Equal(t, "image/cctv/lama.jpg", f.cctv.tagImage[0].Image)
+
+	// referensi uplink dan check menunjuk ke winner
+	assert.Equal(t, [][2]string{{f.loserID, f.winnerID}}, f.genUnit.uplinks)
+	assert.Empty(t, f.genUnit.changed)
+	assert.Len(t, f.ref.replaced, 1)
+	assert.Equal(t, dto.UnitRefReplace{Branch: "BANJARMASIN", FromID: f.loserID, ToID: f.winnerID, ToName: "CCTV GATE"}, f.ref.replaced[0])
+
+	// loser dipindahkan ke tempat sampah, detail dan gen_unit
+	assert.Len(t, f.cctv.deleted, 1)
+	assert.Equal(t, f.loserID, f.cctv.deleted[0].FilterID.Hex())
+	assert.Equal(t, []string{f.loserID}, f.genUnit.deleted)
+
+	assert.Equal(t, []string{f.loserID}, f.indexer.units)
+	assert.Equal(t, []string{f.winnerID}, f.indexer.unitsWithChild)
+}
+
+func TestMergeWinnerUnderLoser(t *testing.T) {
+	f := newMergeFixture()
+	f.genUnit.units[1].Uplink = f.loserID
+
+	preview, err := f.service.Merge(context.Background(), mjwt.CustomClaim{Branch: "BANJARMASIN"}, f.loserID, f.winnerID)
+
+	assert.Nil(t, err)
+	// winner tidak dihitung sebagai turunan dan uplink nya dikosongkan, bukan menunjuk ke dirinya sendiri
+	assert.Equal(t, 1, preview.Downlinks)
+	assert.Equal(t, []dto.GenUnitUplinkEdit{{UnitID: f.winnerID, FilterBranch: "BANJARMASIN", Uplink: ""}}, f.genUnit.changed)
+	assert.Equal(t, [][2]string{{f.loserID, f.winnerID}}, f.genUnit.uplinks)
+	assert.False(t, f.genUnit.outsideTx)
+}
+
+func TestMergeSkipsTagImageWhenNothingChanges(t *testing.T) {
+	f := newMergeFixture()
+	f.cctv.cctvs[1].Tag = []string{"GATE", "PTZ"}
+	f.cctv.cctvs[1].Image = "image/cctv/baru.jpg"
+
+	_, err := f.service.Merge(context.Background(), mjwt.CustomClaim{Branch: "BANJARMASIN"}, f.loserID, f.winnerID)
+
+	assert.Nil(t, err)
+	assert.Empty(t, f.cctv.tagImage)
+	assert.Equal(t, []string{f.loserID}, f.genUnit.deleted)
+}
+
+func TestMergeRejectsInvalidPair(t *testing.T) {
+	f := newMergeFixture()
+	user := mjwt.CustomClaim{Branch: "BANJARMASIN"}
+
+	// unit yang sama
+	_, err := f.service.Merge(context.Background(), user, f.loserID, f.loserID)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+
+	// branch berbeda harus ditransfer terlebih dahulu
+	f.genUnit.units[1].Branch = "KOTABARU"
+	_, err = f.service.Merge(context.Background(), user, f.loserID, f.winnerID)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+
+	// kategori berbeda
+	f.genUnit.units[1].Branch = "BANJARMASIN"
+	f.genUnit.units[1].Category = category.PC
+	_, err = f.service.Merge(context.Background(), user, f.loserID, f.winnerID)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+
+	assert.Equal(t, 0, f.tx.calls)
+	assert.Empty(t, f.genUnit.deleted)
+	assert.Empty(t, f.indexer.unitsWithChild)
+}
+
+func TestMergeTags(t *testing.T) {
+	merged, added := mergeTags([]string{"GATE", "OUTDOOR"}, []string{"OUTDOOR", "PTZ", "", "PTZ"})
+	assert.Equal(t, []string{"GATE", "OUTDOOR", "PTZ"}, merged)
+	assert.Equal(t, []string{"PTZ"}, added)
+
+	merged, added = mergeTags(nil, nil)
+	assert.Equal(t, []string{}, merged)
+	assert.Equal(t, []string{}, added)
+}
@@ -2,15 +2,14 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"github.com/muchlist/erru_utils_go/logger"
 	"github.com/muchlist/erru_utils_go/rest_err"
 	"github.com/muchlist/risa_restfull/constants/enum"
 	"github.com/muchlist/risa_restfull/constants/roles"
 	"github.com/muchlist/risa_restfull/dao/genunitdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
 	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
 	"github.com/muchlist/risa_restfull/utils/sfunc"
@@ -23,18 +22,30 @@ import (
 
 func NewOtherService(otherDao otherdao.OtherDaoAssumer,
 	histDao historydao.HistorySaver,
-	genDao genunitdao.GenUnitDaoAssumer) OtherServiceAssumer {
+	genDao genunitdao.GenUnitDaoAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	customFieldService CustomFieldServiceAssumer,
+	dataQualityService DataQualityServiceAssumer,
+	searchIndexer SearchIndexer) OtherServiceAssumer {
 	return &otherService{
-		daoO: otherDao,
-		daoH: histDao,
-		daoG: genDao,
+		daoO:   otherDao,
+		daoH:   histDao,
+		daoG:   genDao,
+		daoT:   txDao,
+		servCf: customFieldService,
+		servDq: dataQualityService,
+		servSi: searchIndexer,
 	}
 }
 
 type otherService struct {
-	daoO otherdao.OtherDaoAssumer
-	daoH historydao.HistorySaver
-	daoG genunitdao.GenUnitDaoAssumer
+	daoO   otherdao.OtherDaoAssumer
+	daoH   historydao.HistorySaver
+	daoG   genunitdao.GenUnitDaoAssumer
+	daoT   transactiondao.TransactionDaoAssumer
+	servCf CustomFieldServiceAssumer
+	servDq DataQualityServiceAssumer
+	servSi SearchIndexer
 }
 type OtherServiceAssumer interface {
 	InsertOther(ctx context.Context, user mjwt.CustomClaim, input dto.OtherRequest) (*string, rest_err.APIError)
@@ -44,7 +55,7 @@ type OtherServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.Other, rest_err.APIError)
 
 	GetOtherByID(ctx context.Context, otherID string, branchIfSpecific string) (*dto.Other, rest_err.APIError)
-	FindOther(ctx context.Context, filter dto.FilterOther) (dto.OtherResponseMinList, dto.GenUnitResponseList, rest_err.APIError)
+	FindOther(ctx context.Context, filter dto.FilterOther, page dto.PageRequest) (dto.OtherResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError)
 }
 
 func (c *otherService) InsertOther(ctx context.Context, user mjwt.CustomClaim, input dto.OtherRequest) (*string, rest_err.APIError) {
@@ -63,18 +74,22 @@ func (c *otherService) InsertOther(ctx context.Context, user mjwt.CustomClaim, i
 
 	subCategory := strings.ToUpper(input.SubCategory)
 
-	// kembalian dari golang channel
-	type result struct {
-		id  *string
-		err rest_err.APIError
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
 	}
 
-	resultChan := make(chan result, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
+	customFields, err := c.servCf.ValidateValues(ctx, subCategory, input.CustomFields)
+	if err != nil {
+		return nil, err
+	}
 
-	insertToOther := func() {
-		defer wg.Done()
+	var insertedID *string
+	err = c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
 		// Filling data
 		timeNow := time.Now().Unix()
 		data := dto.Other{
@@ -103,22 +118,22 @@ func (c *otherService) InsertOther(ctx context.Context, user mjwt.CustomClaim, i
 			Brand: input.Brand,
 			Type:  input.Type,
 			Note:  input.Note,
+
+			Lifecycle: input.Lifecycle,
+			Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+			CustomFields: customFields,
 		}
 
 		// DB
-		insertedID, err := c.daoO.InsertOther(ctx, data)
-
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
+		resultID, err := c.daoO.InsertOther(txCtx, data)
+		if err != nil {
+			return err
 		}
-	}
 
-	insertToGenUnit := func() {
-		defer wg.Done()
 		// Menambahkan juga General Unit dengan ID yang sama
 		// DB
-		insertedID, err := c.daoG.InsertUnit(ctx,
+		_, err = c.daoG.InsertUnit(txCtx,
 			dto.GenUnit{
 				ID:       idGenerated.Hex(),
 				Category: subCategory,
@@ -126,36 +141,19 @@ func (c *otherService) InsertOther(ctx context.Context, user mjwt.CustomClaim, i
 				IP:       input.IP,
 				Branch:   user.Branch,
 			})
-		resultChan <- result{
-			id:  insertedID,
-			err: err,
-		}
-	}
-
-	go insertToOther()
-	go insertToGenUnit()
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	var resultID *string
-	var errString string
-	for v := range resultChan {
-		if v.err != nil {
-			errString = errString + v.err.Message() + ". "
-		}
-		if resultID == nil {
-			resultID = v.id
+		if err != nil {
+			return err
 		}
-	}
 
-	if errString != "" {
-		return nil, rest_err.NewBadRequestError(errString)
+		insertedID = resultID
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return resultID, nil
+	c.servSi.IndexUnit(ctx, idGenerated.Hex())
+	return insertedID, nil
 }
 
 func (c *otherService) EditOther(ctx context.Context, user mjwt.CustomClaim, otherID string, input dto.OtherEditRequest) (*dto.Other, rest_err.APIError) {
@@ -175,6 +173,26 @@ func (c *otherService) EditOther(ctx context.Context, user mjwt.CustomClaim, oth
 
 	subCategory := strings.ToUpper(input.FilterSubCategory)
 
+	if err := c.servDq.ValidateUnique(ctx, user, dto.UnitUniqueCheck{
+		ID:              otherID,
+		Branch:          user.Branch,
+		IP:              input.IP,
+		InventoryNumber: input.InventoryNumber,
+		Force:           input.ForceDuplicate,
+	}); err != nil {
+		return nil, err
+	}
+
+	// custom_fields yang tidak dikirim (null) tidak merubah nilai tersimpan
+	var customFields dto.CustomFields
+	if input.CustomFields != nil {
+		validated, err := c.servCf.ValidateValues(ctx, subCategory, input.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		customFields = validated
+	}
+
 	// Filling data
 	timeNow := time.Now().Unix()
 	data := dto.OtherEdit{ // Prefer filter dan datanya dipisah dengan dua struct
@@ -199,35 +217,35 @@ func (c *otherService) EditOther(ctx context.Context, user mjwt.CustomClaim, oth
 		Type:              input.Type,
 		Note:              input.Note,
 		DisVendor:         input.DisVendor,
-	}
 
-	// DB
-	otherEdited, err := c.daoO.EditOther(ctx, data)
-	if err != nil {
-		return nil, err
+		Lifecycle: input.Lifecycle,
+		Geo:       dto.NewGeoPoint(input.LocationLat, input.LocationLon),
+
+		CustomFields: customFields,
 	}
 
-	errChan := make(chan rest_err.APIError, 2)
-	var wg sync.WaitGroup
-	wg.Add(2)
+	var edited *dto.Other
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		otherEdited, err := c.daoO.EditOther(txCtx, data)
+		if err != nil {
+			return err
+		}
 
-	editUnit := func() {
-		defer wg.Done()
 		// DB
-		_, err = c.daoG.EditUnit(ctx, otherID, dto.GenUnitEditRequest{
+		_, err = c.daoG.EditUnit(txCtx, otherID, dto.GenUnitEditRequest{
 			Category: subCategory,
 			Name:     otherEdited.Name,
 			IP:       otherEdited.IP,
 			Branch:   otherEdited.Branch,
 		})
-		errChan <- err
-	}
+		if err != nil {
+			return err
+		}
 
-	insertHistory := func() {
 		isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
-		defer wg.Done()
 		// DB
-		_, err = c.daoH.InsertHistory(ctx,
+		_, err = c.daoH.InsertHistory(txCtx,
 			dto.History{
 				ID:             primitive.NewObjectID(),
 				CreatedAt:      timeNow,
@@ -249,32 +267,19 @@ func (c *otherService) EditOther(ctx context.Context, user mjwt.CustomClaim, oth
 				Tag:            []string{},
 				Image:          "",
 			}, isVendor)
-		errChan <- err
-	}
-
-	go editUnit()
-	go insertHistory()
-
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-
-	var errMessage string
-	for err := range errChan {
 		if err != nil {
-			errMessage += ". "
+			return err
 		}
-	}
 
-	if errMessage != "" {
-		errorPartial := errors.New("partial error")
-		restErr := rest_err.NewInternalServerError(fmt.Sprintf("Mengedit %s berhasil namun : %s", subCategory, errMessage), errorPartial)
-		logger.Error(restErr.Message(), errorPartial)
-		return nil, restErr
+		edited = otherEdited
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return otherEdited, nil
+	c.servSi.IndexUnit(ctx, otherID)
+	return edited, nil
 }
 
 func (c *otherService) DeleteOther(ctx context.Context, user mjwt.CustomClaim, subCategory string, otherID string, force bool) rest_err.APIError {
@@ -289,24 +294,27 @@ func (c *otherService) DeleteOther(ctx context.Context, user mjwt.CustomClaim, s
 	if force {
 		timeMinusOneDay = int64(0)
 	}
-	// DB
-	_, err := c.daoO.DeleteOther(ctx, dto.FilterIDBranchCategoryCreateGte{
-		FilterID:          oid,
-		FilterBranch:      user.Branch,
-		FilterSubCategory: subCategory,
-		FilterCreateGTE:   timeMinusOneDay,
-	})
-	if err != nil {
-		return err
-	}
+	err := c.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		// DB
+		_, err := c.daoO.DeleteOther(txCtx, dto.FilterIDBranchCategoryCreateGte{
+			FilterID:          oid,
+			FilterBranch:      user.Branch,
+			FilterSubCategory: subCategory,
+			FilterCreateGTE:   timeMinusOneDay,
+		}, user)
+		if err != nil {
+			return err
+		}
 
-	// Delete unit_gen
-	// DB
-	err = c.daoG.DeleteUnit(ctx, otherID)
+		// Delete unit_gen
+		// DB
+		return c.daoG.DeleteUnit(txCtx, otherID, user)
+	})
 	if err != nil {
 		return err
 	}
 
+	c.servSi.IndexUnit(ctx, otherID)
 	return nil
 }
 
@@ -329,6 +337,7 @@ func (c *otherService) DisableOther(ctx context.Context, otherID string, user mj
 		return nil, err
 	}
 
+	c.servSi.IndexUnit(ctx, oid.Hex())
 	return other, nil
 }
 
@@ -413,18 +422,29 @@ func (c *otherService) GetOtherByID(ctx context.Context, otherID string, branchI
 	return otherData, nil
 }
 
-func (c *otherService) FindOther(ctx context.Context, filter dto.FilterOther) (dto.OtherResponseMinList, dto.GenUnitResponseList, rest_err.APIError) {
+func (c *otherService) FindOther(ctx context.Context, filter dto.FilterOther, page dto.PageRequest) (dto.OtherResponseMinList, dto.GenUnitResponseList, dto.PageInfo, rest_err.APIError) {
 	// cek apakah ip address valid, jika valid maka set filter.FilterName ke kosong supaya pencarian berdasarkan IP
 	if filter.FilterIP != "" {
 		if net.ParseIP(filter.FilterIP) == nil {
-			return nil, nil, rest_err.NewBadRequestError("IP Address tidak valid")
+			return nil, nil, dto.PageInfo{}, rest_err.NewBadRequestError("IP Address tidak valid")
 		}
 		filter.FilterName = ""
 	}
 
+	// custom field hanya dapat difilter pada satu sub category
+	if len(filter.FilterCustomFields) != 0 && (filter.FilterSubCategory == "" || strings.Contains(filter.FilterSubCategory, ",")) {
+		return nil, nil, dto.PageInfo{}, rest_err.NewBadRequestError("filter custom field membutuhkan satu sub category")
+	}
+	customFilter, err := c.servCf.ParseFilter(ctx, filter.FilterSubCategory, filter.FilterCustomFields)
+	if err != nil {
+		return nil, nil, dto.PageInfo{}, err
+	}
+	filter.FilterCustomFields = customFilter
+
 	// wrap golang channel
 	type resultOther struct {
 		data dto.OtherResponseMinList
+		page dto.PageInfo
 		err  rest_err.APIError
 	}
 
@@ -441,9 +461,10 @@ func (c *otherService) FindOther(ctx context.Context, filter dto.FilterOther) (d
 
 	go func() {
 		defer wg.Done()
-		otherList, err := c.daoO.FindOther(ctx, filter)
+		otherList, pageInfo, err := c.daoO.FindOther(ctx, filter, page)
 		otherListChan <- resultOther{
 			data: otherList,
+			page: pageInfo,
 			err:  err,
 		}
 	}()
@@ -460,12 +481,12 @@ func (c *otherService) FindOther(ctx context.Context, filter dto.FilterOther) (d
 			return
 		}
 
-		generalList, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+		generalList, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 			Branch:   filter.FilterBranch,
 			Category: filter.FilterSubCategory,
 			Pings:    false,
 			Name:     "",
-		})
+		}, dto.PageRequest{All: true})
 		generalListChan <- resultGeneral{
 			data: generalList,
 			err:  err,
@@ -474,14 +495,14 @@ func (c *otherService) FindOther(ctx context.Context, filter dto.FilterOther) (d
 
 	otherList := <-otherListChan
 	if otherList.err != nil {
-		return nil, nil, otherList.err
+		return nil, nil, dto.PageInfo{}, otherList.err
 	}
 
 	generalList := <-generalListChan
 	if generalList.err != nil {
-		return nil, nil, generalList.err
+		return nil, nil, dto.PageInfo{}, generalList.err
 	}
 
 	filterGeneral(&generalList.data)
-	return otherList.data, generalList.data, nil
+	return otherList.data, generalList.data, otherList.page, nil
 }
@@ -24,12 +24,14 @@ func NewPRService(
 	genDao genunitdao.GenUnitLoader,
 	userDao userdao.UserLoader,
 	fcmClient fcm.ClientAssumer,
+	searchIndexer SearchIndexer,
 ) PRServiceAssumer {
 	return &prService{
 		daoP: prDao,
 		daoG: genDao,
 		daoU: userDao,
 		fcm:  fcmClient,
+		srch: searchIndexer,
 	}
 }
 
@@ -38,6 +40,7 @@ type prService struct {
 	daoG genunitdao.GenUnitLoader
 	daoU userdao.UserLoader
 	fcm  fcm.ClientAssumer
+	srch SearchIndexer
 }
 
 type PRServiceAssumer interface {
@@ -54,7 +57,7 @@ type PRServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.PendingReportModel, rest_err.APIError)
 
 	GetPRByID(ctx context.Context, id string, branchIfSpecific string) (*dto.PendingReportModel, rest_err.APIError)
-	FindDocs(ctx context.Context, user mjwt.CustomClaim, filter dto.FilterFindPendingReport) ([]dto.PendingReportMin, rest_err.APIError)
+	FindDocs(ctx context.Context, user mjwt.CustomClaim, filter dto.FilterFindPendingReport, page dto.PageRequest) ([]dto.PendingReportMin, dto.PageInfo, rest_err.APIError)
 
 	InsertPRTemplateOne(ctx context.Context, user mjwt.CustomClaim, input dto.PendingReportTempOneRequest) (*string, rest_err.APIError)
 }
@@ -97,8 +100,12 @@ func (ps *prService) InsertPR(ctx context.Context, user mjwt.CustomClaim, input
 		Location:       input.Location,
 		Images:         nil,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return res, err
+	ps.srch.IndexPendingReport(ctx, *res)
+	return res, nil
 }
 
 func (ps *prService) EditPR(ctx context.Context, user mjwt.CustomClaim, id string, input dto.PendingReportEditRequest) (*dto.PendingReportModel, rest_err.APIError) {
@@ -107,7 +114,7 @@ func (ps *prService) EditPR(ctx context.Context, user mjwt.CustomClaim, id strin
 		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
 	}
 
-	return ps.daoP.EditPR(ctx, dto.PendingReportEditModel{
+	doc, err := ps.daoP.EditPR(ctx, dto.PendingReportEditModel{
 		FilterID:        oid,
 		FilterBranch:    user.Branch,
 		FilterTimestamp: input.FilterTimestamp,
@@ -121,6 +128,12 @@ func (ps *prService) EditPR(ctx context.Context, user mjwt.CustomClaim, id strin
 		Equipments:      input.Equipments,
 		Location:        input.Location,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	ps.srch.IndexPendingReport(ctx, id)
+	return doc, nil
 }
 
 func (ps *prService) AddParticipant(ctx context.Context, user mjwt.CustomClaim, id string, userID string, alias string) (*dto.PendingReportModel, rest_err.APIError) {
@@ -493,11 +506,11 @@ func (ps *prService) GetPRByID(ctx context.Context, id string, branchIfSpecific
 	return ps.daoP.GetPRByID(ctx, oid, branchIfSpecific)
 }
 
-func (ps *prService) FindDocs(ctx context.Context, user mjwt.CustomClaim, filter dto.FilterFindPendingReport) ([]dto.PendingReportMin, rest_err.APIError) {
+func (ps *prService) FindDocs(ctx context.Context, user mjwt.CustomClaim, filter dto.FilterFindPendingReport, page dto.PageRequest) ([]dto.PendingReportMin, dto.PageInfo, rest_err.APIError) {
 	if filter.FilterBranch == "" {
 		filter.FilterBranch = user.Branch
 	}
-	return ps.daoP.FindDoc(ctx, filter)
+	return ps.daoP.FindDoc(ctx, filter, page)
 }
 
 func (ps *prService) InsertPRTemplateOne(ctx context.Context, user mjwt.CustomClaim, input dto.PendingReportTempOneRequest) (*string, rest_err.APIError) {
@@ -589,6 +602,10 @@ func (ps *prService) InsertPRTemplateOne(ctx context.Context, user mjwt.CustomCl
 		Location:       input.Location,
 		Images:         nil,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return res, err
+	ps.srch.IndexPendingReport(ctx, *res)
+	return res, nil
 }
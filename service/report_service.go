@@ -13,7 +13,9 @@ import (
 	"github.com/muchlist/risa_restfull/dao/altaicheckdao"
 	"github.com/muchlist/risa_restfull/dao/altaiphycheckdao"
 	"github.com/muchlist/risa_restfull/dao/checkdao"
+	"github.com/muchlist/risa_restfull/dao/commentdao"
 	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/pinghistorydao"
 	"github.com/muchlist/risa_restfull/dao/reportdao"
 	"github.com/muchlist/risa_restfull/dao/stockdao"
 	"github.com/muchlist/risa_restfull/dao/vendorcheckdao"
@@ -26,6 +28,7 @@ import (
 // ReportParams berisi semua dao yang diperlukan reports service, karena sangat banyak maka dibuat struct
 type ReportParams struct {
 	History       historydao.HistoryLoader
+	Comment       commentdao.CommentLoader
 	CheckIT       checkdao.CheckLoader
 	CheckCCTV     vendorcheckdao.CheckVendorLoader
 	CheckCCTVPhy  venphycheckdao.CheckVenPhyLoader
@@ -34,6 +37,7 @@ type ReportParams struct {
 	Stock         stockdao.StockLoader
 	CheckConfig   configcheckdao.CheckConfigLoader
 	Pdf           reportdao.PdfDaoAssumer
+	PingHistory   pinghistorydao.PingHistoryLoader
 }
 
 func NewReportService(dao ReportParams) ReportServiceAssumer {
@@ -69,7 +73,7 @@ func (r *reportService) GenerateReportPDF(ctx context.Context, name string, bran
 	}
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan INFO, COMPLETE, COMPLETE BA
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       "",
@@ -77,7 +81,6 @@ func (r *reportService) GenerateReportPDF(ctx context.Context, name string, bran
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: start,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -86,7 +89,7 @@ func (r *reportService) GenerateReportPDF(ctx context.Context, name string, bran
 	}
 
 	// GET HISTORIES 1, 2, 3 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       "",
@@ -94,7 +97,6 @@ func (r *reportService) GenerateReportPDF(ctx context.Context, name string, bran
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: start - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 	if err != nil {
@@ -142,7 +144,7 @@ func (r *reportService) GenerateReportPDFStartFromLast(ctx context.Context, name
 	}
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       "",
@@ -150,7 +152,6 @@ func (r *reportService) GenerateReportPDFStartFromLast(ctx context.Context, name
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: lastPDFEndTime,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 
@@ -159,7 +160,7 @@ func (r *reportService) GenerateReportPDFStartFromLast(ctx context.Context, name
 	}
 
 	// GET HISTORIES 1, 2, 3 , 5 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       "",
@@ -167,7 +168,6 @@ func (r *reportService) GenerateReportPDFStartFromLast(ctx context.Context, name
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: lastPDFEndTime - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 	if err != nil {
@@ -211,7 +211,7 @@ func (r *reportService) GenerateReportPDFVendor(ctx context.Context, name string
 	}
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s", category.Cctv, category.Altai),
@@ -219,7 +219,6 @@ func (r *reportService) GenerateReportPDFVendor(ctx context.Context, name string
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: start,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -228,7 +227,7 @@ func (r *reportService) GenerateReportPDFVendor(ctx context.Context, name string
 	}
 
 	// GET HISTORIES 1, 2, 3, 5 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s", category.Cctv, category.Altai),
@@ -236,7 +235,6 @@ func (r *reportService) GenerateReportPDFVendor(ctx context.Context, name string
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: end - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -246,11 +244,10 @@ func (r *reportService) GenerateReportPDFVendor(ctx context.Context, name string
 
 	historiesCombined := append(historyList04, historyList123...)
 
-	vendorCheckList, err := r.dao.CheckCCTV.FindCheck(ctx, branch, dto.FilterTimeRangeLimit{
+	vendorCheckList, _, err := r.dao.CheckCCTV.FindCheck(ctx, branch, dto.FilterTimeRangeLimit{
 		FilterStart: start - (2 * 30 * 24 * 60 * 60), // batas awalnya di kurangi 2 bulan
 		FilterEnd:   end,
-		Limit:       20,
-	}, true)
+	}, dto.PageRequest{Size: 20}, true)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +281,7 @@ func (r *reportService) GenerateReportPDFVendorStartFromLast(ctx context.Context
 	}
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s", category.Cctv, category.Altai),
@@ -292,7 +289,6 @@ func (r *reportService) GenerateReportPDFVendorStartFromLast(ctx context.Context
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: lastPDFEndTime,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 
@@ -301,7 +297,7 @@ func (r *reportService) GenerateReportPDFVendorStartFromLast(ctx context.Context
 	}
 
 	// GET HISTORIES 1, 2, 3, 5 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s", category.Cctv, category.Altai),
@@ -309,7 +305,6 @@ func (r *reportService) GenerateReportPDFVendorStartFromLast(ctx context.Context
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: lastPDFEndTime - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 
@@ -319,11 +314,10 @@ func (r *reportService) GenerateReportPDFVendorStartFromLast(ctx context.Context
 
 	historiesCombined := append(historyList04, historyList123...)
 
-	vendorCheckList, err := r.dao.CheckCCTV.FindCheck(ctx, branch, dto.FilterTimeRangeLimit{
+	vendorCheckList, _, err := r.dao.CheckCCTV.FindCheck(ctx, branch, dto.FilterTimeRangeLimit{
 		FilterStart: lastPDFEndTime - (2 * 30 * 24 * 60 * 60), // batas awalnya di kurangi 2 bulan
 		FilterEnd:   currentTime,
-		Limit:       20,
-	}, true)
+	}, dto.PageRequest{Size: 20}, true)
 	if err != nil {
 		return nil, err
 	}
@@ -392,7 +386,7 @@ func (r *reportService) GenerateReportVendorDaily(ctx context.Context, name stri
 	altaiQuarter, _ := r.dao.CheckAltaiPhy.GetLastCheckCreateRange(ctx, targetMinQuarter, end, branch, true)
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s,%s", category.Cctv, category.Altai, category.OtherV),
@@ -400,7 +394,6 @@ func (r *reportService) GenerateReportVendorDaily(ctx context.Context, name stri
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: targetMinDaily,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -409,7 +402,7 @@ func (r *reportService) GenerateReportVendorDaily(ctx context.Context, name stri
 	}
 
 	// GET HISTORIES 1, 2, 3, 5 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s,%s", category.Cctv, category.Altai, category.OtherV),
@@ -417,7 +410,6 @@ func (r *reportService) GenerateReportVendorDaily(ctx context.Context, name stri
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: end - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -486,7 +478,7 @@ func (r *reportService) GenerateReportVendorDailyStartFromLast(ctx context.Conte
 	altaiQuarter, _ := r.dao.CheckAltaiPhy.GetLastCheckCreateRange(ctx, targetMinQuarter, currentTime, branch, true)
 
 	// GET HISTORIES 0, 4, 6 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s,%s", category.Cctv, category.Altai, category.OtherV),
@@ -494,7 +486,6 @@ func (r *reportService) GenerateReportVendorDailyStartFromLast(ctx context.Conte
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: lastPDFEndTime,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 
@@ -503,7 +494,7 @@ func (r *reportService) GenerateReportVendorDailyStartFromLast(ctx context.Conte
 	}
 
 	// GET HISTORIES 1, 2, 3, 5 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s, %s", category.Cctv, category.Altai, category.OtherV),
@@ -511,7 +502,6 @@ func (r *reportService) GenerateReportVendorDailyStartFromLast(ctx context.Conte
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: currentTime - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   currentTime,
-			Limit:       300,
 		},
 	)
 
@@ -559,7 +549,7 @@ func (r *reportService) GenerateReportPDFVendorMonthly(ctx context.Context, name
 	targetMinQuarter := end - 60*60*24*150 // -5 bulan
 
 	// GET HISTORIES 4 sesuai start end inputan
-	historyList04, err := r.dao.History.UnwindHistory(ctx,
+	historyList04, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s,%s", category.Cctv, category.Altai, category.OtherV),
@@ -567,7 +557,6 @@ func (r *reportService) GenerateReportPDFVendorMonthly(ctx context.Context, name
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: start,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -576,7 +565,7 @@ func (r *reportService) GenerateReportPDFVendorMonthly(ctx context.Context, name
 	}
 
 	// GET HISTORIES 1, 2, 3 sesuai end inputan dan start = end - 3 bulan
-	historyList123, err := r.dao.History.UnwindHistory(ctx,
+	historyList123, err := unwindHistoryWithComment(ctx, r.dao.History, r.dao.Comment,
 		dto.FilterBranchCatInCompleteIn{
 			FilterBranch:         branch,
 			FilterCategory:       fmt.Sprintf("%s,%s,%s", category.Cctv, category.Altai, category.OtherV),
@@ -584,7 +573,6 @@ func (r *reportService) GenerateReportPDFVendorMonthly(ctx context.Context, name
 		}, dto.FilterTimeRangeLimit{
 			FilterStart: end - (3 * 30 * 24 * 60 * 60), // 3 bulan,
 			FilterEnd:   end,
-			Limit:       300,
 		},
 	)
 
@@ -609,11 +597,25 @@ func (r *reportService) GenerateReportPDFVendorMonthly(ctx context.Context, name
 	// checklist backup config
 	lastCheckConfig, _ := r.dao.CheckConfig.GetLastCheckCreateRange(ctx, targetMinMonthly, end, branch)
 
+	// ketersediaan cctv dari ping history, laporan tetap dibuat meskipun gagal
+	var uptimeSummary *dto.UptimeBranchSummary
+	samples, err := r.dao.PingHistory.FindSamples(ctx, dto.PingSampleFilter{
+		Branch:   branch,
+		Category: category.Cctv,
+		Start:    start - maxSampleGap,
+		End:      end,
+	})
+	if err == nil {
+		summary := summarizeUptime(samples, start, end)
+		uptimeSummary = &summary
+	}
+
 	errPDF := pdfgen.GeneratePDFVendorMonthly(pdfgen.PDFReqMonth{
 		Name:        name,
 		HistoryList: historiesCombined,
 		Start:       start,
 		End:         end,
+		Uptime:      uptimeSummary,
 	}, dto.ReportResponse{
 		TargetTime:     end,
 		CctvMonthly:    cctvMonthly,
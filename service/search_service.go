@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/constants/searchtype"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/pendingreportdao"
+	"github.com/muchlist/risa_restfull/dao/stockdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/fulltext"
+	"github.com/muchlist/risa_restfull/utils/masterdata"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func NewSearchService(
+	genDao genunitdao.GenUnitLoader,
+	histDao historydao.HistoryLoader,
+	stockDao stockdao.StockLoader,
+	prDao pendingreportdao.PRAssumer,
+) SearchServiceAssumer {
+	return &searchService{
+		daoG:  genDao,
+		daoH:  histDao,
+		daoS:  stockDao,
+		daoPR: prDao,
+		index: fulltext.NewIndex(),
+	}
+}
+
+type searchService struct {
+	daoG  genunitdao.GenUnitLoader
+	daoH  historydao.HistoryLoader
+	daoS  stockdao.StockLoader
+	daoPR pendingreportdao.PRAssumer
+	index *fulltext.Index
+}
+
+type SearchServiceAssumer interface {
+	SearchIndexer
+	Search(ctx context.Context, filter dto.FilterSearch) (*dto.SearchResult, rest_err.APIError)
+	Reindex(ctx context.Context, branch string) (int, rest_err.APIError)
+	ReindexAll(ctx context.Context)
+}
+
+// SearchIndexer dipanggil service lain setelah data tersimpan agar index pencarian tetap sinkron.
+// perubahan yang tidak melalui indexer diperbaiki oleh job SEARCH-REINDEX
+type SearchIndexer interface {
+	IndexUnit(ctx context.Context, unitID string)
+	IndexUnitWithHistory(ctx context.Context, unitID string)
+	IndexHistory(ctx context.Context, historyID string)
+	IndexStock(ctx context.Context, stockID string)
+	IndexPendingReport(ctx context.Context, reportID string)
+}
+
+// Search mencari unit, history, stock dan pending report pada branch dengan ranking relevansi
+func (s *searchService) Search(ctx context.Context, filter dto.FilterSearch) (*dto.SearchResult, rest_err.APIError) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, rest_err.NewBadRequestError("query pencarian tidak boleh kosong")
+	}
+
+	var types []string
+	if filter.Types != "" {
+		for _, t := range strings.Split(strings.ToUpper(filter.Types), ",") {
+			t = strings.TrimSpace(t)
+			if !sfunc.InSlice(t, searchtype.GetSearchTypeAvailable()) {
+				return nil, rest_err.NewBadRequestError(fmt.Sprintf("tipe %s tidak tersedia. gunakan %s", t, searchtype.GetSearchTypeAvailable()))
+			}
+			types = append(types, t)
+		}
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+
+	hits, total := s.index.Search(fulltext.Query{
+		Text:   filter.Query,
+		Branch: strings.ToUpper(filter.Branch),
+		Types:  types,
+		Limit:  filter.Limit,
+	})
+
+	result := dto.SearchResult{
+		Query: filter.Query,
+		Total: total,
+		Hits:  make([]dto.SearchHit, len(hits)),
+	}
+	for i, hit := range hits {
+		result.Hits[i] = dto.SearchHit{
+			Type:    hit.Type,
+			ID:      hit.ID,
+			Branch:  hit.Branch,
+			Title:   hit.Title,
+			Snippet: fulltext.Snippet(hit.Body, filter.Query),
+			Time:    hit.Time,
+			Score:   math.Round(hit.Score*1000) / 1000,
+			Extra:   hit.Extra,
+		}
+	}
+	return &result, nil
+}
+
+// Reindex membangun ulang index pencarian branch dari database, mengembalikan jumlah dokumen
+func (s *searchService) Reindex(ctx context.Context, branch string) (int, rest_err.APIError) {
+	branch = strings.ToUpper(branch)
+	var docs []fulltext.Document
+
+	units, _, err := s.daoG.FindUnit(ctx, dto.GenUnitFilter{Branch: branch}, dto.PageRequest{All: true})
+	if err != nil {
+		return 0, err
+	}
+	for _, unit := range units {
+		docs = append(docs, unitDocument(unit))
+	}
+
+	if err := s.daoH.IterateHistory(ctx, branch, func(history dto.HistoryResponseMin) error {
+		docs = append(docs, historyDocument(history))
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	if err := s.daoS.IterateStock(ctx, dto.FilterBranchNameCatDisable{FilterBranch: branch}, func(stock dto.Stock) error {
+		docs = append(docs, stockDocument(stock))
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	reports, _, err := s.daoPR.FindDoc(ctx, dto.FilterFindPendingReport{FilterBranch: branch}, dto.PageRequest{All: true})
+	if err != nil {
+		return 0, err
+	}
+	for _, report := range reports {
+		docs = append(docs, pendingReportDocument(report))
+	}
+
+	s.index.ReplaceBranch(branch, docs)
+	return len(docs), nil
+}
+
+// ReindexAll membangun index seluruh branch, dijalankan saat aplikasi mulai
+func (s *searchService) ReindexAll(ctx context.Context) {
+	for _, branch := range masterdata.Values(masterkind.Branch) {
+		count, err := s.Reindex(ctx, branch)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Gagal membangun index pencarian %s (ReindexAll)", branch), err)
+			continue
+		}
+		logger.Info(fmt.Sprintf("index pencarian %s : %d dokumen", branch, count))
+	}
+}
+
+func (s *searchService) IndexUnit(ctx context.Context, unitID string) {
+	unit, err := s.daoG.GetUnitByID(ctx, unitID, "")
+	if err != nil || unit.Disable {
+		s.removeFromIndex(searchtype.Unit, unitID, err)
+		return
+	}
+	s.index.Upsert(unitDocument(*unit))
+}
+
+// IndexUnitWithHistory memperbarui unit beserta seluruh history nya,
+// dipakai setelah perubahan yang memindahkan banyak history sekaligus (transfer, merge, restore)
+func (s *searchService) IndexUnitWithHistory(ctx context.Context, unitID string) {
+	s.IndexUnit(ctx, unitID)
+
+	histories, err := s.daoH.FindHistoryForParent(ctx, unitID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Gagal memperbarui index pencarian history unit %s", unitID), err)
+		return
+	}
+	for _, history := range histories {
+		s.index.Upsert(historyDocument(history))
+	}
+}
+
+func (s *searchService) IndexHistory(ctx context.Context, historyID string) {
+	oid, errT := primitive.ObjectIDFromHex(historyID)
+	if errT != nil {
+		return
+	}
+	history, err := s.daoH.GetHistoryByID(ctx, oid, "")
+	if err != nil {
+		s.removeFromIndex(searchtype.History, historyID, err)
+		return
+	}
+	s.index.Upsert(historyDocument(dto.HistoryResponseMin{
+		ID:             history.ID,
+		UpdatedAt:      history.UpdatedAt,
+		Category:       history.Category,
+		Branch:         history.Branch,
+		ParentID:       history.ParentID,
+		ParentName:     history.ParentName,
+		Status:         history.Status,
+		Problem:        history.Problem,
+		ProblemResolve: history.ProblemResolve,
+		CompleteStatus: history.CompleteStatus,
+		Tag:            history.Tag,
+	}))
+}
+
+func (s *searchService) IndexStock(ctx context.Context, stockID string) {
+	oid, errT := primitive.ObjectIDFromHex(stockID)
+	if errT != nil {
+		return
+	}
+	stock, err := s.daoS.GetStockByID(ctx, oid, "")
+	if err != nil || stock.Disable || stock.Deleted {
+		s.removeFromIndex(searchtype.Stock, stockID, err)
+		return
+	}
+	s.index.Upsert(stockDocument(*stock))
+}
+
+func (s *searchService) IndexPendingReport(ctx context.Context, reportID string) {
+	oid, errT := primitive.ObjectIDFromHex(reportID)
+	if errT != nil {
+		return
+	}
+	report, err := s.daoPR.GetPRByID(ctx, oid, "")
+	if err != nil {
+		s.removeFromIndex(searchtype.PendingReport, reportID, err)
+		return
+	}
+	s.index.Upsert(pendingReportDocument(dto.PendingReportMin{
+		ID:             report.ID,
+		UpdatedAt:      report.UpdatedAt,
+		Branch:         report.Branch,
+		Number:         report.Number,
+		Title:          report.Title,
+		Date:           report.Date,
+		CompleteStatus: report.CompleteStatus,
+		Location:       report.Location,
+		DocType:        report.DocType,
+	}))
+}
+
+// removeFromIndex dokumen hanya dihapus dari index jika tidak ditemukan atau tidak aktif,
+// error database lain dicatat dan index dibiarkan sampai reindex berikutnya
+func (s *searchService) removeFromIndex(docType string, id string, err rest_err.APIError) {
+	if err != nil && err.Status() != http.StatusNotFound {
+		logger.Error(fmt.Sprintf("Gagal memperbarui index pencarian %s %s", docType, id), err)
+		return
+	}
+	s.index.Delete(docType, id)
+}
+
+func unitDocument(unit dto.GenUnitResponse) fulltext.Document {
+	return fulltext.Document{
+		Type:   searchtype.Unit,
+		ID:     unit.ID,
+		Branch: unit.Branch,
+		Title:  unit.Name,
+		Body:   strings.Join([]string{unit.Category, unit.IP}, " "),
+		Extra: map[string]string{
+			"category":    unit.Category,
+			"ip":          unit.IP,
+			"last_ping":   unit.LastPing,
+			"alert_state": unit.AlertState,
+			"cases_size":  strconv.Itoa(unit.CasesSize),
+		},
+	}
+}
+
+func historyDocument(history dto.HistoryResponseMin) fulltext.Document {
+	return fulltext.Document{
+		Type:   searchtype.History,
+		ID:     history.ID.Hex(),
+		Branch: history.Branch,
+		Title:  history.ParentName,
+		Body:   strings.Join(append([]string{history.Problem, history.ProblemResolve, history.Status}, history.Tag...), " "),
+		Time:   history.UpdatedAt,
+		Extra: map[string]string{
+			"category":        history.Category,
+			"parent_id":       history.ParentID,
+			"status":          history.Status,
+			"complete_status": strconv.Itoa(history.CompleteStatus),
+		},
+	}
+}
+
+func stockDocument(stock dto.Stock) fulltext.Document {
+	return fulltext.Document{
+		Type:   searchtype.Stock,
+		ID:     stock.ID.Hex(),
+		Branch: stock.Branch,
+		Title:  stock.Name,
+		Body:   strings.Join(append([]string{stock.StockCategory, stock.Location, stock.Note}, stock.Tag...), " "),
+		Time:   stock.UpdatedAt,
+		Extra: map[string]string{
+			"stock_category": stock.StockCategory,
+			"qty":            strconv.Itoa(stock.Qty),
+			"unit":           stock.Unit,
+		},
+	}
+}
+
+func pendingReportDocument(report dto.PendingReportMin) fulltext.Document {
+	return fulltext.Document{
+		Type:   searchtype.PendingReport,
+		ID:     report.ID.Hex(),
+		Branch: report.Branch,
+		Title:  report.Title,
+		Body:   strings.Join([]string{report.Number, report.Location, report.DocType}, " "),
+		Time:   report.Date,
+		Extra: map[string]string{
+			"number":          report.Number,
+			"doc_type":        report.DocType,
+			"complete_status": strconv.Itoa(report.CompleteStatus),
+		},
+	}
+}
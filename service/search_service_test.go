@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/searchtype"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/fulltext"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSearchDocuments(t *testing.T) {
+	historyID := primitive.NewObjectID()
+	stockID := primitive.NewObjectID()
+	reportID := primitive.NewObjectID()
+
+	docs := []fulltext.Document{
+		unitDocument(dto.GenUnitResponse{ID: "unit1", Branch: "BANJARMASIN", Category: "CCTV", Name: "CCTV GATE UTAMA", IP: "10.1.1.5"}),
+		historyDocument(dto.HistoryResponseMin{ID: historyID, Branch: "BANJARMASIN", ParentName: "CCTV GATE UTAMA", Problem: "Adaptor rusak", ProblemResolve: "ganti adaptor baru", CompleteStatus: 4}),
+		stockDocument(dto.Stock{ID: stockID, Branch: "BANJARMASIN", Name: "Adaptor 12V", StockCategory: "CCTV", Qty: 7}),
+		pendingReportDocument(dto.PendingReportMin{ID: reportID, Branch: "BANJARMASIN", Title: "Penggantian adaptor cctv gate", Number: "BA/001"}),
+	}
+
+	assert.Equal(t, searchtype.History, docs[1].Type)
+	assert.Equal(t, historyID.Hex(), docs[1].ID)
+	assert.Equal(t, "4", docs[1].Extra["complete_status"])
+	assert.Equal(t, "7", docs[2].Extra["qty"])
+	assert.Equal(t, "10.1.1.5", docs[0].Extra["ip"])
+
+	index := fulltext.NewIndex()
+	index.ReplaceBranch("BANJARMASIN", docs)
+
+	// salah ketik tetap menemukan stock, history dan pending report
+	hits, total := index.Search(fulltext.Query{Text: "adaptr", Branch: "BANJARMASIN"})
+	assert.Equal(t, 3, total)
+	assert.Equal(t, searchtype.Stock, hits[0].Type)
+
+	hits, total = index.Search(fulltext.Query{Text: "10.1.1.5", Branch: "BANJARMASIN"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, searchtype.Unit, hits[0].Type)
+}
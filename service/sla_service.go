@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/priority"
+	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/constants/slastate"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/sladao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	slaLevelNone = iota
+	slaLevelResponse
+	slaLevelResolve
+)
+
+// defaultSLAPolicies digunakan jika belum ada policy untuk category dan priority history
+var defaultSLAPolicies = map[string]dto.SLAPolicy{
+	priority.Critical: {Priority: priority.Critical, ResponseMinutes: 30, ResolveMinutes: 4 * 60},
+	priority.High:     {Priority: priority.High, ResponseMinutes: 60, ResolveMinutes: 24 * 60},
+	priority.Medium:   {Priority: priority.Medium, ResponseMinutes: 4 * 60, ResolveMinutes: 3 * 24 * 60},
+	priority.Low:      {Priority: priority.Low, ResponseMinutes: 24 * 60, ResolveMinutes: 7 * 24 * 60},
+}
+
+func NewSLAService(slaDao sladao.SLADaoAssumer,
+	histDao historydao.HistoryDaoAssumer,
+	userDao userdao.UserLoader,
+	fcmClient fcm.ClientAssumer) SLAServiceAssumer {
+	return &slaService{
+		daoS:      slaDao,
+		daoH:      histDao,
+		daoU:      userDao,
+		fcmClient: fcmClient,
+	}
+}
+
+type slaService struct {
+	daoS      sladao.SLADaoAssumer
+	daoH      historydao.HistoryDaoAssumer
+	daoU      userdao.UserLoader
+	fcmClient fcm.ClientAssumer
+}
+type SLAServiceAssumer interface {
+	EvaluateBranch(ctx context.Context, branch string) (int, rest_err.APIError)
+
+	UpsertPolicy(ctx context.Context, user mjwt.CustomClaim, input dto.SLAPolicyRequest) (*dto.SLAPolicy, rest_err.APIError)
+	DeletePolicy(ctx context.Context, policyID string) rest_err.APIError
+	FindPolicy(ctx context.Context) ([]dto.SLAPolicy, rest_err.APIError)
+}
+
+// EvaluateBranch menghitung ulang SLA history yang masih berjalan pada branch,
+// pelanggaran baru dieskalasi ke user approver. mengembalikan jumlah history yang dieskalasi
+func (s *slaService) EvaluateBranch(ctx context.Context, branch string) (int, rest_err.APIError) {
+	policies, err := s.daoS.FindPolicy(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	histories, err := s.daoH.FindHistoryForSLA(ctx, branch)
+	if err != nil {
+		return 0, err
+	}
+
+	timeNow := time.Now().Unix()
+	escalations := make(map[int][]string)
+	for _, history := range histories {
+		sla := evaluateSLA(slaSubjectFromMin(history), pickSLAPolicy(policies, history.Category, history.Priority), timeNow)
+
+		if level := slaEscalationLevel(sla); level > sla.EscalationLevel {
+			sla.EscalationLevel = level
+			sla.EscalatedAt = timeNow
+			escalations[level] = append(escalations[level], fmt.Sprintf("%s (%s)", history.ParentName, slaPriority(history.Priority)))
+		}
+
+		if sla == history.SLA {
+			continue
+		}
+		if err := s.daoH.SetSLA(ctx, history.ID, sla); err != nil {
+			logger.Error(fmt.Sprintf("Gagal menyimpan sla history %s (EvaluateBranch)", history.ID.Hex()), err)
+		}
+	}
+
+	escalated := 0
+	for _, names := range escalations {
+		escalated += len(names)
+	}
+	if escalated != 0 {
+		s.sendEscalation(ctx, branch, escalations)
+	}
+	return escalated, nil
+}
+
+// sendEscalation mengirim satu notifikasi per level eskalasi ke user approver pada branch
+func (s *slaService) sendEscalation(ctx context.Context, branch string, escalations map[int][]string) {
+	users, err := s.daoU.FindUser(ctx, branch)
+	if err != nil {
+		logger.Error("mendapatkan user gagal saat menambahkan fcm (sendEscalation)", err)
+		return
+	}
+	var tokens []string
+	for _, u := range users {
+		if u.FcmToken != "" && sfunc.InSlice(roles.RoleApprove, u.Roles) {
+			tokens = append(tokens, u.FcmToken)
+		}
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	titles := map[int]string{
+		slaLevelResponse: "%d insiden belum direspon melewati target SLA",
+		slaLevelResolve:  "%d insiden melewati target penyelesaian SLA",
+	}
+	for level, names := range escalations {
+		s.fcmClient.SendMessage(fcm.Payload{
+			Title:          fmt.Sprintf(titles[level], len(names)),
+			Message:        strings.Join(names, ", "),
+			ReceiverTokens: tokens,
+		})
+	}
+}
+
+func (s *slaService) UpsertPolicy(ctx context.Context, user mjwt.CustomClaim, input dto.SLAPolicyRequest) (*dto.SLAPolicy, rest_err.APIError) {
+	policy, err := s.daoS.UpsertPolicy(ctx, dto.SLAPolicy{
+		UpdatedAt:       time.Now().Unix(),
+		UpdatedBy:       user.Name,
+		UpdatedByID:     user.Identity,
+		Category:        input.Category,
+		Priority:        input.Priority,
+		ResponseMinutes: input.ResponseMinutes,
+		ResolveMinutes:  input.ResolveMinutes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *slaService) DeletePolicy(ctx context.Context, policyID string) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(policyID)
+	if errT != nil {
+		return rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	return s.daoS.DeletePolicy(ctx, oid)
+}
+
+func (s *slaService) FindPolicy(ctx context.Context) ([]dto.SLAPolicy, rest_err.APIError) {
+	policies, err := s.daoS.FindPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// slaSubject bagian history yang dibutuhkan untuk menghitung SLA
+type slaSubject struct {
+	DateStart      int64
+	DateEnd        int64
+	CompleteStatus int
+	Updates        []dto.HistoryUpdate
+	Current        dto.HistorySLA
+}
+
+func slaSubjectFromMin(history dto.HistoryResponseMin) slaSubject {
+	return slaSubject{
+		DateStart:      history.DateStart,
+		DateEnd:        history.DateEnd,
+		CompleteStatus: history.CompleteStatus,
+		Updates:        history.Updates,
+		Current:        history.SLA,
+	}
+}
+
+func slaPriority(prior string) string {
+	if prior == "" {
+		return priority.Default
+	}
+	return prior
+}
+
+// pickSLAPolicy memilih policy yang paling spesifik : category+priority, priority saja, lalu default
+func pickSLAPolicy(policies []dto.SLAPolicy, category string, prior string) dto.SLAPolicy {
+	prior = slaPriority(prior)
+	var priorityPolicy *dto.SLAPolicy
+	for i := range policies {
+		if policies[i].Priority != prior {
+			continue
+		}
+		if policies[i].Category == category {
+			return policies[i]
+		}
+		if policies[i].Category == "" {
+			priorityPolicy = &policies[i]
+		}
+	}
+	if priorityPolicy != nil {
+		return *priorityPolicy
+	}
+	return defaultSLAPolicies[prior]
+}
+
+func isSLAResolved(completeStatus int) bool {
+	return completeStatus == enum.HComplete ||
+		completeStatus == enum.HCompleteWithBA ||
+		completeStatus == enum.HRequestComplete
+}
+
+func isSLAPaused(completeStatus int) bool {
+	return completeStatus == enum.HPending || completeStatus == enum.HRequestPending
+}
+
+// slaRespondedAt waktu respon pertama yaitu update pertama setelah insiden dibuat.
+// insiden yang dibuat langsung dengan status selain progress dianggap sudah direspon saat dibuat
+func slaRespondedAt(updates []dto.HistoryUpdate) int64 {
+	if len(updates) == 0 {
+		return 0
+	}
+	if updates[0].CompleteStatus != enum.HProgress {
+		return updates[0].Time
+	}
+	if len(updates) > 1 {
+		return updates[1].Time
+	}
+	return 0
+}
+
+// slaPausedSeconds lama insiden berada pada status pending sampai waktu until,
+// waktu pending tidak dihitung pada target penyelesaian
+func slaPausedSeconds(updates []dto.HistoryUpdate, until int64) int64 {
+	var paused int64
+	for i, update := range updates {
+		if !isSLAPaused(update.CompleteStatus) {
+			continue
+		}
+		end := until
+		if i+1 < len(updates) && updates[i+1].Time < until {
+			end = updates[i+1].Time
+		}
+		if end > update.Time {
+			paused += end - update.Time
+		}
+	}
+	return paused
+}
+
+// evaluateSLA menghitung target dan status SLA history pada waktu now.
+// info dan data info tidak memiliki SLA, data eskalasi sebelumnya dipertahankan
+func evaluateSLA(subject slaSubject, policy dto.SLAPolicy, now int64) dto.HistorySLA {
+	if subject.CompleteStatus == enum.HInfo || subject.CompleteStatus == enum.HDataInfo {
+		return dto.HistorySLA{}
+	}
+
+	sla := dto.HistorySLA{
+		EscalationLevel: subject.Current.EscalationLevel,
+		EscalatedAt:     subject.Current.EscalatedAt,
+		RespondedAt:     slaRespondedAt(subject.Updates),
+	}
+
+	until := now
+	resolved := isSLAResolved(subject.CompleteStatus)
+	if resolved {
+		sla.ResolvedAt = subject.DateEnd
+		if sla.ResolvedAt == 0 && len(subject.Updates) != 0 {
+			sla.ResolvedAt = subject.Updates[len(subject.Updates)-1].Time
+		}
+		if sla.ResolvedAt == 0 {
+			sla.ResolvedAt = now
+		}
+		// history lama tanpa updates dianggap direspon saat diselesaikan
+		if sla.RespondedAt == 0 {
+			sla.RespondedAt = sla.ResolvedAt
+		}
+		until = sla.ResolvedAt
+	}
+
+	sla.ResponseDue = subject.DateStart + policy.ResponseMinutes*60
+	sla.ResolveDue = subject.DateStart + policy.ResolveMinutes*60 + slaPausedSeconds(subject.Updates, until)
+
+	if sla.RespondedAt == 0 {
+		sla.ResponseBreached = now > sla.ResponseDue
+	} else {
+		sla.ResponseBreached = sla.RespondedAt > sla.ResponseDue
+	}
+	sla.ResolveBreached = until > sla.ResolveDue
+
+	switch {
+	case resolved && sla.ResolveBreached:
+		sla.State = slastate.Missed
+	case resolved:
+		sla.State = slastate.Met
+	case isSLAPaused(subject.CompleteStatus):
+		sla.State = slastate.Paused
+	case sla.ResolveBreached:
+		sla.State = slastate.ResolveBreached
+	case sla.RespondedAt == 0 && sla.ResponseBreached:
+		sla.State = slastate.ResponseBreached
+	default:
+		sla.State = slastate.OnTrack
+	}
+	return sla
+}
+
+// slaEscalationLevel level eskalasi yang dibutuhkan oleh state SLA saat ini
+func slaEscalationLevel(sla dto.HistorySLA) int {
+	switch sla.State {
+	case slastate.ResolveBreached:
+		return slaLevelResolve
+	case slastate.ResponseBreached:
+		return slaLevelResponse
+	default:
+		return slaLevelNone
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/priority"
+	"github.com/muchlist/risa_restfull/constants/slastate"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+const slaHour = int64(60 * 60)
+
+// slaTestPolicy respon 1 jam, penyelesaian 10 jam
+var slaTestPolicy = dto.SLAPolicy{Priority: priority.High, ResponseMinutes: 60, ResolveMinutes: 600}
+
+func TestPickSLAPolicy(t *testing.T) {
+	policies := []dto.SLAPolicy{
+		{Category: "", Priority: priority.High, ResponseMinutes: 20},
+		{Category: "CCTV", Priority: priority.High, ResponseMinutes: 10},
+	}
+
+	assert.Equal(t, int64(10), pickSLAPolicy(policies, "CCTV", priority.High).ResponseMinutes)
+	assert.Equal(t, int64(20), pickSLAPolicy(policies, "PC", priority.High).ResponseMinutes)
+	assert.Equal(t, defaultSLAPolicies[priority.Low].ResponseMinutes, pickSLAPolicy(policies, "CCTV", priority.Low).ResponseMinutes)
+	// history lama tanpa prioritas memakai prioritas default
+	assert.Equal(t, defaultSLAPolicies[priority.Default].ResolveMinutes, pickSLAPolicy(nil, "CCTV", "").ResolveMinutes)
+}
+
+func TestEvaluateSLAOpen(t *testing.T) {
+	start := int64(1000000)
+	created := []dto.HistoryUpdate{{Time: start, CompleteStatus: enum.HProgress}}
+
+	// belum direspon dan masih dalam target
+	sla := evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HProgress, Updates: created}, slaTestPolicy, start+30*60)
+	assert.Equal(t, slastate.OnTrack, sla.State)
+	assert.Equal(t, start+slaHour, sla.ResponseDue)
+	assert.Equal(t, start+10*slaHour, sla.ResolveDue)
+
+	// belum direspon melewati target respon
+	sla = evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HProgress, Updates: created}, slaTestPolicy, start+2*slaHour)
+	assert.Equal(t, slastate.ResponseBreached, sla.State)
+	assert.Equal(t, slaLevelResponse, slaEscalationLevel(sla))
+
+	// sudah direspon terlambat, state kembali on track tetapi pelanggaran respon tetap tercatat
+	updated := append(created, dto.HistoryUpdate{Time: start + 2*slaHour, CompleteStatus: enum.HProgress})
+	sla = evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HProgress, Updates: updated}, slaTestPolicy, start+3*slaHour)
+	assert.Equal(t, slastate.OnTrack, sla.State)
+	assert.True(t, sla.ResponseBreached)
+	assert.Equal(t, start+2*slaHour, sla.RespondedAt)
+
+	// melewati target penyelesaian
+	sla = evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HProgress, Updates: updated}, slaTestPolicy, start+11*slaHour)
+	assert.Equal(t, slastate.ResolveBreached, sla.State)
+	assert.Equal(t, slaLevelResolve, slaEscalationLevel(sla))
+}
+
+func TestEvaluateSLAPendingPausesResolve(t *testing.T) {
+	start := int64(1000000)
+	updates := []dto.HistoryUpdate{
+		{Time: start, CompleteStatus: enum.HProgress},
+		{Time: start + slaHour, CompleteStatus: enum.HPending},
+		{Time: start + 6*slaHour, CompleteStatus: enum.HProgress},
+	}
+
+	// pending selama 5 jam menggeser target penyelesaian
+	sla := evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HProgress, Updates: updates}, slaTestPolicy, start+12*slaHour)
+	assert.Equal(t, start+15*slaHour, sla.ResolveDue)
+	assert.Equal(t, slastate.OnTrack, sla.State)
+
+	// selama pending tidak dieskalasi
+	sla = evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HPending, Updates: updates[:2]}, slaTestPolicy, start+20*slaHour)
+	assert.Equal(t, slastate.Paused, sla.State)
+	assert.Equal(t, slaLevelNone, slaEscalationLevel(sla))
+}
+
+func TestEvaluateSLAResolved(t *testing.T) {
+	start := int64(1000000)
+	updates := []dto.HistoryUpdate{
+		{Time: start, CompleteStatus: enum.HProgress},
+		{Time: start + 5*slaHour, CompleteStatus: enum.HComplete},
+	}
+	current := dto.HistorySLA{EscalationLevel: slaLevelResponse, EscalatedAt: start + 2*slaHour}
+
+	sla := evaluateSLA(slaSubject{DateStart: start, DateEnd: start + 5*slaHour, CompleteStatus: enum.HComplete, Updates: updates, Current: current}, slaTestPolicy, start+50*slaHour)
+	assert.Equal(t, slastate.Met, sla.State)
+	assert.Equal(t, start+5*slaHour, sla.ResolvedAt)
+	assert.Equal(t, slaLevelResponse, sla.EscalationLevel)
+
+	sla = evaluateSLA(slaSubject{DateStart: start, DateEnd: start + 12*slaHour, CompleteStatus: enum.HComplete, Updates: updates}, slaTestPolicy, start+50*slaHour)
+	assert.Equal(t, slastate.Missed, sla.State)
+
+	// info tidak memiliki SLA
+	assert.Equal(t, dto.HistorySLA{}, evaluateSLA(slaSubject{DateStart: start, CompleteStatus: enum.HInfo}, slaTestPolicy, start))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/muchlist/risa_restfull/constants/roles"
 	"github.com/muchlist/risa_restfull/dao/historydao"
 	"github.com/muchlist/risa_restfull/dao/stockdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
 	"github.com/muchlist/risa_restfull/dao/userdao"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/mjwt"
@@ -21,12 +22,16 @@ import (
 
 func NewStockService(stockDao stockdao.StockDaoAssumer,
 	histDao historydao.HistorySaver, userDao userdao.UserDaoAssumer,
-	fcmClient fcm.ClientAssumer) StockServiceAssumer {
+	fcmClient fcm.ClientAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	searchIndexer SearchIndexer) StockServiceAssumer {
 	return &stockService{
 		daoS:      stockDao,
 		daoH:      histDao,
 		daoU:      userDao,
+		daoT:      txDao,
 		fcmClient: fcmClient,
+		servSi:    searchIndexer,
 	}
 }
 
@@ -34,7 +39,9 @@ type stockService struct {
 	daoS      stockdao.StockDaoAssumer
 	daoH      historydao.HistorySaver
 	daoU      userdao.UserDaoAssumer
+	daoT      transactiondao.TransactionDaoAssumer
 	fcmClient fcm.ClientAssumer
+	servSi    SearchIndexer
 }
 type StockServiceAssumer interface {
 	InsertStock(ctx context.Context, user mjwt.CustomClaim, input dto.StockRequest) (*string, rest_err.APIError)
@@ -44,7 +51,7 @@ type StockServiceAssumer interface {
 	PutImage(ctx context.Context, user mjwt.CustomClaim, id string, imagePath string) (*dto.Stock, rest_err.APIError)
 	ChangeQtyStock(ctx context.Context, user mjwt.CustomClaim, stockID string, data dto.StockChangeRequest) (*dto.Stock, rest_err.APIError)
 	GetStockByID(ctx context.Context, stockID string, branchIfSpecific string) (*dto.Stock, rest_err.APIError)
-	FindStock(ctx context.Context, filter dto.FilterBranchNameCatDisable) (dto.StockResponseMinList, rest_err.APIError)
+	FindStock(ctx context.Context, filter dto.FilterBranchNameCatDisable, page dto.PageRequest) (dto.StockResponseMinList, dto.PageInfo, rest_err.APIError)
 	FindNeedReStock(ctx context.Context, branch string) (dto.StockResponseMinList, rest_err.APIError)
 	FindNeedReStock2(ctx context.Context, filter dto.FilterBranchCatDisable) ([]dto.Stock, rest_err.APIError)
 }
@@ -91,6 +98,8 @@ func (s *stockService) InsertStock(ctx context.Context, user mjwt.CustomClaim, i
 	if err != nil {
 		return nil, err
 	}
+	s.servSi.IndexStock(ctx, oidGenerated.Hex())
+
 	isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
 	// DB
 	_, err = s.daoH.InsertHistory(ctx, dto.History{
@@ -152,6 +161,7 @@ func (s *stockService) EditStock(ctx context.Context, user mjwt.CustomClaim, sto
 	if err != nil {
 		return nil, err
 	}
+	s.servSi.IndexStock(ctx, stockID)
 
 	return stockEdited, nil
 }
@@ -172,10 +182,11 @@ func (s *stockService) DeleteStock(ctx context.Context, user mjwt.CustomClaim, i
 		FilterID:        oid,
 		FilterBranch:    user.Branch,
 		FilterCreateGTE: timeMinusOneDay,
-	})
+	}, user)
 	if err != nil {
 		return err
 	}
+	s.servSi.IndexStock(ctx, id)
 	return nil
 }
 
@@ -191,6 +202,7 @@ func (s *stockService) DisableStock(ctx context.Context, stockID string, user mj
 	if err != nil {
 		return nil, err
 	}
+	s.servSi.IndexStock(ctx, stockID)
 
 	return stock, nil
 }
@@ -233,64 +245,72 @@ func (s *stockService) ChangeQtyStock(ctx context.Context, user mjwt.CustomClaim
 		FilterBranch: user.Branch,
 	}
 
-	// DB
-	stockEdited, err := s.daoS.ChangeQtyStock(ctx, filter, incDec)
-	if err != nil {
-		return nil, err
-	}
+	// perubahan qty dan history dalam satu transaction, jika history gagal qty ikut di-rollback
+	var stockEdited *dto.Stock
+	var history dto.History
+	err := s.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		var err rest_err.APIError
+		// DB
+		stockEdited, err = s.daoS.ChangeQtyStock(txCtx, filter, incDec)
+		if err != nil {
+			return err
+		}
 
-	// Filling Data History
-	history := dto.History{
-		ID:             primitive.NewObjectID(),
-		CreatedAt:      timeNow,
-		CreatedBy:      user.Name,
-		CreatedByID:    user.Identity,
-		UpdatedAt:      timeNow,
-		UpdatedBy:      user.Name,
-		UpdatedByID:    user.Identity,
-		Category:       category.Stock,
-		Branch:         user.Branch,
-		ParentID:       stockID,
-		ParentName:     stockEdited.Name,
-		Status:         "Change",
-		ProblemResolve: "",
-		CompleteStatus: enum.HInfo,
-		DateStart:      timeNow,
-		DateEnd:        timeNow,
-		Tag:            []string{},
-		Image:          "",
-	}
-	if data.Qty > 0 {
-		history.Problem = fmt.Sprintf("Menambahkan stok %d %s : %s", data.Qty, stockEdited.Unit, data.Note)
-	} else {
-		if stockEdited.Qty <= stockEdited.Threshold {
-			history.Problem = fmt.Sprintf("Mengurangi stok (%d) %s : %s - sisa stok %d %s (perlu restock)",
-				-data.Qty,
-				stockEdited.Unit,
-				data.Note,
-				stockEdited.Qty,
-				stockEdited.Unit,
-			)
+		// Filling Data History
+		history = dto.History{
+			ID:             primitive.NewObjectID(),
+			CreatedAt:      timeNow,
+			CreatedBy:      user.Name,
+			CreatedByID:    user.Identity,
+			UpdatedAt:      timeNow,
+			UpdatedBy:      user.Name,
+			UpdatedByID:    user.Identity,
+			Category:       category.Stock,
+			Branch:         user.Branch,
+			ParentID:       stockID,
+			ParentName:     stockEdited.Name,
+			Status:         "Change",
+			ProblemResolve: "",
+			CompleteStatus: enum.HInfo,
+			DateStart:      timeNow,
+			DateEnd:        timeNow,
+			Tag:            []string{},
+			Image:          "",
+		}
+		if data.Qty > 0 {
+			history.Problem = fmt.Sprintf("Menambahkan stok %d %s : %s", data.Qty, stockEdited.Unit, data.Note)
 		} else {
-			history.Problem = fmt.Sprintf("Mengurangi stok (%d) %s : %s - sisa stok %d %s",
-				-data.Qty,
-				stockEdited.Unit,
-				data.Note,
-				stockEdited.Qty,
-				stockEdited.Unit,
-			)
+			if stockEdited.Qty <= stockEdited.Threshold {
+				history.Problem = fmt.Sprintf("Mengurangi stok (%d) %s : %s - sisa stok %d %s (perlu restock)",
+					-data.Qty,
+					stockEdited.Unit,
+					data.Note,
+					stockEdited.Qty,
+					stockEdited.Unit,
+				)
+			} else {
+				history.Problem = fmt.Sprintf("Mengurangi stok (%d) %s : %s - sisa stok %d %s",
+					-data.Qty,
+					stockEdited.Unit,
+					data.Note,
+					stockEdited.Qty,
+					stockEdited.Unit,
+				)
+			}
 		}
-	}
 
-	isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
-	// DB
-	_, err = s.daoH.InsertHistory(ctx, history, isVendor)
+		isVendor := sfunc.InSlice(roles.RoleVendor, user.Roles)
+		// DB
+		_, err = s.daoH.InsertHistory(txCtx, history, isVendor)
+		return err
+	})
 	if err != nil {
-		logger.Error("Berhasil membuat stock namun gagal membuat History (ChangeQtyStock)", err)
-		errPlus := rest_err.NewInternalServerError(fmt.Sprintf("galat : stock berhasil diuubah -> %s", err.Message()), err)
-		return nil, errPlus
+		return nil, err
 	}
 
+	s.servSi.IndexStock(ctx, stockID)
+	s.servSi.IndexHistory(ctx, history.ID.Hex())
+
 	go func() {
 		users, err := s.daoU.FindUser(ctx, user.Branch)
 		if err != nil {
@@ -331,18 +351,18 @@ func (s *stockService) GetStockByID(ctx context.Context, stockID string, branchI
 	return stock, nil
 }
 
-func (s *stockService) FindStock(ctx context.Context, filter dto.FilterBranchNameCatDisable) (dto.StockResponseMinList, rest_err.APIError) {
-	stockList, err := s.daoS.FindStock(ctx, filter)
+func (s *stockService) FindStock(ctx context.Context, filter dto.FilterBranchNameCatDisable, page dto.PageRequest) (dto.StockResponseMinList, dto.PageInfo, rest_err.APIError) {
+	stockList, pageInfo, err := s.daoS.FindStock(ctx, filter, page)
 	if err != nil {
-		return nil, err
+		return nil, dto.PageInfo{}, err
 	}
-	return stockList, nil
+	return stockList, pageInfo, nil
 }
 
 func (s *stockService) FindNeedReStock(ctx context.Context, branch string) (dto.StockResponseMinList, rest_err.APIError) {
-	stockList, err := s.daoS.FindStock(ctx, dto.FilterBranchNameCatDisable{
+	stockList, _, err := s.daoS.FindStock(ctx, dto.FilterBranchNameCatDisable{
 		FilterBranch: branch,
-	})
+	}, dto.PageRequest{All: true})
 
 	if err != nil {
 		return nil, err
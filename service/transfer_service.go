@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/constants/transferstate"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dao/transferdao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/muchlist/risa_restfull/utils/sfunc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func NewTransferService(
+	transferDao transferdao.TransferDaoAssumer,
+	cctvDao cctvdao.CctvDaoAssumer,
+	computerDao computerdao.ComputerDaoAssumer,
+	otherDao otherdao.OtherDaoAssumer,
+	genDao genunitdao.GenUnitDaoAssumer,
+	histDao historydao.HistoryDaoAssumer,
+	userDao userdao.UserLoader,
+	fcmClient fcm.ClientAssumer,
+	txDao transactiondao.TransactionDaoAssumer,
+	searchIndexer SearchIndexer,
+) TransferServiceAssumer {
+	return &transferService{
+		daoTr:     transferDao,
+		daoC:      cctvDao,
+		daoPC:     computerDao,
+		daoO:      otherDao,
+		daoG:      genDao,
+		daoH:      histDao,
+		daoU:      userDao,
+		fcmClient: fcmClient,
+		daoT:      txDao,
+		servSi:    searchIndexer,
+	}
+}
+
+type transferService struct {
+	daoTr     transferdao.TransferDaoAssumer
+	daoC      cctvdao.CctvDaoAssumer
+	daoPC     computerdao.ComputerDaoAssumer
+	daoO      otherdao.OtherDaoAssumer
+	daoG      genunitdao.GenUnitDaoAssumer
+	daoH      historydao.HistoryDaoAssumer
+	daoU      userdao.UserLoader
+	fcmClient fcm.ClientAssumer
+	daoT      transactiondao.TransactionDaoAssumer
+	servSi    SearchIndexer
+}
+
+type TransferServiceAssumer interface {
+	InsertTransfer(ctx context.Context, user mjwt.CustomClaim, input dto.TransferRequest) (*string, rest_err.APIError)
+	AcceptTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string, input dto.TransferDecisionRequest) (*dto.Transfer, rest_err.APIError)
+	RejectTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string, input dto.TransferDecisionRequest) (*dto.Transfer, rest_err.APIError)
+	CancelTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string) (*dto.Transfer, rest_err.APIError)
+
+	GetTransferByID(ctx context.Context, transferID string, branchIfSpecific string) (*dto.Transfer, rest_err.APIError)
+	FindTransfer(ctx context.Context, filter dto.FilterTransfer, page dto.PageRequest) ([]dto.Transfer, dto.PageInfo, rest_err.APIError)
+}
+
+// InsertTransfer membuat permintaan transfer dari cabang user ke cabang tujuan.
+// unit harus milik cabang user dan tidak sedang berada di transfer lain yang masih PENDING
+func (t *transferService) InsertTransfer(ctx context.Context, user mjwt.CustomClaim, input dto.TransferRequest) (*string, rest_err.APIError) {
+	input.ToBranch = strings.ToUpper(input.ToBranch)
+	if input.ToBranch == strings.ToUpper(user.Branch) {
+		return nil, rest_err.NewBadRequestError("Cabang tujuan tidak boleh sama dengan cabang asal")
+	}
+
+	units := make([]dto.TransferUnit, 0, len(input.Units))
+	unitIDs := make([]string, 0, len(input.Units))
+	for _, unitReq := range input.Units {
+		unit, err := t.getTransferUnit(ctx, unitReq, user.Branch)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, *unit)
+		unitIDs = append(unitIDs, unit.ID)
+	}
+
+	pending, _, err := t.daoTr.FindTransfer(ctx, dto.FilterTransfer{
+		FilterState:   transferstate.Pending,
+		FilterUnitIDs: unitIDs,
+	}, dto.PageRequest{Size: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) != 0 {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("Unit sudah berada di permintaan transfer %s yang belum diputuskan", pending[0].ID.Hex()))
+	}
+
+	timeNow := time.Now().Unix()
+	data := dto.Transfer{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   timeNow,
+		CreatedBy:   user.Name,
+		CreatedByID: user.Identity,
+		UpdatedAt:   timeNow,
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+		FromBranch:  user.Branch,
+		ToBranch:    input.ToBranch,
+		Units:       units,
+		Reason:      input.Reason,
+	}
+
+	insertedID, err := t.daoTr.InsertTransfer(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	t.notifyBranch(input.ToBranch,
+		"Permintaan transfer aset",
+		fmt.Sprintf("%s mengirim %d unit ke %s : %s", user.Branch, len(units), input.ToBranch, input.Reason))
+
+	return insertedID, nil
+}
+
+// AcceptTransfer dilakukan oleh user approve cabang tujuan. Dokumen detail, gen_unit (beserta cases), dan seluruh
+// history unit dipindahkan dalam satu transaction lalu history transfer ditulis di kedua cabang
+func (t *transferService) AcceptTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string, input dto.TransferDecisionRequest) (*dto.Transfer, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(transferID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	if err := canDecideTransfer(user); err != nil {
+		return nil, err
+	}
+
+	var accepted *dto.Transfer
+	err := t.daoT.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		timeNow := time.Now().Unix()
+		transfer, err := t.daoTr.DecideTransfer(txCtx, dto.TransferDecide{
+			FilterID:       oid,
+			FilterToBranch: user.Branch,
+			State:          transferstate.Accepted,
+			DecidedAt:      timeNow,
+			DecidedBy:      user.Name,
+			DecidedByID:    user.Identity,
+			DecisionNote:   input.Note,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, unit := range transfer.Units {
+			if err := t.moveUnit(txCtx, user, *transfer, unit, timeNow); err != nil {
+				return err
+			}
+		}
+
+		accepted = transfer
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, unit := range accepted.Units {
+		t.servSi.IndexUnitWithHistory(ctx, unit.ID)
+	}
+
+	t.notifyBranch(accepted.FromBranch,
+		"Transfer aset diterima",
+		fmt.Sprintf("%d unit diterima oleh %s", len(accepted.Units), accepted.ToBranch))
+
+	return accepted, nil
+}
+
+// RejectTransfer dilakukan oleh user approve cabang tujuan, unit tetap berada di cabang asal
+func (t *transferService) RejectTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string, input dto.TransferDecisionRequest) (*dto.Transfer, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(transferID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	if err := canDecideTransfer(user); err != nil {
+		return nil, err
+	}
+
+	transfer, err := t.daoTr.DecideTransfer(ctx, dto.TransferDecide{
+		FilterID:       oid,
+		FilterToBranch: user.Branch,
+		State:          transferstate.Rejected,
+		DecidedAt:      time.Now().Unix(),
+		DecidedBy:      user.Name,
+		DecidedByID:    user.Identity,
+		DecisionNote:   input.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.notifyBranch(transfer.FromBranch,
+		"Transfer aset ditolak",
+		fmt.Sprintf("%d unit ditolak oleh %s : %s", len(transfer.Units), transfer.ToBranch, input.Note))
+
+	return transfer, nil
+}
+
+// canDecideTransfer menerima atau menolak perpindahan aset antar cabang sama seperti persetujuan history,
+// hanya dapat dilakukan oleh role approve
+func canDecideTransfer(user mjwt.CustomClaim) rest_err.APIError {
+	if !sfunc.InSlice(roles.RoleApprove, user.Roles) {
+		return rest_err.NewUnauthorizedError("keputusan transfer hanya dapat dilakukan oleh role approve")
+	}
+	return nil
+}
+
+// CancelTransfer dilakukan oleh cabang asal selama transfer belum diputuskan
+func (t *transferService) CancelTransfer(ctx context.Context, user mjwt.CustomClaim, transferID string) (*dto.Transfer, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(transferID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	return t.daoTr.DecideTransfer(ctx, dto.TransferDecide{
+		FilterID:         oid,
+		FilterFromBranch: user.Branch,
+		State:            transferstate.Cancelled,
+		DecidedAt:        time.Now().Unix(),
+		DecidedBy:        user.Name,
+		DecidedByID:      user.Identity,
+	})
+}
+
+func (t *transferService) GetTransferByID(ctx context.Context, transferID string, branchIfSpecific string) (*dto.Transfer, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(transferID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+	return t.daoTr.GetTransferByID(ctx, oid, branchIfSpecific)
+}
+
+func (t *transferService) FindTransfer(ctx context.Context, filter dto.FilterTransfer, page dto.PageRequest) ([]dto.Transfer, dto.PageInfo, rest_err.APIError) {
+	return t.daoTr.FindTransfer(ctx, filter, page)
+}
+
+// getTransferUnit memastikan unit ada di cabang asal dan kategorinya sesuai
+func (t *transferService) getTransferUnit(ctx context.Context, input dto.TransferUnitRequest, branch string) (*dto.TransferUnit, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(input.ID)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError(fmt.Sprintf("ObjectID unit %s salah", input.ID))
+	}
+
+	unit := dto.TransferUnit{
+		ID:       input.ID,
+		Category: input.Category,
+	}
+	switch input.Category {
+	case category.Cctv:
+		cctv, err := t.daoC.GetCctvByID(ctx, oid, branch)
+		if err != nil {
+			return nil, err
+		}
+		unit.Name = cctv.Name
+	case category.PC:
+		pc, err := t.daoPC.GetPcByID(ctx, oid, branch)
+		if err != nil {
+			return nil, err
+		}
+		unit.Name = pc.Name
+	default:
+		other, err := t.daoO.GetOtherByID(ctx, oid, branch)
+		if err != nil {
+			return nil, err
+		}
+		if other.SubCategory != input.Category {
+			return nil, rest_err.NewBadRequestError(fmt.Sprintf("Unit %s bukan kategori %s", other.Name, input.Category))
+		}
+		unit.Name = other.Name
+	}
+	return &unit, nil
+}
+
+// moveUnit memindahkan satu unit ke cabang tujuan, dijalankan didalam transaction AcceptTransfer
+func (t *transferService) moveUnit(ctx context.Context, user mjwt.CustomClaim, transfer dto.Transfer, unit dto.TransferUnit, timeNow int64) rest_err.APIError {
+	oid, errT := primitive.ObjectIDFromHex(unit.ID)
+	if errT != nil {
+		return rest_err.NewBadRequestError(fmt.Sprintf("ObjectID unit %s salah", unit.ID))
+	}
+
+	move := dto.BranchMove{
+		ID:          oid,
+		FromBranch:  transfer.FromBranch,
+		ToBranch:    transfer.ToBranch,
+		UpdatedAt:   timeNow,
+		UpdatedBy:   user.Name,
+		UpdatedByID: user.Identity,
+	}
+	var err rest_err.APIError
+	switch unit.Category {
+	case category.Cctv:
+		_, err = t.daoC.MoveCctvBranch(ctx, move)
+	case category.PC:
+		_, err = t.daoPC.MovePcBranch(ctx, move)
+	default:
+		_, err = t.daoO.MoveOtherBranch(ctx, move)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.daoG.MoveUnitBranch(ctx, unit.ID, transfer.FromBranch, transfer.ToBranch); err != nil {
+		return err
+	}
+	if _, err := t.daoH.MoveHistoryBranch(ctx, unit.ID, transfer.FromBranch, transfer.ToBranch); err != nil {
+		return err
+	}
+
+	// history transfer ditulis di kedua cabang agar riwayat cabang asal tetap mencatat keluarnya unit
+	histories := []dto.History{
+		transferHistory(user, transfer, unit, transfer.FromBranch, fmt.Sprintf("Unit ditransfer ke %s", transfer.ToBranch), timeNow),
+		transferHistory(user, transfer, unit, transfer.ToBranch, fmt.Sprintf("Unit diterima dari %s", transfer.FromBranch), timeNow),
+	}
+	if _, err := t.daoH.InsertManyHistory(ctx, histories, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *transferService) notifyBranch(branch string, title string, message string) {
+	go func() {
+		users, err := t.daoU.FindUser(context.Background(), branch)
+		if err != nil {
+			logger.Error("mendapatkan user gagal saat menambahkan fcm (transfer notifyBranch)", err)
+			return
+		}
+		var tokens []string
+		for _, u := range users {
+			if u.FcmToken != "" {
+				tokens = append(tokens, u.FcmToken)
+			}
+		}
+		t.fcmClient.SendMessage(fcm.Payload{
+			Title:          title,
+			Message:        message,
+			ReceiverTokens: tokens,
+		})
+	}()
+}
+
+func transferHistory(user mjwt.CustomClaim, transfer dto.Transfer, unit dto.TransferUnit, branch string, problem string, timeNow int64) dto.History {
+	return dto.History{
+		ID:             primitive.NewObjectID(),
+		CreatedAt:      timeNow,
+		CreatedBy:      user.Name,
+		CreatedByID:    user.Identity,
+		UpdatedAt:      timeNow,
+		UpdatedBy:      user.Name,
+		UpdatedByID:    user.Identity,
+		Category:       unit.Category,
+		Branch:         branch,
+		ParentID:       unit.ID,
+		ParentName:     unit.Name,
+		Status:         "Transfer",
+		Problem:        problem,
+		ProblemResolve: fmt.Sprintf("%s (transfer %s)", transfer.Reason, transfer.ID.Hex()),
+		CompleteStatus: enum.HDataInfo,
+		DateStart:      timeNow,
+		DateEnd:        timeNow,
+		Tag:            []string{},
+		Image:          "",
+	}
+}
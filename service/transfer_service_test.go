@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/clients/fcm"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/roles"
+	"github.com/muchlist/risa_restfull/constants/transferstate"
+	"github.com/muchlist/risa_restfull/dao/cctvdao"
+	"github.com/muchlist/risa_restfull/dao/computerdao"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/historydao"
+	"github.com/muchlist/risa_restfull/dao/otherdao"
+	"github.com/muchlist/risa_restfull/dao/transferdao"
+	"github.com/muchlist/risa_restfull/dao/userdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fake dao di bawah ini meng-embed interface dao sehingga hanya method yang dipakai yang perlu diisi,
+// method lain yang terpanggil akan panic dan menandakan alur service berubah
+
+type txKey struct{}
+
+// fakeTx menjalankan fn secara langsung dan menandai ctx agar fake dao dapat memastikan penulisan ada didalam transaction
+type fakeTx struct {
+	calls int
+}
+
+func (f *fakeTx) WithTransaction(ctx context.Context, fn func(ctx context.Context) rest_err.APIError) rest_err.APIError {
+	f.calls++
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+type fakeIndexer struct {
+	SearchIndexer
+	units          []string
+	unitsWithChild []string
+}
+
+func (f *fakeIndexer) IndexUnit(_ context.Context, unitID string) {
+	f.units = append(f.units, unitID)
+}
+
+func (f *fakeIndexer) IndexUnitWithHistory(_ context.Context, unitID string) {
+	f.unitsWithChild = append(f.unitsWithChild, unitID)
+}
+
+type fakeUserLoader struct {
+	userdao.UserLoader
+}
+
+func (f *fakeUserLoader) FindUser(context.Context, string) (dto.UserResponseList, rest_err.APIError) {
+	return dto.UserResponseList{}, nil
+}
+
+type fakeFcm struct{}
+
+func (f *fakeFcm) SendMessage(fcm.Payload) {}
+
+type fakeTransferDao struct {
+	transferdao.TransferDaoAssumer
+	transfer dto.Transfer
+	decided  []dto.TransferDecide
+}
+
+func (f *fakeTransferDao) DecideTransfer(_ context.Context, input dto.TransferDecide) (*dto.Transfer, rest_err.APIError) {
+	f.decided = append(f.decided, input)
+	transfer := f.transfer
+	transfer.State = input.State
+	return &transfer, nil
+}
+
+type fakeCctvDao struct {
+	cctvdao.CctvDaoAssumer
+	moved []dto.BranchMove
+}
+
+func (f *fakeCctvDao) MoveCctvBranch(_ context.Context, input dto.BranchMove) (*dto.Cctv, rest_err.APIError) {
+	f.moved = append(f.moved, input)
+	return &dto.Cctv{ID: input.ID, Branch: input.ToBranch}, nil
+}
+
+type fakeComputerDao struct {
+	computerdao.ComputerDaoAssumer
+	moved []dto.BranchMove
+}
+
+func (f *fakeComputerDao) MovePcBranch(_ context.Context, input dto.BranchMove) (*dto.Computer, rest_err.APIError) {
+	f.moved = append(f.moved, input)
+	return &dto.Computer{ID: input.ID, Branch: input.ToBranch}, nil
+}
+
+type fakeOtherDao struct {
+	otherdao.OtherDaoAssumer
+	moved []dto.BranchMove
+}
+
+func (f *fakeOtherDao) MoveOtherBranch(_ context.Context, input dto.BranchMove) (*dto.Other, rest_err.APIError) {
+	f.moved = append(f.moved, input)
+	return &dto.Other{ID: input.ID, Branch: input.ToBranch}, nil
+}
+
+type fakeGenUnitDao struct {
+	genunitdao.GenUnitDaoAssumer
+	failUnitID string
+	outsideTx  bool
+	moved      []string
+}
+
+func (f *fakeGenUnitDao) MoveUnitBranch(ctx context.Context, unitID string, _ string, toBranch string) (*dto.GenUnitResponse, rest_err.APIError) {
+	f.outsideTx = f.outsideTx || !inTx(ctx)
+	if unitID == f.failUnitID {
+		return nil, rest_err.NewBadRequestError("unit tidak ditemukan")
+	}
+	f.moved = append(f.moved, unitID)
+	return &dto.GenUnitResponse{ID: unitID, Branch: toBranch}, nil
+}
+
+type fakeHistoryDao struct {
+	historydao.HistoryDaoAssumer
+	movedParents []string
+	inserted     []dto.History
+}
+
+func (f *fakeHistoryDao) MoveHistoryBranch(_ context.Context, parentID string, _ string, _ string) (int64, rest_err.APIError) {
+	f.movedParents = append(f.movedParents, parentID)
+	return 1, nil
+}
+
+func (f *fakeHistoryDao) InsertManyHistory(_ context.Context, dataList []dto.History, _ bool) (int, rest_err.APIError) {
+	f.inserted = append(f.inserted, dataList...)
+	return len(dataList), nil
+}
+
+type transferFixture struct {
+	service  *transferService
+	transfer *fakeTransferDao
+	cctv     *fakeCctvDao
+	computer *fakeComputerDao
+	other    *fakeOtherDao
+	genUnit  *fakeGenUnitDao
+	history  *fakeHistoryDao
+	tx       *fakeTx
+	indexer  *fakeIndexer
+}
+
+func newTransferFixture(units ...dto.TransferUnit) transferFixture {
+	f := transferFixture{
+		transfer: &fakeTransferDao{transfer: dto.Transfer{
+			ID:         primitive.NewObjectID(),
+			FromBranch: "BANJARMASIN",
+			ToBranch:   "KOTABARU",
+			Units:      units,
+		}},
+		cctv:     &fakeCctvDao{},
+		computer: &fakeComputerDao{},
+		other:    &fakeOtherDao{},
+		genUnit:  &fakeGenUnitDao{},
+		history:  &fakeHistoryDao{},
+		tx:       &fakeTx{},
+		indexer:  &fakeIndexer{},
+	}
+	f.service = NewTransferService(f.transfer, f.cctv, f.computer, f.other, f.genUnit, f.history,
+		&fakeUserLoader{}, &fakeFcm{}, f.tx, f.indexer).(*transferService)
+	return f
+}
+
+func TestAcceptTransferMovesEveryUnit(t *testing.T) {
+	cctvUnit := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Cctv, Name: "CCTV GATE"}
+	pcUnit := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.PC, Name: "PC ADMIN"}
+	altaiUnit := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Altai, Name: "ALTAI DERMAGA"}
+	f := newTransferFixture(cctvUnit, pcUnit, altaiUnit)
+	user := mjwt.CustomClaim{Identity: "user1", Name: "User Satu", Branch: "KOTABARU", Roles: []string{roles.RoleApprove}}
+
+	accepted, err := f.service.AcceptTransfer(context.Background(), user, f.transfer.transfer.ID.Hex(), dto.TransferDecisionRequest{Note: "ok"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, transferstate.Accepted, accepted.State)
+	// hanya cabang tujuan yang dapat menerima
+	assert.Len(t, f.transfer.decided, 1)
+	assert.Equal(t, "KOTABARU", f.transfer.decided[0].FilterToBranch)
+	assert.Equal(t, 1, f.tx.calls)
+	assert.False(t, f.genUnit.outsideTx)
+
+	// dokumen detail dipindahkan sesuai kategori
+	assert.Len(t, f.cctv.moved, 1)
+	assert.Equal(t, cctvUnit.ID, f.cctv.moved[0].ID.Hex())
+	assert.Equal(t, "BANJARMASIN", f.cctv.moved[0].FromBranch)
+	assert.Equal(t, "KOTABARU", f.cctv.moved[0].ToBranch)
+	assert.Len(t, f.computer.moved, 1)
+	assert.Len(t, f.other.moved, 1)
+
+	ids := []string{cctvUnit.ID, pcUnit.ID, altaiUnit.ID}
+	assert.Equal(t, ids, f.genUnit.moved)
+	assert.Equal(t, ids, f.history.movedParents)
+
+	// setiap unit mendapat history transfer di cabang asal dan tujuan
+	assert.Len(t, f.history.inserted, 6)
+	assert.Equal(t, "BANJARMASIN", f.history.inserted[0].Branch)
+	assert.Equal(t, "KOTABARU", f.history.inserted[1].Branch)
+	assert.Equal(t, cctvUnit.ID, f.history.inserted[1].ParentID)
+	assert.Equal(t, enum.HDataInfo, f.history.inserted[1].CompleteStatus)
+
+	// index pencarian diperbarui setelah transaction selesai
+	assert.Equal(t, ids, f.indexer.unitsWithChild)
+}
+
+func TestAcceptTransferAbortsOnFirstError(t *testing.T) {
+	first := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Cctv, Name: "CCTV 1"}
+	second := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Cctv, Name: "CCTV 2"}
+	third := dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Cctv, Name: "CCTV 3"}
+	f := newTransferFixture(first, second, third)
+	f.genUnit.failUnitID = second.ID
+	user := mjwt.CustomClaim{Identity: "user1", Name: "User Satu", Branch: "KOTABARU", Roles: []string{roles.RoleApprove}}
+
+	accepted, err := f.service.AcceptTransfer(context.Background(), user, f.transfer.transfer.ID.Hex(), dto.TransferDecisionRequest{})
+
+	assert.Nil(t, accepted)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+	// unit ketiga tidak disentuh dan index tidak diperbarui
+	assert.Equal(t, []string{first.ID}, f.genUnit.moved)
+	assert.Len(t, f.cctv.moved, 2)
+	assert.Len(t, f.history.inserted, 2)
+	assert.Empty(t, f.indexer.unitsWithChild)
+}
+
+func TestAcceptTransferInvalidID(t *testing.T) {
+	f := newTransferFixture()
+
+	_, err := f.service.AcceptTransfer(context.Background(), mjwt.CustomClaim{Branch: "KOTABARU"}, "bukan-id", dto.TransferDecisionRequest{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+	assert.Equal(t, 0, f.tx.calls)
+	assert.Empty(t, f.transfer.decided)
+}
+
+func TestDecideTransferRequiresApprove(t *testing.T) {
+	f := newTransferFixture(dto.TransferUnit{ID: primitive.NewObjectID().Hex(), Category: category.Cctv, Name: "CCTV GATE"})
+	user := mjwt.CustomClaim{Identity: "user2", Name: "User Dua", Branch: "KOTABARU", Roles: []string{}}
+
+	_, err := f.service.AcceptTransfer(context.Background(), user, f.transfer.transfer.ID.Hex(), dto.TransferDecisionRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 401, err.Status())
+
+	_, err = f.service.RejectTransfer(context.Background(), user, f.transfer.transfer.ID.Hex(), dto.TransferDecisionRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 401, err.Status())
+
+	// tidak ada keputusan maupun perpindahan unit
+	assert.Equal(t, 0, f.tx.calls)
+	assert.Empty(t, f.transfer.decided)
+	assert.Empty(t, f.genUnit.moved)
+}
+
+func TestMoveUnitInvalidUnitID(t *testing.T) {
+	f := newTransferFixture()
+
+	err := f.service.moveUnit(context.Background(), mjwt.CustomClaim{}, f.transfer.transfer, dto.TransferUnit{ID: "salah", Category: category.Cctv}, 100)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+	assert.Empty(t, f.cctv.moved)
+	assert.Empty(t, f.genUnit.moved)
+}
+
+func TestTransferHistory(t *testing.T) {
+	user := mjwt.CustomClaim{Identity: "user1", Name: "User Satu", Branch: "KOTABARU"}
+	transfer := dto.Transfer{
+		ID:         primitive.NewObjectID(),
+		FromBranch: "BANJARMASIN",
+		ToBranch:   "KOTABARU",
+		Reason:     "pindah lokasi",
+	}
+	unit := dto.TransferUnit{ID: "60b9f1c2a1b2c3d4e5f60718", Category: category.Cctv, Name: "CCTV GATE"}
+
+	history := transferHistory(user, transfer, unit, transfer.FromBranch, "Unit ditransfer ke KOTABARU", 100)
+
+	assert.Equal(t, "BANJARMASIN", history.Branch)
+	assert.Equal(t, unit.ID, history.ParentID)
+	assert.Equal(t, unit.Name, history.ParentName)
+	assert.Equal(t, category.Cctv, history.Category)
+	assert.Equal(t, enum.HDataInfo, history.CompleteStatus)
+	assert.Contains(t, history.ProblemResolve, transfer.ID.Hex())
+	assert.Equal(t, int64(100), history.DateStart)
+	assert.Equal(t, user.Identity, history.CreatedByID)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dao/transactiondao"
+	"github.com/muchlist/risa_restfull/dao/trashdao"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	trashRetentionKey     = "TRASH_RETENTION_DAYS"
+	defaultTrashRetention = 30
+
+	// trashEntityStock satu-satunya entity tempat sampah yang tidak memiliki gen_unit
+	trashEntityStock = "stock"
+)
+
+func NewTrashService(trashDao trashdao.TrashDaoAssumer, txDao transactiondao.TransactionDaoAssumer, searchIndexer SearchIndexer) TrashServiceAssumer {
+	return &trashService{
+		daoT:   trashDao,
+		daoTx:  txDao,
+		servSi: searchIndexer,
+	}
+}
+
+type trashService struct {
+	daoT   trashdao.TrashDaoAssumer
+	daoTx  transactiondao.TransactionDaoAssumer
+	servSi SearchIndexer
+}
+type TrashServiceAssumer interface {
+	FindTrash(ctx context.Context, filter dto.FilterTrash, page dto.PageRequest) ([]dto.TrashItem, dto.PageInfo, rest_err.APIError)
+	RestoreTrash(ctx context.Context, entity string, id string) (*dto.TrashItem, rest_err.APIError)
+	PurgeTrash(ctx context.Context, branch string) (int64, rest_err.APIError)
+}
+
+func (t *trashService) FindTrash(ctx context.Context, filter dto.FilterTrash, page dto.PageRequest) ([]dto.TrashItem, dto.PageInfo, rest_err.APIError) {
+	trashList, pageInfo, err := t.daoT.FindTrash(ctx, filter, page)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+	return trashList, pageInfo, nil
+}
+
+// RestoreTrash mengembalikan dokumen beserta gen_unit nya sehingga history yang menunjuk ParentID kembali valid.
+// kedua penulisan berjalan dalam satu transaction agar dokumen dan gen_unit tidak berbeda status
+func (t *trashService) RestoreTrash(ctx context.Context, entity string, id string) (*dto.TrashItem, rest_err.APIError) {
+	oid, errT := primitive.ObjectIDFromHex(id)
+	if errT != nil {
+		return nil, rest_err.NewBadRequestError("ObjectID yang dimasukkan salah")
+	}
+
+	var item *dto.TrashItem
+	err := t.daoTx.WithTransaction(ctx, func(txCtx context.Context) rest_err.APIError {
+		var err rest_err.APIError
+		item, err = t.daoT.RestoreTrash(txCtx, dto.TrashRestore{
+			Entity: entity,
+			ID:     oid,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Entity == trashEntityStock {
+		t.servSi.IndexStock(ctx, id)
+	} else {
+		t.servSi.IndexUnitWithHistory(ctx, id)
+	}
+	return item, nil
+}
+
+// PurgeTrash menghapus permanen dokumen yang sudah melewati masa retensi
+func (t *trashService) PurgeTrash(ctx context.Context, branch string) (int64, rest_err.APIError) {
+	deletedBefore := time.Now().AddDate(0, 0, -trashRetentionDays(os.Getenv(trashRetentionKey))).Unix()
+
+	purged, err := t.daoT.PurgeTrash(ctx, dto.TrashPurge{
+		FilterBranch:  branch,
+		DeletedBefore: deletedBefore,
+	})
+	if err != nil {
+		return purged, err
+	}
+	return purged, nil
+}
+
+// trashRetentionDays membaca masa retensi dalam hari, nilai kosong atau tidak valid memakai default
+func trashRetentionDays(value string) int {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return defaultTrashRetention
+	}
+	return days
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashRetentionDays(t *testing.T) {
+	assert.Equal(t, defaultTrashRetention, trashRetentionDays(""))
+	assert.Equal(t, defaultTrashRetention, trashRetentionDays("abc"))
+	assert.Equal(t, defaultTrashRetention, trashRetentionDays("0"))
+	assert.Equal(t, 7, trashRetentionDays("7"))
+}
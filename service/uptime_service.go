@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dao/genunitdao"
+	"github.com/muchlist/risa_restfull/dao/pinghistorydao"
+	"github.com/muchlist/risa_restfull/dto"
+)
+
+// maxSampleGap batas maksimal (detik) sebuah sample dianggap mewakili kondisi perangkat.
+// jika jarak ke sample berikutnya lebih dari ini, sisa waktunya dianggap tidak teramati
+const maxSampleGap = 60 * 60
+
+func NewUptimeService(pingDao pinghistorydao.PingHistoryLoader,
+	genDao genunitdao.GenUnitLoader) UptimeServiceAssumer {
+	return &uptimeService{
+		daoP: pingDao,
+		daoG: genDao,
+	}
+}
+
+type uptimeService struct {
+	daoP pinghistorydao.PingHistoryLoader
+	daoG genunitdao.GenUnitLoader
+}
+type UptimeServiceAssumer interface {
+	GetUnitUptime(ctx context.Context, unitID string, branchIfSpecific string, start int64, end int64) (*dto.UptimeResponse, rest_err.APIError)
+	GetBranchUptime(ctx context.Context, branch string, category string, start int64, end int64) (*dto.UptimeBranchSummary, rest_err.APIError)
+}
+
+func (u *uptimeService) GetUnitUptime(ctx context.Context, unitID string, branchIfSpecific string, start int64, end int64) (*dto.UptimeResponse, rest_err.APIError) {
+	if start >= end {
+		return nil, rest_err.NewBadRequestError("tanggal awal tidak boleh lebih besar dari tanggal akhir")
+	}
+
+	unit, err := u.daoG.GetUnitByID(ctx, unitID, branchIfSpecific)
+	if err != nil {
+		return nil, err
+	}
+
+	// sample sebelum start diambil agar kondisi perangkat di awal rentang diketahui
+	samples, err := u.daoP.FindSamples(ctx, dto.PingSampleFilter{
+		UnitID: unit.ID,
+		Start:  start - maxSampleGap,
+		End:    end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UptimeResponse{
+		UnitID:     unit.ID,
+		Name:       unit.Name,
+		Category:   unit.Category,
+		Branch:     unit.Branch,
+		Start:      start,
+		End:        end,
+		UptimeStat: calculateUptime(samples, start, end),
+	}, nil
+}
+
+func (u *uptimeService) GetBranchUptime(ctx context.Context, branch string, category string, start int64, end int64) (*dto.UptimeBranchSummary, rest_err.APIError) {
+	if start >= end {
+		return nil, rest_err.NewBadRequestError("tanggal awal tidak boleh lebih besar dari tanggal akhir")
+	}
+
+	units, _, err := u.daoG.FindUnit(ctx, dto.GenUnitFilter{
+		Branch:   branch,
+		Category: category,
+	}, dto.PageRequest{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := u.daoP.FindSamples(ctx, dto.PingSampleFilter{
+		Branch:   branch,
+		Category: category,
+		Start:    start - maxSampleGap,
+		End:      end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := summarizeUptime(samples, start, end)
+	summary.Branch = branch
+	summary.Category = category
+
+	// melengkapi nama unit, unit yang sudah dihapus tetap dihitung dengan nama kosong
+	unitNames := make(map[string]dto.GenUnitResponse, len(units))
+	for _, unit := range units {
+		unitNames[unit.ID] = unit
+	}
+	for i := range summary.Units {
+		if unit, ok := unitNames[summary.Units[i].UnitID]; ok {
+			summary.Units[i].Name = unit.Name
+			summary.Units[i].Category = unit.Category
+		}
+	}
+
+	return &summary, nil
+}
+
+// summarizeUptime menghitung uptime setiap unit lalu menggabungkannya.
+// samples harus terurut berdasarkan unit lalu waktu (seperti hasil FindSamples)
+func summarizeUptime(samples []dto.PingSample, start int64, end int64) dto.UptimeBranchSummary {
+	summary := dto.UptimeBranchSummary{
+		Start: start,
+		End:   end,
+		Units: []dto.UptimeResponse{},
+	}
+
+	flush := func(unitSamples []dto.PingSample) {
+		if len(unitSamples) == 0 {
+			return
+		}
+		stat := calculateUptime(unitSamples, start, end)
+		summary.Units = append(summary.Units, dto.UptimeResponse{
+			UnitID:     unitSamples[0].Meta.UnitID,
+			Branch:     unitSamples[0].Meta.Branch,
+			Category:   unitSamples[0].Meta.Category,
+			Start:      start,
+			End:        end,
+			UptimeStat: stat,
+		})
+		summary.Samples += stat.Samples
+		summary.UpSeconds += stat.UpSeconds
+		summary.DownSeconds += stat.DownSeconds
+		summary.Failures += stat.Failures
+	}
+
+	startIndex := 0
+	for i := range samples {
+		if samples[i].Meta.UnitID != samples[startIndex].Meta.UnitID {
+			flush(samples[startIndex:i])
+			startIndex = i
+		}
+	}
+	flush(samples[startIndex:])
+
+	summary.UnitCount = len(summary.Units)
+	finalizeUptime(&summary.UptimeStat)
+	return summary
+}
+
+// calculateUptime menghitung uptime satu unit. setiap sample dianggap mewakili kondisi perangkat
+// sampai sample berikutnya (maksimal maxSampleGap). HALF dihitung sebagai up karena perangkat masih merespon.
+// Failures adalah jumlah perpindahan kondisi ke DOWN
+func calculateUptime(samples []dto.PingSample, start int64, end int64) dto.UptimeStat {
+	var stat dto.UptimeStat
+	previousDown := false
+
+	for i, sample := range samples {
+		from := sample.Time.Unix()
+		to := from + maxSampleGap
+		if i+1 < len(samples) && samples[i+1].Time.Unix() < to {
+			to = samples[i+1].Time.Unix()
+		}
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		if to <= from {
+			continue
+		}
+
+		stat.Samples++
+		isDown := sample.Code == enum.PingDown
+		if isDown {
+			if !previousDown {
+				stat.Failures++
+			}
+			stat.DownSeconds += to - from
+		} else {
+			stat.UpSeconds += to - from
+		}
+		previousDown = isDown
+	}
+
+	finalizeUptime(&stat)
+	return stat
+}
+
+// finalizeUptime mengisi nilai turunan (observed, persen, MTBF, MTTR)
+func finalizeUptime(stat *dto.UptimeStat) {
+	stat.ObservedSeconds = stat.UpSeconds + stat.DownSeconds
+	if stat.ObservedSeconds != 0 {
+		uptime := float64(stat.UpSeconds) / float64(stat.ObservedSeconds) * 100
+		stat.Uptime = math.Round(uptime*100) / 100
+	}
+	if stat.Failures != 0 {
+		stat.MTBF = stat.UpSeconds / int64(stat.Failures)
+		stat.MTTR = stat.DownSeconds / int64(stat.Failures)
+	}
+}
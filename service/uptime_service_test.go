@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func pingSample(unitID string, unix int64, code int) dto.PingSample {
+	return dto.PingSample{
+		Time: time.Unix(unix, 0),
+		Meta: dto.PingMeta{UnitID: unitID, Branch: "BANJARMASIN", Category: "CCTV"},
+		Code: code,
+	}
+}
+
+func TestCalculateUptime(t *testing.T) {
+	// up 0-600, down 600-900, up 900-1200
+	samples := []dto.PingSample{
+		pingSample("a", 0, enum.PingUp),
+		pingSample("a", 300, enum.PingHalf),
+		pingSample("a", 600, enum.PingDown),
+		pingSample("a", 900, enum.PingUp),
+	}
+
+	stat := calculateUptime(samples, 0, 1200)
+
+	assert.Equal(t, int64(900), stat.UpSeconds)
+	assert.Equal(t, int64(300), stat.DownSeconds)
+	assert.Equal(t, int64(1200), stat.ObservedSeconds)
+	assert.Equal(t, 75.0, stat.Uptime)
+	assert.Equal(t, 1, stat.Failures)
+	assert.Equal(t, int64(900), stat.MTBF)
+	assert.Equal(t, int64(300), stat.MTTR)
+}
+
+func TestCalculateUptimeGapNotObserved(t *testing.T) {
+	// sample tunggal hanya mewakili maxSampleGap detik
+	samples := []dto.PingSample{
+		pingSample("a", 0, enum.PingUp),
+	}
+
+	stat := calculateUptime(samples, 0, 3*maxSampleGap)
+
+	assert.Equal(t, int64(maxSampleGap), stat.ObservedSeconds)
+	assert.Equal(t, 100.0, stat.Uptime)
+	assert.Equal(t, 0, stat.Failures)
+}
+
+func TestCalculateUptimeClampToRange(t *testing.T) {
+	samples := []dto.PingSample{
+		pingSample("a", 0, enum.PingDown),
+		pingSample("a", 600, enum.PingUp),
+	}
+
+	stat := calculateUptime(samples, 300, 900)
+
+	assert.Equal(t, int64(300), stat.DownSeconds)
+	assert.Equal(t, int64(300), stat.UpSeconds)
+	assert.Equal(t, 50.0, stat.Uptime)
+}
+
+func TestSummarizeUptime(t *testing.T) {
+	samples := []dto.PingSample{
+		pingSample("a", 0, enum.PingUp),
+		pingSample("a", 600, enum.PingUp),
+		pingSample("b", 0, enum.PingDown),
+		pingSample("b", 600, enum.PingUp),
+	}
+
+	summary := summarizeUptime(samples, 0, 1200)
+
+	assert.Equal(t, 2, summary.UnitCount)
+	assert.Equal(t, 100.0, summary.Units[0].Uptime)
+	assert.Equal(t, 50.0, summary.Units[1].Uptime)
+	assert.Equal(t, 75.0, summary.Uptime)
+	assert.Equal(t, 1, summary.Failures)
+}
+
+func TestSummarizeUptimeEmpty(t *testing.T) {
+	summary := summarizeUptime([]dto.PingSample{}, 0, 1200)
+
+	assert.Equal(t, 0, summary.UnitCount)
+	assert.Equal(t, 0.0, summary.Uptime)
+}
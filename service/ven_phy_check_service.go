@@ -40,7 +40,7 @@ type VenPhyCheckServiceAssumer interface {
 	InsertVenPhyCheck(ctx context.Context, user mjwt.CustomClaim, name string, isQuarterMode bool) (*string, rest_err.APIError)
 	DeleteVenPhyCheck(ctx context.Context, user mjwt.CustomClaim, id string) rest_err.APIError
 	GetVenPhyCheckByID(ctx context.Context, vendorCheckID string, branchIfSpecific string) (*dto.VenPhyCheck, rest_err.APIError)
-	FindVenPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.VenPhyCheck, rest_err.APIError)
+	FindVenPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.VenPhyCheck, dto.PageInfo, rest_err.APIError)
 	UpdateVenPhyCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.VenPhyCheckItemUpdateRequest) (*dto.VenPhyCheck, rest_err.APIError)
 	BulkUpdateVenPhyItem(ctx context.Context, user mjwt.CustomClaim, inputs []dto.VenPhyCheckItemUpdateRequest) (string, rest_err.APIError)
 	FinishCheck(ctx context.Context, user mjwt.CustomClaim, detailID string) (*dto.VenPhyCheck, rest_err.APIError)
@@ -53,20 +53,20 @@ func (vc *venPhyCheckService) InsertVenPhyCheck(ctx context.Context, user mjwt.C
 
 	// ambil cctv genUnit item berdasarkan cabang yang di input
 	// mendapatkan data cases
-	genItems, err := vc.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := vc.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Cctv,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	// ambil cctv untuk mendapatkan data lokasi
 	// cctvItems sudah sorted berdasarkan lokasi sedangkan genItems tidak
-	cctvItems, err := vc.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
+	cctvItems, _, err := vc.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
 		FilterBranch: user.Branch,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -314,10 +314,10 @@ func (vc *venPhyCheckService) FreshUpdateNameCCTV(ctx context.Context, branch st
 	}
 
 	// mendapatkan nama cctv eksisting
-	cctvList, err := vc.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
+	cctvList, _, err := vc.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
 		FilterBranch:  strings.ToUpper(branch),
 		FilterDisable: false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return "", err
 	}
@@ -376,10 +376,10 @@ func (vc *venPhyCheckService) GetVenPhyCheckByID(ctx context.Context, vendorChec
 	return vendorCheck, nil
 }
 
-func (vc *venPhyCheckService) FindVenPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.VenPhyCheck, rest_err.APIError) {
-	vendorCheckList, err := vc.daoC.FindCheck(ctx, branch, filter, false)
+func (vc *venPhyCheckService) FindVenPhyCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.VenPhyCheck, dto.PageInfo, rest_err.APIError) {
+	vendorCheckList, pageInfo, err := vc.daoC.FindCheck(ctx, branch, filter, page, false)
 	if err != nil {
-		return []dto.VenPhyCheck{}, err
+		return nil, dto.PageInfo{}, err
 	}
-	return vendorCheckList, nil
+	return vendorCheckList, pageInfo, nil
 }
@@ -42,7 +42,7 @@ type VendorCheckServiceAssumer interface {
 	InsertVendorCheck(ctx context.Context, user mjwt.CustomClaim) (*string, rest_err.APIError)
 	DeleteVendorCheck(ctx context.Context, user mjwt.CustomClaim, id string) rest_err.APIError
 	GetVendorCheckByID(ctx context.Context, vendorCheckID string, branchIfSpecific string) (*dto.VendorCheck, rest_err.APIError)
-	FindVendorCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.VendorCheck, rest_err.APIError)
+	FindVendorCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.VendorCheck, dto.PageInfo, rest_err.APIError)
 	UpdateVendorCheckItem(ctx context.Context, user mjwt.CustomClaim, input dto.VendorCheckItemUpdateRequest) (*dto.VendorCheck, rest_err.APIError)
 	BulkUpdateVendorItem(ctx context.Context, user mjwt.CustomClaim, inputs []dto.VendorCheckItemUpdateRequest) (string, rest_err.APIError)
 	FinishCheck(ctx context.Context, user mjwt.CustomClaim, detailID string) (*dto.VendorCheck, rest_err.APIError)
@@ -53,20 +53,20 @@ func (c *vendorCheckService) InsertVendorCheck(ctx context.Context, user mjwt.Cu
 
 	// ambil cctv genUnit item berdasarkan cabang yang di input
 	// mendapatkan data cases
-	genItems, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Cctv,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	// ambil cctv untuk mendapatkan data lokasi
 	// cctvItems sudah sorted berdasarkan lokasi sedangkan genItems tidak
-	cctvItems, err := c.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
+	cctvItems, _, err := c.daoCTV.FindCctv(ctx, dto.FilterBranchLocIPNameDisable{
 		FilterBranch: user.Branch,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -223,11 +223,11 @@ func (c *vendorCheckService) FinishCheck(ctx context.Context, user mjwt.CustomCl
 	timeNow := time.Now().Unix()
 
 	// 1. cek cctv existing, untuk mendapatkan keterangan apakah ada case
-	genItems, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
+	genItems, _, err := c.daoG.FindUnit(ctx, dto.GenUnitFilter{
 		Branch:   user.Branch,
 		Category: category.Cctv,
 		Pings:    false,
-	})
+	}, dto.PageRequest{All: true})
 	if err != nil {
 		return nil, err
 	}
@@ -328,10 +328,10 @@ func (c *vendorCheckService) GetVendorCheckByID(ctx context.Context, vendorCheck
 	return vendorCheck, nil
 }
 
-func (c *vendorCheckService) FindVendorCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit) ([]dto.VendorCheck, rest_err.APIError) {
-	vendorCheckList, err := c.daoC.FindCheck(ctx, branch, filter, false)
+func (c *vendorCheckService) FindVendorCheck(ctx context.Context, branch string, filter dto.FilterTimeRangeLimit, page dto.PageRequest) ([]dto.VendorCheck, dto.PageInfo, rest_err.APIError) {
+	vendorCheckList, pageInfo, err := c.daoC.FindCheck(ctx, branch, filter, page, false)
 	if err != nil {
-		return []dto.VendorCheck{}, err
+		return nil, dto.PageInfo{}, err
 	}
-	return vendorCheckList, nil
+	return vendorCheckList, pageInfo, nil
 }
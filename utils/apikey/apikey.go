@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/erru_utils_go/rest_err"
+)
+
+const (
+	// KEYCLAIMS key untuk menyimpan dto.ApiKeyClaim pada c.Locals
+	KEYCLAIMS = "api_key_claims"
+
+	keyPrefix    = "risa_"
+	secretLength = 32
+	// prefixLength panjang key yang boleh ditampilkan untuk identifikasi (risa_ + 8 karakter)
+	prefixLength = len(keyPrefix) + 8
+)
+
+// Generate membuat api key baru. key plaintext hanya dikembalikan sekali,
+// yang disimpan ke database hanyalah prefix dan hash nya
+func Generate() (key string, prefix string, hash string, apiErr rest_err.APIError) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		logger.Error("Gagal membuat random bytes (Generate api key)", err)
+		return "", "", "", rest_err.NewInternalServerError("Crypto error", err)
+	}
+
+	key = keyPrefix + hex.EncodeToString(secret)
+	return key, key[:prefixLength], Hash(key), nil
+}
+
+// Hash menghasilkan sha256 dari api key. api key memiliki entropi tinggi sehingga
+// tidak memerlukan bcrypt dan hash bisa langsung digunakan sebagai filter pencarian
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(key, keyPrefix))
+	assert.Equal(t, key[:prefixLength], prefix)
+	assert.Equal(t, Hash(key), hash)
+	assert.NotContains(t, hash, key)
+}
+
+func TestGenerateUnique(t *testing.T) {
+	key1, _, _, _ := Generate()
+	key2, _, _, _ := Generate()
+
+	assert.NotEqual(t, key1, key2)
+}
+
+func TestHashConsistent(t *testing.T) {
+	assert.Equal(t, Hash("risa_abc"), Hash("risa_abc"))
+	assert.NotEqual(t, Hash("risa_abc"), Hash("risa_abd"))
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	ActionInsert  = "INSERT"
+	ActionEdit    = "EDIT"
+	ActionDelete  = "DELETE"
+	ActionDisable = "DISABLE"
+	ActionRestore = "RESTORE"
+	ActionPurge   = "PURGE"
+	ActionMove    = "MOVE"
+
+	systemActor = "SYSTEM"
+)
+
+// ignoredFields tidak dicatat pada diff, field updated_* selalu berubah
+// dan field rahasia tidak boleh tersimpan di audit
+var ignoredFields = map[string]bool{
+	"_id":           true,
+	"updated_at":    true,
+	"updated_by":    true,
+	"updated_by_id": true,
+	"password":      true,
+	"hash_pw":       true,
+	"hash_key":      true,
+	"fcm_token":     true,
+}
+
+type actorKey struct{}
+
+// WithActor menyimpan user pelaku ke context, digunakan jika context tidak berasal dari request fiber
+func WithActor(ctx context.Context, user mjwt.CustomClaim) context.Context {
+	return context.WithValue(ctx, actorKey{}, user)
+}
+
+// ActorFromContext mendapatkan identitas pelaku dari context.
+// urutan : WithActor, claims jwt dari fiber (c.Context()), lalu SYSTEM
+func ActorFromContext(ctx context.Context) (string, string) {
+	if ctx == nil {
+		return systemActor, systemActor
+	}
+	if user, ok := ctx.Value(actorKey{}).(mjwt.CustomClaim); ok {
+		return user.Identity, user.Name
+	}
+	if user, ok := ctx.Value(mjwt.CLAIMS).(*mjwt.CustomClaim); ok && user != nil {
+		return user.Identity, user.Name
+	}
+	return systemActor, systemActor
+}
+
+// Diff membandingkan dua dokumen berdasarkan tag bson dan mengembalikan field yang berbeda.
+// before atau after boleh nil (insert atau delete). jika keduanya ada, hanya field milik after
+// yang dibandingkan karena dto hasil update bisa jadi hanya sebagian dari dokumen database
+func Diff(before interface{}, after interface{}) []dto.AuditChange {
+	beforeMap := toMap(before)
+	afterMap := toMap(after)
+
+	keys := make(map[string]bool)
+	if len(afterMap) == 0 {
+		for k := range beforeMap {
+			keys[k] = true
+		}
+	}
+	for k := range afterMap {
+		keys[k] = true
+	}
+
+	changes := make([]dto.AuditChange, 0)
+	for k := range keys {
+		if ignoredFields[k] {
+			continue
+		}
+		valBefore, valAfter := beforeMap[k], afterMap[k]
+		if reflect.DeepEqual(valBefore, valAfter) || (isEmpty(valBefore) && isEmpty(valAfter)) {
+			continue
+		}
+		changes = append(changes, dto.AuditChange{
+			Field:  k,
+			Before: valBefore,
+			After:  valAfter,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// isEmpty menyamakan field yang belum ada di dokumen lama dengan zero value
+func isEmpty(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func toMap(doc interface{}) bson.M {
+	result := bson.M{}
+	if doc == nil || (reflect.ValueOf(doc).Kind() == reflect.Ptr && reflect.ValueOf(doc).IsNil()) {
+		return result
+	}
+	if m, ok := doc.(bson.M); ok {
+		return m
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return result
+	}
+	_ = bson.Unmarshal(raw, &result)
+	return result
+}
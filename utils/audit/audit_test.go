@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muchlist/risa_restfull/utils/mjwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Name      string   `bson:"name"`
+	IP        string   `bson:"ip"`
+	Tag       []string `bson:"tag"`
+	UpdatedAt int64    `bson:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	before := sample{Name: "CCTV 1", IP: "10.0.0.1", Tag: []string{"A"}, UpdatedAt: 1}
+	after := sample{Name: "CCTV 1", IP: "10.0.0.2", Tag: []string{"A", "B"}, UpdatedAt: 2}
+
+	changes := Diff(before, &after)
+
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "ip", changes[0].Field)
+	assert.Equal(t, "10.0.0.1", changes[0].Before)
+	assert.Equal(t, "10.0.0.2", changes[0].After)
+	assert.Equal(t, "tag", changes[1].Field)
+}
+
+func TestDiffInsertDelete(t *testing.T) {
+	doc := sample{Name: "CCTV 1"}
+	var nilDoc *sample
+
+	inserted := Diff(nil, doc)
+	assert.Len(t, inserted, 1)
+	assert.Nil(t, inserted[0].Before)
+
+	deleted := Diff(doc, nilDoc)
+	assert.Len(t, deleted, 1)
+	assert.Nil(t, deleted[0].After)
+}
+
+func TestActorFromContext(t *testing.T) {
+	id, name := ActorFromContext(context.Background())
+	assert.Equal(t, "SYSTEM", id)
+	assert.Equal(t, "SYSTEM", name)
+
+	ctx := WithActor(context.Background(), mjwt.CustomClaim{Identity: "muchlis", Name: "MUCHLIS"})
+	id, name = ActorFromContext(ctx)
+	assert.Equal(t, "muchlis", id)
+	assert.Equal(t, "MUCHLIS", name)
+
+	ctx = context.WithValue(context.Background(), mjwt.CLAIMS, &mjwt.CustomClaim{Identity: "budi", Name: "BUDI"})
+	id, _ = ActorFromContext(ctx)
+	assert.Equal(t, "budi", id)
+}
+
+func TestDiffIgnoreMissingField(t *testing.T) {
+	// dokumen lama belum memiliki field tag dan ip
+	before := map[string]interface{}{"name": "CCTV 1", "legacy": "x"}
+	after := sample{Name: "CCTV 1"}
+
+	assert.Len(t, Diff(before, after), 0)
+}
@@ -0,0 +1,396 @@
+// Package fulltext index pencarian teks penuh in-memory dengan ranking BM25,
+// pencocokan prefix untuk kata terakhir dan toleransi salah ketik berbasis edit distance.
+// index tidak disimpan ke disk, isinya dibangun ulang dari database oleh pemanggil
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// titleBoost bobot kata pada judul dibanding isi
+	titleBoost = 3
+
+	// parameter BM25
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	fuzzyWeight  = 0.7
+
+	minPrefixLen = 2
+	defaultLimit = 20
+	snippetLen   = 160
+)
+
+// Document satu dokumen yang dapat dicari, kombinasi Type dan ID bersifat unik.
+// Extra tidak diindex dan dikembalikan apa adanya pada hasil pencarian
+type Document struct {
+	Type   string
+	ID     string
+	Branch string
+	Title  string
+	Body   string
+	Time   int64
+	Extra  map[string]string
+}
+
+type Hit struct {
+	Document
+	Score float64
+}
+
+// Query Branch dan Types kosong berarti tanpa batasan
+type Query struct {
+	Text   string
+	Branch string
+	Types  []string
+	Limit  int
+}
+
+type entry struct {
+	doc    Document
+	terms  map[string]int
+	length int
+}
+
+// Index aman digunakan oleh beberapa goroutine
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*entry
+	postings map[string]map[string]int
+	totalLen int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*entry),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+func docKey(docType, id string) string {
+	return docType + "/" + id
+}
+
+// Upsert menambahkan atau mengganti dokumen
+func (idx *Index) Upsert(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(docKey(doc.Type, doc.ID))
+	idx.add(doc)
+}
+
+func (idx *Index) Delete(docType, id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(docKey(docType, id))
+}
+
+// ReplaceBranch mengganti seluruh dokumen milik branch dengan docs
+func (idx *Index) ReplaceBranch(branch string, docs []Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for key, e := range idx.docs {
+		if e.doc.Branch == branch {
+			idx.remove(key)
+		}
+	}
+	for _, doc := range docs {
+		idx.remove(docKey(doc.Type, doc.ID))
+		idx.add(doc)
+	}
+}
+
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func (idx *Index) add(doc Document) {
+	terms := make(map[string]int)
+	length := 0
+	for _, term := range Tokenize(doc.Title) {
+		terms[term] += titleBoost
+		length += titleBoost
+	}
+	for _, term := range Tokenize(doc.Body) {
+		terms[term]++
+		length++
+	}
+
+	key := docKey(doc.Type, doc.ID)
+	idx.docs[key] = &entry{doc: doc, terms: terms, length: length}
+	idx.totalLen += length
+	for term, tf := range terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]int)
+			idx.postings[term] = posting
+		}
+		posting[key] = tf
+	}
+}
+
+func (idx *Index) remove(key string) {
+	e, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for term := range e.terms {
+		delete(idx.postings[term], key)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= e.length
+	delete(idx.docs, key)
+}
+
+// Search mengembalikan hit terurut berdasarkan skor beserta jumlah seluruh dokumen yang cocok.
+// setiap kata pada query dicocokkan persis, sebagai prefix (kata terakhir) atau dengan salah ketik,
+// dokumen yang cocok dengan lebih banyak kata query mendapat skor lebih tinggi
+func (idx *Index) Search(q Query) ([]Hit, int) {
+	queryTerms := Tokenize(q.Text)
+	if len(queryTerms) == 0 {
+		return []Hit{}, 0
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	types := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 {
+		return []Hit{}, 0
+	}
+	total := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / total
+
+	// skor terbaik per dokumen untuk setiap kata query
+	scores := make(map[string][]float64)
+	for qi, queryTerm := range queryTerms {
+		for term, weight := range idx.expand(queryTerm, qi == len(queryTerms)-1) {
+			posting := idx.postings[term]
+			df := float64(len(posting))
+			idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+			for key, tf := range posting {
+				e := idx.docs[key]
+				if q.Branch != "" && e.doc.Branch != q.Branch {
+					continue
+				}
+				if len(types) != 0 && !types[e.doc.Type] {
+					continue
+				}
+				freq := float64(tf)
+				norm := freq * (bm25K1 + 1) / (freq + bm25K1*(1-bm25B+bm25B*float64(e.length)/avgLen))
+				score := weight * idf * norm
+
+				termScores, ok := scores[key]
+				if !ok {
+					termScores = make([]float64, len(queryTerms))
+					scores[key] = termScores
+				}
+				if score > termScores[qi] {
+					termScores[qi] = score
+				}
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for key, termScores := range scores {
+		var sum float64
+		matched := 0
+		for _, s := range termScores {
+			if s > 0 {
+				sum += s
+				matched++
+			}
+		}
+		hits = append(hits, Hit{
+			Document: idx.docs[key].doc,
+			Score:    sum * float64(matched) / float64(len(queryTerms)),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Time != hits[j].Time {
+			return hits[i].Time > hits[j].Time
+		}
+		return docKey(hits[i].Type, hits[i].ID) < docKey(hits[j].Type, hits[j].ID)
+	})
+
+	matchedTotal := len(hits)
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, matchedTotal
+}
+
+// expand mencari term pada index yang cocok dengan kata query beserta bobotnya
+func (idx *Index) expand(queryTerm string, prefix bool) map[string]float64 {
+	expansions := make(map[string]float64)
+	if _, ok := idx.postings[queryTerm]; ok {
+		expansions[queryTerm] = exactWeight
+	}
+
+	maxDist := maxEditDistance(queryTerm)
+	queryLen := len([]rune(queryTerm))
+	for term := range idx.postings {
+		if term == queryTerm {
+			continue
+		}
+		if prefix && queryLen >= minPrefixLen && strings.HasPrefix(term, queryTerm) {
+			expansions[term] = prefixWeight
+			continue
+		}
+		if maxDist == 0 {
+			continue
+		}
+		termLen := len([]rune(term))
+		if termLen-queryLen > maxDist || queryLen-termLen > maxDist {
+			continue
+		}
+		if dist := EditDistance(queryTerm, term); dist <= maxDist {
+			expansions[term] = fuzzyWeight / float64(dist)
+		}
+	}
+	return expansions
+}
+
+// maxEditDistance kata pendek dan angka (ip, nomor) harus persis
+func maxEditDistance(term string) int {
+	hasLetter := false
+	for _, r := range term {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			break
+		}
+	}
+	n := len([]rune(term))
+	switch {
+	case !hasLetter || n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Tokenize memecah teks menjadi kata huruf kecil. titik di antara angka dipertahankan
+// sehingga ip address seperti 10.0.0.1 tetap menjadi satu kata
+func Tokenize(text string) []string {
+	var tokens []string
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+	for _, field := range fields {
+		field = strings.Trim(field, ".")
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, ".") || isNumericDotted(field) {
+			tokens = append(tokens, field)
+			continue
+		}
+		for _, part := range strings.Split(field, ".") {
+			if part != "" {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	return tokens
+}
+
+func isNumericDotted(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// EditDistance jarak optimal string alignment antara a dan b,
+// pertukaran dua huruf bersebelahan (salah ketik umum) dihitung satu edit
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Snippet memotong text di sekitar kata query pertama yang ditemukan
+func Snippet(text string, query string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetLen {
+		return text
+	}
+
+	start := 0
+	lower := strings.ToLower(text)
+	for _, term := range Tokenize(query) {
+		if pos := strings.Index(lower, term); pos >= 0 {
+			start = len([]rune(lower[:pos])) - snippetLen/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetLen
+	if end > len(runes) {
+		end = len(runes)
+		start = end - snippetLen
+	}
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
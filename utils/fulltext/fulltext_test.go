@@ -0,0 +1,105 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testIndex() *Index {
+	idx := NewIndex()
+	idx.Upsert(Document{Type: "UNIT", ID: "1", Branch: "BANJARMASIN", Title: "CCTV Gerbang Utama", Body: "10.10.1.5 CCTV"})
+	idx.Upsert(Document{Type: "UNIT", ID: "2", Branch: "BANJARMASIN", Title: "PC Gudang", Body: "10.10.1.6 PC"})
+	idx.Upsert(Document{Type: "HISTORY", ID: "3", Branch: "BANJARMASIN", Title: "CCTV Gerbang Utama", Body: "kamera mati karena adaptor rusak, adaptor diganti", Time: 10})
+	idx.Upsert(Document{Type: "UNIT", ID: "4", Branch: "SAMPIT", Title: "CCTV Gerbang Sampit", Body: "10.20.1.5 CCTV"})
+	return idx
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"cctv", "gate", "10.0.0.1"}, Tokenize("CCTV-Gate (10.0.0.1)"))
+	assert.Equal(t, []string{"adaptor", "rusak", "diganti"}, Tokenize("adaptor.rusak. diganti."))
+	assert.Empty(t, Tokenize(" - "))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("gudang", "gudang"))
+	assert.Equal(t, 1, EditDistance("gudang", "gudan"))
+	assert.Equal(t, 2, EditDistance("adaptor", "adpatro"))
+	assert.Equal(t, 3, EditDistance("", "abc"))
+}
+
+func TestSearch_RankingAndScope(t *testing.T) {
+	idx := testIndex()
+
+	hits, total := idx.Search(Query{Text: "gerbang", Branch: "BANJARMASIN"})
+	assert.Equal(t, 2, total)
+	for _, hit := range hits {
+		assert.Equal(t, "BANJARMASIN", hit.Branch)
+	}
+
+	hits, total = idx.Search(Query{Text: "gerbang", Types: []string{"UNIT"}})
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "UNIT", hits[0].Type)
+
+	// dokumen yang cocok dengan semua kata berada di atas
+	hits, _ = idx.Search(Query{Text: "gerbang adaptor"})
+	assert.Equal(t, "3", hits[0].ID)
+}
+
+func TestSearch_FuzzyPrefixAndIP(t *testing.T) {
+	idx := testIndex()
+
+	// salah ketik
+	hits, total := idx.Search(Query{Text: "adaptr"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "3", hits[0].ID)
+
+	// prefix untuk kata terakhir
+	hits, total = idx.Search(Query{Text: "gud"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "2", hits[0].ID)
+
+	// ip persis dan sebagian
+	hits, total = idx.Search(Query{Text: "10.10.1.5"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "1", hits[0].ID)
+	_, total = idx.Search(Query{Text: "10.10.1"})
+	assert.Equal(t, 2, total)
+
+	// angka tidak dicocokkan secara fuzzy
+	_, total = idx.Search(Query{Text: "10.10.1.7 x"})
+	assert.Equal(t, 0, total)
+}
+
+func TestIndex_DeleteAndReplaceBranch(t *testing.T) {
+	idx := testIndex()
+	assert.Equal(t, 4, idx.Len())
+
+	idx.Delete("UNIT", "2")
+	_, total := idx.Search(Query{Text: "gudang"})
+	assert.Equal(t, 0, total)
+
+	idx.ReplaceBranch("BANJARMASIN", []Document{{Type: "STOCK", ID: "9", Branch: "BANJARMASIN", Title: "Adaptor 12V"}})
+	assert.Equal(t, 2, idx.Len())
+	hits, total := idx.Search(Query{Text: "adaptor"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "STOCK", hits[0].Type)
+
+	// upsert mengganti isi dokumen lama
+	idx.Upsert(Document{Type: "STOCK", ID: "9", Branch: "BANJARMASIN", Title: "Kabel UTP"})
+	_, total = idx.Search(Query{Text: "adaptor"})
+	assert.Equal(t, 0, total)
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, "pendek", Snippet("pendek", "x"))
+
+	long := ""
+	for i := 0; i < 40; i++ {
+		long += "lorem ipsum "
+	}
+	long += "adaptor rusak"
+	snippet := Snippet(long, "adaptor")
+	assert.Contains(t, snippet, "adaptor")
+	assert.True(t, len([]rune(snippet)) <= snippetLen+6)
+}
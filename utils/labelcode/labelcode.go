@@ -0,0 +1,71 @@
+// Package labelcode membuat dan memverifikasi kode yang dicetak pada QR label unit.
+// kode berisi jenis unit dan ID unit yang ditandatangani HMAC supaya label palsu atau
+// hasil ketikan manual tidak dapat digunakan untuk membuka unit lain
+package labelcode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	labelSecretKey = "LABEL_SECRET_KEY"
+	jwtSecretKey   = "SECRET_KEY"
+
+	// signatureLength jumlah byte hmac yang disimpan, dipotong supaya QR tetap kecil
+	signatureLength = 12
+	separator       = "."
+)
+
+var secret []byte
+
+// Init membaca secret dari LABEL_SECRET_KEY, jika kosong memakai SECRET_KEY.
+// mengganti secret membuat semua label yang sudah tercetak tidak berlaku
+func Init() {
+	key := os.Getenv(labelSecretKey)
+	if key == "" {
+		key = os.Getenv(jwtSecretKey)
+	}
+	if key == "" {
+		log.Fatal("Secret key label tidak boleh kosong, ENV : LABEL_SECRET_KEY atau SECRET_KEY")
+	}
+	secret = []byte(key)
+}
+
+// Sign menghasilkan kode dengan format KIND.ID.SIGNATURE
+func Sign(kind string, id string) string {
+	payload := strings.ToUpper(kind) + separator + id
+	return payload + separator + signature(payload)
+}
+
+// Verify memecah kode dan mencocokkan signature, mengembalikan kind dan ID unit
+func Verify(code string) (kind string, id primitive.ObjectID, apiErr rest_err.APIError) {
+	parts := strings.Split(strings.TrimSpace(code), separator)
+	if len(parts) != 3 {
+		return "", primitive.NilObjectID, rest_err.NewBadRequestError("Kode label tidak valid")
+	}
+
+	payload := parts[0] + separator + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signature(payload))) {
+		return "", primitive.NilObjectID, rest_err.NewBadRequestError("Kode label tidak valid")
+	}
+
+	oid, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return "", primitive.NilObjectID, rest_err.NewBadRequestError("Kode label tidak valid")
+	}
+	return parts[0], oid, nil
+}
+
+func signature(payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
@@ -0,0 +1,34 @@
+package labelcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret = []byte("rahasia")
+	id := primitive.NewObjectID()
+
+	code := Sign("cctv", id.Hex())
+	kind, oid, err := Verify(code)
+	assert.Nil(t, err)
+	assert.Equal(t, "CCTV", kind)
+	assert.Equal(t, id, oid)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	secret = []byte("rahasia")
+	code := Sign("PC", primitive.NewObjectID().Hex())
+
+	_, _, err := Verify("CCTV" + code[2:])
+	assert.NotNil(t, err)
+
+	_, _, err = Verify(Sign("PC", primitive.NewObjectID().Hex())[:10])
+	assert.NotNil(t, err)
+
+	secret = []byte("rahasia-baru")
+	_, _, err = Verify(code)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,225 @@
+// Package masterdata cache in-memory untuk master data (branch, sub category, lokasi, divisi, dll)
+// yang disimpan di database. validator dan option membaca dari cache ini sehingga tidak menyentuh database.
+// sebelum loader berhasil dijalankan cache berisi nilai awal dari package constants
+package masterdata
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muchlist/erru_utils_go/logger"
+	"github.com/muchlist/risa_restfull/constants/branches"
+	"github.com/muchlist/risa_restfull/constants/category"
+	"github.com/muchlist/risa_restfull/constants/checktype"
+	"github.com/muchlist/risa_restfull/constants/headerlang"
+	"github.com/muchlist/risa_restfull/constants/hwlist"
+	"github.com/muchlist/risa_restfull/constants/location"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/muchlist/risa_restfull/constants/stocklist"
+)
+
+const (
+	// refreshInterval cache dimuat ulang di background jika lebih lama dari ini,
+	// sehingga perubahan dari instance lain tetap terbaca
+	refreshInterval = 5 * time.Minute
+	loadTimeout     = 10 * time.Second
+)
+
+// Entry satu nilai master data. Branch hanya digunakan oleh LOCATION, kosong berarti berlaku untuk semua cabang.
+// Lang hanya digunakan oleh BRANCH sebagai bahasa header export
+type Entry struct {
+	Kind   string
+	Value  string
+	Branch string
+	Lang   string
+}
+
+// Loader mengembalikan seluruh master data yang sudah terurut
+type Loader func(ctx context.Context) ([]Entry, error)
+
+type cache struct {
+	mu         sync.RWMutex
+	entries    map[string][]Entry
+	loader     Loader
+	loadedAt   time.Time
+	refreshing int32
+}
+
+var store = newCache(Defaults())
+
+func newCache(entries []Entry) *cache {
+	c := &cache{}
+	c.set(entries)
+	return c
+}
+
+func (c *cache) set(entries []Entry) {
+	perKind := make(map[string][]Entry)
+	for _, e := range entries {
+		perKind[e.Kind] = append(perKind[e.Kind], e)
+	}
+	c.mu.Lock()
+	c.entries = perKind
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *cache) refresh(ctx context.Context) error {
+	c.mu.RLock()
+	loader := c.loader
+	c.mu.RUnlock()
+	if loader == nil {
+		return nil
+	}
+
+	entries, err := loader(ctx)
+	if err != nil {
+		return err
+	}
+	c.set(entries)
+	return nil
+}
+
+// get mengembalikan entry kind, memicu refresh di background jika cache sudah kadaluarsa
+func (c *cache) get(kind string) []Entry {
+	c.mu.RLock()
+	entries := c.entries[kind]
+	stale := c.loader != nil && time.Since(c.loadedAt) > refreshInterval
+	c.mu.RUnlock()
+
+	if stale && atomic.CompareAndSwapInt32(&c.refreshing, 0, 1) {
+		go func() {
+			defer atomic.StoreInt32(&c.refreshing, 0)
+			ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+			defer cancel()
+			if err := c.refresh(ctx); err != nil {
+				logger.Error("Gagal memuat ulang cache master data (get)", err)
+			}
+		}()
+	}
+	return entries
+}
+
+// SetLoader mendaftarkan sumber data cache, dipanggil sekali saat dependency disiapkan
+func SetLoader(loader Loader) {
+	store.mu.Lock()
+	store.loader = loader
+	store.mu.Unlock()
+}
+
+// Refresh memuat ulang cache dari loader secara langsung
+func Refresh(ctx context.Context) error {
+	return store.refresh(ctx)
+}
+
+// Invalidate menandai cache kadaluarsa sehingga pembacaan berikutnya memuat ulang dari loader
+func Invalidate() {
+	store.mu.Lock()
+	store.loadedAt = time.Time{}
+	store.mu.Unlock()
+}
+
+// Values nilai unik kind sesuai urutan, LOCATION dari seluruh cabang
+func Values(kind string) []string {
+	return uniqueValues(store.get(kind), func(Entry) bool { return true })
+}
+
+// ValuesFrom nilai kind milik branch beserta nilai yang berlaku untuk semua cabang
+func ValuesFrom(kind string, branch string) []string {
+	return uniqueValues(store.get(kind), func(e Entry) bool {
+		return e.Branch == "" || e.Branch == branch
+	})
+}
+
+// IntValues nilai kind yang berupa angka, nilai yang bukan angka dilewati
+func IntValues(kind string) []int {
+	values := Values(kind)
+	numbers := make([]int, 0, len(values))
+	for _, v := range values {
+		if n, err := strconv.Atoi(v); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+func IsAvailable(kind string, value string) bool {
+	for _, e := range store.get(kind) {
+		if e.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchLang bahasa header export cabang, kosong jika cabang tidak terdaftar atau belum diatur
+func BranchLang(branch string) string {
+	for _, e := range store.get(masterkind.Branch) {
+		if e.Value == branch {
+			return e.Lang
+		}
+	}
+	return ""
+}
+
+func uniqueValues(entries []Entry, match func(Entry) bool) []string {
+	seen := make(map[string]bool, len(entries))
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !match(e) || seen[e.Value] {
+			continue
+		}
+		seen[e.Value] = true
+		values = append(values, e.Value)
+	}
+	return values
+}
+
+// Defaults nilai awal master data dari package constants, digunakan untuk seed database
+// dan sebagai isi cache sebelum database terbaca
+func Defaults() []Entry {
+	var entries []Entry
+	add := func(kind string, values []string) {
+		for _, v := range values {
+			entries = append(entries, Entry{Kind: kind, Value: v})
+		}
+	}
+	addInt := func(kind string, values []int) {
+		for _, v := range values {
+			entries = append(entries, Entry{Kind: kind, Value: strconv.Itoa(v)})
+		}
+	}
+
+	for _, branch := range branches.GetBranchesAvailable() {
+		// header export cabang OTHER memakai bahasa inggris
+		branchLang := headerlang.ID
+		if branch == branches.Other {
+			branchLang = headerlang.EN
+		}
+		entries = append(entries, Entry{Kind: masterkind.Branch, Value: branch, Lang: branchLang})
+	}
+	add(masterkind.SubCategory, category.GetSubCategoryAvailable())
+	for _, branch := range branches.GetBranchesAvailable() {
+		for _, loc := range location.GetLocationAvailableFrom(branch) {
+			// Lainnya tersedia di semua cabang sehingga disimpan sekali tanpa branch
+			if loc == location.Lainnya {
+				continue
+			}
+			entries = append(entries, Entry{Kind: masterkind.Location, Value: loc, Branch: branch})
+		}
+	}
+	entries = append(entries, Entry{Kind: masterkind.Location, Value: location.Lainnya})
+	add(masterkind.Division, location.GetDivisionAvailable())
+	add(masterkind.StockCategory, stocklist.GetStockCategoryAvailable())
+	add(masterkind.CheckType, checktype.GetCheckTypeAvailable())
+	add(masterkind.CctvType, hwlist.GetCctvTypeAvailable())
+	add(masterkind.ComputerType, hwlist.GetComputerTypeAvailable())
+	add(masterkind.PcOS, hwlist.GetPCOSAvailable())
+	add(masterkind.PcProcessor, hwlist.GetPCProcessor())
+	addInt(masterkind.PcRam, hwlist.GetPCRam())
+	addInt(masterkind.PcHDD, hwlist.GetPCHDD())
+	return entries
+}
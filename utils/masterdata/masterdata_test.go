@@ -0,0 +1,66 @@
+package masterdata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/muchlist/risa_restfull/constants/branches"
+	"github.com/muchlist/risa_restfull/constants/location"
+	"github.com/muchlist/risa_restfull/constants/masterkind"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	// nilai awal sama dengan konstanta sebelumnya
+	assert.Equal(t, branches.GetBranchesAvailable(), Values(masterkind.Branch))
+	assert.ElementsMatch(t, location.GetLocationAvailable(), Values(masterkind.Location))
+	assert.ElementsMatch(t, location.GetLocationAvailableFrom(branches.Sampit), ValuesFrom(masterkind.Location, branches.Sampit))
+	assert.Equal(t, []int{0, 1000, 2000, 3000, 4000, 6000, 8000, 12000, 16000, 32000}, IntValues(masterkind.PcRam))
+	assert.True(t, IsAvailable(masterkind.Branch, branches.Kumai))
+	assert.False(t, IsAvailable(masterkind.Branch, "kumai"))
+}
+
+func TestRefresh(t *testing.T) {
+	c := newCache(Defaults())
+
+	// tanpa loader refresh tidak merubah isi cache
+	assert.Nil(t, c.refresh(context.Background()))
+	assert.Len(t, c.get(masterkind.Branch), len(branches.GetBranchesAvailable()))
+
+	c.loader = func(ctx context.Context) ([]Entry, error) {
+		return []Entry{
+			{Kind: masterkind.Branch, Value: "BANJARMASIN"},
+			{Kind: masterkind.Branch, Value: "PALANGKARAYA"},
+			{Kind: masterkind.Location, Value: "Dermaga", Branch: "PALANGKARAYA"},
+			{Kind: masterkind.Location, Value: "Lainnya"},
+		}, nil
+	}
+	assert.Nil(t, c.refresh(context.Background()))
+	assert.Len(t, c.get(masterkind.Branch), 2)
+	assert.Len(t, c.get(masterkind.Division), 0)
+
+	// loader gagal, isi cache terakhir dipertahankan
+	c.loader = func(ctx context.Context) ([]Entry, error) {
+		return nil, errors.New("database error")
+	}
+	assert.NotNil(t, c.refresh(context.Background()))
+	assert.Len(t, c.get(masterkind.Branch), 2)
+}
+
+func TestUniqueValues(t *testing.T) {
+	entries := []Entry{
+		{Kind: masterkind.Location, Value: "Dermaga", Branch: "SAMPIT"},
+		{Kind: masterkind.Location, Value: "Dermaga", Branch: "KUMAI"},
+		{Kind: masterkind.Location, Value: "Gudang", Branch: "KUMAI"},
+		{Kind: masterkind.Location, Value: "Lainnya"},
+	}
+	all := uniqueValues(entries, func(Entry) bool { return true })
+	assert.Equal(t, []string{"Dermaga", "Gudang", "Lainnya"}, all)
+
+	kumai := uniqueValues(entries, func(e Entry) bool { return e.Branch == "" || e.Branch == "KUMAI" })
+	assert.Equal(t, []string{"Dermaga", "Gudang", "Lainnya"}, kumai)
+
+	sampit := uniqueValues(entries, func(e Entry) bool { return e.Branch == "" || e.Branch == "SAMPIT" })
+	assert.Equal(t, []string{"Dermaga", "Lainnya"}, sampit)
+}
@@ -0,0 +1,219 @@
+package paging
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+
+	"github.com/muchlist/erru_utils_go/rest_err"
+	"github.com/muchlist/risa_restfull/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MaxSize batas atas ukuran halaman yang boleh diminta client
+	MaxSize = 1000
+
+	keyID = "_id"
+)
+
+// SortKey satu field pengurutan, Dir bernilai 1 (ascending) atau -1 (descending)
+type SortKey struct {
+	Field string
+	Dir   int
+}
+
+// token isi cursor sebelum di encode. Nilai disimpan sebagai bson.RawValue agar tipe data
+// (int64, string, ObjectID) tetap sama saat dipakai kembali sebagai filter
+type token struct {
+	Keys   []string        `bson:"k"`
+	Values []bson.RawValue `bson:"v"`
+	Prev   bool            `bson:"p"`
+}
+
+// Query menerjemahkan dto.PageRequest menjadi filter keyset, sort dan limit mongodb.
+// Urutan selalu diakhiri _id sehingga posisi cursor unik walaupun nilai field sort sama
+type Query struct {
+	sort  []SortKey
+	size  int64
+	all   bool
+	token *token
+}
+
+// New membuat Query dari permintaan halaman. cursor yang tidak dapat dibaca atau dibuat
+// untuk urutan yang berbeda akan mengembalikan bad request
+func New(page dto.PageRequest, defaultSize int64, sort ...SortKey) (*Query, rest_err.APIError) {
+	q := &Query{
+		size: page.Size,
+		all:  page.All,
+	}
+	if q.size <= 0 {
+		q.size = defaultSize
+	}
+	if q.size > MaxSize {
+		q.size = MaxSize
+	}
+
+	q.sort = append(q.sort, sort...)
+	if len(q.sort) == 0 || q.sort[len(q.sort)-1].Field != keyID {
+		dir := -1
+		if len(q.sort) != 0 {
+			dir = q.sort[len(q.sort)-1].Dir
+		}
+		q.sort = append(q.sort, SortKey{Field: keyID, Dir: dir})
+	}
+
+	if page.All || page.Cursor == "" {
+		return q, nil
+	}
+
+	t, err := decode(page.Cursor)
+	if err != nil || len(t.Keys) != len(q.sort) || len(t.Values) != len(q.sort) {
+		return nil, rest_err.NewBadRequestError("cursor tidak valid")
+	}
+	for i, key := range t.Keys {
+		if key != q.sort[i].Field {
+			return nil, rest_err.NewBadRequestError("cursor tidak valid")
+		}
+	}
+	q.token = t
+
+	return q, nil
+}
+
+// Filter menambahkan kondisi keyset pada filter. filter tanpa cursor dikembalikan apa adanya
+func (q *Query) Filter(filter bson.M) bson.M {
+	if q.token == nil {
+		return filter
+	}
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... sesuai arah masing-masing field
+	ors := bson.A{}
+	for i, key := range q.sort {
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[q.sort[j].Field] = q.token.Values[j]
+		}
+		cond[key.Field] = bson.M{q.operator(key.Dir): q.token.Values[i]}
+		ors = append(ors, cond)
+	}
+
+	and, _ := filter["$and"].(bson.A)
+	filter["$and"] = append(and, bson.M{"$or": ors})
+	return filter
+}
+
+// Options mengembalikan FindOptions dengan sort dan limit. limit dilebihkan satu dokumen
+// untuk mengetahui apakah masih ada halaman berikutnya
+func (q *Query) Options() *options.FindOptions {
+	opts := options.Find()
+	sort := bson.D{}
+	for _, key := range q.sort {
+		dir := key.Dir
+		if q.isPrev() {
+			dir = -dir
+		}
+		sort = append(sort, bson.E{Key: key.Field, Value: dir})
+	}
+	opts.SetSort(sort)
+	if !q.all {
+		opts.SetLimit(q.size + 1)
+	}
+	return opts
+}
+
+// CountTotal bernilai false jika total tidak perlu dihitung terpisah dari hasil query
+func (q *Query) CountTotal() bool {
+	return !q.all
+}
+
+// Info merapikan slice hasil query (memotong dokumen kelebihan dan membalik urutan untuk
+// halaman sebelumnya) lalu membuat PageInfo. list harus berupa pointer ke slice hasil decode
+func (q *Query) Info(list interface{}, total int64) dto.PageInfo {
+	v := reflect.ValueOf(list).Elem()
+
+	if q.all {
+		return dto.PageInfo{Size: int64(v.Len()), Total: int64(v.Len())}
+	}
+
+	hasMore := int64(v.Len()) > q.size
+	if hasMore {
+		v.Set(v.Slice(0, int(q.size)))
+	}
+	if q.isPrev() {
+		swap := reflect.Swapper(v.Interface())
+		for i, j := 0, v.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	info := dto.PageInfo{Size: int64(v.Len()), Total: total}
+	if v.Len() == 0 {
+		return info
+	}
+
+	// halaman berikutnya ada jika query maju masih menyisakan dokumen atau jika
+	// halaman ini didapat dengan mundur dari halaman setelahnya. berlaku sebaliknya untuk prev
+	if (!q.isPrev() && hasMore) || q.isPrev() {
+		info.NextCursor = q.encodeAt(v.Index(v.Len()-1).Interface(), false)
+	}
+	if (q.isPrev() && hasMore) || (!q.isPrev() && q.token != nil) {
+		info.PrevCursor = q.encodeAt(v.Index(0).Interface(), true)
+	}
+	return info
+}
+
+// Backward bernilai true jika halaman diambil mundur dari prev cursor. hasil query masih berurutan terbalik
+// sampai dirapikan oleh Info, digunakan pemanggil yang menggabungkan hasil beberapa collection sebelum Info
+func (q *Query) Backward() bool {
+	return q.isPrev()
+}
+
+func (q *Query) isPrev() bool {
+	return q.token != nil && q.token.Prev
+}
+
+// operator memilih pembanding keyset, dibalik saat bergerak ke halaman sebelumnya
+func (q *Query) operator(dir int) string {
+	if (dir >= 0) != q.isPrev() {
+		return "$gt"
+	}
+	return "$lt"
+}
+
+// encodeAt membuat cursor dari nilai field sort pada satu dokumen
+func (q *Query) encodeAt(item interface{}, prev bool) string {
+	raw, err := bson.Marshal(item)
+	if err != nil {
+		return ""
+	}
+
+	t := token{Prev: prev}
+	for _, key := range q.sort {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(key.Field, ".")...)
+		if err != nil {
+			return ""
+		}
+		t.Keys = append(t.Keys, key.Field)
+		t.Values = append(t.Values, value)
+	}
+
+	b, err := bson.Marshal(t)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(cursor string) (*token, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var t token
+	if err := bson.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
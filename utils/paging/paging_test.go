@@ -0,0 +1,100 @@
+package paging
+
+import (
+	"testing"
+
+	"github.com/muchlist/risa_restfull/dto"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type item struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UpdatedAt int64              `bson:"updated_at"`
+}
+
+func makeItems(n int) []item {
+	items := make([]item, n)
+	for i := range items {
+		items[i] = item{ID: primitive.NewObjectID(), UpdatedAt: int64(100 - i)}
+	}
+	return items
+}
+
+func TestFirstPage(t *testing.T) {
+	q, err := New(dto.PageRequest{Size: 2}, 100, SortKey{Field: "updated_at", Dir: -1})
+	assert.Nil(t, err)
+
+	filter := q.Filter(bson.M{})
+	assert.Empty(t, filter)
+
+	items := makeItems(3)
+	info := q.Info(&items, 10)
+
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, int64(2), info.Size)
+	assert.Equal(t, int64(10), info.Total)
+	assert.NotEmpty(t, info.NextCursor)
+	assert.Empty(t, info.PrevCursor)
+}
+
+func TestNextAndPrevCursor(t *testing.T) {
+	first, _ := New(dto.PageRequest{Size: 2}, 100, SortKey{Field: "updated_at", Dir: -1})
+	items := makeItems(3)
+	info := first.Info(&items, 3)
+
+	next, err := New(dto.PageRequest{Size: 2, Cursor: info.NextCursor}, 100, SortKey{Field: "updated_at", Dir: -1})
+	assert.Nil(t, err)
+
+	filter := next.Filter(bson.M{"branch": "BANJARMASIN"})
+	assert.Equal(t, "BANJARMASIN", filter["branch"])
+	assert.Len(t, filter["$and"], 1)
+	_, mErr := bson.Marshal(filter)
+	assert.Nil(t, mErr)
+
+	second := []item{{ID: primitive.NewObjectID(), UpdatedAt: 98}}
+	infoSecond := next.Info(&second, 3)
+	assert.Empty(t, infoSecond.NextCursor)
+	assert.NotEmpty(t, infoSecond.PrevCursor)
+
+	prev, err := New(dto.PageRequest{Size: 2, Cursor: infoSecond.PrevCursor}, 100, SortKey{Field: "updated_at", Dir: -1})
+	assert.Nil(t, err)
+	assert.Equal(t, bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}, prev.Options().Sort)
+	assert.True(t, prev.Backward())
+	assert.False(t, next.Backward())
+
+	// query mundur mengembalikan urutan terbalik, Info mengembalikan ke urutan semula
+	back := []item{items[1], items[0]}
+	infoBack := prev.Info(&back, 3)
+	assert.Equal(t, int64(100), back[0].UpdatedAt)
+	assert.NotEmpty(t, infoBack.NextCursor)
+	assert.Empty(t, infoBack.PrevCursor)
+}
+
+func TestInvalidCursor(t *testing.T) {
+	_, err := New(dto.PageRequest{Cursor: "bukan-cursor"}, 100, SortKey{Field: "updated_at", Dir: -1})
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status())
+
+	q, _ := New(dto.PageRequest{Size: 1}, 100, SortKey{Field: "updated_at", Dir: -1})
+	items := makeItems(2)
+	info := q.Info(&items, 2)
+
+	// cursor dari urutan lain tidak boleh dipakai
+	_, err = New(dto.PageRequest{Cursor: info.NextCursor}, 100, SortKey{Field: "name", Dir: 1})
+	assert.NotNil(t, err)
+}
+
+func TestAllAndMaxSize(t *testing.T) {
+	q, _ := New(dto.PageRequest{All: true}, 100)
+	assert.Nil(t, q.Options().Limit)
+
+	items := makeItems(5)
+	info := q.Info(&items, 0)
+	assert.Equal(t, int64(5), info.Total)
+	assert.Empty(t, info.NextCursor)
+
+	q, _ = New(dto.PageRequest{Size: 5000}, 100)
+	assert.Equal(t, int64(MaxSize+1), *q.Options().Limit)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/johnfercher/maroto/pkg/pdf"
 	"github.com/johnfercher/maroto/pkg/props"
 	"github.com/muchlist/risa_restfull/constants/enum"
+	"github.com/muchlist/risa_restfull/constants/slastate"
 	"github.com/muchlist/risa_restfull/dto"
 	"github.com/muchlist/risa_restfull/utils/timegen"
 	"strconv"
@@ -142,7 +143,7 @@ func buildHeading(m pdf.Maroto, subtitle string) error {
 }
 
 func buildHistoryList(m pdf.Maroto, dataList []dto.HistoryUnwindResponse, title string, customColor color.Color) {
-	tableHeading := []string{"Nama", "Kategori", "Keterangan", "Solusi", "Status", "Update", "Oleh"}
+	tableHeading := []string{"Nama", "Kategori", "Keterangan", "Solusi", "Status", "Prioritas", "SLA", "Update", "Oleh"}
 
 	var contents [][]string
 	for _, data := range dataList {
@@ -157,6 +158,8 @@ func buildHistoryList(m pdf.Maroto, dataList []dto.HistoryUnwindResponse, title
 			data.Updates.Problem,
 			data.Updates.ProblemResolve,
 			enum.GetProgressString(data.Updates.CompleteStatus),
+			priorityString(data.Priority),
+			slaString(data.SLA),
 			updateAt,
 			strings.ToLower(data.UpdatedBy)},
 		)
@@ -182,11 +185,11 @@ func buildHistoryList(m pdf.Maroto, dataList []dto.HistoryUnwindResponse, title
 	m.TableList(tableHeading, contents, props.TableList{
 		HeaderProp: props.TableListContent{
 			Size:      9,
-			GridSizes: []uint{2, 1, 3, 3, 1, 1, 1},
+			GridSizes: []uint{2, 1, 2, 2, 1, 1, 1, 1, 1},
 		},
 		ContentProp: props.TableListContent{
 			Size:      9,
-			GridSizes: []uint{2, 1, 3, 3, 1, 1, 1},
+			GridSizes: []uint{2, 1, 2, 2, 1, 1, 1, 1, 1},
 		},
 		Align:                consts.Left,
 		AlternatedBackground: &lightPurpleColor,
@@ -195,6 +198,33 @@ func buildHistoryList(m pdf.Maroto, dataList []dto.HistoryUnwindResponse, title
 	})
 }
 
+func priorityString(prior string) string {
+	if prior == "" {
+		return "-"
+	}
+	return strings.ToLower(prior)
+}
+
+// slaString status SLA ringkas untuk tabel, history lama tanpa SLA ditampilkan "-"
+func slaString(sla dto.HistorySLA) string {
+	switch sla.State {
+	case slastate.OnTrack:
+		return "on track"
+	case slastate.ResponseBreached:
+		return "telat respon"
+	case slastate.ResolveBreached:
+		return "lewat target"
+	case slastate.Paused:
+		return "ditunda"
+	case slastate.Met:
+		return "tercapai"
+	case slastate.Missed:
+		return "terlewat"
+	default:
+		return "-"
+	}
+}
+
 func buildCheckList(m pdf.Maroto, checkList []dto.Check) {
 	tableHeading := []string{"Judul", "Shift", "Lokasi", "Keterangan", "Problem", "Cek", "Oleh"}
 
@@ -1,6 +1,7 @@
 package pdfgen
 
 import (
+	"fmt"
 	"github.com/johnfercher/maroto/pkg/color"
 	"github.com/johnfercher/maroto/pkg/consts"
 	"github.com/johnfercher/maroto/pkg/pdf"
@@ -149,3 +150,13 @@ func getDarkColor() color.Color {
 		Blue:  36,
 	}
 }
+
+// durationString mengubah detik menjadi teks jam dan menit
+func durationString(seconds int64) string {
+	hour := seconds / 3600
+	minute := (seconds % 3600) / 60
+	if hour == 0 {
+		return fmt.Sprintf("%d menit", minute)
+	}
+	return fmt.Sprintf("%d jam %d menit", hour, minute)
+}
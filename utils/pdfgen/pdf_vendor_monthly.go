@@ -19,6 +19,8 @@ type PDFReqMonth struct {
 	HistoryList dto.HistoryUnwindResponseList
 	Start       int64
 	End         int64
+	// Uptime ketersediaan cctv berdasarkan ping history, nil jika tidak tersedia
+	Uptime *dto.UptimeBranchSummary
 }
 
 func GeneratePDFVendorMonthly(
@@ -160,6 +162,17 @@ func GeneratePDFVendorMonthly(
 		textBody(m, fmt.Sprintf("- Pencadangan konfigurasi : %d dari %d perangkat jaringan dicadangkan (data terlampir)", configUpdated, totalConfig), 5)
 	})
 
+	if data.Uptime != nil && data.Uptime.ObservedSeconds != 0 {
+		buildTitleHeadingView(m, " Ketersediaan CCTV", getDarkGreyColorLight())
+		m.Row(5, func() {
+			textBody(m, fmt.Sprintf("- Ketersediaan : %.2f%% dari %d unit yang termonitor ping", data.Uptime.Uptime, data.Uptime.UnitCount), 5)
+		})
+		m.Row(5, func() {
+			textBody(m, fmt.Sprintf("- Gangguan : %d kali, MTBF %s, MTTR %s", data.Uptime.Failures,
+				durationString(data.Uptime.MTBF), durationString(data.Uptime.MTTR)), 5)
+		})
+	}
+
 	// NEW PAGE ================================================================= \\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\
 	m.AddPage()
 	m.SetPageMargins(5, 10, 5)
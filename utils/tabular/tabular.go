@@ -0,0 +1,257 @@
+// Package tabular membaca file CSV dan XLSX menjadi baris dengan key nama kolom, serta menulis keduanya secara stream.
+// XLSX dibaca langsung dari struktur zip + xml sehingga tidak memerlukan library tambahan,
+// hanya sheet pertama dan nilai sel (bukan formula) yang dibaca
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Row satu baris data. Line adalah nomor baris pada file (header = baris 1)
+type Row struct {
+	Line   int
+	Values map[string]string
+}
+
+// Get mengembalikan nilai kolom yang sudah di trim
+func (r Row) Get(key string) string {
+	return strings.TrimSpace(r.Values[key])
+}
+
+var ErrUnsupported = errors.New("format file tidak didukung, gunakan .csv atau .xlsx")
+
+// Read membaca file berdasarkan ekstensi nama file
+func Read(fileName string, r io.Reader) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return ReadCSV(r)
+	case ".xlsx":
+		return ReadXLSX(r)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// ReadCSV membaca csv dengan pemisah koma atau titik koma (default excel locale indonesia)
+func ReadCSV(r io.Reader) ([]Row, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return toRows(records), nil
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref       string `xml:"r,attr"`
+			Type      string `xml:"t,attr"`
+			Value     string `xml:"v"`
+			InlineStr string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX membaca sheet pertama dari file xlsx
+func ReadXLSX(r io.Reader) ([]Row, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("file xlsx tidak valid: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	strs := make([]string, len(shared.Items))
+	for i, item := range shared.Items {
+		if len(item.Runs) == 0 {
+			strs[i] = item.Text
+			continue
+		}
+		var sb strings.Builder
+		for _, run := range item.Runs {
+			sb.WriteString(run.Text)
+		}
+		strs[i] = sb.String()
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, errors.New("file xlsx tidak memiliki sheet")
+	}
+	var sheet xlsxSheet
+	if err := decodeXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		record := make([]string, 0, len(row.Cells))
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscanf(cell.Value, "%d", &idx); err == nil && idx < len(strs) {
+					value = strs[idx]
+				}
+			case "inlineStr":
+				value = cell.InlineStr
+			}
+			record[col] = value
+		}
+		records = append(records, record)
+	}
+	return toRows(records), nil
+}
+
+// firstSheetPath mencari lokasi sheet pertama melalui workbook, fallback ke sheet1.xml
+func firstSheetPath(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	wbFile, okWb := files["xl/workbook.xml"]
+	relFile, okRel := files["xl/_rels/workbook.xml.rels"]
+	if !okWb || !okRel || decodeXML(wbFile, &wb) != nil || decodeXML(relFile, &rels) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != wb.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// columnIndex merubah referensi sel (contoh "AB12") menjadi index kolom berbasis 0
+func columnIndex(ref string) int {
+	idx := 0
+	for _, ch := range strings.ToUpper(ref) {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		idx = idx*26 + int(ch-'A'+1)
+	}
+	return idx - 1
+}
+
+// toRows menjadikan baris pertama sebagai header, baris kosong dilewati
+func toRows(records [][]string) []Row {
+	if len(records) == 0 {
+		return []Row{}
+	}
+
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = normalizeHeader(h)
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for i, record := range records[1:] {
+		values := make(map[string]string, len(header))
+		empty := true
+		for j, value := range record {
+			if j >= len(header) || header[j] == "" {
+				continue
+			}
+			values[header[j]] = value
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, Row{Line: i + 2, Values: values})
+	}
+	return rows
+}
+
+// normalizeHeader menyamakan penulisan header, "Inventory Number" menjadi "inventory_number"
+func normalizeHeader(h string) string {
+	h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	h = strings.ToLower(h)
+	return strings.Join(strings.Fields(h), "_")
+}
@@ -0,0 +1,92 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := "Name,Inventory Number,IP\nCCTV GATE,INV-01,10.0.0.1\n,,\nCCTV POS,INV-02,\n"
+
+	rows, err := Read("data.csv", strings.NewReader(data))
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "INV-01", rows[0].Get("inventory_number"))
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, "CCTV POS", rows[1].Get("name"))
+	assert.Equal(t, 4, rows[1].Line)
+}
+
+func TestReadCSVSemicolon(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader("\xef\xbb\xbfname;location\nPC 1;KANTOR\n"))
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "KANTOR", rows[0].Get("location"))
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(content))
+	}
+	write("xl/workbook.xml", `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Data" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	write("xl/_rels/workbook.xml.rels", `<Relationships><Relationship Id="rId1" Target="worksheets/data.xml"/></Relationships>`)
+	write("xl/sharedStrings.xml", `<sst><si><t>name</t></si><si><t>ip</t></si><si><r><t>CCTV </t></r><r><t>GATE</t></r></si></sst>`)
+	write("xl/worksheets/data.xml", `<worksheet><sheetData>
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+		<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2" t="inlineStr"><is><t>10.0.0.1</t></is></c></row>
+	</sheetData></worksheet>`)
+	_ = zw.Close()
+
+	rows, err := Read("data.xlsx", &buf)
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "CCTV GATE", rows[0].Get("name"))
+	assert.Equal(t, "10.0.0.1", rows[0].Get("ip"))
+}
+
+func TestReadUnsupported(t *testing.T) {
+	_, err := Read("data.pdf", strings.NewReader(""))
+	assert.Equal(t, ErrUnsupported, err)
+}
+
+func TestColumnIndex(t *testing.T) {
+	assert.Equal(t, 0, columnIndex("A1"))
+	assert.Equal(t, 27, columnIndex("AB12"))
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, err := NewWriter(format, &buf)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Write([]string{"Name", "IP", "Note"}))
+		assert.Nil(t, w.Write([]string{"CCTV <GATE> & POS", "", "baris, dua"}))
+		assert.Nil(t, w.Close())
+
+		rows, err := Read("export."+format, &buf)
+		assert.Nil(t, err, format)
+		assert.Len(t, rows, 1, format)
+		assert.Equal(t, "CCTV <GATE> & POS", rows[0].Get("name"), format)
+		assert.Equal(t, "", rows[0].Get("ip"), format)
+		assert.Equal(t, "baris, dua", rows[0].Get("note"), format)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "text/csv; charset=utf-8", ContentType("CSV"))
+	assert.Equal(t, "", ContentType("pdf"))
+}
+
+func TestColumnName(t *testing.T) {
+	for _, i := range []int{0, 25, 26, 51, 701, 702} {
+		assert.Equal(t, i, columnIndex(columnName(i)))
+	}
+	assert.Equal(t, "AA", columnName(26))
+}